@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"log"
 	"net/http"
 	"os"
@@ -23,6 +24,11 @@ func main() {
 		log.Fatalf("wire failed: %v", err)
 	}
 
+	// the metrics of the container, next to the package ones on /debug/vars
+	for name, v := range container.Metrics() {
+		expvar.Publish(name, v)
+	}
+
 	// I created a container.HTTP handler to remove depency of gin
 	// If necessary to change in the future
 	srv := &http.Server{
@@ -33,6 +39,26 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// reports the switches to scheduled pack sizes until shutdown
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	if container.Watcher != nil {
+		go container.Watcher.Run(background)
+	}
+	// keeps the pack sizes of the catalogue service fresh
+	if container.Remote != nil {
+		go container.Remote.Run(background)
+	}
+	// removes the expired Idempotency-Key records
+	if container.IdemFiles != nil {
+		go container.IdemFiles.Run(background)
+	}
+	// sends the changes of the pack list to the webhooks
+	if container.Webhooks != nil {
+		go container.Webhooks.Run(background)
+		go container.Changes.Run(background)
+	}
+
 	// start
 	go func() {
 		log.Printf("listening on %s", cfg.HTTPAddr)
@@ -47,6 +73,7 @@ func main() {
 
 	<-stop
 	log.Print("shutting down...")
+	stopBackground()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -54,5 +81,8 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("graceful shutdown failed: %v", err)
 	}
+	if container.Audit != nil {
+		_ = container.Audit.Close()
+	}
 	log.Print("bye")
 }
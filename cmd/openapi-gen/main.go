@@ -0,0 +1,24 @@
+// Command openapi-gen writes the OpenAPI spec generated from the HTTP route
+// table (see ginadapter.OpenAPISpec). Used by go generate in docs/api/v1.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	ginadapter "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/gin"
+)
+
+func main() {
+	out := flag.String("o", "openapi.yaml", "output file")
+	flag.Parse()
+
+	spec, err := ginadapter.OpenAPISpec()
+	if err != nil {
+		log.Fatalf("build spec: %v", err)
+	}
+	if err := os.WriteFile(*out, spec, 0o644); err != nil {
+		log.Fatalf("write spec: %v", err)
+	}
+}
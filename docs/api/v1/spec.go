@@ -0,0 +1,10 @@
+// Package v1 ships the OpenAPI spec of /v1. The YAML is generated from the
+// HTTP route table and the DTOs; a test fails when it is stale.
+package v1
+
+import _ "embed"
+
+//go:generate go run ../../../cmd/openapi-gen -o openapi.yaml
+
+//go:embed openapi.yaml
+var OpenAPI []byte
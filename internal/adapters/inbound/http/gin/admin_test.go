@@ -0,0 +1,206 @@
+package ginadapter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/idempotency"
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/audit"
+	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
+)
+
+func TestAdminAuth(t *testing.T) {
+	h := contractHandler(t, defaultSizes, ValidateResponses)
+	body := `{"effectiveFrom":"2026-03-01T00:00:00Z","sizes":[500]}`
+
+	tests := []struct {
+		name       string
+		auth       string
+		wantStatus int
+	}{
+		{"no header", "", http.StatusUnauthorized},
+		{"another scheme", "Basic " + contractAdminToken, http.StatusUnauthorized},
+		{"token prefix", "Bearer " + contractAdminToken[:5], http.StatusUnauthorized},
+		{"valid", "Bearer " + contractAdminToken, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/packsizes/schedule", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status got=%d want=%d body=%s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusUnauthorized {
+				if rec.Header().Get("WWW-Authenticate") == "" || !strings.Contains(rec.Body.String(), presenter.CodeUnauthorized) {
+					t.Fatalf("want a bearer challenge, got headers=%v body=%s", rec.Header(), rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestAdminRoutes_DebugVars(t *testing.T) {
+	h := contractHandler(t, defaultSizes, ValidateOff)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status without token got=%d want=401", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+	req.Header.Set("Authorization", "Bearer "+contractAdminToken)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"memstats"`) {
+		t.Fatalf("status got=%d body=%.100s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAdminRoutes_NeedAToken(t *testing.T) {
+	ctrl := ctr.NewController(&fakeCalc{}, &fakeGet{})
+	schedule, err := usecases.NewSchedulePackSizes(defaultSizes, contractNow)
+	if err != nil {
+		t.Fatalf("NewSchedulePackSizes: %v", err)
+	}
+	ctrl.Schedule = schedule
+	h := BuildHandler(ctrl)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/v1/admin/packsizes/schedule", strings.NewReader(`{}`)),
+		httptest.NewRequest(http.MethodGet, "/debug/vars", nil),
+	} {
+		req.Header.Set("Authorization", "Bearer ")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s %s without WithAdminToken: status got=%d want=404", req.Method, req.URL.Path, rec.Code)
+		}
+	}
+}
+
+func TestAdminRoutes_PackSizeParam(t *testing.T) {
+	h := contractHandler(t, defaultSizes, ValidateOff)
+	for _, path := range []string{"/v1/admin/packsizes/abc/disable", "/v1/admin/packsizes/0/enable"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer "+contractAdminToken)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"field":"size"`) {
+			t.Fatalf("%s: status got=%d body=%s", path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// recordedCalls keeps the entries appended by AuditCalls.
+type recordedCalls struct{ entries []audit.Entry }
+
+func (r *recordedCalls) Append(_ context.Context, e audit.Entry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func (r *recordedCalls) Entries(context.Context, audit.Filter) ([]audit.Entry, error) {
+	return r.entries, nil
+}
+
+func TestAdminAuthTokens_AuditCalls(t *testing.T) {
+	ctrl := ctr.NewController(&fakeCalc{}, &fakeGet{})
+	schedule, err := usecases.NewSchedulePackSizes(defaultSizes, contractNow)
+	if err != nil {
+		t.Fatalf("NewSchedulePackSizes: %v", err)
+	}
+	ctrl.Schedule = schedule
+	calls := &recordedCalls{}
+	h := BuildHandler(ctrl,
+		WithAdminTokens(map[string]string{"alice": "alice-token", "bob": "bob-token"}),
+		WithAuditLog(calls),
+		WithIdempotency(idempotency.NewMemoryStore(idempotency.Options{}), time.Hour),
+	)
+
+	send := func(method, path, token, requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(`{"effectiveFrom":"2026-03-01T00:00:00Z","sizes":[500]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(IdempotencyKeyHeader, "schedule-1")
+		if requestID != "" {
+			req.Header.Set(RequestIDHeader, requestID)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	scheduled := send(http.MethodPost, "/v1/admin/packsizes/schedule", "alice-token", "")
+	replayed := send(http.MethodPost, "/v1/admin/packsizes/schedule", "alice-token", "lb-42")
+	vars := send(http.MethodGet, "/debug/vars", "bob-token", "")
+	denied := send(http.MethodPost, "/v1/admin/packsizes/schedule", "carol-token", "")
+	if scheduled.Code != http.StatusCreated || replayed.Code != http.StatusCreated || vars.Code != http.StatusOK || denied.Code != http.StatusUnauthorized {
+		t.Fatalf("status got=%d,%d,%d,%d want=201,201,200,401", scheduled.Code, replayed.Code, vars.Code, denied.Code)
+	}
+	if replayed.Header().Get(ReplayedHeader) != "true" {
+		t.Fatalf("the second call must be a replay")
+	}
+
+	// the replay is recorded too; the read of /debug/vars is not
+	want := []audit.Entry{
+		{
+			Action: audit.ActionAdminCall, Actor: "alice", SourceIP: "192.0.2.1", RequestID: scheduled.Header().Get(RequestIDHeader),
+			Method: http.MethodPost, Path: "/v1/admin/packsizes/schedule", Status: http.StatusCreated,
+		},
+		{
+			Action: audit.ActionAdminCall, Actor: "alice", SourceIP: "192.0.2.1", RequestID: "lb-42",
+			Method: http.MethodPost, Path: "/v1/admin/packsizes/schedule", Status: http.StatusCreated,
+		},
+	}
+	if len(want[0].RequestID) != 32 || replayed.Header().Get(RequestIDHeader) != "lb-42" {
+		t.Fatalf("request IDs got %q and %q", want[0].RequestID, replayed.Header().Get(RequestIDHeader))
+	}
+	if !reflect.DeepEqual(calls.entries, want) {
+		t.Fatalf("audit got %+v\nwant %+v", calls.entries, want)
+	}
+}
+
+func TestAuditCalls_TrustedProxies(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"forwarded address ignored by default", nil, "192.0.2.1"},
+		{"from a trusted proxy", []Option{WithTrustedProxies([]string{"192.0.2.0/24"})}, "203.0.113.9"},
+		{"from another proxy", []Option{WithTrustedProxies([]string{"10.0.0.1"})}, "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := &recordedCalls{}
+			opts := append([]Option{WithAdminToken("t"), WithAuditLog(calls)}, tt.opts...)
+			ctrl := ctr.NewController(&fakeCalc{}, &fakeGet{})
+			ctrl.Schedule, _ = usecases.NewSchedulePackSizes(defaultSizes, contractNow)
+			h := BuildHandler(ctrl, opts...)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/packsizes/schedule", strings.NewReader("{}")) // from 192.0.2.1
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer t")
+			req.Header.Set("X-Forwarded-For", "203.0.113.9")
+			req.Header.Set("X-Real-IP", "203.0.113.9")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if len(calls.entries) != 1 || calls.entries[0].SourceIP != tt.want {
+				t.Fatalf("entries %+v, want source IP %s", calls.entries, tt.want)
+			}
+		})
+	}
+}
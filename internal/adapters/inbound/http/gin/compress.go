@@ -0,0 +1,190 @@
+package ginadapter
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultCompressMinSize: below it compression costs more than it saves.
+const DefaultCompressMinSize = 1024
+
+// encoder is the common API of gzip.Writer and brotli.Writer.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	"br": {New: func() any { return brotli.NewWriterLevel(nil, brotli.DefaultCompression) }},
+	"gzip": {New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}},
+}
+
+// Compression compresses JSON responses (application/json, problem+json,
+// NDJSON) with br or gzip, as negotiated by Accept-Encoding. The body is
+// buffered up to minSize: smaller responses are sent as is. A Flush (streaming)
+// commits to compression right away.
+func Compression(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		w := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       negotiateEncoding(c.GetHeader("Accept-Encoding"), "br", "gzip"),
+			minSize:        minSize,
+		}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			c.Writer = w.ResponseWriter // e.g. gin's default 404 body is written after the chain
+		}()
+		c.Next()
+	}
+}
+
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string // "" when the client accepts neither
+	minSize  int
+
+	buf     []byte
+	decided bool
+	enc     encoder // nil: passthrough
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided && (w.encoding == "" || !compressibleJSON(w.Header().Get("Content-Type"))) {
+		// nothing to compress: no need to buffer
+		if err := w.decide(false); err != nil {
+			return 0, err
+		}
+	}
+	if w.decided {
+		return w.write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.minSize {
+		if err := w.decide(false); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(true)
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Unwrap lets http.ResponseController reach the connection (deadlines).
+func (w *compressWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *compressWriter) write(p []byte) (int, error) {
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// decide picks the encoding once the size (or the streaming) is known,
+// adjusts the headers and releases the buffer.
+func (w *compressWriter) decide(streaming bool) error {
+	w.decided = true
+	h := w.Header()
+	if compressibleJSON(h.Get("Content-Type")) && h.Get("Content-Encoding") == "" {
+		h.Add("Vary", "Accept-Encoding")
+		if w.encoding != "" && compressibleStatus(w.Status()) && (streaming || len(w.buf) >= w.minSize) {
+			h.Set("Content-Encoding", w.encoding)
+			h.Del("Content-Length")
+			if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+				h.Set("ETag", "W/"+etag) // the bytes differ from the identity body
+			}
+			w.enc = encoderPools[w.encoding].Get().(encoder)
+			w.enc.Reset(w.ResponseWriter)
+		}
+	}
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.write(buf)
+	return err
+}
+
+func (w *compressWriter) finish() {
+	if !w.decided && len(w.buf) > 0 {
+		_ = w.decide(false)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		encoderPools[w.encoding].Put(w.enc)
+		w.enc = nil
+	}
+}
+
+func compressibleJSON(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || mediaType == "application/x-ndjson" || strings.HasSuffix(mediaType, "+json")
+}
+
+func compressibleStatus(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent &&
+		status != http.StatusPartialContent && status != http.StatusNotModified
+}
+
+// negotiateEncoding returns the supported coding with the highest q-value in
+// Accept-Encoding (ties: the order of supported), or "" when none is accepted.
+func negotiateEncoding(header string, supported ...string) string {
+	if header == "" {
+		return ""
+	}
+	q := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		weight := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			weight = v
+		}
+		q[coding] = weight
+	}
+
+	best, bestQ := "", 0.0
+	for _, s := range supported {
+		weight, ok := q[s]
+		if !ok {
+			weight, ok = q["*"]
+		}
+		if ok && weight > bestQ {
+			best, bestQ = s, weight
+		}
+	}
+	return best
+}
@@ -0,0 +1,176 @@
+package ginadapter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+)
+
+func manySizes(n int) []int {
+	sizes := make([]int, n)
+	for i := range sizes {
+		sizes[i] = 1000 + i
+	}
+	return sizes
+}
+
+func newCompressedHandler(sizes []int, opts ...Option) http.Handler {
+	get := &fakeGet{out: uc.GetPackSizesOutput{Sizes: sizes, Version: "v1"}}
+	opts = append([]Option{WithCompression(DefaultCompressMinSize)}, opts...)
+	return BuildHandler(ctr.NewController(&fakeCalc{}, get), opts...)
+}
+
+func serveEncoded(h http.Handler, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder) []byte {
+	t.Helper()
+	var r io.Reader = rec.Body
+	switch rec.Header().Get("Content-Encoding") {
+	case "gzip":
+		zr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("gzip: %v", err)
+		}
+		r = zr
+	case "br":
+		r = brotli.NewReader(rec.Body)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decode %s: %v", rec.Header().Get("Content-Encoding"), err)
+	}
+	return b
+}
+
+func TestCompression_Negotiation(t *testing.T) {
+	sizes := manySizes(400)
+	h := newCompressedHandler(sizes)
+
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"br", "br"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"br;q=0, gzip;q=0", ""},
+		{"identity", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			rec := serveEncoded(h, "/v1/packsizes", tt.acceptEncoding)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status got=%d", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.want {
+				t.Fatalf("content-encoding got=%q want=%q", got, tt.want)
+			}
+			if rec.Header().Get("Vary") != "Accept-Encoding" {
+				t.Fatalf("missing Vary: %v", rec.Header())
+			}
+			var body struct {
+				Sizes []int `json:"sizes"`
+			}
+			if err := json.Unmarshal(decodeBody(t, rec), &body); err != nil || len(body.Sizes) != len(sizes) {
+				t.Fatalf("body: err=%v sizes=%d", err, len(body.Sizes))
+			}
+		})
+	}
+}
+
+func TestCompression_SmallBodiesAreNotCompressed(t *testing.T) {
+	rec := serveEncoded(newCompressedHandler([]int{250, 500}), "/v1/packsizes", "gzip, br")
+	if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != `{"sizes":[250,500],"packs":[]}` {
+		t.Fatalf("headers=%v body=%q", rec.Header(), rec.Body.String())
+	}
+}
+
+func TestCompression_OnlyJSON(t *testing.T) {
+	h := newCompressedHandler(nil)
+
+	// the Swagger UI bundle is large JavaScript: not this middleware's business
+	rec := serveEncoded(h, "/docs/assets/swagger-ui-bundle.js", "gzip")
+	if rec.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("javascript must not be compressed: %v", rec.Header())
+	}
+
+	// the JSON spec is, and its strong ETag becomes weak
+	rec = serveEncoded(h, "/docs/openapi.json", "gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Content-Length") != "" {
+		t.Fatalf("spec headers: %v", rec.Header())
+	}
+	if etag := rec.Header().Get("ETag"); len(etag) < 2 || etag[:2] != "W/" {
+		t.Fatalf("etag got=%q want weak", etag)
+	}
+	if !json.Valid(decodeBody(t, rec)) {
+		t.Fatalf("invalid decoded spec")
+	}
+
+	// gin's default 404 is still written after the chain
+	rec = serveEncoded(h, "/nope", "gzip")
+	if rec.Code != http.StatusNotFound || rec.Body.String() != "404 page not found" {
+		t.Fatalf("404: status=%d body=%q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCompression_NotModifiedHasNoBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/v1/packsizes", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", `W/"v1"`)
+	rec := httptest.NewRecorder()
+	newCompressedHandler(manySizes(400)).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("status=%d headers=%v body=%d bytes", rec.Code, rec.Header(), rec.Body.Len())
+	}
+}
+
+func TestCompression_WithResponseValidation(t *testing.T) {
+	// the validator sees the plain body, the client gets the compressed one
+	rec := serveEncoded(newCompressedHandler(manySizes(400), WithOpenAPIValidation(ValidateResponses)), "/v1/packsizes", "br")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Encoding") != "br" {
+		t.Fatalf("status=%d headers=%v body=%q", rec.Code, rec.Header(), rec.Body.String())
+	}
+	if !bytes.HasPrefix(decodeBody(t, rec), []byte(`{"sizes":[1000,`)) {
+		t.Fatalf("unexpected decoded body")
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"deflate, gzip;q=0.8", "gzip"},
+		{"*", "br"},
+		{"*;q=0.5, br;q=0", "gzip"},
+		{"gzip;q=0", ""},
+		{"gzip; q=0.0", ""},
+		{"br;q=0.4, gzip;q=0.9", "gzip"},
+		{"gzip;q=abc", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header, "br", "gzip"); got != tt.want {
+			t.Fatalf("negotiateEncoding(%q) got=%q want=%q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package ginadapter
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// conditionalGET sets the validators of the response and reports whether the
+// client copy is still fresh, in which case it answers 304 without body.
+// If-None-Match wins over If-Modified-Since (RFC 9110, section 13.2.2).
+func conditionalGET(c *gin.Context, etag string, lastModified time.Time, cacheControl string) bool {
+	h := c.Writer.Header()
+	if etag != "" {
+		h.Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		h.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if cacheControl != "" {
+		h.Set("Cache-Control", cacheControl)
+	}
+
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+	fresh := false
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		fresh = etag != "" && etagListMatches(inm, etag)
+	} else if ims := c.GetHeader("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		// Last-Modified has a resolution of one second
+		fresh = err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	if fresh {
+		c.Status(http.StatusNotModified)
+	}
+	return fresh
+}
+
+// etagListMatches is the weak comparison of If-None-Match: W/"x" matches "x".
+func etagListMatches(list, etag string) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// weakETag builds a weak validator: the body is only semantically equal
+// across encodings (see Compression), not byte-for-byte. No id, no ETag.
+func weakETag(id string) string {
+	if id == "" {
+		return ""
+	}
+	return `W/"` + id + `"`
+}
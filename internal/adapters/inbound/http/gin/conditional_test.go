@@ -0,0 +1,96 @@
+package ginadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+)
+
+var packsUpdatedAt = time.Date(2025, 9, 1, 10, 30, 15, 500, time.UTC)
+
+func newVersionedHandler(opts ...Option) http.Handler {
+	get := &fakeGet{out: uc.GetPackSizesOutput{Sizes: []int{250, 500}, Version: "v42", UpdatedAt: packsUpdatedAt}}
+	return BuildHandler(ctr.NewController(&fakeCalc{}, get), opts...)
+}
+
+func TestGET_PackSizes_Validators(t *testing.T) {
+	rec := serve(newVersionedHandler(), http.MethodGet, "/v1/packsizes")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status got=%d want=200", rec.Code)
+	}
+	want := map[string]string{
+		"ETag":          `W/"v42"`,
+		"Last-Modified": "Mon, 01 Sep 2025 10:30:15 GMT",
+		"Cache-Control": DefaultPackSizesCacheControl,
+	}
+	for k, v := range want {
+		if got := rec.Header().Get(k); got != v {
+			t.Fatalf("%s got=%q want=%q", k, got, v)
+		}
+	}
+}
+
+func TestGET_PackSizes_Conditional(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"same etag", map[string]string{"If-None-Match": `W/"v42"`}, http.StatusNotModified},
+		{"strong form of the etag", map[string]string{"If-None-Match": `"v42"`}, http.StatusNotModified},
+		{"etag in a list", map[string]string{"If-None-Match": `"v1", W/"v42"`}, http.StatusNotModified},
+		{"any", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"old etag", map[string]string{"If-None-Match": `W/"v41"`}, http.StatusOK},
+		{"not modified since", map[string]string{"If-Modified-Since": "Mon, 01 Sep 2025 10:30:15 GMT"}, http.StatusNotModified},
+		{"modified since", map[string]string{"If-Modified-Since": "Mon, 01 Sep 2025 10:30:14 GMT"}, http.StatusOK},
+		{"invalid date", map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+		{
+			"etag wins over date",
+			map[string]string{"If-None-Match": `W/"v41"`, "If-Modified-Since": "Mon, 01 Sep 2025 10:30:15 GMT"},
+			http.StatusOK,
+		},
+	}
+	h := newVersionedHandler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/packsizes", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status got=%d want=%d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusNotModified && (rec.Body.Len() != 0 || rec.Header().Get("ETag") != `W/"v42"`) {
+				t.Fatalf("304 must carry the ETag and no body: headers=%v body=%q", rec.Header(), rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGET_PackSizes_CacheControlOption(t *testing.T) {
+	rec := serve(newVersionedHandler(WithPackSizesCacheControl("public, max-age=60")), http.MethodGet, "/v1/packsizes")
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=60" {
+		t.Fatalf("cache-control got=%q", cc)
+	}
+}
+
+func TestGET_PackSizes_NoVersion(t *testing.T) {
+	// use cases that do not know the version: no validators, always 200
+	h := newTestHandler(&fakeCalc{}, &fakeGet{out: uc.GetPackSizesOutput{Sizes: []int{250}}})
+	req := httptest.NewRequest(http.MethodGet, "/v1/packsizes", nil)
+	req.Header.Set("If-None-Match", "*")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != "" || rec.Header().Get("Last-Modified") != "" {
+		t.Fatalf("status=%d headers=%v", rec.Code, rec.Header())
+	}
+}
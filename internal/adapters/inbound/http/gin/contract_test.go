@@ -0,0 +1,546 @@
+package ginadapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	apiv1 "github.com/reangeline/go-shipping-products/docs/api/v1"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/idempotency"
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/audit"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/events"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
+)
+
+// The contract suite runs every example documented in openapi.yaml through
+// BuildHandler with the real use cases; only the pack sizes provider is fake.
+
+type contractProvider struct {
+	sizes []int
+	sets  []packsizes.PackSet // history, EffectiveFrom asc
+	err   error
+	// disabled keeps the sizes taken out by SetEnabled (nil: the changes
+	// are not kept); only with sizes
+	disabled map[int]bool
+}
+
+// contractNow is the clock of the contract suite: the examples are set in
+// September 2025 (see exampleVersions).
+func contractNow() time.Time { return time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC) }
+
+// current is the set in effect at contractNow, or sizes in effect since
+// always.
+func (p contractProvider) current() packsizes.PackSet {
+	if len(p.sets) > 0 {
+		set, _ := p.At(context.Background(), contractNow())
+		return set
+	}
+	sizes := slices.DeleteFunc(slices.Clone(p.sizes), func(size int) bool { return p.disabled[size] })
+	return packsizes.PackSet{Version: packsizes.VersionOf(sizes), Sizes: sizes, Packs: p.packs()}
+}
+
+// packs is nil unless a size is disabled.
+func (p contractProvider) packs() []packsizes.Pack {
+	if len(p.disabled) == 0 {
+		return nil
+	}
+	packs := packsizes.PlainPacks(p.sizes)
+	for i := range packs {
+		packs[i].Enabled = !p.disabled[packs[i].Size]
+	}
+	return packs
+}
+
+func (p contractProvider) Load(context.Context) (packsizes.Catalogue, error) {
+	if p.err != nil {
+		return packsizes.Catalogue{}, p.err
+	}
+	set := p.current()
+	return packsizes.Catalogue{Sizes: set.Sizes, Version: packsizes.Version{ID: set.Version}, Source: "file", Packs: set.Packs}, nil
+}
+
+func (p contractProvider) At(_ context.Context, t time.Time) (packsizes.PackSet, error) {
+	if p.err != nil || len(p.sets) == 0 {
+		return p.current(), p.err
+	}
+	for i := len(p.sets) - 1; i >= 0; i-- {
+		if !p.sets[i].EffectiveFrom.After(t) {
+			return p.sets[i], nil
+		}
+	}
+	return packsizes.PackSet{}, packsizes.ErrNoPackSet
+}
+
+func (p contractProvider) Versions(context.Context) ([]packsizes.PackSet, error) {
+	if len(p.sets) == 0 {
+		return []packsizes.PackSet{p.current()}, p.err
+	}
+	return p.sets, p.err
+}
+
+// Schedule accepts any set that does not conflict; it is not kept.
+func (p contractProvider) Schedule(_ context.Context, set packsizes.PackSet) error {
+	for _, s := range p.sets {
+		if s.Version == set.Version || s.EffectiveFrom.Equal(set.EffectiveFrom) {
+			return packsizes.ErrScheduleConflict
+		}
+	}
+	return p.err
+}
+
+// Unschedule withdraws nothing: Schedule keeps no set.
+func (p contractProvider) Unschedule(context.Context, string) error {
+	return p.err
+}
+
+// SetEnabled answers as the file provider would; the change is only kept
+// in disabled.
+func (p contractProvider) SetEnabled(_ context.Context, size int, enabled bool) (packsizes.Pack, error) {
+	set := p.current()
+	for _, pk := range (packsizes.Catalogue{Sizes: set.Sizes, Packs: set.Packs}).AllPacks() {
+		if pk.Size != size {
+			continue
+		}
+		if !enabled && pk.Enabled && len(p.current().Sizes) == 1 {
+			return packsizes.Pack{}, packsizes.ErrLastEnabledPack
+		}
+		if p.disabled != nil && p.err == nil {
+			p.disabled[size] = !enabled
+		}
+		pk.Enabled = enabled
+		return pk, p.err
+	}
+	return packsizes.Pack{}, packsizes.ErrUnknownPackSize
+}
+
+// exampleSets is the catalogue of the documented examples (exampleVersions);
+// the version in effect has the metadata of examplePacks.
+func exampleSets() []packsizes.PackSet {
+	sets := make([]packsizes.PackSet, 0, len(exampleVersions.Versions))
+	for _, v := range exampleVersions.Versions {
+		set := packsizes.PackSet{Version: v.Version, EffectiveFrom: *v.EffectiveFrom, Sizes: v.Sizes}
+		if v.Version == exampleVersions.Current {
+			for _, p := range examplePacks {
+				pk := packsizes.Pack{Size: p.Size, Enabled: p.Enabled, Label: p.Label, SKU: p.SKU, Cost: p.Cost, Stock: p.Stock}
+				if d := p.Dimensions; d != nil {
+					pk.Dimensions = &packsizes.Dimensions{Length: d.Length, Width: d.Width, Height: d.Height, Unit: d.Unit}
+				}
+				set.Packs = append(set.Packs, pk)
+			}
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// contractDeliveries is the delivery log of exampleDeliveries.
+type contractDeliveries struct{}
+
+func (contractDeliveries) Deliveries(_ context.Context, f events.DeliveryFilter) ([]events.Delivery, error) {
+	out := []events.Delivery{}
+	for _, d := range exampleDeliveries.Deliveries {
+		if (f.EventID != "" && d.EventID != f.EventID) || (f.Status != "" && d.Status != f.Status) {
+			continue
+		}
+		e := events.Delivery{
+			EventID: d.EventID, EventType: d.EventType, Endpoint: d.Endpoint, Attempt: d.Attempt, At: d.At,
+			Duration: time.Duration(d.DurationMs) * time.Millisecond, Status: d.Status, StatusCode: d.StatusCode, Error: d.Error,
+		}
+		if d.NextAttemptAt != nil {
+			e.NextAttemptAt = *d.NextAttemptAt
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// contractAudit is the audit trail of exampleAudit; the admin calls of the
+// contract cases are not kept, or fail with err.
+type contractAudit struct{ err error }
+
+func (a contractAudit) Append(context.Context, audit.Entry) error { return a.err }
+
+func (contractAudit) Entries(_ context.Context, f audit.Filter) ([]audit.Entry, error) {
+	out := []audit.Entry{}
+	for _, e := range exampleAudit.Entries {
+		if (f.Actor != "" && e.Actor != f.Actor) || (f.Action != "" && e.Action != f.Action) {
+			continue
+		}
+		out = append(out, audit.Entry{
+			At: e.At, Action: e.Action, Actor: e.Actor, SourceIP: e.SourceIP, RequestID: e.RequestID,
+			Method: e.Method, Path: e.Path, Status: e.Status, Before: contractPackList(e.Before), After: contractPackList(e.After),
+		})
+	}
+	return out, nil
+}
+
+func contractPackList(l *ctr.AuditPackListResponse) *audit.PackList {
+	if l == nil {
+		return nil
+	}
+	return &audit.PackList{Version: l.Version, Sizes: l.Sizes}
+}
+
+var (
+	defaultSizes = contractProvider{sets: exampleSets()}
+	brokenSizes  = contractProvider{err: errors.New("read packs.csv: permission denied")}
+	// every source of a chain failed
+	unavailableSizes = contractProvider{err: fmt.Errorf("%w: file: connection refused", packsizes.ErrUnavailable)}
+)
+
+const contractAdminToken = "contract-admin-token"
+
+var adminHeaders = map[string]string{"Authorization": "Bearer " + contractAdminToken}
+
+type contractCase struct {
+	method   string
+	path     string
+	body     string
+	provider contractProvider
+	// Some handler errors are pre-empted by the request validation (e.g.
+	// quantity 0 breaks "minimum: 1"); those cases run without the
+	// middleware and the test validates the response itself.
+	withoutValidation bool
+	headers           map[string]string
+	// prior is the body of a request sent first, with the same headers
+	// (e.g. the original request of an idempotent retry)
+	prior string
+	// auditErr breaks the audit trail of the changes
+	auditErr error
+}
+
+func tooManySizes() string {
+	sizes := make([]string, usecases.DefaultLimits.MaxPackSizes+1)
+	for i := range sizes {
+		sizes[i] = strconv.Itoa(i + 1)
+	}
+	return `{"quantity":5,"packsOverride":[` + strings.Join(sizes, ",") + `]}`
+}
+
+// responseExamples is keyed by "operationId status example".
+var responseExamples = map[string]contractCase{
+	"listPackSizes 200 ok":             {method: http.MethodGet, path: "/v1/packsizes", provider: defaultSizes},
+	"listPackSizes 400 invalid_status": {method: http.MethodGet, path: "/v1/packsizes?status=active", provider: defaultSizes},
+	"listPackSizes 500 provider_error": {method: http.MethodGet, path: "/v1/packsizes", provider: brokenSizes},
+	"listPackSizes 503 unavailable":    {method: http.MethodGet, path: "/v1/packsizes", provider: unavailableSizes},
+	"getLimits 200 ok":                 {method: http.MethodGet, path: "/v1/limits", provider: defaultSizes},
+
+	"listPackSizeVersions 200 ok":             {method: http.MethodGet, path: "/v1/packsizes/versions", provider: defaultSizes},
+	"listPackSizeVersions 500 provider_error": {method: http.MethodGet, path: "/v1/packsizes/versions", provider: brokenSizes},
+
+	"calculatePacks 200 ok":               {method: http.MethodPost, path: "/v1/calculate", body: `{"quantity":12001}`, provider: defaultSizes},
+	"calculatePacks 200 explained":        {method: http.MethodPost, path: "/v1/calculate?explain=true", body: `{"quantity":12001}`, provider: defaultSizes},
+	"calculatePacks 200 as_of":            {method: http.MethodPost, path: "/v1/calculate", body: `{"quantity":12001,"asOf":"2025-03-01T12:00:00Z"}`, provider: defaultSizes},
+	"calculatePacks 400 invalid_quantity": {method: http.MethodPost, path: "/v1/calculate", body: `{"quantity":0}`, provider: defaultSizes, withoutValidation: true},
+	"calculatePacks 400 invalid_request":  {method: http.MethodPost, path: "/v1/calculate", body: `{"quantity": 1,}`, provider: defaultSizes},
+	"calculatePacks 400 invalid_pack":     {method: http.MethodPost, path: "/v1/calculate", body: `{"quantity":5,"packsOverride":[250,500,0]}`, provider: defaultSizes, withoutValidation: true},
+	"calculatePacks 422 no_packs":         {method: http.MethodPost, path: "/v1/calculate", body: `{"quantity":5}`},
+	"calculatePacks 422 no_catalogue_at": {
+		method: http.MethodPost, path: "/v1/calculate", body: `{"quantity":5,"asOf":"2020-01-01T00:00:00Z"}`, provider: defaultSizes,
+	},
+	"calculatePacks 422 quantity_too_large": {
+		method: http.MethodPost, path: "/v1/calculate", body: `{"quantity":100000001}`, provider: defaultSizes,
+	},
+	"calculatePacks 422 too_many_pack_sizes": {method: http.MethodPost, path: "/v1/calculate", body: tooManySizes(), provider: defaultSizes},
+	"calculatePacks 422 calculation_too_large": {
+		method: http.MethodPost, path: "/v1/calculate", body: `{"quantity":9999999,"packsOverride":[1,2]}`, provider: defaultSizes,
+	},
+	"calculatePacks 422 idempotency_key_reused": {
+		method: http.MethodPost, path: "/v1/calculate", body: `{"quantity":12001}`, provider: defaultSizes,
+		headers: map[string]string{IdempotencyKeyHeader: "order-42"}, prior: `{"quantity":751}`,
+	},
+	"calculatePacks 500 internal":    {method: http.MethodPost, path: "/v1/calculate", body: `{"quantity":5}`, provider: brokenSizes},
+	"calculatePacks 503 unavailable": {method: http.MethodPost, path: "/v1/calculate", body: `{"quantity":5}`, provider: unavailableSizes},
+	"calculatePacksCSV 400 no_orders": {
+		method: http.MethodPost, path: "/v1/calculate/csv", body: "id,quantity\n", provider: defaultSizes,
+		headers: map[string]string{"Content-Type": CSVContentType},
+	},
+	"calculatePacksCSV 415 unsupported_media_type": {
+		method: http.MethodPost, path: "/v1/calculate/csv", body: `{"quantity":1}`, provider: defaultSizes,
+		withoutValidation: true, // the validator already rejects the media type
+	},
+	"calculatePacksCSV 500 provider_error": {
+		method: http.MethodPost, path: "/v1/calculate/csv", body: "a,1\n", provider: brokenSizes,
+		headers: map[string]string{"Content-Type": CSVContentType},
+	},
+	"listUpcomingPackSizes 200 ok":             {method: http.MethodGet, path: "/v1/packsizes/upcoming", provider: defaultSizes},
+	"listUpcomingPackSizes 500 provider_error": {method: http.MethodGet, path: "/v1/packsizes/upcoming", provider: brokenSizes},
+	"schedulePackSizes 201 ok": {
+		method: http.MethodPost, path: "/v1/admin/packsizes/schedule", provider: defaultSizes, headers: adminHeaders,
+		body: `{"version":"2026-03","effectiveFrom":"2026-03-01T00:00:00Z","sizes":[500,1000,2000,5000,10000]}`,
+	},
+	"schedulePackSizes 400 invalid_schedule": {
+		method: http.MethodPost, path: "/v1/admin/packsizes/schedule", provider: defaultSizes, headers: adminHeaders,
+		body: `{"effectiveFrom":"2025-01-01T00:00:00Z","sizes":[500]}`,
+	},
+	"schedulePackSizes 401 unauthorized": {
+		method: http.MethodPost, path: "/v1/admin/packsizes/schedule", provider: defaultSizes,
+		headers: map[string]string{"Authorization": "Bearer wrong"},
+		body:    `{"effectiveFrom":"2026-03-01T00:00:00Z","sizes":[500]}`,
+	},
+	"schedulePackSizes 503 audit_unavailable": {
+		method: http.MethodPost, path: "/v1/admin/packsizes/schedule", provider: defaultSizes, headers: adminHeaders,
+		body: `{"version":"2026-03","effectiveFrom":"2026-03-01T00:00:00Z","sizes":[500]}`, auditErr: errors.New("disk full"),
+	},
+	"schedulePackSizes 409 schedule_conflict": {
+		method: http.MethodPost, path: "/v1/admin/packsizes/schedule", provider: defaultSizes, headers: adminHeaders,
+		body: `{"version":"2025-06","effectiveFrom":"2026-03-01T00:00:00Z","sizes":[500]}`,
+	},
+	"enablePackSize 200 ok": {method: http.MethodPost, path: "/v1/admin/packsizes/250/enable", provider: defaultSizes, headers: adminHeaders},
+	"enablePackSize 404 unknown_pack_size": {
+		method: http.MethodPost, path: "/v1/admin/packsizes/42/enable", provider: defaultSizes, headers: adminHeaders,
+	},
+	"disablePackSize 200 ok": {method: http.MethodPost, path: "/v1/admin/packsizes/250/disable", provider: defaultSizes, headers: adminHeaders},
+	"disablePackSize 404 unknown_pack_size": {
+		method: http.MethodPost, path: "/v1/admin/packsizes/42/disable", provider: defaultSizes, headers: adminHeaders,
+	},
+	"disablePackSize 409 last_enabled_pack_size": {
+		method: http.MethodPost, path: "/v1/admin/packsizes/250/disable", provider: contractProvider{sizes: []int{250}}, headers: adminHeaders,
+	},
+	"disablePackSize 503 audit_unavailable": {
+		method: http.MethodPost, path: "/v1/admin/packsizes/250/disable", headers: adminHeaders,
+		provider: contractProvider{sizes: []int{250, 500, 1000}, disabled: map[int]bool{}}, auditErr: errors.New("disk full"),
+	},
+	"enablePackSize 503 audit_unavailable": {
+		method: http.MethodPost, path: "/v1/admin/packsizes/250/enable", headers: adminHeaders,
+		provider: contractProvider{sizes: []int{250, 500, 1000}, disabled: map[int]bool{250: true}}, auditErr: errors.New("disk full"),
+	},
+	"listWebhookDeliveries 200 ok": {
+		method: http.MethodGet, path: "/v1/admin/webhooks/deliveries?eventId=4f3c2a1b9d8e7f60a1b2c3d4e5f60718", provider: defaultSizes,
+		headers: adminHeaders,
+	},
+	"listWebhookDeliveries 400 invalid_limit": {
+		method: http.MethodGet, path: "/v1/admin/webhooks/deliveries?limit=501", provider: defaultSizes, headers: adminHeaders,
+	},
+	"listAuditEntries 200 ok": {
+		method: http.MethodGet, path: "/v1/audit?actor=alice&from=2025-10-01T00:00:00Z", provider: defaultSizes, headers: adminHeaders,
+	},
+	"listAuditEntries 400 invalid_audit_range": {
+		method: http.MethodGet, path: "/v1/audit?from=2025-11-02T00:00:00Z&to=2025-11-01T00:00:00Z", provider: defaultSizes,
+		headers: adminHeaders,
+	},
+	"calculatePacksStream 415 unsupported_media_type": {
+		method: http.MethodPost, path: "/v1/calculate/stream", body: `{"quantity":1}`, provider: defaultSizes,
+	},
+}
+
+func contractHandler(t *testing.T, prov contractProvider, mode ValidationMode) http.Handler {
+	t.Helper()
+	return contractHandlerWith(t, prov, mode, contractAudit{})
+}
+
+// contractHandlerWith records the changes in log, as the app does.
+func contractHandlerWith(t *testing.T, prov contractProvider, mode ValidationMode, log contractAudit) http.Handler {
+	t.Helper()
+	calc, err := usecases.NewCalculatePacks(domain.NewPackCalculator(), prov)
+	if err != nil {
+		t.Fatalf("NewCalculatePacks: %v", err)
+	}
+	get, err := usecases.NewGetPackSizes(prov)
+	if err != nil {
+		t.Fatalf("NewGetPackSizes: %v", err)
+	}
+	controller := ctr.NewController(calc, get)
+	controller.Limits = usecases.NewGetLimits(usecases.DefaultLimits)
+	if controller.Versions, err = usecases.NewListPackSizeVersions(prov); err != nil {
+		t.Fatalf("NewListPackSizeVersions: %v", err)
+	}
+	if controller.Upcoming, err = usecases.NewListUpcomingPackSizes(prov, contractNow); err != nil {
+		t.Fatalf("NewListUpcomingPackSizes: %v", err)
+	}
+	if controller.Schedule, err = usecases.NewSchedulePackSizes(prov, contractNow); err != nil {
+		t.Fatalf("NewSchedulePackSizes: %v", err)
+	}
+	if controller.Toggle, err = usecases.NewSetPackSizeEnabled(prov); err != nil {
+		t.Fatalf("NewSetPackSizeEnabled: %v", err)
+	}
+	auditOpts := usecases.AuditOptions{Now: contractNow}
+	if controller.Schedule, err = usecases.NewAuditedSchedulePackSizes(controller.Schedule, prov, log, auditOpts); err != nil {
+		t.Fatalf("NewAuditedSchedulePackSizes: %v", err)
+	}
+	if controller.Toggle, err = usecases.NewAuditedSetPackSizeEnabled(controller.Toggle, prov, log, auditOpts); err != nil {
+		t.Fatalf("NewAuditedSetPackSizeEnabled: %v", err)
+	}
+	if controller.Deliveries, err = usecases.NewListWebhookDeliveries(contractDeliveries{}); err != nil {
+		t.Fatalf("NewListWebhookDeliveries: %v", err)
+	}
+	if controller.Audit, err = usecases.NewListAuditEntries(contractAudit{}); err != nil {
+		t.Fatalf("NewListAuditEntries: %v", err)
+	}
+	return BuildHandler(controller,
+		WithOpenAPIValidation(mode),
+		WithIdempotency(idempotency.NewMemoryStore(idempotency.Options{}), time.Hour),
+		WithAdminToken(contractAdminToken),
+		WithAuditLog(log),
+	)
+}
+
+func loadSpec(t *testing.T) (*openapi3.T, routers.Router) {
+	t.Helper()
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(apiv1.OpenAPI)
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	// also checks that every example matches its schema
+	if err := doc.Validate(loader.Context); err != nil {
+		t.Fatalf("invalid spec: %v", err)
+	}
+	doc.Servers = nil
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("router: %v", err)
+	}
+	return doc, router
+}
+
+func (tc contractCase) do(t *testing.T) *httptest.ResponseRecorder {
+	t.Helper()
+	mode := ValidateResponses
+	if tc.withoutValidation {
+		mode = ValidateOff
+	}
+	h := contractHandlerWith(t, tc.provider, mode, contractAudit{err: tc.auditErr})
+	if tc.prior != "" {
+		h.ServeHTTP(httptest.NewRecorder(), tc.request(tc.prior))
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, tc.request(tc.body))
+	return rec
+}
+
+func (tc contractCase) request(body string) *http.Request {
+	req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range tc.headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+// checkResponse validates a response against the spec outside the middleware.
+func checkResponse(t *testing.T, router routers.Router, tc contractCase, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+	req.Header.Set("Content-Type", "application/json")
+	route, params, err := router.FindRoute(req)
+	if err != nil {
+		t.Fatalf("route not documented: %v", err)
+	}
+	err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{Request: req, PathParams: params, Route: route},
+		Status:                 rec.Code,
+		Header:                 rec.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	})
+	if err != nil {
+		t.Fatalf("response outside the contract: %v", err)
+	}
+}
+
+func normalizeJSON(t *testing.T, v any) any {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return out
+}
+
+func TestContract_ResponseExamples(t *testing.T) {
+	doc, router := loadSpec(t)
+
+	seen := make(map[string]bool)
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			for status, resp := range op.Responses.Map() {
+				media := resp.Value.Content.Get("application/json")
+				if media == nil {
+					continue
+				}
+				for name, ex := range media.Examples {
+					key := fmt.Sprintf("%s %s %s", op.OperationID, status, name)
+					seen[key] = true
+					t.Run(key, func(t *testing.T) {
+						tc, ok := responseExamples[key]
+						if !ok {
+							t.Fatalf("documented example without a contract case")
+						}
+						rec := tc.do(t)
+						if got := strconv.Itoa(rec.Code); got != status {
+							t.Fatalf("status got=%s want=%s body=%s", got, status, rec.Body.String())
+						}
+						checkResponse(t, router, tc, rec)
+
+						var got any
+						if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+							t.Fatalf("invalid json: %v", err)
+						}
+						if want := normalizeJSON(t, ex.Value.Value); !reflect.DeepEqual(got, want) {
+							t.Fatalf("body drifted from the example\n got: %s\nwant: %v", rec.Body.String(), want)
+						}
+					})
+				}
+			}
+		}
+	}
+
+	for key := range responseExamples {
+		if !seen[key] {
+			t.Errorf("contract case %q has no example in the spec", key)
+		}
+	}
+}
+
+func TestContract_RequestExamples(t *testing.T) {
+	doc, _ := loadSpec(t)
+
+	for path, item := range doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			if op.RequestBody == nil {
+				continue
+			}
+			media := op.RequestBody.Value.Content.Get("application/json")
+			if media == nil {
+				continue
+			}
+			for name, ex := range media.Examples {
+				t.Run(op.OperationID+" "+name, func(t *testing.T) {
+					body, err := json.Marshal(ex.Value.Value)
+					if err != nil {
+						t.Fatalf("marshal example: %v", err)
+					}
+					rec := contractCase{method: method, path: path, body: string(body), provider: defaultSizes, headers: adminHeaders}.do(t)
+					if rec.Code != http.StatusOK && rec.Code != http.StatusCreated {
+						t.Fatalf("status got=%d want=200 or 201 body=%s", rec.Code, rec.Body.String())
+					}
+				})
+			}
+		}
+	}
+}
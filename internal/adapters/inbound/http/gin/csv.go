@@ -0,0 +1,386 @@
+package ginadapter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+	"github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/file"
+	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
+)
+
+const (
+	CSVContentType = "text/csv"
+
+	csvFormField   = "file"
+	maxCSVBodySize = 8 << 20
+	maxCSVOrders   = 10_000
+)
+
+// csvColumns are the positions of the input columns (-1: absent).
+type csvColumns struct{ id, quantity, packs int }
+
+// positionalColumns is the layout of a file without header.
+var positionalColumns = csvColumns{id: 0, quantity: 1, packs: 2}
+
+// csvHeaderNames are the accepted header names, compared lowercased and
+// without spaces, dashes and underscores.
+var csvHeaderNames = map[string]func(*csvColumns, int){
+	"id":            func(c *csvColumns, i int) { c.id = i },
+	"orderid":       func(c *csvColumns, i int) { c.id = i },
+	"order":         func(c *csvColumns, i int) { c.id = i },
+	"quantity":      func(c *csvColumns, i int) { c.quantity = i },
+	"qty":           func(c *csvColumns, i int) { c.quantity = i },
+	"packsoverride": func(c *csvColumns, i int) { c.packs = i },
+	"packoverride":  func(c *csvColumns, i int) { c.packs = i },
+	"packs":         func(c *csvColumns, i int) { c.packs = i },
+}
+
+type csvOrder struct {
+	id       string
+	quantity string
+	packs    string
+}
+
+type csvResult struct {
+	csvOrder
+	res *ctr.CalculateResponse
+	err *presenter.ErrorBody
+}
+
+// handleCalculateCSV calculates every row of a CSV (multipart "file" part or
+// raw text/csv body) and answers a CSV with one row per order: id, quantity,
+// one column per pack size (the provider sizes plus the overrides used),
+// totalItems, totalPacks, leftover, error and message. A bad row gets its
+// error columns filled; the others are still calculated. The file is
+// checked (size, maxCSVOrders) before any calculation, and each row gives
+// the connection streamIdleTimeout more, so the server timeouts do not cut
+// a long file.
+func handleCalculateCSV(ctrl *ctr.Controller, _ *options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		extendDeadlines := idleDeadlines(http.NewResponseController(c.Writer))
+		extendDeadlines()
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCSVBodySize)
+		in, name, ok := csvInput(c)
+		if !ok {
+			return
+		}
+		orders, err := readCSVOrders(in)
+		if err != nil {
+			writeCSVError(c, err)
+			return
+		}
+		current, err := ctrl.HandleGetPackSizes(c.Request.Context(), ctr.PackStatusEnabled)
+		if err != nil {
+			writeUseCaseError(c, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		results := make([]csvResult, 0, len(orders))
+		for _, o := range orders {
+			if ctx.Err() != nil {
+				return // the client is gone
+			}
+			results = append(results, calculateCSVRow(c, ctrl, o))
+			extendDeadlines()
+		}
+
+		c.Header("Content-Type", CSVContentType+"; charset=utf-8")
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": resultFileName(name)}))
+		c.Status(http.StatusOK)
+		if err := writeCSVResults(c.Writer, packColumns(current.Sizes, results), results); err != nil {
+			_ = c.Error(err) // headers already sent
+		}
+	}
+}
+
+// csvInput returns the CSV reader and the uploaded file name ("" for a raw
+// body); on a wrong media type or a missing part it answers and reports false.
+func csvInput(c *gin.Context) (io.Reader, string, bool) {
+	switch c.ContentType() {
+	case CSVContentType:
+		return c.Request.Body, "", true
+	case "multipart/form-data":
+		mr, err := c.Request.MultipartReader()
+		if err != nil {
+			status, body := presenter.InvalidParam("body", "malformed multipart body")
+			writeError(c, status, body)
+			return nil, "", false
+		}
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					status, body := presenter.InvalidParam(csvFormField, "missing CSV file part")
+					writeError(c, status, body)
+				} else {
+					writeCSVError(c, err)
+				}
+				return nil, "", false
+			}
+			if part.FormName() == csvFormField {
+				return part, part.FileName(), true
+			}
+		}
+	default:
+		writeError(c, http.StatusUnsupportedMediaType, presenter.ErrorBody{
+			Code:    presenter.CodeInvalidRequest,
+			Message: "Content-Type must be " + CSVContentType + " or multipart/form-data",
+		})
+		return nil, "", false
+	}
+}
+
+// readCSVOrders reads the orders. The header is optional: without it the
+// columns are id, quantity and packsOverride; with it (a first row naming
+// known columns, see csvHeaderNames) they are matched by name and may come in
+// any order. The override uses the separators of file.ParsePackSizes.
+func readCSVOrders(r io.Reader) ([]csvOrder, error) {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		_, _ = br.Discard(3) // spreadsheets export UTF-8 with a BOM
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	cols := positionalColumns
+	var orders []csvOrder
+	for first := true; ; first = false {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first && !isInteger(field(rec, positionalColumns.quantity)) {
+			header, ok, err := parseCSVHeader(rec)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				cols = header
+				continue
+			}
+		}
+		if len(orders) == maxCSVOrders {
+			return nil, errTooManyCSVOrders
+		}
+		orders = append(orders, csvOrder{
+			id:       field(rec, cols.id),
+			quantity: field(rec, cols.quantity),
+			packs:    field(rec, cols.packs),
+		})
+	}
+	if len(orders) == 0 {
+		return nil, errNoCSVOrders
+	}
+	return orders, nil
+}
+
+var (
+	errNoCSVOrders      = errors.New("the CSV has no orders")
+	errNoQuantityInCSV  = errors.New("the CSV header has no quantity column")
+	errTooManyCSVOrders = fmt.Errorf("the CSV has more than %d orders", maxCSVOrders)
+)
+
+// parseCSVHeader reports false when rec has no known column name (a data
+// row with a bad quantity).
+func parseCSVHeader(rec []string) (csvColumns, bool, error) {
+	cols := csvColumns{id: -1, quantity: -1, packs: -1}
+	known := false
+	for i, name := range rec {
+		name = strings.Map(func(r rune) rune {
+			switch r {
+			case ' ', '-', '_':
+				return -1
+			}
+			return r
+		}, strings.ToLower(strings.TrimSpace(name)))
+		if set, ok := csvHeaderNames[name]; ok {
+			set(&cols, i)
+			known = true
+		}
+	}
+	if !known {
+		return cols, false, nil
+	}
+	if cols.quantity < 0 {
+		return cols, false, errNoQuantityInCSV
+	}
+	return cols, true, nil
+}
+
+func calculateCSVRow(c *gin.Context, ctrl *ctr.Controller, o csvOrder) csvResult {
+	out := csvResult{csvOrder: o}
+	quantity, err := strconv.Atoi(o.quantity)
+	if err != nil {
+		_, body := presenter.InvalidParam("quantity", "must be an integer")
+		out.err = &body
+		return out
+	}
+	var override []int
+	if o.packs != "" {
+		if override, err = file.ParsePackSizes(o.packs); err != nil {
+			_, body := presenter.MapError(usecases.ErrInvalidPackInOverride)
+			body.Details = []presenter.FieldError{{Field: "packsOverride", Reason: err.Error()}}
+			out.err = &body
+			return out
+		}
+	}
+	res, err := ctrl.HandleCalculate(c.Request.Context(), ctr.CalculateRequest{Quantity: quantity, PacksOverride: override})
+	if err != nil {
+		_, body := presenter.MapError(err)
+		out.err = &body
+		return out
+	}
+	out.res = &res
+	return out
+}
+
+// packColumns are the provider sizes plus every size a result used, asc.
+func packColumns(sizes []int, results []csvResult) []int {
+	seen := make(map[int]struct{}, len(sizes))
+	for _, s := range sizes {
+		seen[s] = struct{}{}
+	}
+	for _, r := range results {
+		if r.res == nil {
+			continue
+		}
+		for s := range r.res.ItemsByPack {
+			seen[s] = struct{}{}
+		}
+	}
+	out := make([]int, 0, len(seen))
+	for s := range seen {
+		out = append(out, s)
+	}
+	sort.Ints(out)
+	return out
+}
+
+func writeCSVResults(w io.Writer, packs []int, results []csvResult) error {
+	cw := csv.NewWriter(w)
+	header := []string{"id", "quantity"}
+	for _, s := range packs {
+		header = append(header, strconv.Itoa(s))
+	}
+	header = append(header, "totalItems", "totalPacks", "leftover", "error", "message")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(header))
+	for _, r := range results {
+		clear(row)
+		row[0], row[1] = csvCell(r.id), r.quantity
+		if !isInteger(r.quantity) {
+			row[1] = csvCell(r.quantity)
+		}
+		n := 2 + len(packs)
+		switch {
+		case r.res != nil:
+			for i, s := range packs {
+				row[2+i] = strconv.Itoa(r.res.ItemsByPack[s])
+			}
+			row[n] = strconv.Itoa(r.res.TotalItems)
+			row[n+1] = strconv.Itoa(r.res.TotalPacks)
+			row[n+2] = strconv.Itoa(r.res.Leftover)
+		case r.err != nil:
+			row[n+3] = r.err.Code
+			row[n+4] = csvCell(errorMessage(r.err))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// errorMessage folds the field details into the message (a cell is flat).
+func errorMessage(body *presenter.ErrorBody) string {
+	fields, _ := body.Details.([]presenter.FieldError)
+	var parts []string
+	for _, f := range fields {
+		if !strings.Contains(body.Message, f.Reason) {
+			parts = append(parts, strings.TrimSpace(f.Field+" "+f.Reason))
+		}
+	}
+	if len(parts) == 0 {
+		return body.Message
+	}
+	return body.Message + " (" + strings.Join(parts, "; ") + ")"
+}
+
+// writeCSVError answers an unreadable CSV: 413 above maxCSVBodySize or
+// maxCSVOrders, 400 otherwise (with the line of a syntax error).
+func writeCSVError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(c, http.StatusRequestEntityTooLarge, presenter.ErrorBody{
+			Code:    presenter.CodeInvalidRequest,
+			Message: fmt.Sprintf("the CSV exceeds %d MiB", maxCSVBodySize>>20),
+		})
+		return
+	}
+	if errors.Is(err, errTooManyCSVOrders) {
+		writeError(c, http.StatusRequestEntityTooLarge, presenter.ErrorBody{Code: presenter.CodeInvalidRequest, Message: err.Error()})
+		return
+	}
+	body := presenter.ErrorBody{Code: presenter.CodeInvalidRequest, Message: "invalid CSV"}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		body.Details = []presenter.FieldError{{Field: "line " + strconv.Itoa(parseErr.Line), Reason: parseErr.Err.Error()}}
+	} else {
+		body.Details = []presenter.FieldError{{Reason: err.Error()}}
+	}
+	writeError(c, http.StatusBadRequest, body)
+}
+
+// resultFileName names the download after the upload: orders.csv gives
+// orders-packs.csv.
+func resultFileName(upload string) string {
+	base := path.Base(strings.ReplaceAll(upload, `\`, "/"))
+	base = strings.TrimSuffix(base, path.Ext(base))
+	if base == "" || base == "." || base == "/" {
+		return "packs.csv"
+	}
+	return base + "-packs.csv"
+}
+
+// csvCell keeps input echoed back from being run as a formula when the
+// file is opened in a spreadsheet (CSV injection).
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func field(rec []string, i int) string {
+	if i < 0 || i >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[i])
+}
+
+func isInteger(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
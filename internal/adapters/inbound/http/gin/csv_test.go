@@ -0,0 +1,158 @@
+package ginadapter
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+)
+
+func postCSV(h http.Handler, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/calculate/csv", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func multipartCSV(t *testing.T, field, filename, content string) (string, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.WriteField("note", "ignored"); err != nil {
+		t.Fatalf("multipart: %v", err)
+	}
+	fw, err := mw.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatalf("multipart: %v", err)
+	}
+	fw.Write([]byte(content))
+	if err := mw.Close(); err != nil {
+		t.Fatalf("multipart: %v", err)
+	}
+	return mw.FormDataContentType(), buf.String()
+}
+
+func TestCSV_RawBody(t *testing.T) {
+	h := contractHandler(t, defaultSizes, ValidateResponses)
+	body := strings.Join([]string{
+		"o1,251",
+		"o2,12001",
+		`o3,751,"250,500"`,
+		"o4,abc",
+		"o5,10,0;5",
+		"=HYPERLINK(1),0",
+	}, "\n")
+
+	rec := postCSV(h, CSVContentType, body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status got=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Fatalf("content-type got=%q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != "attachment; filename=packs.csv" {
+		t.Fatalf("content-disposition got=%q", cd)
+	}
+	want := strings.Join([]string{
+		"id,quantity,250,500,1000,2000,5000,totalItems,totalPacks,leftover,error,message",
+		"o1,251,0,1,0,0,0,500,1,249,,",
+		"o2,12001,1,0,0,1,2,12250,4,249,,",
+		"o3,751,0,2,0,0,0,1000,2,249,,",
+		"o4,abc,,,,,,,,,invalid_request,quantity must be an integer",
+		`o5,10,,,,,,,,,invalid_pack,packsOverride must contain positive integers (packsOverride pack size must be > 0)`,
+		"'=HYPERLINK(1),0,,,,,,,,,invalid_quantity,quantity must be > 0",
+		"",
+	}, "\n")
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("csv got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCSV_MultipartWithHeader(t *testing.T) {
+	h := contractHandler(t, defaultSizes, ValidateResponses)
+	// BOM, reordered and renamed columns, an override size outside the provider list
+	contentType, body := multipartCSV(t, "file", `C:\exports\orders.csv`, "\ufeffQuantity,Pack Override,Order ID\n7,3,a\n")
+
+	rec := postCSV(h, contentType, body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status got=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != "attachment; filename=orders-packs.csv" {
+		t.Fatalf("content-disposition got=%q", cd)
+	}
+	want := "id,quantity,3,250,500,1000,2000,5000,totalItems,totalPacks,leftover,error,message\n" +
+		"a,7,3,0,0,0,0,0,9,3,2,,\n"
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("csv got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCSV_RequestErrors(t *testing.T) {
+	noFile, noFileBody := multipartCSV(t, "upload", "orders.csv", "a,1\n")
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantDetail  string
+	}{
+		{"bare quote", CSVContentType, "a,1\nb,\"2\n", http.StatusBadRequest, "line 2"},
+		{"header without quantity", CSVContentType, "id,packs\na,250\n", http.StatusBadRequest, errNoQuantityInCSV.Error()},
+		{"only a header", CSVContentType, "id,quantity\n", http.StatusBadRequest, errNoCSVOrders.Error()},
+		{"no file part", noFile, noFileBody, http.StatusBadRequest, "file"},
+		{"too large", CSVContentType, strings.Repeat("a,1\n", maxCSVBodySize/4+1), http.StatusRequestEntityTooLarge, ""},
+		{"too many orders", CSVContentType, strings.Repeat("a,1\n", maxCSVOrders+1), http.StatusRequestEntityTooLarge, errTooManyCSVOrders.Error()},
+		{"json", "application/json", `{"quantity":1}`, http.StatusUnsupportedMediaType, ""},
+	}
+	h := contractHandler(t, defaultSizes, ValidateOff)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postCSV(h, tt.contentType, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status got=%d want=%d body=%s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), presenter.CodeInvalidRequest) || !strings.Contains(rec.Body.String(), tt.wantDetail) {
+				t.Fatalf("body got=%s want detail %q", rec.Body.String(), tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestResultFileName(t *testing.T) {
+	tests := map[string]string{
+		"":                     "packs.csv",
+		"orders.csv":           "orders-packs.csv",
+		"march.orders.CSV":     "march.orders-packs.csv",
+		`C:\Users\ops\in.csv`:  "in-packs.csv",
+		"../../etc/passwd.csv": "passwd-packs.csv",
+	}
+	for in, want := range tests {
+		if got := resultFileName(in); got != want {
+			t.Fatalf("resultFileName(%q) got=%q want=%q", in, got, want)
+		}
+	}
+}
+
+func TestCSV_IgnoresIdempotencyKey(t *testing.T) {
+	calc := &countingCalc{}
+	h := newIdempotentHandler(calc)
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/v1/calculate/csv", strings.NewReader("a,250\n"))
+		req.Header.Set("Content-Type", CSVContentType)
+		req.Header.Set(IdempotencyKeyHeader, "batch-1")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || rec.Header().Get(ReplayedHeader) != "" {
+			t.Fatalf("status=%d replayed=%q body=%s", rec.Code, rec.Header().Get(ReplayedHeader), rec.Body.String())
+		}
+	}
+	if n := calc.calls.Load(); n != 2 {
+		t.Fatalf("calls got=%d want=2: a CSV is never replayed", n)
+	}
+}
@@ -1,40 +1,53 @@
 package ginadapter
 
 import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
 	"net/http"
+	"path"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
 )
 
-// RegisterDocs publishes openapi.yaml and a Swagger UI page.
-// - specPath: local path to the YAML
-// - mount: public prefix (e.g., "/docs")
-func RegisterDocs(r *gin.Engine, specPath, mount string) {
-	if mount == "" {
-		mount = "/docs"
-	}
-	r.StaticFile(mount+"/openapi.yaml", specPath)
+//go:embed swaggerui/*.js swaggerui/*.css swaggerui/*.png
+var swaggerUI embed.FS
 
-	const page = `<!DOCTYPE html>
+// Cache policy:
+// - the page and the spec change with each deploy: revalidate with the ETag
+// - Swagger UI assets only change when the vendored version changes
+const (
+	docsCacheControl   = "no-cache"
+	assetsCacheControl = "public, max-age=86400"
+)
+
+const docsPage = `<!DOCTYPE html>
 <html lang="en">
 <head>
   <meta charset="UTF-8">
   <title>Shipping Packs API – Swagger UI</title>
-  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+  <link rel="stylesheet" href="%[1]s/assets/swagger-ui.css">
+  <link rel="icon" type="image/png" href="%[1]s/assets/favicon-32x32.png" sizes="32x32">
+  <link rel="icon" type="image/png" href="%[1]s/assets/favicon-16x16.png" sizes="16x16">
   <style>
-    html,body,#swagger-ui {height:100%; margin:0; background:#fff;}
+    html,body,#swagger-ui {height:100%%; margin:0; background:#fff;}
   </style>
 </head>
 <body>
   <div id="swagger-ui"></div>
-  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script src="%[1]s/assets/swagger-ui-bundle.js"></script>
+  <script src="%[1]s/assets/swagger-ui-standalone-preset.js"></script>
   <script>
     window.onload = () => {
       window.ui = SwaggerUIBundle({
-        url: window.location.pathname.replace(/\/$/, '') + '/openapi.yaml',
+        url: '%[1]s/openapi.yaml',
         dom_id: '#swagger-ui',
         deepLinking: true,
-        presets: [SwaggerUIBundle.presets.apis, SwaggerUIBundle.SwaggerUIStandalonePreset],
+        presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
         layout: "BaseLayout"
       });
     };
@@ -42,7 +55,94 @@ func RegisterDocs(r *gin.Engine, specPath, mount string) {
 </body>
 </html>`
 
-	r.GET(mount, func(c *gin.Context) {
-		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+// RegisterDocs publishes the OpenAPI spec and a Swagger UI page; everything
+// is served from memory (no disk, no CDN).
+// - spec: the YAML document (see OpenAPISpec)
+// - mount: public prefix (e.g., "/docs")
+//
+// Routes: mount (UI), mount/openapi.yaml, mount/openapi.json, mount/assets/*.
+func RegisterDocs(r *gin.Engine, spec []byte, mount string) error {
+	if mount == "" {
+		mount = "/docs"
+	}
+
+	specJSON, err := yamlToJSON(spec)
+	if err != nil {
+		return fmt.Errorf("openapi spec: %w", err)
+	}
+	assets, err := fs.Sub(swaggerUI, "swaggerui")
+	if err != nil {
+		return err
+	}
+
+	page := []byte(fmt.Sprintf(docsPage, mount))
+	r.GET(mount, serveBytes("index.html", page, docsCacheControl))
+	r.GET(mount+"/openapi.yaml", serveBytes("openapi.yaml", spec, docsCacheControl))
+	r.GET(mount+"/openapi.json", serveBytes("openapi.json", specJSON, docsCacheControl))
+
+	return fs.WalkDir(assets, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(assets, name)
+		if err != nil {
+			return err
+		}
+		r.GET(mount+"/assets/"+name, serveBytes(name, b, assetsCacheControl))
+		return nil
 	})
 }
+
+// serveBytes serves content with a strong ETag; http.ServeContent answers
+// conditional requests (If-None-Match -> 304) and ranges.
+func serveBytes(name string, content []byte, cacheControl string) gin.HandlerFunc {
+	etag := etagOf(content, "")
+	contentType := map[string]string{
+		".yaml": "application/yaml",
+		".json": "application/json",
+		".html": "text/html; charset=utf-8",
+	}[path.Ext(name)]
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("ETag", etag)
+		h.Set("Cache-Control", cacheControl)
+		if contentType != "" {
+			h.Set("Content-Type", contentType)
+		}
+		http.ServeContent(c.Writer, c.Request, name, time.Time{}, bytes.NewReader(content))
+	}
+}
+
+// yamlToJSON renders the spec as JSON. YAML allows non-string keys
+// (e.g. status codes like 200), JSON does not: keys are stringified.
+func yamlToJSON(in []byte) ([]byte, error) {
+	var doc any
+	if err := yaml.Unmarshal(in, &doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonCompatible(doc))
+}
+
+func jsonCompatible(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			t[k] = jsonCompatible(e)
+		}
+		return t
+	case map[any]any:
+		m := make(map[string]any, len(t))
+		for k, e := range t {
+			m[fmt.Sprint(k)] = jsonCompatible(e)
+		}
+		return m
+	case []any:
+		for i, e := range t {
+			t[i] = jsonCompatible(e)
+		}
+		return t
+	default:
+		return v
+	}
+}
@@ -0,0 +1,105 @@
+package ginadapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testSpec = `openapi: 3.0.3
+info:
+  title: test
+  version: "1"
+paths:
+  /ping:
+    get:
+      responses:
+        200:
+          description: ok
+`
+
+func newDocsEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	r := gin.New()
+	if err := RegisterDocs(r, []byte(testSpec), "/docs"); err != nil {
+		t.Fatalf("RegisterDocs: %v", err)
+	}
+	return r
+}
+
+func TestDocs_Routes(t *testing.T) {
+	r := newDocsEngine(t)
+
+	tests := []struct {
+		path        string
+		contentType string
+		cache       string
+		contains    string
+	}{
+		{"/docs", "text/html; charset=utf-8", docsCacheControl, "/docs/assets/swagger-ui-bundle.js"},
+		{"/docs/openapi.yaml", "application/yaml", docsCacheControl, "openapi: 3.0.3"},
+		{"/docs/openapi.json", "application/json", docsCacheControl, `"openapi":"3.0.3"`},
+		{"/docs/assets/swagger-ui-bundle.js", "text/javascript; charset=utf-8", assetsCacheControl, "SwaggerUIBundle"},
+		{"/docs/assets/swagger-ui.css", "text/css; charset=utf-8", assetsCacheControl, ".swagger-ui"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := serve(r, http.MethodGet, tt.path)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status got=%d want=200", rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Fatalf("content-type got=%q want=%q", ct, tt.contentType)
+			}
+			if cc := rec.Header().Get("Cache-Control"); cc != tt.cache {
+				t.Fatalf("cache-control got=%q want=%q", cc, tt.cache)
+			}
+			if rec.Header().Get("ETag") == "" {
+				t.Fatalf("missing ETag")
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Fatalf("body does not contain %q", tt.contains)
+			}
+		})
+	}
+}
+
+func TestDocs_JSONStringifiesKeys(t *testing.T) {
+	rec := serve(newDocsEngine(t), http.MethodGet, "/docs/openapi.json")
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Responses map[string]any `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if _, ok := doc.Paths["/ping"]["get"].Responses["200"]; !ok {
+		t.Fatalf("status code key lost: %s", rec.Body.String())
+	}
+}
+
+func TestDocs_NotModified(t *testing.T) {
+	r := newDocsEngine(t)
+	etag := serve(r, http.MethodGet, "/docs/openapi.yaml").Header().Get("ETag")
+
+	req := httptest.NewRequest(http.MethodGet, "/docs/openapi.yaml", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("status got=%d body=%q want 304 without body", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDocs_InvalidSpec(t *testing.T) {
+	if err := RegisterDocs(gin.New(), []byte("openapi: [unclosed"), "/docs"); err == nil {
+		t.Fatalf("expected error for invalid YAML")
+	}
+}
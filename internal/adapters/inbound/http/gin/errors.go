@@ -0,0 +1,23 @@
+package ginadapter
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+)
+
+// writeError renders the legacy ErrorBody, or an RFC 7807 problem when the
+// client sends "Accept: application/problem+json".
+func writeError(c *gin.Context, status int, body presenter.ErrorBody) {
+	if presenter.WantsProblem(c.GetHeader("Accept")) {
+		c.Header("Content-Type", presenter.ProblemContentType)
+		c.JSON(status, presenter.ToProblem(status, body, c.Request.URL.RequestURI()))
+		return
+	}
+	c.JSON(status, body)
+}
+
+// writeUseCaseError maps a use case error before rendering it.
+func writeUseCaseError(c *gin.Context, err error) {
+	status, body := presenter.MapError(err)
+	writeError(c, status, body)
+}
@@ -0,0 +1,176 @@
+package ginadapter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache policy of the frontend:
+// - Vite fingerprints everything under assets/ (name-[hash].js): cache forever
+// - index.html and the public/ files keep their names: revalidate with the ETag
+const (
+	frontendCacheControl = "no-cache"
+	hashedCacheControl   = "public, max-age=31536000, immutable"
+)
+
+// gzipMinSize skips files where the gzip framing costs more than it saves.
+const gzipMinSize = 1024
+
+// apiPrefixes never fall back to the SPA: an unknown API path stays a 404.
+var apiPrefixes = []string{"/v1", "/docs"}
+
+// Frontend is a built single-page app (web/dist) held in memory.
+type Frontend struct {
+	files map[string]*staticFile // slash-separated name, e.g. "assets/index-3f2a.js"
+	index *staticFile
+}
+
+type staticFile struct {
+	name         string
+	contentType  string
+	cacheControl string
+	content      []byte
+	etag         string
+	gzipped      []byte // nil when not worth compressing
+	gzipETag     string
+}
+
+// LoadFrontend reads every file of fsys (the embedded build or os.DirFS);
+// a new build needs a restart. fsys must contain index.html.
+func LoadFrontend(fsys fs.FS) (*Frontend, error) {
+	if fsys == nil {
+		return nil, errors.New("nil frontend fs")
+	}
+	f := &Frontend{files: make(map[string]*staticFile)}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sf, err := newStaticFile(name, b)
+		if err != nil {
+			return err
+		}
+		f.files[name] = sf
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if f.index = f.files["index.html"]; f.index == nil {
+		return nil, errors.New("frontend: index.html not found")
+	}
+	return f, nil
+}
+
+func newStaticFile(name string, content []byte) (*staticFile, error) {
+	sf := &staticFile{
+		name:         name,
+		contentType:  mime.TypeByExtension(path.Ext(name)),
+		cacheControl: frontendCacheControl,
+		content:      content,
+		etag:         etagOf(content, ""),
+	}
+	if strings.HasPrefix(name, "assets/") {
+		sf.cacheControl = hashedCacheControl
+	}
+	if len(content) >= gzipMinSize && compressible(sf.contentType) {
+		var buf bytes.Buffer
+		zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := zw.Write(content); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		if buf.Len() < len(content) {
+			sf.gzipped = buf.Bytes()
+			sf.gzipETag = etagOf(content, "-gzip")
+		}
+	}
+	return sf, nil
+}
+
+// handler serves the files of the build; any other GET that looks like a
+// client-side route (no extension) gets index.html so deep links work.
+func (f *Frontend) handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			return
+		}
+		p := path.Clean("/" + c.Request.URL.Path)
+		for _, prefix := range apiPrefixes {
+			if p == prefix || strings.HasPrefix(p, prefix+"/") {
+				return
+			}
+		}
+
+		name := strings.TrimPrefix(p, "/")
+		if name == "" {
+			name = "index.html"
+		}
+		sf, ok := f.files[name]
+		if !ok {
+			if path.Ext(name) != "" {
+				return // a missing asset is a 404, not the app
+			}
+			sf = f.index
+		}
+		sf.serve(c)
+	}
+}
+
+func (sf *staticFile) serve(c *gin.Context) {
+	h := c.Writer.Header()
+	h.Set("Cache-Control", sf.cacheControl)
+	if sf.contentType != "" {
+		h.Set("Content-Type", sf.contentType)
+	}
+	content, etag := sf.content, sf.etag
+	if sf.gzipped != nil {
+		h.Add("Vary", "Accept-Encoding")
+		if negotiateEncoding(c.GetHeader("Accept-Encoding"), "gzip") == "gzip" {
+			h.Set("Content-Encoding", "gzip")
+			content, etag = sf.gzipped, sf.gzipETag
+		}
+	}
+	h.Set("ETag", etag)
+	http.ServeContent(c.Writer, c.Request, sf.name, time.Time{}, bytes.NewReader(content))
+}
+
+func etagOf(content []byte, suffix string) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:8]) + suffix + `"`
+}
+
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasSuffix(mediaType, "+xml"), strings.HasSuffix(mediaType, "+json"):
+		return true
+	}
+	switch mediaType {
+	case "application/javascript", "application/json", "application/wasm", "application/xml":
+		return true
+	}
+	return false
+}
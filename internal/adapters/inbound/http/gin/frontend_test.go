@@ -0,0 +1,140 @@
+package ginadapter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+)
+
+var bigJS = strings.Repeat("console.log('packs');\n", 200)
+
+func newFrontendHandler(t *testing.T) http.Handler {
+	t.Helper()
+	frontend, err := LoadFrontend(fstest.MapFS{
+		"index.html":           {Data: []byte(`<!doctype html><div id="root"></div>`)},
+		"vite.svg":             {Data: []byte(`<svg/>`)},
+		"assets/index-3f2a.js": {Data: []byte(bigJS)},
+	})
+	if err != nil {
+		t.Fatalf("LoadFrontend: %v", err)
+	}
+	return BuildHandler(ctr.NewController(&fakeCalc{}, &fakeGet{}), WithFrontend(frontend))
+}
+
+func TestFrontend_Routes(t *testing.T) {
+	h := newFrontendHandler(t)
+
+	tests := []struct {
+		path     string
+		status   int
+		cache    string
+		contains string
+	}{
+		{"/", http.StatusOK, frontendCacheControl, `id="root"`},
+		{"/index.html", http.StatusOK, frontendCacheControl, `id="root"`},
+		{"/calculator", http.StatusOK, frontendCacheControl, `id="root"`}, // SPA fallback
+		{"/orders/42/summary", http.StatusOK, frontendCacheControl, `id="root"`},
+		{"/vite.svg", http.StatusOK, frontendCacheControl, "<svg/>"},
+		{"/assets/index-3f2a.js", http.StatusOK, hashedCacheControl, "console.log"},
+		{"/assets/missing-1234.js", http.StatusNotFound, "", ""},
+		{"/v1/unknown", http.StatusNotFound, "", ""},
+		{"/docs/unknown", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, tt.path)
+			if rec.Code != tt.status {
+				t.Fatalf("status got=%d want=%d", rec.Code, tt.status)
+			}
+			if cc := rec.Header().Get("Cache-Control"); cc != tt.cache {
+				t.Fatalf("cache-control got=%q want=%q", cc, tt.cache)
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Fatalf("body %q does not contain %q", rec.Body.String(), tt.contains)
+			}
+		})
+	}
+}
+
+func TestFrontend_APIStillWins(t *testing.T) {
+	h := newFrontendHandler(t)
+	for _, p := range []string{"/v1/packsizes", "/docs", "/healthz"} {
+		rec := serve(h, http.MethodGet, p)
+		if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), `id="root"`) {
+			t.Fatalf("GET %s status=%d body=%q", p, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := serve(h, http.MethodPost, "/calculator"); rec.Code != http.StatusNotFound {
+		t.Fatalf("POST /calculator status got=%d want=404", rec.Code)
+	}
+}
+
+func TestFrontend_Gzip(t *testing.T) {
+	h := newFrontendHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/index-3f2a.js", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("headers: %v", rec.Header())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	plain, err := io.ReadAll(zr)
+	if err != nil || string(plain) != bigJS {
+		t.Fatalf("decompressed body mismatch (err=%v)", err)
+	}
+	gzETag := rec.Header().Get("ETag")
+
+	// identity and gzip are different representations
+	rec = serve(h, http.MethodGet, "/assets/index-3f2a.js")
+	if rec.Header().Get("Content-Encoding") != "" || !bytes.Equal(rec.Body.Bytes(), []byte(bigJS)) {
+		t.Fatalf("identity response expected, headers: %v", rec.Header())
+	}
+	if rec.Header().Get("ETag") == gzETag {
+		t.Fatalf("gzip and identity share the ETag %s", gzETag)
+	}
+
+	// small files are not worth it
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("index.html should not be compressed")
+	}
+}
+
+func TestFrontend_NotModified(t *testing.T) {
+	h := newFrontendHandler(t)
+	etag := serve(h, http.MethodGet, "/calculator").Header().Get("ETag")
+
+	req := httptest.NewRequest(http.MethodGet, "/calculator", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("status got=%d want=304", rec.Code)
+	}
+}
+
+func TestLoadFrontend_RequiresIndex(t *testing.T) {
+	if _, err := LoadFrontend(fstest.MapFS{"app.js": {Data: []byte("x")}}); err == nil {
+		t.Fatalf("expected error without index.html")
+	}
+	if _, err := LoadFrontend(nil); err == nil {
+		t.Fatalf("expected error for nil fs")
+	}
+}
@@ -0,0 +1,229 @@
+package ginadapter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/idempotency"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+)
+
+const (
+	// IdempotencyKeyHeader follows the IETF draft "The Idempotency-Key HTTP
+	// Header Field".
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// ReplayedHeader marks a response served from the idempotency store.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 255
+	// maxIdempotentBodySize bounds the request body read to fingerprint it
+	maxIdempotentBodySize = 1 << 20
+	// maxRecordedBodySize bounds the response kept in memory for the store;
+	// a larger one is sent but not stored, so a retry runs again
+	maxRecordedBodySize = 1 << 20
+)
+
+// replayedHeaders are the response headers kept with a record; the others
+// (Vary, Content-Encoding, ...) are set again by the middlewares on replay.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// Idempotency answers retries of a non-safe request (POST, PUT, PATCH,
+// DELETE) carrying an Idempotency-Key with the first response, for ttl:
+// - same key and caller, same method/URI/body: the stored response is replayed
+// - same key and caller, other payload: 422 idempotency_key_reused
+// - same key while the first request is running: 409 idempotency_key_in_use
+//
+// The caller is the Authorization header when present, the client IP
+// otherwise. 5xx responses are not stored, so a retry runs again. In-flight
+// detection is local to the process. A body above maxIdempotentBodySize
+// gets a 413. BuildHandler only runs it on the routes that document
+// idempotencyKeyParam.
+func Idempotency(store idempotency.Store, ttl time.Duration) gin.HandlerFunc {
+	var inFlight sync.Map // store key -> struct{}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !unsafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			status, body := presenter.InvalidParam(IdempotencyKeyHeader, "must be at most 255 characters")
+			writeError(c, status, body)
+			c.Abort()
+			return
+		}
+
+		payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(c, http.StatusRequestEntityTooLarge, presenter.ErrorBody{
+					Code:    presenter.CodeInvalidRequest,
+					Message: fmt.Sprintf("the body of a request with %s exceeds %d MiB", IdempotencyKeyHeader, maxIdempotentBodySize>>20),
+				})
+			} else {
+				status, body := presenter.InvalidParam("body", "could not be read")
+				writeError(c, status, body)
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(payload))
+
+		storeKey := callerOf(c) + "\n" + key
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.RequestURI(), payload)
+
+		if _, busy := inFlight.LoadOrStore(storeKey, struct{}{}); busy {
+			writeError(c, http.StatusConflict, presenter.ErrorBody{
+				Code:    presenter.CodeIdempotencyKeyInUse,
+				Message: "a request with this Idempotency-Key is still being processed",
+			})
+			c.Abort()
+			return
+		}
+		defer inFlight.Delete(storeKey)
+
+		rec, ok, err := store.Get(storeKey)
+		if err != nil {
+			writeUseCaseError(c, err)
+			c.Abort()
+			return
+		}
+		if ok {
+			if rec.Fingerprint != fingerprint {
+				writeError(c, http.StatusUnprocessableEntity, presenter.ErrorBody{
+					Code:    presenter.CodeIdempotencyKeyReused,
+					Message: "Idempotency-Key was already used with a different payload",
+				})
+			} else {
+				replay(c, rec)
+			}
+			c.Abort()
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.Status() >= http.StatusInternalServerError || w.overflow {
+			return
+		}
+		rec = idempotency.Record{Fingerprint: fingerprint, Status: w.Status(), Body: w.body.Bytes()}
+		for _, name := range replayedHeaders {
+			if v := w.Header().Values(name); len(v) > 0 {
+				if rec.Header == nil {
+					rec.Header = make(http.Header)
+				}
+				rec.Header[name] = v
+			}
+		}
+		if err := store.Put(storeKey, rec, ttl); err != nil {
+			// the response is already sent: the retry will just run again
+			log.Printf("idempotency: storing %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+	}
+}
+
+func replay(c *gin.Context, rec idempotency.Record) {
+	h := c.Writer.Header()
+	for name, values := range rec.Header {
+		h[name] = values
+	}
+	h.Set(ReplayedHeader, "true")
+	c.Status(rec.Status)
+	_, _ = c.Writer.Write(rec.Body)
+}
+
+func unsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// callerOf scopes the keys: two clients may pick the same one.
+func callerOf(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		return "auth:" + hex.EncodeToString(sum[:])
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func requestFingerprint(method, uri string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, method+" "+uri+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter keeps a copy of the body sent to the client, up to
+// maxRecordedBodySize.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	overflow bool // the copy was dropped: too large to store
+}
+
+func (w *recordingWriter) record(n int, write func()) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+n > maxRecordedBodySize {
+		w.overflow = true
+		w.body = bytes.Buffer{}
+		return
+	}
+	write()
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.record(len(p), func() { w.body.Write(p) })
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record(len(s), func() { w.body.WriteString(s) })
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Unwrap lets http.ResponseController reach the connection (deadlines).
+func (w *recordingWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// onlyOn runs h on the given routes ("METHOD /path" as registered, see
+// gin.Context.FullPath) and just continues on the others.
+func onlyOn(routes map[string]bool, h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !routes[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+		h(c)
+	}
+}
+
+// idempotentRoutes are the routes documenting idempotencyKeyParam.
+func idempotentRoutes() map[string]bool {
+	routes := map[string]bool{}
+	for _, rt := range v1Routes() {
+		for _, p := range rt.Params {
+			if p.Name == idempotencyKeyParam.Name && p.In == idempotencyKeyParam.In {
+				routes[rt.Method+" "+rt.Path] = true
+			}
+		}
+	}
+	return routes
+}
@@ -0,0 +1,211 @@
+package ginadapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/idempotency"
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+)
+
+// countingCalc counts executions and can hold them until release is closed.
+type countingCalc struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+	err     error
+}
+
+func (f *countingCalc) Execute(_ context.Context, in uc.CalculatePacksInput) (uc.CalculatePacksOutput, error) {
+	f.calls.Add(1)
+	if f.started != nil {
+		f.started <- struct{}{}
+		<-f.release
+	}
+	if f.err != nil {
+		return uc.CalculatePacksOutput{}, f.err
+	}
+	return uc.CalculatePacksOutput{ItemsByPack: map[int]int{in.Quantity: 1}, TotalItems: in.Quantity, TotalPacks: 1}, nil
+}
+
+func newIdempotentHandler(calc *countingCalc) http.Handler {
+	store := idempotency.NewMemoryStore(idempotency.Options{})
+	return BuildHandler(ctr.NewController(calc, &fakeGet{}), WithIdempotency(store, time.Hour))
+}
+
+func postCalculate(h http.Handler, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/calculate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_ReplaysTheFirstResponse(t *testing.T) {
+	calc := &countingCalc{}
+	h := newIdempotentHandler(calc)
+	key := map[string]string{IdempotencyKeyHeader: "order-1"}
+
+	first := postCalculate(h, `{"quantity":250}`, key)
+	retry := postCalculate(h, `{"quantity":250}`, key)
+
+	if first.Code != http.StatusOK || retry.Code != http.StatusOK {
+		t.Fatalf("status first=%d retry=%d", first.Code, retry.Code)
+	}
+	if retry.Body.String() != first.Body.String() || retry.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Fatalf("replay differs\nfirst: %s\nretry: %s", first.Body.String(), retry.Body.String())
+	}
+	if first.Header().Get(ReplayedHeader) != "" || retry.Header().Get(ReplayedHeader) != "true" {
+		t.Fatalf("replayed header first=%q retry=%q", first.Header().Get(ReplayedHeader), retry.Header().Get(ReplayedHeader))
+	}
+	if n := calc.calls.Load(); n != 1 {
+		t.Fatalf("calculations got=%d want=1", n)
+	}
+}
+
+func TestIdempotency_Scope(t *testing.T) {
+	tests := []struct {
+		name      string
+		second    string
+		headers   map[string]string
+		wantCode  int
+		wantCalls int32
+	}{
+		{"other payload", `{"quantity":500}`, map[string]string{IdempotencyKeyHeader: "k"}, http.StatusUnprocessableEntity, 1},
+		{"other key", `{"quantity":250}`, map[string]string{IdempotencyKeyHeader: "k2"}, http.StatusOK, 2},
+		{"other caller", `{"quantity":250}`, map[string]string{IdempotencyKeyHeader: "k", "Authorization": "Bearer b"}, http.StatusOK, 2},
+		{"no key", `{"quantity":250}`, nil, http.StatusOK, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := &countingCalc{}
+			h := newIdempotentHandler(calc)
+			postCalculate(h, `{"quantity":250}`, map[string]string{IdempotencyKeyHeader: "k"})
+
+			rec := postCalculate(h, tt.second, tt.headers)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status got=%d want=%d body=%s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantCode == http.StatusUnprocessableEntity && !strings.Contains(rec.Body.String(), `"idempotency_key_reused"`) {
+				t.Fatalf("unexpected body: %s", rec.Body.String())
+			}
+			if n := calc.calls.Load(); n != tt.wantCalls {
+				t.Fatalf("calculations got=%d want=%d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	calc := &countingCalc{err: errors.New("disk on fire")}
+	h := newIdempotentHandler(calc)
+	key := map[string]string{IdempotencyKeyHeader: "order-1"}
+
+	if rec := postCalculate(h, `{"quantity":250}`, key); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status got=%d want=500", rec.Code)
+	}
+	calc.err = nil
+	if rec := postCalculate(h, `{"quantity":250}`, key); rec.Code != http.StatusOK || rec.Header().Get(ReplayedHeader) != "" {
+		t.Fatalf("retry after 500 must run again: status=%d", rec.Code)
+	}
+	if n := calc.calls.Load(); n != 2 {
+		t.Fatalf("calculations got=%d want=2", n)
+	}
+}
+
+func TestIdempotency_InFlight(t *testing.T) {
+	calc := &countingCalc{started: make(chan struct{}), release: make(chan struct{})}
+	h := newIdempotentHandler(calc)
+	key := map[string]string{IdempotencyKeyHeader: "order-1"}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postCalculate(h, `{"quantity":250}`, key) }()
+	<-calc.started
+
+	rec := postCalculate(h, `{"quantity":250}`, key)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), `"idempotency_key_in_use"`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	close(calc.release)
+	if first := <-done; first.Code != http.StatusOK {
+		t.Fatalf("first request status=%d", first.Code)
+	}
+	if rec := postCalculate(h, `{"quantity":250}`, key); rec.Header().Get(ReplayedHeader) != "true" {
+		t.Fatalf("retry after completion must be replayed")
+	}
+}
+
+func TestIdempotency_KeyTooLong(t *testing.T) {
+	calc := &countingCalc{}
+	rec := postCalculate(newIdempotentHandler(calc), `{"quantity":250}`, map[string]string{IdempotencyKeyHeader: strings.Repeat("k", 256)})
+	if rec.Code != http.StatusBadRequest || calc.calls.Load() != 0 {
+		t.Fatalf("status=%d calls=%d", rec.Code, calc.calls.Load())
+	}
+}
+
+func TestIdempotency_ReplayIsCompressedAgain(t *testing.T) {
+	calc := &countingCalc{}
+	store := idempotency.NewMemoryStore(idempotency.Options{})
+	h := BuildHandler(ctr.NewController(calc, &fakeGet{}), WithIdempotency(store, time.Hour), WithCompression(1))
+	headers := map[string]string{IdempotencyKeyHeader: "order-1", "Accept-Encoding": "gzip"}
+
+	first := postCalculate(h, `{"quantity":250}`, headers)
+	retry := postCalculate(h, `{"quantity":250}`, headers)
+	for _, rec := range []*httptest.ResponseRecorder{first, retry} {
+		if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Values("Vary")[0] != "Accept-Encoding" || len(rec.Header().Values("Vary")) != 1 {
+			t.Fatalf("headers: %v", rec.Header())
+		}
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Fatalf("compressed replay differs")
+	}
+}
+
+func TestIdempotency_BodyTooLarge(t *testing.T) {
+	calc := &countingCalc{}
+	body := `{"quantity":250,"pad":"` + strings.Repeat("x", maxIdempotentBodySize) + `"}`
+	rec := postCalculate(newIdempotentHandler(calc), body, map[string]string{IdempotencyKeyHeader: "order-1"})
+	if rec.Code != http.StatusRequestEntityTooLarge || calc.calls.Load() != 0 {
+		t.Fatalf("status=%d calls=%d body=%s", rec.Code, calc.calls.Load(), rec.Body.String())
+	}
+}
+
+func TestIdempotency_LargeResponseIsNotStored(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	w := &recordingWriter{ResponseWriter: c.Writer}
+	_, _ = w.WriteString(strings.Repeat("x", maxRecordedBodySize))
+	_, _ = w.Write([]byte("y"))
+	if !w.overflow || w.body.Len() != 0 {
+		t.Fatalf("overflow=%v kept=%d", w.overflow, w.body.Len())
+	}
+	if rec.Body.Len() != maxRecordedBodySize+1 {
+		t.Fatalf("client got %d bytes", rec.Body.Len())
+	}
+}
+
+func TestIdempotentRoutes(t *testing.T) {
+	routes := idempotentRoutes()
+	for _, want := range []string{"POST /v1/calculate", "POST /v1/admin/packsizes/schedule", "POST /v1/admin/packsizes/:size/disable"} {
+		if !routes[want] {
+			t.Fatalf("%s must be idempotent: %v", want, routes)
+		}
+	}
+	for rt := range routes {
+		if !strings.HasPrefix(rt, http.MethodPost+" ") {
+			t.Fatalf("only POST routes take an Idempotency-Key: %s", rt)
+		}
+	}
+}
@@ -17,7 +17,8 @@ func LoggerMiddleware() gin.HandlerFunc {
 		status := c.Writer.Status()
 		method := c.Request.Method
 		path := c.Request.URL.Path
+		id := c.Writer.Header().Get(RequestIDHeader) // set by RequestID, when in use
 
-		log.Printf("[%d] %s %s (%s)", status, method, path, latency.Round(time.Millisecond))
+		log.Printf("[%d] %s %s (%s) %s", status, method, path, latency.Round(time.Millisecond), id)
 	}
 }
@@ -0,0 +1,192 @@
+package ginadapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+)
+
+// ValidationMode tells what the OpenAPI middleware checks.
+type ValidationMode string
+
+const (
+	ValidateOff       ValidationMode = "off"
+	ValidateRequests  ValidationMode = "requests"
+	ValidateResponses ValidationMode = "all" // requests and responses; meant for test/dev
+)
+
+// ParseValidationMode accepts "", "off", "requests" and "all".
+func ParseValidationMode(s string) (ValidationMode, error) {
+	switch m := ValidationMode(s); m {
+	case "":
+		return ValidateOff, nil
+	case ValidateOff, ValidateRequests, ValidateResponses:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown OpenAPI validation mode: %q", s)
+	}
+}
+
+// OpenAPIValidator checks the traffic of documented operations against the
+// spec. Routes the spec does not know (docs, health checks) pass untouched.
+//   - requests violating the contract are answered with 400 invalid_request;
+//     malformed JSON is left to the handler, which reports its position
+//   - in ValidateResponses mode the response is buffered and replaced by a
+//     500 invalid_response when it drifts from the spec
+func OpenAPIValidator(spec []byte, mode ValidationMode) (gin.HandlerFunc, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi spec: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+	// match on paths only: the servers list is informative
+	doc.Servers = nil
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		SkipSettingDefaults:   true, // never rewrite the request
+		IncludeResponseStatus: true,
+	}
+	// streams are validated up to the headers: reading the body would
+	// buffer it, and so would holding the response. CSV bodies are left to
+	// the handler too: rows of different lengths are valid input there.
+	skipBodyOpts := *opts
+	skipBodyOpts.ExcludeRequestBody = true
+
+	return func(c *gin.Context) {
+		if mode == ValidateOff {
+			c.Next()
+			return
+		}
+		route, params, err := router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+		streaming := isStreaming(route.Operation)
+		in := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: params,
+			Route:      route,
+			Options:    opts,
+		}
+		if streaming || c.ContentType() == CSVContentType {
+			in.Options = &skipBodyOpts
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), in); err != nil && !isParseError(err) {
+			status, body := presenter.ContractViolation(requestViolation(err))
+			writeError(c, status, body)
+			c.Abort()
+			return
+		}
+
+		if mode != ValidateResponses || streaming {
+			c.Next()
+			return
+		}
+		validateResponse(c, in, route)
+	}, nil
+}
+
+// isStreaming reports operations exchanging NDJSON.
+func isStreaming(op *openapi3.Operation) bool {
+	if op.RequestBody != nil && op.RequestBody.Value != nil && op.RequestBody.Value.Content.Get(NDJSONContentType) != nil {
+		return true
+	}
+	for _, resp := range op.Responses.Map() {
+		if resp.Value != nil && resp.Value.Content.Get(NDJSONContentType) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func isParseError(err error) bool {
+	var pe *openapi3filter.ParseError
+	return errors.As(err, &pe)
+}
+
+// requestViolation points at the offending parameter or body field.
+func requestViolation(err error) presenter.FieldError {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return presenter.FieldError{Reason: err.Error()}
+	}
+	fe := presenter.FieldError{Reason: reqErr.Reason}
+	if reqErr.Parameter != nil {
+		fe.Field = reqErr.Parameter.Name
+	}
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		fe.Reason = schemaErr.Reason
+		if fe.Field == "" {
+			fe.Field = presenter.JSONPointerPath(schemaErr.JSONPointer())
+		}
+	}
+	if fe.Reason == "" {
+		fe.Reason = err.Error()
+	}
+	return fe
+}
+
+func validateResponse(c *gin.Context, in *openapi3filter.RequestValidationInput, route *routers.Route) {
+	w := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	err := openapi3filter.ValidateResponse(context.WithoutCancel(c.Request.Context()), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: in,
+		Status:                 w.status,
+		Header:                 w.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(w.body.Bytes())),
+		Options:                in.Options,
+	})
+	if err == nil {
+		c.Writer.WriteHeader(w.status)
+		_, _ = c.Writer.Write(w.body.Bytes())
+		return
+	}
+
+	log.Printf("openapi: %s %s answered %d outside the contract: %v", route.Method, route.Path, w.status, err)
+	c.Writer.Header().Del("Content-Type")
+	c.Writer.Header().Del("Content-Length")
+	writeError(c, http.StatusInternalServerError, presenter.ErrorBody{
+		Code:    presenter.CodeInvalidResponse,
+		Message: "response does not match the API contract",
+		Details: []presenter.FieldError{{Reason: err.Error()}},
+	})
+}
+
+// bufferedWriter holds the response until it has been validated.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int)              { w.status = code }
+func (w *bufferedWriter) WriteHeaderNow()                   {}
+func (w *bufferedWriter) Status() int                       { return w.status }
+func (w *bufferedWriter) Size() int                         { return w.body.Len() }
+func (w *bufferedWriter) Written() bool                     { return w.body.Len() > 0 }
+func (w *bufferedWriter) Write(b []byte) (int, error)       { return w.body.Write(b) }
+func (w *bufferedWriter) WriteString(s string) (int, error) { return w.body.WriteString(s) }
@@ -0,0 +1,122 @@
+package ginadapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+)
+
+func TestParseValidationMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ValidationMode
+		wantErr bool
+	}{
+		{"", ValidateOff, false},
+		{"off", ValidateOff, false},
+		{"requests", ValidateRequests, false},
+		{"all", ValidateResponses, false},
+		{"strict", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseValidationMode(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Fatalf("ParseValidationMode(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestOpenAPIValidator_Requests(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		wantField  string
+		reachesUC  bool
+	}{
+		{"valid", "/v1/calculate", `{"quantity":10}`, http.StatusOK, "", true},
+		{"below minimum", "/v1/calculate", `{"quantity":0}`, http.StatusBadRequest, "quantity", false},
+		{"override entry", "/v1/calculate", `{"quantity":10,"packsOverride":[250,0]}`, http.StatusBadRequest, "packsOverride[1]", false},
+		{"missing quantity", "/v1/calculate", `{}`, http.StatusBadRequest, "quantity", false},
+		{"query parameter", "/v1/calculate?explain=maybe", `{"quantity":10}`, http.StatusBadRequest, "explain", false},
+		// malformed JSON is reported by the handler, with its offset
+		{"syntax error", "/v1/calculate", `{"quantity":}`, http.StatusBadRequest, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := &fakeCalc{out: uc.CalculatePacksOutput{ItemsByPack: map[int]int{250: 1}, TotalItems: 250, TotalPacks: 1, Leftover: 240}}
+			h := BuildHandler(ctr.NewController(calc, &fakeGet{}), WithOpenAPIValidation(ValidateRequests))
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status got=%d want=%d body=%s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if reached := calc.lastIn.Quantity != 0; reached != tt.reachesUC {
+				t.Fatalf("use case reached=%v want=%v", reached, tt.reachesUC)
+			}
+			if tt.wantField == "" {
+				return
+			}
+			var body struct {
+				Code    string `json:"code"`
+				Details []struct {
+					Field string `json:"field"`
+				} `json:"details"`
+			}
+			_ = json.Unmarshal(rec.Body.Bytes(), &body)
+			if body.Code != "invalid_request" || len(body.Details) != 1 || body.Details[0].Field != tt.wantField {
+				t.Fatalf("unexpected body: %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestOpenAPIValidator_UndocumentedRoutesPass(t *testing.T) {
+	h := BuildHandler(ctr.NewController(&fakeCalc{}, &fakeGet{}), WithOpenAPIValidation(ValidateResponses))
+	for _, p := range []string{"/healthz", "/docs/openapi.yaml"} {
+		if rec := serve(h, http.MethodGet, p); rec.Code != http.StatusOK {
+			t.Fatalf("GET %s status=%d", p, rec.Code)
+		}
+	}
+}
+
+func TestOpenAPIValidator_ResponseDrift(t *testing.T) {
+	// leftover < 0 and no totalItems: the handler answers outside the spec
+	drifted := &fakeCalc{out: uc.CalculatePacksOutput{ItemsByPack: map[int]int{}, Leftover: -1}}
+
+	for _, tt := range []struct {
+		mode       ValidationMode
+		wantStatus int
+	}{
+		{ValidateRequests, http.StatusOK},
+		{ValidateResponses, http.StatusInternalServerError},
+	} {
+		h := BuildHandler(ctr.NewController(drifted, &fakeGet{}), WithOpenAPIValidation(tt.mode))
+		req := httptest.NewRequest(http.MethodPost, "/v1/calculate", bytes.NewBufferString(`{"quantity":10}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s: status got=%d want=%d body=%s", tt.mode, rec.Code, tt.wantStatus, rec.Body.String())
+		}
+		if tt.mode == ValidateResponses {
+			var body struct{ Code string }
+			_ = json.Unmarshal(rec.Body.Bytes(), &body)
+			if body.Code != "invalid_response" {
+				t.Fatalf("unexpected body: %s", rec.Body.String())
+			}
+		}
+	}
+}
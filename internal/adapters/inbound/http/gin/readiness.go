@@ -0,0 +1,62 @@
+package ginadapter
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Readiness states, from best to worst.
+const (
+	ReadyOK       = "ok"       // fully operational
+	ReadyDegraded = "degraded" // serving, e.g. from a fallback source
+	ReadyDown     = "down"     // cannot serve: /readyz answers 503
+)
+
+// Readiness is the answer of one readiness check; Detail is free JSON.
+type Readiness struct {
+	Status string `json:"status"`
+	Detail any    `json:"detail,omitempty"`
+}
+
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) Readiness
+}
+
+// WithReadinessCheck adds a named check to /readyz, run on every probe with
+// the context of the probe request.
+func WithReadinessCheck(name string, check func(ctx context.Context) Readiness) Option {
+	return func(o *options) { o.readiness = append(o.readiness, readinessCheck{name, check}) }
+}
+
+// readyz answers "ready" without checks. With checks it answers
+// {"status", "checks": {name: Readiness}}, the status being the worst one:
+// 200 while ok or degraded (the instance still serves), 503 when down.
+func readyz(checks []readinessCheck) gin.HandlerFunc {
+	if len(checks) == 0 {
+		return func(c *gin.Context) { c.String(http.StatusOK, "ready") }
+	}
+	rank := map[string]int{ReadyOK: 0, ReadyDegraded: 1, ReadyDown: 2}
+	return func(c *gin.Context) {
+		status := ReadyOK
+		results := make(map[string]Readiness, len(checks))
+		for _, rc := range checks {
+			r := rc.check(c.Request.Context())
+			if _, known := rank[r.Status]; !known {
+				r.Status = ReadyDown
+			}
+			if rank[r.Status] > rank[status] {
+				status = r.Status
+			}
+			results[rc.name] = r
+		}
+		code := http.StatusOK
+		if status == ReadyDown {
+			code = http.StatusServiceUnavailable
+		}
+		c.Header("Cache-Control", "no-store")
+		c.JSON(code, gin.H{"status": status, "checks": results})
+	}
+}
@@ -0,0 +1,58 @@
+package ginadapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+)
+
+func TestReadyz(t *testing.T) {
+	check := func(status string) Option {
+		return WithReadinessCheck("packsizes", func(context.Context) Readiness {
+			return Readiness{Status: status, Detail: map[string]any{"source": "env"}}
+		})
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantStatus int
+		wantBody   string // "" for a JSON body
+		wantReady  string
+	}{
+		{"no checks", nil, http.StatusOK, "ready", ""},
+		{"ok", []Option{check(ReadyOK)}, http.StatusOK, "", ReadyOK},
+		{"degraded", []Option{check(ReadyDegraded)}, http.StatusOK, "", ReadyDegraded},
+		{"down", []Option{check(ReadyDegraded), WithReadinessCheck("other", func(context.Context) Readiness { return Readiness{Status: ReadyDown} })}, http.StatusServiceUnavailable, "", ReadyDown},
+		{"unknown status", []Option{check("meh")}, http.StatusServiceUnavailable, "", ReadyDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := BuildHandler(ctr.NewController(&fakeCalc{}, &fakeGet{}), tt.opts...)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status got=%d want=%d body=%s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantBody != "" {
+				if rec.Body.String() != tt.wantBody {
+					t.Fatalf("body got %q", rec.Body.String())
+				}
+				return
+			}
+			var body struct {
+				Status string               `json:"status"`
+				Checks map[string]Readiness `json:"checks"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v (%s)", err, rec.Body.String())
+			}
+			if body.Status != tt.wantReady || body.Checks["packsizes"].Detail == nil {
+				t.Fatalf("body got %s", rec.Body.String())
+			}
+		})
+	}
+}
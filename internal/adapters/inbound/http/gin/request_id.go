@@ -0,0 +1,54 @@
+package ginadapter
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/audit"
+)
+
+// RequestIDHeader identifies a request in the logs and the audit trail.
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLen bounds the X-Request-Id kept from the client.
+const maxRequestIDLen = 128
+
+// RequestID keeps the X-Request-Id of the client (e.g. set by a proxy) when
+// it is sane, otherwise generates one, and sends it back. The request
+// context carries it, with the client address, as an audit.Caller.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		ctx := audit.WithCaller(c.Request.Context(), audit.Caller{SourceIP: c.ClientIP(), RequestID: id})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// validRequestID accepts up to maxRequestIDLen letters, digits and "-_.:",
+// which keeps the logs and the audit lines clean.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // never fails (crypto/rand panics instead)
+	return hex.EncodeToString(b)
+}
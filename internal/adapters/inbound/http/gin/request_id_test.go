@@ -0,0 +1,51 @@
+package ginadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/audit"
+)
+
+func TestRequestID(t *testing.T) {
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, audit.CallerFrom(c.Request.Context()).RequestID)
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"none", "", false},
+		{"kept", "req-1.a:b_C", true},
+		{"bad characters", "req 1\nforged", false},
+		{"too long", strings.Repeat("a", maxRequestIDLen+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(RequestIDHeader)
+			if got != rec.Body.String() {
+				t.Fatalf("header %q, context %q", got, rec.Body.String())
+			}
+			if tt.keep && got != tt.incoming {
+				t.Fatalf("got %q want %q", got, tt.incoming)
+			}
+			if !tt.keep && (got == tt.incoming || len(got) != 32) {
+				t.Fatalf("got %q, want a new ID", got)
+			}
+		})
+	}
+}
@@ -1,25 +1,134 @@
 package ginadapter
 
 import (
+	"expvar"
+	"maps"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/idempotency"
 	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
-	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/audit"
 )
 
-func BuildHandler(ctrl *ctr.Controller) http.Handler {
+// Option customizes BuildHandler.
+type Option func(*options)
+
+type options struct {
+	validation            ValidationMode
+	frontend              *Frontend
+	compressMinSize       int    // 0: no compression
+	packSizesCacheControl string // Cache-Control of GET /v1/packsizes
+	idempotency           idempotency.Store
+	idempotencyTTL        time.Duration
+	adminTokens           map[string]string // actor -> token; empty: admin routes are not registered
+	auditLog              audit.Log         // nil: admin calls are not recorded
+	trustedProxies        []string          // nil: X-Forwarded-For and X-Real-IP are ignored
+	readiness             []readinessCheck
+}
+
+// DefaultPackSizesCacheControl lets clients keep the list but revalidate it
+// (ETag / Last-Modified) on every use.
+const DefaultPackSizesCacheControl = "no-cache"
+
+// WithOpenAPIValidation checks the traffic against the generated spec
+// (see OpenAPIValidator).
+func WithOpenAPIValidation(mode ValidationMode) Option {
+	return func(o *options) { o.validation = mode }
+}
+
+// WithFrontend serves the single-page app for every GET outside /v1 and
+// /docs (see LoadFrontend).
+func WithFrontend(f *Frontend) Option {
+	return func(o *options) { o.frontend = f }
+}
+
+// WithCompression compresses JSON responses of at least minSize bytes with br
+// or gzip (see Compression); minSize <= 0 disables it.
+func WithCompression(minSize int) Option {
+	return func(o *options) { o.compressMinSize = minSize }
+}
+
+// WithPackSizesCacheControl sets the Cache-Control of GET /v1/packsizes
+// (e.g. "public, max-age=60"); empty keeps DefaultPackSizesCacheControl.
+func WithPackSizesCacheControl(v string) Option {
+	return func(o *options) {
+		if v != "" {
+			o.packSizesCacheControl = v
+		}
+	}
+}
+
+// WithIdempotency replays the first response of each Idempotency-Key for
+// ttl (see Idempotency); a nil store disables it.
+func WithIdempotency(store idempotency.Store, ttl time.Duration) Option {
+	return func(o *options) {
+		o.idempotency = store
+		o.idempotencyTTL = ttl
+	}
+}
+
+// WithAdminToken registers the admin routes (and /debug/vars) behind
+// AdminAuth(token); an empty token leaves them out.
+func WithAdminToken(token string) Option {
+	return WithAdminTokens(map[string]string{DefaultAdminActor: token})
+}
+
+// WithAdminTokens is WithAdminToken with one token per actor (see
+// AdminAuthTokens); it adds to the tokens of the previous options.
+func WithAdminTokens(tokens map[string]string) Option {
+	return func(o *options) {
+		for actor, token := range tokens {
+			if token == "" {
+				continue
+			}
+			if o.adminTokens == nil {
+				o.adminTokens = map[string]string{}
+			}
+			o.adminTokens[actor] = token
+		}
+	}
+}
+
+// WithAuditLog records every call to an admin route, and /debug/vars (see
+// AuditCalls); a nil log disables it.
+func WithAuditLog(l audit.Log) Option {
+	return func(o *options) { o.auditLog = l }
+}
+
+// WithTrustedProxies takes the client address from X-Forwarded-For (or
+// X-Real-IP) when the request comes from one of proxies (IPs or CIDRs).
+// Without it, the headers are ignored: the audit trail and the idempotency
+// keys use the address of the connection, which a client cannot forge.
+func WithTrustedProxies(proxies []string) Option {
+	return func(o *options) { o.trustedProxies = proxies }
+}
+
+func BuildHandler(ctrl *ctr.Controller, opts ...Option) http.Handler {
+	o := options{validation: ValidateOff, packSizesCacheControl: DefaultPackSizesCacheControl}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	r := gin.New()
 	gin.SetMode(gin.ReleaseMode)
+	// gin trusts every proxy by default; an invalid list trusts none
+	if err := r.SetTrustedProxies(o.trustedProxies); err != nil {
+		_ = r.SetTrustedProxies(nil)
+	}
 
 	r.Use(gin.Recovery())
+	r.Use(RequestID())
 	r.Use(LoggerMiddleware())
 
 	// CORS
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET,POST,OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization,Accept,"+IdempotencyKeyHeader+","+RequestIDHeader)
+		c.Writer.Header().Set("Access-Control-Expose-Headers", ReplayedHeader+","+RequestIDHeader)
 		if c.Request.Method == http.MethodOptions {
 			c.AbortWithStatus(http.StatusNoContent)
 			return
@@ -27,40 +136,79 @@ func BuildHandler(ctrl *ctr.Controller) http.Handler {
 		c.Next()
 	})
 
-	v1 := r.Group("/v1")
-	{
-		v1.GET("/packsizes", func(c *gin.Context) {
-			res, err := ctrl.HandleGetPackSizes(c.Request.Context())
-			if err != nil {
-				status, body := presenter.MapError(err)
-				c.JSON(status, body)
-				return
+	// before the validator: it must see (and buffer) the plain body
+	if o.compressMinSize > 0 {
+		r.Use(Compression(o.compressMinSize))
+	}
+	// stores the final response (after the response validation), uncompressed;
+	// only on the routes that document the header. The admin ones run it
+	// after AdminAuth and AuditCalls instead, so a replay is authenticated
+	// and recorded.
+	var idempotent gin.HandlerFunc
+	idempotentRoutes := idempotentRoutes()
+	if o.idempotency != nil {
+		idempotent = Idempotency(o.idempotency, o.idempotencyTTL)
+		public := maps.Clone(idempotentRoutes)
+		for _, rt := range v1Routes() {
+			if rt.admin {
+				delete(public, rt.Method+" "+rt.Path)
 			}
-			c.JSON(http.StatusOK, res)
-		})
-
-		v1.POST("/calculate", func(c *gin.Context) {
-			var req ctr.CalculateRequest
-			if err := c.ShouldBindJSON(&req); err != nil {
-				c.JSON(http.StatusBadRequest, presenter.ErrorBody{
-					Code: "invalid_request", Message: "invalid JSON payload",
-				})
-				return
+		}
+		r.Use(onlyOn(public, idempotent))
+	}
+
+	// the spec comes from the route table: an error here is a build problem
+	spec, err := OpenAPISpec()
+	if err != nil {
+		panic(err)
+	}
+	if o.validation != ValidateOff {
+		validator, err := OpenAPIValidator(spec, o.validation)
+		if err != nil {
+			panic(err)
+		}
+		r.Use(validator)
+	}
+
+	// recorded once authenticated: the 401s are not admin calls
+	var admin []gin.HandlerFunc
+	if len(o.adminTokens) > 0 {
+		admin = append(admin, AdminAuthTokens(o.adminTokens))
+		if o.auditLog != nil {
+			admin = append(admin, AuditCalls(o.auditLog))
+		}
+	}
+	for _, rt := range v1Routes() {
+		if rt.enabled != nil && !rt.enabled(ctrl) {
+			continue
+		}
+		if rt.admin {
+			if admin == nil {
+				continue
 			}
-			res, err := ctrl.HandleCalculate(c.Request.Context(), req)
-			if err != nil {
-				status, body := presenter.MapError(err)
-				c.JSON(status, body)
-				return
+			handlers := slices.Clone(admin)
+			if idempotent != nil && idempotentRoutes[rt.Method+" "+rt.Path] {
+				handlers = append(handlers, idempotent)
 			}
-			c.JSON(http.StatusOK, res)
-		})
+			r.Handle(rt.Method, rt.Path, append(handlers, rt.handler(ctrl, &o))...)
+			continue
+		}
+		r.Handle(rt.Method, rt.Path, rt.handler(ctrl, &o))
+	}
+	if admin != nil {
+		r.GET("/debug/vars", append(slices.Clone(admin), gin.WrapH(expvar.Handler()))...)
 	}
 
 	r.GET("/healthz", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
-	r.GET("/readyz", func(c *gin.Context) { c.String(http.StatusOK, "ready") })
+	r.GET("/readyz", readyz(o.readiness))
 
-	RegisterDocs(r, "docs/api/v1/openapi.yaml", "/docs")
+	if err := RegisterDocs(r, spec, "/docs"); err != nil {
+		panic(err)
+	}
+
+	if o.frontend != nil {
+		r.NoRoute(o.frontend.handler())
+	}
 
 	return r
 }
@@ -18,11 +18,13 @@ import (
 // ---- fakes usecases ----
 
 type fakeCalc struct {
-	out uc.CalculatePacksOutput
-	err error
+	out    uc.CalculatePacksOutput
+	err    error
+	lastIn uc.CalculatePacksInput
 }
 
 func (f *fakeCalc) Execute(_ context.Context, in uc.CalculatePacksInput) (uc.CalculatePacksOutput, error) {
+	f.lastIn = in
 	return f.out, f.err
 }
 
@@ -199,6 +201,189 @@ func TestPOST_Calculate_NoPackSizes_422(t *testing.T) {
 	}
 }
 
+func TestPOST_Calculate_Explain(t *testing.T) {
+	calc := &fakeCalc{out: uc.CalculatePacksOutput{
+		ItemsByPack: map[int]int{500: 1},
+		TotalItems:  500,
+		TotalPacks:  1,
+		Leftover:    249,
+		Explanation: &uc.Explanation{
+			GCD:              250,
+			ScaledQuantity:   2,
+			ScaledUpperBound: 5,
+			RunnersUp: []uc.RunnerUp{
+				{ItemsByPack: map[int]int{250: 2}, TotalItems: 500, TotalPacks: 2, Leftover: 249, RejectedBy: "packs"},
+			},
+		},
+	}}
+	h := newTestHandler(calc, &fakeGet{})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/calculate?explain=true", bytes.NewBufferString(`{"quantity":251}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status got=%d want=%d", rec.Code, http.StatusOK)
+	}
+	if !calc.lastIn.Explain {
+		t.Fatalf("explain flag not forwarded to the use case")
+	}
+	var body struct {
+		Explanation struct {
+			GCD       int `json:"gcd"`
+			RunnersUp []struct {
+				TotalPacks int    `json:"totalPacks"`
+				RejectedBy string `json:"rejectedBy"`
+			} `json:"runnersUp"`
+		} `json:"explanation"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if body.Explanation.GCD != 250 || len(body.Explanation.RunnersUp) != 1 || body.Explanation.RunnersUp[0].RejectedBy != "packs" {
+		t.Fatalf("unexpected explanation: %s", rec.Body.String())
+	}
+}
+
+func TestPOST_Calculate_InvalidExplain_400(t *testing.T) {
+	h := newTestHandler(&fakeCalc{}, &fakeGet{})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/calculate?explain=maybe", bytes.NewBufferString(`{"quantity":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status got=%d want=%d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestPOST_Calculate_LimitExceeded_422(t *testing.T) {
+	h := newTestHandler(
+		&fakeCalc{err: usecases.ErrQuantityTooLarge.With(map[string]any{"limit": "maxQuantity", "max": 100, "actual": 101})},
+		&fakeGet{},
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/calculate", bytes.NewBufferString(`{"quantity":101}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status got=%d want=%d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	var body struct {
+		Code    string `json:"code"`
+		Details struct {
+			Limit  string `json:"limit"`
+			Max    int    `json:"max"`
+			Actual int    `json:"actual"`
+		} `json:"details"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	if body.Code != "quantity_too_large" || body.Details.Limit != "maxQuantity" || body.Details.Max != 100 || body.Details.Actual != 101 {
+		t.Fatalf("unexpected body: %s", rec.Body.String())
+	}
+}
+
+func TestGET_Limits(t *testing.T) {
+	controller := ctr.NewController(&fakeCalc{}, &fakeGet{})
+	if rec := serve(BuildHandler(controller), http.MethodGet, "/v1/limits"); rec.Code != http.StatusNotFound {
+		t.Fatalf("limits route must not exist without a use case, got=%d", rec.Code)
+	}
+
+	controller.Limits = usecases.NewGetLimits(usecases.Limits{MaxQuantity: 10, MaxPackSizes: 2, MaxDPCells: 100})
+	rec := serve(BuildHandler(controller), http.MethodGet, "/v1/limits")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status got=%d want=%d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Body.String(); got != `{"maxQuantity":10,"maxPackSizes":2,"maxDpCells":100}` {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
+
+func serve(h http.Handler, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPOST_Calculate_ProblemJSON(t *testing.T) {
+	h := newTestHandler(
+		&fakeCalc{err: usecases.ErrInvalidPackInOverride.WithViolation("packsOverride[2]", "must be > 0, got 0")},
+		&fakeGet{},
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/calculate?explain=false", bytes.NewBufferString(`{"quantity":5,"packsOverride":[1,2,0]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/problem+json")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status got=%d want=%d", rec.Code, http.StatusBadRequest)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("content-type got=%q", ct)
+	}
+	var body struct {
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Status   int    `json:"status"`
+		Detail   string `json:"detail"`
+		Instance string `json:"instance"`
+		Code     string `json:"code"`
+		Details  []struct {
+			Field string `json:"field"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if body.Type != "urn:shipping-packs:problem:invalid_pack" || body.Title != "Bad Request" || body.Status != 400 ||
+		body.Instance != "/v1/calculate?explain=false" || body.Code != "invalid_pack" || body.Detail == "" {
+		t.Fatalf("unexpected problem: %s", rec.Body.String())
+	}
+	if len(body.Details) != 1 || body.Details[0].Field != "packsOverride[2]" {
+		t.Fatalf("unexpected details: %s", rec.Body.String())
+	}
+}
+
+func TestPOST_Calculate_InvalidJSON_Details(t *testing.T) {
+	h := newTestHandler(&fakeCalc{}, &fakeGet{})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/calculate", bytes.NewBufferString(`{"quantity":"10"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status got=%d want=%d", rec.Code, http.StatusBadRequest)
+	}
+	// legacy clients keep the {code, message, details} shape
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Fatalf("content-type got=%q", ct)
+	}
+	var body struct {
+		Code    string `json:"code"`
+		Details []struct {
+			Field  string `json:"field"`
+			Reason string `json:"reason"`
+		} `json:"details"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	if body.Code != "invalid_request" || len(body.Details) != 1 || body.Details[0].Field != "quantity" {
+		t.Fatalf("unexpected body: %s", rec.Body.String())
+	}
+}
+
 func TestOPTIONS_CORS_Preflight(t *testing.T) {
 	h := newTestHandler(&fakeCalc{}, &fakeGet{})
 
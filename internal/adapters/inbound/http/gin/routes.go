@@ -0,0 +1,826 @@
+package ginadapter
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/openapi"
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
+)
+
+// route is one /v1 operation: its documentation (the source of the OpenAPI
+// spec) and its handler.
+type route struct {
+	openapi.Operation
+	handler func(ctrl *ctr.Controller, o *options) gin.HandlerFunc
+	enabled func(ctrl *ctr.Controller) bool // nil: always registered
+	admin   bool                            // behind AdminAuth, only with WithAdminToken
+}
+
+var exampleSizes = []int{250, 500, 1000, 2000, 5000}
+
+func v1Routes() []route {
+	return []route{
+		{
+			Operation: openapi.Operation{
+				Method:  http.MethodGet,
+				Path:    "/v1/packsizes",
+				ID:      "listPackSizes",
+				Summary: "Listar tamanhos de pacotes vigentes",
+				Description: "sizes traz os tamanhos habilitados (os usados no cálculo); packs traz também os desabilitados, com os " +
+					"metadados do catálogo. Suporta GET condicional: envie o ETag recebido em If-None-Match (ou Last-Modified em " +
+					"If-Modified-Since) para receber 304 enquanto a lista não mudar.",
+				Tags: []string{"packs"},
+				Params: []openapi.Param{
+					{Name: "status", In: "query", Description: "Filtra packs: all, enabled ou disabled (sizes não é filtrado)", Type: "", Default: string(ctr.PackStatusAll)},
+					{Name: "If-None-Match", In: "header", Description: "ETag de uma resposta anterior", Type: ""},
+					{Name: "If-Modified-Since", In: "header", Description: "Last-Modified de uma resposta anterior (ignorado com If-None-Match)", Type: ""},
+				},
+				Responses: []openapi.Response{
+					withHeaders(jsonResponse(http.StatusOK, "Lista de tamanhos (ordenados asc)", ctr.PackSizesResponse{},
+						example("ok", ctr.PackSizesResponse{Sizes: exampleSizes, Packs: examplePacks})), validatorHeaders...),
+					{Status: http.StatusNotModified, Description: "A lista não mudou desde a versão informada", Headers: validatorHeaders},
+					errorResponse(http.StatusBadRequest, "status desconhecido",
+						example("invalid_status", presenter.ErrorBody{
+							Code: presenter.CodeInvalidRequest, Message: "status " + packStatusReason,
+							Details: []presenter.FieldError{{Field: "status", Reason: packStatusReason}},
+						}),
+					),
+					errorResponse(http.StatusInternalServerError, "Erro ao carregar tamanhos do provider (arquivo/env)",
+						example("provider_error", internalError)),
+					errorResponse(http.StatusServiceUnavailable, unavailableDescription, example("unavailable", unavailableError)),
+				},
+			},
+			handler: handleGetPackSizes,
+		},
+		{
+			Operation: openapi.Operation{
+				Method:  http.MethodGet,
+				Path:    "/v1/packsizes/versions",
+				ID:      "listPackSizeVersions",
+				Summary: "Listar as versões do catálogo de tamanhos",
+				Description: "Histórico do catálogo, incluindo versões futuras já publicadas. Envie asOf em /v1/calculate para " +
+					"reproduzir um cálculo com a versão vigente em um instante passado.",
+				Tags: []string{"packs"},
+				Responses: []openapi.Response{
+					jsonResponse(http.StatusOK, "Versões por effectiveFrom asc", ctr.PackSizeVersionsResponse{},
+						example("ok", exampleVersions)),
+					errorResponse(http.StatusInternalServerError, "Erro ao carregar o catálogo do provider",
+						example("provider_error", internalError)),
+					errorResponse(http.StatusServiceUnavailable, unavailableDescription),
+				},
+			},
+			handler: handleListPackSizeVersions,
+			enabled: func(ctrl *ctr.Controller) bool { return ctrl.Versions != nil },
+		},
+		{
+			Operation: openapi.Operation{
+				Method:  http.MethodGet,
+				Path:    "/v1/packsizes/upcoming",
+				ID:      "listUpcomingPackSizes",
+				Summary: "Listar as mudanças de tamanhos agendadas",
+				Description: "Versões do catálogo que ainda não entraram em vigor. Cada uma substitui a anterior automaticamente " +
+					"em effectiveFrom, sem reiniciar o serviço.",
+				Tags: []string{"packs"},
+				Responses: []openapi.Response{
+					jsonResponse(http.StatusOK, "Versão vigente e versões agendadas (effectiveFrom asc)", ctr.UpcomingPackSizesResponse{},
+						example("ok", exampleUpcoming)),
+					errorResponse(http.StatusInternalServerError, "Erro ao carregar o catálogo do provider",
+						example("provider_error", internalError)),
+					errorResponse(http.StatusServiceUnavailable, unavailableDescription),
+				},
+			},
+			handler: handleListUpcomingPackSizes,
+			enabled: func(ctrl *ctr.Controller) bool { return ctrl.Upcoming != nil },
+		},
+		{
+			Operation: openapi.Operation{
+				Method:  http.MethodPost,
+				Path:    "/v1/admin/packsizes/schedule",
+				ID:      "schedulePackSizes",
+				Summary: "Agendar uma nova versão do catálogo",
+				Description: "Registra uma versão que entra em vigor em effectiveFrom (ex. descontinuar um tamanho em uma data " +
+					"conhecida). A versão é gravada no arquivo do provider e sobrevive a reinícios. Requer o ADMIN_TOKEN.",
+				Tags:     []string{"admin"},
+				Security: []string{adminSecurityScheme},
+				Params:   []openapi.Param{idempotencyKeyParam},
+				Body: &openapi.Content{
+					Type:     ctr.ScheduleRequest{},
+					Examples: []openapi.Example{example("sem_250", exampleScheduleRequest)},
+				},
+				Responses: []openapi.Response{
+					jsonResponse(http.StatusCreated, "Versão agendada", ctr.PackSizeVersionResponse{},
+						example("ok", exampleScheduled)),
+					errorResponse(http.StatusBadRequest, "Agendamento inválido (ex. effectiveFrom no passado) ou JSON malformado",
+						example("invalid_schedule", presenter.ErrorBody{
+							Code: "invalid_schedule", Message: "invalid pack sizes schedule",
+							Details: []presenter.FieldError{{Field: "effectiveFrom", Reason: "must be in the future"}},
+						}),
+					),
+					errorResponse(http.StatusUnauthorized, "Authorization ausente ou com token inválido",
+						example("unauthorized", presenter.ErrorBody{Code: presenter.CodeUnauthorized, Message: "missing or invalid admin token"})),
+					errorResponse(http.StatusConflict, "O catálogo já tem essa versão ou outra versão com o mesmo effectiveFrom",
+						example("schedule_conflict", presenter.ErrorBody{
+							Code: "schedule_conflict", Message: "the catalogue already has this version or effective time",
+							Details: map[string]any{"version": "2025-06"},
+						}),
+					),
+					errorResponse(http.StatusUnprocessableEntity, "O Idempotency-Key já foi usado com outro payload"),
+					errorResponse(http.StatusInternalServerError, "Erro ao gravar a versão no provider"),
+					errorResponse(http.StatusServiceUnavailable, "A versão não pôde ser registrada na trilha de auditoria e não foi agendada",
+						example("audit_unavailable", presenter.ErrorBody{
+							Code: "audit_unavailable", Message: "the change could not be recorded in the audit trail",
+							Details: map[string]any{"version": "2026-03"},
+						}),
+					),
+				},
+			},
+			handler: handleSchedulePackSizes,
+			enabled: func(ctrl *ctr.Controller) bool { return ctrl.Schedule != nil },
+			admin:   true,
+		},
+		setPackSizeEnabledRoute(true),
+		setPackSizeEnabledRoute(false),
+		{
+			Operation: openapi.Operation{
+				Method:  http.MethodGet,
+				Path:    "/v1/admin/webhooks/deliveries",
+				ID:      "listWebhookDeliveries",
+				Summary: "Listar as entregas dos eventos aos webhooks",
+				Description: "Cada mudança da lista de tamanhos gera um evento packsizes.changed, enviado por POST assinado " +
+					"(HMAC-SHA256) a cada WEBHOOK_URLS, com novas tentativas e backoff exponencial. Lista as tentativas, " +
+					"da mais recente para a mais antiga. Requer o ADMIN_TOKEN.",
+				Tags:     []string{"admin"},
+				Security: []string{adminSecurityScheme},
+				Params: []openapi.Param{
+					{Name: "eventId", In: "query", Description: "Só as tentativas desse evento", Type: ""},
+					{Name: "status", In: "query", Description: "Só as tentativas com esse status: delivered, retrying ou failed", Type: ""},
+					{Name: "limit", In: "query", Description: "Máximo de tentativas (1 a 500)", Type: 0, Default: usecases.DefaultDeliveriesLimit},
+				},
+				Responses: []openapi.Response{
+					jsonResponse(http.StatusOK, "Tentativas de entrega", ctr.WebhookDeliveriesResponse{},
+						example("ok", exampleDeliveries)),
+					errorResponse(http.StatusBadRequest, "status ou limit inválido",
+						example("invalid_limit", presenter.ErrorBody{
+							Code: presenter.CodeInvalidRequest, Message: "limit " + deliveriesLimitReason,
+							Details: []presenter.FieldError{{Field: "limit", Reason: deliveriesLimitReason}},
+						}),
+					),
+					errorResponse(http.StatusUnauthorized, "Authorization ausente ou com token inválido"),
+					errorResponse(http.StatusInternalServerError, "Erro ao ler o log de entregas"),
+				},
+			},
+			handler: handleListWebhookDeliveries,
+			enabled: func(ctrl *ctr.Controller) bool { return ctrl.Deliveries != nil },
+			admin:   true,
+		},
+		{
+			Operation: openapi.Operation{
+				Method:  http.MethodGet,
+				Path:    "/v1/audit",
+				ID:      "listAuditEntries",
+				Summary: "Consultar a trilha de auditoria",
+				Description: "Registro só de acréscimos (AUDIT_LOG) das mudanças do catálogo, com a lista de tamanhos antes e " +
+					"depois, e de todas as chamadas autenticadas às rotas admin: quem (o nome do token em ADMIN_TOKENS), " +
+					"quando, de qual IP e com qual X-Request-Id. Do mais recente para o mais antigo. Requer um token de admin.",
+				Tags:     []string{"admin"},
+				Security: []string{adminSecurityScheme},
+				Params: []openapi.Param{
+					{Name: "from", In: "query", Description: "Só os registros a partir desse instante, inclusive (RFC 3339)", Type: time.Time{}},
+					{Name: "to", In: "query", Description: "Só os registros antes desse instante (RFC 3339)", Type: time.Time{}},
+					{Name: "actor", In: "query", Description: "Só os registros desse token de admin", Type: ""},
+					{Name: "action", In: "query", Description: "Só os registros dessa ação, ex. admin.call ou packsizes.disable", Type: ""},
+					{Name: "limit", In: "query", Description: "Máximo de registros (1 a 1000)", Type: 0, Default: usecases.DefaultAuditLimit},
+				},
+				Responses: []openapi.Response{
+					jsonResponse(http.StatusOK, "Registros da trilha de auditoria", ctr.AuditEntriesResponse{},
+						example("ok", exampleAudit)),
+					errorResponse(http.StatusBadRequest, "from, to ou limit inválido, ou from não anterior a to",
+						example("invalid_audit_range", presenter.ErrorBody{
+							Code: "invalid_audit_range", Message: "from must be before to",
+							Details: map[string]any{"from": "2025-11-02T00:00:00Z", "to": "2025-11-01T00:00:00Z"},
+						}),
+					),
+					errorResponse(http.StatusUnauthorized, "Authorization ausente ou com token inválido"),
+					errorResponse(http.StatusInternalServerError, "Erro ao ler a trilha de auditoria"),
+				},
+			},
+			handler: handleListAuditEntries,
+			enabled: func(ctrl *ctr.Controller) bool { return ctrl.Audit != nil },
+			admin:   true,
+		},
+		{
+			Operation: openapi.Operation{
+				Method:      http.MethodGet,
+				Path:        "/v1/limits",
+				ID:          "getLimits",
+				Summary:     "Limites aplicados ao cálculo",
+				Description: `Valores 0 significam "sem limite".`,
+				Tags:        []string{"packs"},
+				Responses: []openapi.Response{
+					jsonResponse(http.StatusOK, "Limites configurados", ctr.LimitsResponse{},
+						example("ok", ctr.LimitsResponse{MaxQuantity: 100_000_000, MaxPackSizes: 50, MaxDPCells: 5_000_000})),
+				},
+			},
+			handler: handleGetLimits,
+			enabled: func(ctrl *ctr.Controller) bool { return ctrl.Limits != nil },
+		},
+		{
+			Operation: openapi.Operation{
+				Method:  http.MethodPost,
+				Path:    "/v1/calculate",
+				ID:      "calculatePacks",
+				Summary: "Calcular a combinação ótima de pacotes",
+				Tags:    []string{"packs"},
+				Params:  []openapi.Param{explainQueryParam, idempotencyKeyParam},
+				Body: &openapi.Content{
+					Type: ctr.CalculateRequest{},
+					Examples: []openapi.Example{
+						example("sem_override", ctr.CalculateRequest{Quantity: 12001}),
+						example("com_override", ctr.CalculateRequest{Quantity: 751, PacksOverride: []int{250, 500, 1000}}),
+						example("com_as_of", ctr.CalculateRequest{Quantity: 12001, AsOf: &exampleAsOf}),
+					},
+				},
+				Responses: []openapi.Response{
+					withHeaders(jsonResponse(http.StatusOK, "Resultado do cálculo", ctr.CalculateResponse{},
+						example("ok", calculateExample),
+						openapi.Example{Name: "explained", Summary: "Com explain=true", Value: explainedExample},
+						openapi.Example{Name: "as_of", Summary: "Com asOf anterior à versão vigente", Value: asOfExample},
+					), replayedHeader),
+					errorResponse(http.StatusBadRequest, "Requisição inválida (ex. quantity ≤ 0 ou JSON malformado)",
+						example("invalid_quantity", presenter.ErrorBody{
+							Code: "invalid_quantity", Message: "quantity must be > 0",
+							Details: []presenter.FieldError{{Field: "quantity", Reason: "must be > 0"}},
+						}),
+						example("invalid_request", presenter.ErrorBody{
+							Code: presenter.CodeInvalidRequest, Message: "invalid JSON payload",
+							Details: []presenter.FieldError{{Offset: 16, Reason: "invalid character '}' looking for beginning of object key string"}},
+						}),
+						example("invalid_pack", presenter.ErrorBody{
+							Code: "invalid_pack", Message: "packsOverride must contain positive integers",
+							Details: []presenter.FieldError{{Field: "packsOverride[2]", Reason: "must be > 0, got 0"}},
+						}),
+					),
+					errorResponse(http.StatusConflict, "Uma requisição com o mesmo Idempotency-Key ainda está em processamento"),
+					errorResponse(http.StatusUnprocessableEntity, "Não há tamanhos de pacote disponíveis, nenhuma versão do catálogo estava vigente em asOf, "+
+						"o pedido excede os limites (ver /v1/limits) ou o Idempotency-Key já foi usado com outro payload",
+						example("no_packs", presenter.ErrorBody{Code: "no_pack_sizes", Message: "no pack sizes available"}),
+						example("no_catalogue_at", presenter.ErrorBody{
+							Code: "no_catalogue_at", Message: "no pack sizes catalogue was in effect at asOf",
+							Details: map[string]any{"asOf": "2020-01-01T00:00:00Z"},
+						}),
+						example("quantity_too_large", presenter.ErrorBody{
+							Code: "quantity_too_large", Message: "quantity exceeds the configured maximum",
+							Details: presenter.LimitDetails{Limit: "maxQuantity", Max: 100_000_000, Actual: 100_000_001},
+						}),
+						example("too_many_pack_sizes", presenter.ErrorBody{
+							Code: "too_many_pack_sizes", Message: "too many distinct pack sizes",
+							Details: presenter.LimitDetails{Limit: "maxPackSizes", Max: 50, Actual: 51},
+						}),
+						example("calculation_too_large", presenter.ErrorBody{
+							Code: "calculation_too_large", Message: "quantity is too large for the smallest pack size",
+							Details: presenter.LimitDetails{Limit: "maxDpCells", Max: 5_000_000, Actual: 10_000_001},
+						}),
+						example("idempotency_key_reused", presenter.ErrorBody{
+							Code: presenter.CodeIdempotencyKeyReused, Message: "Idempotency-Key was already used with a different payload",
+						}),
+					),
+					errorResponse(http.StatusInternalServerError, "Erro interno inesperado (ex. I/O do provider)",
+						example("internal", internalError)),
+					errorResponse(http.StatusServiceUnavailable, unavailableDescription, example("unavailable", unavailableError)),
+				},
+			},
+			handler: handleCalculate,
+		},
+		{
+			Operation: openapi.Operation{
+				Method:  http.MethodPost,
+				Path:    "/v1/calculate/stream",
+				ID:      "calculatePacksStream",
+				Summary: "Calcular pedidos em lote (NDJSON, streaming)",
+				Description: "Cada linha do corpo (application/x-ndjson) é um pedido (StreamOrderLine). Cada resultado é enviado assim que " +
+					"calculado, na ordem de entrada, como uma linha StreamLine com o número da linha de entrada; uma linha inválida " +
+					"recebe uma linha com error e o stream continua. A última linha traz o summary. A próxima linha só é lida depois " +
+					"que o resultado anterior foi enviado (backpressure); cancelar a requisição interrompe o stream. O Idempotency-Key " +
+					"não se aplica (o corpo não tem limite de tamanho): o header é ignorado.",
+				Tags:   []string{"packs"},
+				Params: []openapi.Param{explainQueryParam},
+				Body:   &openapi.Content{MediaType: NDJSONContentType, Type: ctr.StreamOrderLine{}},
+				Responses: []openapi.Response{
+					{
+						Status:      http.StatusOK,
+						Description: "Uma linha StreamLine por pedido e uma linha final com summary",
+						Content:     []openapi.Content{{MediaType: NDJSONContentType, Type: ctr.StreamLine{}}},
+					},
+					errorResponse(http.StatusBadRequest, "Parâmetro inválido (ex. explain)"),
+					errorResponse(http.StatusUnsupportedMediaType, "O corpo não é application/x-ndjson",
+						example("unsupported_media_type", presenter.ErrorBody{
+							Code: presenter.CodeInvalidRequest, Message: "Content-Type must be " + NDJSONContentType,
+						})),
+				},
+			},
+			handler: handleCalculateStream,
+		},
+		{
+			Operation: openapi.Operation{
+				Method:  http.MethodPost,
+				Path:    "/v1/calculate/csv",
+				ID:      "calculatePacksCSV",
+				Summary: "Calcular pedidos em lote (planilha CSV)",
+				Description: "Recebe um CSV (corpo text/csv ou parte \"file\" de multipart/form-data, até 8 MiB e 10000 pedidos) com as colunas id, quantity " +
+					"e, opcionalmente, packsOverride (tamanhos separados por ponto e vírgula, espaço ou vírgula entre aspas). O cabeçalho " +
+					"é opcional: sem ele as colunas são lidas nessa ordem. Responde um CSV com uma linha por pedido: id, quantity, uma " +
+					"coluna por tamanho de pacote (os vigentes mais os usados nos overrides), totalItems, totalPacks, leftover, error e " +
+					"message; uma linha inválida preenche error/message e não interrompe as outras. O Idempotency-Key não se aplica: " +
+					"o header é ignorado.",
+				Tags:      []string{"packs"},
+				Body:      &openapi.Content{MediaType: CSVContentType, Type: ""},
+				AltBodies: []openapi.Content{{MediaType: "multipart/form-data", Type: ctr.CSVUpload{}}},
+				Responses: []openapi.Response{
+					{
+						Status:      http.StatusOK,
+						Description: "Uma linha por pedido, na ordem de entrada",
+						Headers: []openapi.Header{
+							{Name: "Content-Disposition", Description: "attachment; o nome segue o do arquivo enviado (orders.csv → orders-packs.csv)", Type: ""},
+						},
+						Content: []openapi.Content{{MediaType: CSVContentType, Type: ""}},
+					},
+					errorResponse(http.StatusBadRequest, "CSV ilegível, sem pedidos ou sem a coluna quantity",
+						example("no_orders", presenter.ErrorBody{
+							Code: presenter.CodeInvalidRequest, Message: "invalid CSV",
+							Details: []presenter.FieldError{{Reason: errNoCSVOrders.Error()}},
+						}),
+					),
+					errorResponse(http.StatusRequestEntityTooLarge, "O CSV excede 8 MiB ou 10000 pedidos"),
+					errorResponse(http.StatusUnsupportedMediaType, "O corpo não é text/csv nem multipart/form-data",
+						example("unsupported_media_type", presenter.ErrorBody{
+							Code: presenter.CodeInvalidRequest, Message: "Content-Type must be " + CSVContentType + " or multipart/form-data",
+						})),
+					errorResponse(http.StatusInternalServerError, "Erro ao carregar tamanhos do provider (arquivo/env)",
+						example("provider_error", internalError)),
+					errorResponse(http.StatusServiceUnavailable, unavailableDescription),
+				},
+			},
+			handler: handleCalculateCSV,
+		},
+	}
+}
+
+// setPackSizeEnabledRoute documents POST /v1/admin/packsizes/:size/enable
+// and its /disable twin.
+func setPackSizeEnabledRoute(enabled bool) route {
+	action, id, summary, description := "enable", "enablePackSize", "Habilitar um tamanho de pacote",
+		"Volta a usar no cálculo um tamanho da versão vigente que estava desabilitado."
+	ok := examplePacks[0]
+	if !enabled {
+		action, id, summary, description = "disable", "disablePackSize", "Desabilitar um tamanho de pacote",
+			"Tira do cálculo um tamanho da versão vigente sem removê-lo do catálogo (os metadados são mantidos), "+
+				"ex. uma caixa em falta."
+		ok.Enabled = false
+	}
+	responses := []openapi.Response{
+		jsonResponse(http.StatusOK, "O pacote com o novo status (repetir a operação não muda nada)", ctr.PackResponse{},
+			example("ok", ok)),
+		errorResponse(http.StatusBadRequest, "size não é um inteiro positivo"),
+		errorResponse(http.StatusUnauthorized, "Authorization ausente ou com token inválido"),
+		errorResponse(http.StatusNotFound, "A versão vigente não tem esse tamanho",
+			example("unknown_pack_size", presenter.ErrorBody{
+				Code: "unknown_pack_size", Message: "the current catalogue has no such pack size",
+				Details: map[string]any{"size": 42},
+			}),
+		),
+	}
+	if !enabled {
+		responses = append(responses, errorResponse(http.StatusConflict, "É o último tamanho habilitado",
+			example("last_enabled_pack_size", presenter.ErrorBody{
+				Code: "last_enabled_pack_size", Message: "the last enabled pack size cannot be disabled",
+				Details: map[string]any{"size": 250},
+			}),
+		))
+	}
+	responses = append(responses,
+		errorResponse(http.StatusUnprocessableEntity, "O Idempotency-Key já foi usado com outro payload"),
+		errorResponse(http.StatusInternalServerError, "Erro ao gravar o status no provider"),
+		errorResponse(http.StatusServiceUnavailable, "A mudança não pôde ser registrada na trilha de auditoria e foi desfeita",
+			example("audit_unavailable", presenter.ErrorBody{
+				Code: "audit_unavailable", Message: "the change could not be recorded in the audit trail",
+				Details: map[string]any{"size": 250},
+			}),
+		),
+	)
+	return route{
+		Operation: openapi.Operation{
+			Method:      http.MethodPost,
+			Path:        "/v1/admin/packsizes/:size/" + action,
+			ID:          id,
+			Summary:     summary,
+			Description: description + " O status é gravado no arquivo do provider e muda a versão da lista. Requer o ADMIN_TOKEN.",
+			Tags:        []string{"admin"},
+			Security:    []string{adminSecurityScheme},
+			Params: []openapi.Param{
+				{Name: "size", In: "path", Description: "Tamanho do pacote", Type: 0},
+				idempotencyKeyParam,
+			},
+			Responses: responses,
+		},
+		handler: func(ctrl *ctr.Controller, _ *options) gin.HandlerFunc { return handleSetPackSizeEnabled(ctrl, enabled) },
+		enabled: func(ctrl *ctr.Controller) bool { return ctrl.Toggle != nil },
+		admin:   true,
+	}
+}
+
+// -------- handlers --------
+
+const packStatusReason = "must be all, enabled or disabled"
+
+func handleGetPackSizes(ctrl *ctr.Controller, o *options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, ok := ctr.ParsePackStatus(c.Query("status"))
+		if !ok {
+			code, body := presenter.InvalidParam("status", packStatusReason)
+			writeError(c, code, body)
+			return
+		}
+		res, err := ctrl.HandleGetPackSizes(c.Request.Context(), status)
+		if err != nil {
+			writeUseCaseError(c, err)
+			return
+		}
+		if conditionalGET(c, weakETag(res.Version), res.UpdatedAt, o.packSizesCacheControl) {
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+func handleListPackSizeVersions(ctrl *ctr.Controller, _ *options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, err := ctrl.HandleListPackSizeVersions(c.Request.Context())
+		if err != nil {
+			writeUseCaseError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+func handleListUpcomingPackSizes(ctrl *ctr.Controller, _ *options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, err := ctrl.HandleListUpcomingPackSizes(c.Request.Context())
+		if err != nil {
+			writeUseCaseError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+func handleSchedulePackSizes(ctrl *ctr.Controller, _ *options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ctr.ScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			status, body := presenter.MapBindError(err)
+			writeError(c, status, body)
+			return
+		}
+		res, err := ctrl.HandleSchedulePackSizes(c.Request.Context(), req)
+		if err != nil {
+			writeUseCaseError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, res)
+	}
+}
+
+func handleSetPackSizeEnabled(ctrl *ctr.Controller, enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		size, err := strconv.Atoi(c.Param("size"))
+		if err != nil || size <= 0 {
+			status, body := presenter.InvalidParam("size", "must be a positive integer")
+			writeError(c, status, body)
+			return
+		}
+		res, err := ctrl.HandleSetPackSizeEnabled(c.Request.Context(), size, enabled)
+		if err != nil {
+			writeUseCaseError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+var (
+	deliveriesLimitReason  = "must be an integer between 1 and " + strconv.Itoa(usecases.MaxDeliveriesLimit)
+	deliveryStatusReason   = "must be delivered, retrying or failed"
+	deliveryStatusesByName = map[string]bool{"delivered": true, "retrying": true, "failed": true}
+)
+
+func handleListWebhookDeliveries(ctrl *ctr.Controller, _ *options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := c.Query("status")
+		if status != "" && !deliveryStatusesByName[status] {
+			code, body := presenter.InvalidParam("status", deliveryStatusReason)
+			writeError(c, code, body)
+			return
+		}
+		limit := 0
+		if v := c.Query("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > usecases.MaxDeliveriesLimit {
+				code, body := presenter.InvalidParam("limit", deliveriesLimitReason)
+				writeError(c, code, body)
+				return
+			}
+			limit = n
+		}
+		res, err := ctrl.HandleListWebhookDeliveries(c.Request.Context(), c.Query("eventId"), status, limit)
+		if err != nil {
+			writeUseCaseError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+var (
+	auditLimitReason = "must be an integer between 1 and " + strconv.Itoa(usecases.MaxAuditLimit)
+	auditTimeReason  = "must be an RFC 3339 time"
+)
+
+func handleListAuditEntries(ctrl *ctr.Controller, _ *options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var from, to time.Time
+		for _, p := range []struct {
+			name string
+			t    *time.Time
+		}{{"from", &from}, {"to", &to}} {
+			v := c.Query(p.name)
+			if v == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				code, body := presenter.InvalidParam(p.name, auditTimeReason)
+				writeError(c, code, body)
+				return
+			}
+			*p.t = t
+		}
+		limit := 0
+		if v := c.Query("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > usecases.MaxAuditLimit {
+				code, body := presenter.InvalidParam("limit", auditLimitReason)
+				writeError(c, code, body)
+				return
+			}
+			limit = n
+		}
+		res, err := ctrl.HandleListAuditEntries(c.Request.Context(), from, to, c.Query("actor"), c.Query("action"), limit)
+		if err != nil {
+			writeUseCaseError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+func handleGetLimits(ctrl *ctr.Controller, _ *options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, err := ctrl.HandleGetLimits(c.Request.Context())
+		if err != nil {
+			writeUseCaseError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+func handleCalculate(ctrl *ctr.Controller, _ *options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ctr.CalculateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			status, body := presenter.MapBindError(err)
+			writeError(c, status, body)
+			return
+		}
+		explain, ok := explainParam(c)
+		if !ok {
+			return
+		}
+		req.Explain = explain
+		res, err := ctrl.HandleCalculate(c.Request.Context(), req)
+		if err != nil {
+			writeUseCaseError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// explainParam reads ?explain; on a malformed value it answers 400 and
+// reports false.
+func explainParam(c *gin.Context) (explain, ok bool) {
+	v := c.Query("explain")
+	if v == "" {
+		return false, true
+	}
+	explain, err := strconv.ParseBool(v)
+	if err != nil {
+		status, body := presenter.InvalidParam("explain", "must be a boolean")
+		writeError(c, status, body)
+		return false, false
+	}
+	return explain, true
+}
+
+// -------- documentation helpers --------
+
+func example(name string, v any) openapi.Example { return openapi.Example{Name: name, Value: v} }
+
+func jsonResponse(status int, description string, body any, examples ...openapi.Example) openapi.Response {
+	return openapi.Response{
+		Status:      status,
+		Description: description,
+		Content:     []openapi.Content{{Type: body, Examples: examples}},
+	}
+}
+
+// errorResponse documents both error shapes (see writeError).
+func errorResponse(status int, description string, examples ...openapi.Example) openapi.Response {
+	return openapi.Response{
+		Status:      status,
+		Description: description,
+		Content: []openapi.Content{
+			{MediaType: presenter.ProblemContentType, Type: presenter.Problem{}},
+			{Type: presenter.ErrorBody{}, Examples: examples},
+		},
+	}
+}
+
+// validatorHeaders are sent by conditionalGET.
+var validatorHeaders = []openapi.Header{
+	{Name: "ETag", Description: "Versão da lista (validador fraco)", Type: ""},
+	{Name: "Last-Modified", Description: "Quando a versão foi publicada (se o provider souber)", Type: ""},
+	{Name: "Cache-Control", Description: "Configurável (PACKSIZES_CACHE_CONTROL)", Type: ""},
+}
+
+var explainQueryParam = openapi.Param{
+	Name:        "explain",
+	In:          "query",
+	Description: "Quando true, inclui a explicação do cálculo (GCD, limite da busca e alternativas rejeitadas)",
+	Type:        false,
+	Default:     false,
+}
+
+// idempotencyKeyParam documents the Idempotency middleware on a route, and
+// turns it on there (see idempotentRoutes).
+var idempotencyKeyParam = openapi.Param{
+	Name: IdempotencyKeyHeader,
+	In:   "header",
+	Description: "Opcional (até 255 caracteres). Repetições com a mesma chave e o mesmo payload recebem a primeira resposta " +
+		"(exceto 5xx) sem recalcular; com outro payload, 422. Com a chave, o corpo é limitado a 1 MiB (413).",
+	Type: "",
+}
+
+var replayedHeader = openapi.Header{
+	Name:        ReplayedHeader,
+	Description: `"true" quando a resposta foi reaproveitada de uma requisição anterior com o mesmo Idempotency-Key`,
+	Type:        "",
+}
+
+func withHeaders(r openapi.Response, headers ...openapi.Header) openapi.Response {
+	r.Headers = headers
+	return r
+}
+
+var internalError = presenter.ErrorBody{Code: presenter.CodeInternalError, Message: "unexpected error"}
+
+// unavailableError: every source of the pack sizes chain failed and none
+// has answered yet (or the last list is older than PACK_MAX_STALE).
+var unavailableError = presenter.ErrorBody{Code: "pack_sizes_unavailable", Message: "pack sizes are temporarily unavailable"}
+
+const unavailableDescription = "Nenhuma fonte de tamanhos respondeu e não há lista recente em cache; tente novamente"
+
+// exampleVersions is a catalogue where exampleSizes replaced a list without
+// the 5000 pack, and the 250 pack is discontinued in November (the examples
+// are set in September 2025).
+var exampleVersions = ctr.PackSizeVersionsResponse{
+	Versions: []ctr.PackSizeVersionResponse{
+		{Version: "2024-01", EffectiveFrom: exampleTime("2024-01-01T00:00:00Z"), Sizes: []int{250, 500, 1000, 2000}},
+		{Version: "2025-06", EffectiveFrom: exampleTime("2025-06-01T00:00:00Z"), Sizes: exampleSizes},
+		{Version: "2025-11", EffectiveFrom: exampleTime("2025-11-01T00:00:00Z"), Sizes: []int{500, 1000, 2000, 5000}},
+	},
+	Current: "2025-06",
+}
+
+// examplePacks is the metadata of exampleSizes.
+var examplePacks = []ctr.PackResponse{
+	{Size: 250, Enabled: true, Label: "Envelope", SKU: "BOX-250", Cost: exampleFloat(0.12), Stock: exampleInt(4200)},
+	{Size: 500, Enabled: true, Label: "Caixa P", SKU: "BOX-500", Dimensions: &ctr.DimensionsResponse{Length: 30, Width: 20, Height: 15, Unit: "cm"}},
+	{Size: 1000, Enabled: true, Label: "Caixa M", SKU: "BOX-1000"},
+	{Size: 2000, Enabled: true, Label: "Caixa G", SKU: "BOX-2000"},
+	{Size: 5000, Enabled: true, Label: "Pallet", SKU: "PAL-5000"},
+}
+
+func exampleFloat(v float64) *float64 { return &v }
+func exampleInt(v int) *int           { return &v }
+
+var exampleUpcoming = ctr.UpcomingPackSizesResponse{
+	Current:  exampleVersions.Current,
+	Upcoming: exampleVersions.Versions[2:],
+}
+
+var exampleScheduleRequest = ctr.ScheduleRequest{
+	Version:       "2026-03",
+	EffectiveFrom: *exampleTime("2026-03-01T00:00:00Z"),
+	Sizes:         []int{500, 1000, 2000, 5000, 10000},
+}
+
+var exampleScheduled = ctr.PackSizeVersionResponse{
+	Version:       exampleScheduleRequest.Version,
+	EffectiveFrom: &exampleScheduleRequest.EffectiveFrom,
+	Sizes:         exampleScheduleRequest.Sizes,
+}
+
+// exampleDeliveries is the 250 pack discontinued in November: the first
+// webhook answered at the second attempt.
+var exampleDeliveries = ctr.WebhookDeliveriesResponse{
+	Deliveries: []ctr.WebhookDeliveryResponse{
+		{
+			EventID: "4f3c2a1b9d8e7f60a1b2c3d4e5f60718", EventType: "packsizes.changed", Endpoint: "https://hooks.example.com/packs",
+			Attempt: 2, At: *exampleTime("2025-11-01T00:00:31Z"), DurationMs: 84, Status: "delivered", StatusCode: 204,
+		},
+		{
+			EventID: "4f3c2a1b9d8e7f60a1b2c3d4e5f60718", EventType: "packsizes.changed", Endpoint: "https://hooks.example.com/packs",
+			Attempt: 1, At: *exampleTime("2025-11-01T00:00:20Z"), DurationMs: 10000, Status: "retrying",
+			Error: "context deadline exceeded", NextAttemptAt: exampleTime("2025-11-01T00:00:30Z"),
+		},
+	},
+}
+
+// exampleAudit is the 250 pack disabled by alice: her call, and the change
+// it made, recorded with the same request ID.
+var exampleAudit = ctr.AuditEntriesResponse{
+	Entries: []ctr.AuditEntryResponse{
+		{
+			At: *exampleTime("2025-10-20T14:03:12Z"), Action: "admin.call", Actor: "alice", SourceIP: "203.0.113.7",
+			RequestID: "9b2f6c1e0d4a4f3e8c7b6a5d4e3f2a1b", Method: http.MethodPost, Path: "/v1/admin/packsizes/250/disable", Status: http.StatusOK,
+		},
+		{
+			At: *exampleTime("2025-10-20T14:03:12Z"), Action: "packsizes.disable", Actor: "alice", SourceIP: "203.0.113.7",
+			RequestID: "9b2f6c1e0d4a4f3e8c7b6a5d4e3f2a1b",
+			Before:    &ctr.AuditPackListResponse{Version: "2025-06", Sizes: exampleSizes},
+			After:     &ctr.AuditPackListResponse{Version: "2025-06", Sizes: exampleSizes[1:]},
+		},
+	},
+}
+
+var exampleAsOf = *exampleTime("2025-03-01T12:00:00Z")
+
+func exampleTime(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+var calculateExample = ctr.CalculateResponse{
+	ItemsByPack:      map[int]int{5000: 2, 2000: 1, 250: 1},
+	TotalItems:       12250,
+	TotalPacks:       4,
+	Leftover:         249,
+	CatalogueVersion: "2025-06",
+}
+
+var asOfExample = ctr.CalculateResponse{
+	ItemsByPack:      map[int]int{2000: 6, 250: 1},
+	TotalItems:       12250,
+	TotalPacks:       7,
+	Leftover:         249,
+	CatalogueVersion: "2024-01",
+}
+
+var explainedExample = ctr.CalculateResponse{
+	ItemsByPack: calculateExample.ItemsByPack,
+	TotalItems:  12250,
+	TotalPacks:  4,
+	Leftover:    249,
+	Explanation: &ctr.ExplanationResponse{
+		GCD:              250,
+		ScaledQuantity:   49,
+		ScaledUpperBound: 68,
+		RunnersUp: []ctr.RunnerUpResponse{
+			{ItemsByPack: map[int]int{5000: 2, 2000: 1, 500: 1}, TotalItems: 12500, TotalPacks: 4, Leftover: 499, RejectedBy: "items"},
+			{ItemsByPack: map[int]int{5000: 3}, TotalItems: 15000, TotalPacks: 3, Leftover: 2999, RejectedBy: "items"},
+			{ItemsByPack: map[int]int{5000: 2, 1000: 1, 500: 2, 250: 1}, TotalItems: 12250, TotalPacks: 6, Leftover: 249, RejectedBy: "packs"},
+			{ItemsByPack: map[int]int{5000: 2, 1000: 2, 250: 1}, TotalItems: 12250, TotalPacks: 5, Leftover: 249, RejectedBy: "packs"},
+		},
+	},
+	CatalogueVersion: "2025-06",
+}
@@ -0,0 +1,72 @@
+package ginadapter
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/openapi"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+)
+
+// specHeader marks docs/api/v1/openapi.yaml as generated.
+const specHeader = "# Code generated by cmd/openapi-gen from the route table and the DTOs; DO NOT EDIT.\n" +
+	"# Run: go generate ./docs/api/v1\n"
+
+// OpenAPISpec renders the spec of every /v1 route (optional ones included).
+// It is served under /docs and committed as docs/api/v1/openapi.yaml.
+var OpenAPISpec = sync.OnceValues(buildSpec)
+
+func buildSpec() ([]byte, error) {
+	b := openapi.NewBuilder(
+		openapi.Info{
+			Title:       "Shipping Packs API",
+			Version:     "1.0.0",
+			Description: "API to calculate packge optimazation.\n",
+		},
+		[]openapi.Server{{URL: "http://localhost:8080"}},
+		[]openapi.Tag{
+			{Name: "packs", Description: "Operações relacionadas a tamanhos de pacotes e cálculo"},
+			{Name: "admin", Description: "Operações administrativas (requerem o ADMIN_TOKEN ou um dos ADMIN_TOKENS)"},
+		},
+	)
+
+	// ErrorBody.Details has no static type: it is either the rejected
+	// fields, the exceeded limit or the params of the core error.
+	fieldErrors, err := b.SchemaOf(reflect.TypeOf([]presenter.FieldError{}))
+	if err != nil {
+		return nil, err
+	}
+	fieldErrors.Description = "Campos rejeitados"
+	limitDetails, err := b.SchemaOf(reflect.TypeOf(presenter.LimitDetails{}))
+	if err != nil {
+		return nil, err
+	}
+	b.Schema("ErrorDetails", &openapi.Schema{
+		Description: "Informações adicionais (opcional)",
+		AnyOf: []*openapi.Schema{
+			fieldErrors,
+			limitDetails,
+			{Type: "object", Description: `Parâmetros do erro (ex. "size" de um pacote inválido)`, AdditionalProperties: true},
+		},
+	})
+
+	b.SecurityScheme(adminSecurityScheme, &openapi.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "ADMIN_TOKEN ou um dos ADMIN_TOKENS do servidor; sem eles as rotas administrativas não são registradas",
+	})
+
+	for _, rt := range v1Routes() {
+		if err := b.Add(rt.Operation); err != nil {
+			return nil, err
+		}
+	}
+
+	codes := presenter.ErrorCodes()
+	schemas := b.Document().Components.Schemas
+	for _, name := range []string{"ErrorBody", "Problem"} {
+		schemas[name].Property("code").Enum = codes
+	}
+
+	return b.YAML(specHeader)
+}
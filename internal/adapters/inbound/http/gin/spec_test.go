@@ -0,0 +1,46 @@
+package ginadapter
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	apiv1 "github.com/reangeline/go-shipping-products/docs/api/v1"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+)
+
+func TestOpenAPISpec_CommittedIsUpToDate(t *testing.T) {
+	spec, err := OpenAPISpec()
+	if err != nil {
+		t.Fatalf("OpenAPISpec: %v", err)
+	}
+	if !bytes.Equal(spec, apiv1.OpenAPI) {
+		t.Fatalf("docs/api/v1/openapi.yaml is stale: run `go generate ./docs/api/v1`")
+	}
+}
+
+func TestOpenAPISpec_FollowsTheCode(t *testing.T) {
+	spec, err := OpenAPISpec()
+	if err != nil {
+		t.Fatalf("OpenAPISpec: %v", err)
+	}
+	doc := string(spec)
+
+	ginParam := regexp.MustCompile(`:(\w+)`)
+	for _, rt := range v1Routes() {
+		path := ginParam.ReplaceAllString(rt.Path, "{$1}")
+		if !strings.Contains(doc, "\n  "+path+":\n") || !strings.Contains(doc, "operationId: "+rt.ID+"\n") {
+			t.Fatalf("route %s %s (%s) missing from the spec", rt.Method, rt.Path, rt.ID)
+		}
+	}
+	for _, code := range presenter.ErrorCodes() {
+		if !strings.Contains(doc, "- "+code+"\n") {
+			t.Fatalf("error code %s missing from the spec", code)
+		}
+	}
+	// json:"-" fields are not part of the contract
+	if strings.Contains(doc, "Explain:") {
+		t.Fatalf("CalculateRequest.Explain leaked into the spec")
+	}
+}
@@ -0,0 +1,130 @@
+package ginadapter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+)
+
+const (
+	NDJSONContentType = "application/x-ndjson"
+
+	maxStreamLineSize = 64 << 10
+	// streamIdleTimeout replaces the server read/write timeouts, which would
+	// cut long streams: each line has this long to arrive and to be sent.
+	streamIdleTimeout = 30 * time.Second
+)
+
+// idleDeadlines returns a func giving the connection streamIdleTimeout more
+// to read and to write, to call as the work progresses.
+func idleDeadlines(rc *http.ResponseController) func() {
+	return func() {
+		deadline := time.Now().Add(streamIdleTimeout)
+		_ = rc.SetReadDeadline(deadline) // not supported by every writer (e.g. tests)
+		_ = rc.SetWriteDeadline(deadline)
+	}
+}
+
+// handleCalculateStream reads one order per NDJSON line and writes one result
+// line per order as soon as it is computed, then a summary line:
+//   - backpressure: the next line is only read once the previous result has
+//     been flushed, so a slow reader slows the writer down (no buffering)
+//   - a bad line gets an error line; the stream goes on
+//   - a cancelled request stops the stream (the summary says complete=false)
+func handleCalculateStream(ctrl *ctr.Controller, _ *options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.ContentType() != NDJSONContentType {
+			writeError(c, http.StatusUnsupportedMediaType, presenter.ErrorBody{
+				Code:    presenter.CodeInvalidRequest,
+				Message: "Content-Type must be " + NDJSONContentType,
+			})
+			return
+		}
+		explain, ok := explainParam(c)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		rc := http.NewResponseController(c.Writer)
+		extendDeadlines := idleDeadlines(rc)
+		extendDeadlines()
+		// HTTP/1 would otherwise drain the body before the first result is sent
+		_ = rc.EnableFullDuplex()
+
+		c.Header("Content-Type", NDJSONContentType)
+		c.Status(http.StatusOK)
+		enc := json.NewEncoder(c.Writer)
+		emit := func(line ctr.StreamLine) bool {
+			if err := enc.Encode(line); err != nil {
+				return false
+			}
+			c.Writer.Flush()
+			return true
+		}
+
+		scanner := bufio.NewScanner(c.Request.Body)
+		scanner.Buffer(make([]byte, 0, 4096), maxStreamLineSize)
+		var summary ctr.StreamSummary
+		n := 0
+		for ctx.Err() == nil && scanner.Scan() {
+			n++
+			raw := bytes.TrimSpace(scanner.Bytes())
+			if len(raw) == 0 {
+				continue
+			}
+			line := calculateLine(ctx, ctrl, n, raw, explain)
+			summary.Lines++
+			if line.Error != nil {
+				summary.Failed++
+			} else {
+				summary.Succeeded++
+			}
+			extendDeadlines()
+			if !emit(line) {
+				return // the client is gone
+			}
+		}
+
+		summary.Complete = ctx.Err() == nil
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			reason := err.Error()
+			if errors.Is(err, bufio.ErrTooLong) {
+				reason = "line exceeds 64 KiB"
+			}
+			summary.Lines++
+			summary.Failed++
+			summary.Complete = false
+			if !emit(ctr.StreamLine{Line: n + 1, Error: &presenter.ErrorBody{Code: presenter.CodeInvalidRequest, Message: reason}}) {
+				return
+			}
+		}
+		emit(ctr.StreamLine{Summary: &summary})
+	}
+}
+
+func calculateLine(ctx context.Context, ctrl *ctr.Controller, n int, raw []byte, explain bool) ctr.StreamLine {
+	var in ctr.StreamOrderLine
+	if err := json.Unmarshal(raw, &in); err != nil {
+		_, body := presenter.MapBindError(err)
+		return ctr.StreamLine{Line: n, Error: &body}
+	}
+	res, err := ctrl.HandleCalculate(ctx, ctr.CalculateRequest{
+		Quantity:      in.Quantity,
+		PacksOverride: in.PacksOverride,
+		Explain:       explain,
+	})
+	if err != nil {
+		_, body := presenter.MapError(err)
+		return ctr.StreamLine{Line: n, ID: in.ID, Error: &body}
+	}
+	return ctr.StreamLine{Line: n, ID: in.ID, Result: &res}
+}
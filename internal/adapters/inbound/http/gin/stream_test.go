@@ -0,0 +1,247 @@
+package ginadapter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
+)
+
+func postStream(h http.Handler, ctx context.Context, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)).WithContext(ctx)
+	req.Header.Set("Content-Type", NDJSONContentType)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeStream(t *testing.T, r io.Reader) []ctr.StreamLine {
+	t.Helper()
+	var lines []ctr.StreamLine
+	dec := json.NewDecoder(r)
+	for {
+		var l ctr.StreamLine
+		err := dec.Decode(&l)
+		if err == io.EOF {
+			return lines
+		}
+		if err != nil {
+			t.Fatalf("invalid ndjson: %v", err)
+		}
+		lines = append(lines, l)
+	}
+}
+
+func TestStream_ResultsErrorsAndSummary(t *testing.T) {
+	h := contractHandler(t, defaultSizes, ValidateResponses)
+	body := strings.Join([]string{
+		`{"id":"a","quantity":251}`,
+		``,
+		`{"quantity":`,
+		`{"id":"b","quantity":0}`,
+		`{"id":"c","quantity":12001}`,
+	}, "\n")
+
+	rec := postStream(h, context.Background(), "/v1/calculate/stream", body)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != NDJSONContentType {
+		t.Fatalf("status=%d content-type=%q body=%s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+	lines := decodeStream(t, rec.Body)
+	if len(lines) != 5 {
+		t.Fatalf("lines got=%d want=5: %s", len(lines), rec.Body.String())
+	}
+
+	tests := []struct {
+		line      int
+		id        string
+		errorCode string
+		total     int
+	}{
+		{line: 1, id: "a", total: 500},
+		{line: 3, errorCode: presenter.CodeInvalidRequest},
+		{line: 4, id: "b", errorCode: domain.ErrInvalidQuantity.Code},
+		{line: 5, id: "c", total: 12250},
+	}
+	for i, tt := range tests {
+		got := lines[i]
+		if got.Line != tt.line || got.ID != tt.id {
+			t.Fatalf("line %d: got line=%d id=%q", i, got.Line, got.ID)
+		}
+		switch {
+		case tt.errorCode != "":
+			if got.Error == nil || got.Error.Code != tt.errorCode || got.Result != nil {
+				t.Fatalf("line %d: want error %s, got %+v", tt.line, tt.errorCode, got)
+			}
+		case got.Result == nil || got.Result.TotalItems != tt.total:
+			t.Fatalf("line %d: want total %d, got %+v", tt.line, tt.total, got)
+		}
+	}
+
+	want := ctr.StreamSummary{Lines: 4, Succeeded: 2, Failed: 2, Complete: true}
+	if s := lines[4].Summary; s == nil || *s != want {
+		t.Fatalf("summary got=%+v want=%+v", lines[4].Summary, want)
+	}
+}
+
+func TestStream_Explain(t *testing.T) {
+	h := contractHandler(t, defaultSizes, ValidateResponses)
+
+	rec := postStream(h, context.Background(), "/v1/calculate/stream?explain=true", `{"quantity":251}`)
+	lines := decodeStream(t, rec.Body)
+	if len(lines) != 2 || lines[0].Result == nil || lines[0].Result.Explanation == nil {
+		t.Fatalf("want an explained result, got %s", rec.Body.String())
+	}
+
+	rec = postStream(h, context.Background(), "/v1/calculate/stream?explain=maybe", `{"quantity":251}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status got=%d want=400", rec.Code)
+	}
+}
+
+func TestStream_LineTooLong(t *testing.T) {
+	h := contractHandler(t, defaultSizes, ValidateResponses)
+	body := `{"quantity":1}` + "\n" + `{"id":"` + strings.Repeat("x", maxStreamLineSize) + `","quantity":1}` + "\n" + `{"quantity":1}`
+
+	rec := postStream(h, context.Background(), "/v1/calculate/stream", body)
+	lines := decodeStream(t, rec.Body)
+	if len(lines) != 3 {
+		t.Fatalf("lines got=%d want=3: %s", len(lines), rec.Body.String())
+	}
+	if l := lines[1]; l.Line != 2 || l.Error == nil || l.Error.Code != presenter.CodeInvalidRequest {
+		t.Fatalf("want an error on line 2, got %+v", l)
+	}
+	want := ctr.StreamSummary{Lines: 2, Succeeded: 1, Failed: 1, Complete: false}
+	if s := lines[2].Summary; s == nil || *s != want {
+		t.Fatalf("summary got=%+v want=%+v", lines[2].Summary, want)
+	}
+}
+
+func TestStream_UnsupportedMediaType(t *testing.T) {
+	h := contractHandler(t, defaultSizes, ValidateResponses)
+	req := httptest.NewRequest(http.MethodPost, "/v1/calculate/stream", strings.NewReader(`{"quantity":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("status got=%d want=415", rec.Code)
+	}
+}
+
+func TestStream_Cancellation(t *testing.T) {
+	calc := &countingCalc{started: make(chan struct{}), release: make(chan struct{})}
+	h := BuildHandler(ctr.NewController(calc, &fakeGet{}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- postStream(h, ctx, "/v1/calculate/stream", "{\"quantity\":1}\n{\"quantity\":2}\n{\"quantity\":3}\n")
+	}()
+	<-calc.started
+	cancel()
+	close(calc.release)
+
+	rec := <-done
+	lines := decodeStream(t, rec.Body)
+	if n := calc.calls.Load(); n != 1 {
+		t.Fatalf("calculations got=%d want=1", n)
+	}
+	want := ctr.StreamSummary{Lines: 1, Succeeded: 1, Complete: false}
+	if len(lines) != 2 || lines[1].Summary == nil || *lines[1].Summary != want {
+		t.Fatalf("want one result and an incomplete summary, got %s", rec.Body.String())
+	}
+}
+
+// Each result reaches the client before the next line is sent, through the
+// compression and the response validation.
+func TestStream_Interleaved(t *testing.T) {
+	calc := &countingCalc{}
+	srv := httptest.NewServer(BuildHandler(ctr.NewController(calc, &fakeGet{}),
+		WithCompression(DefaultCompressMinSize),
+		WithOpenAPIValidation(ValidateResponses),
+	))
+	defer srv.Close()
+
+	pr, pw := io.Pipe()
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/v1/calculate/stream", pr)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	req.Header.Set("Content-Type", NDJSONContentType)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	go io.WriteString(pw, "{\"id\":\"first\",\"quantity\":7}\n")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("content-encoding got=%q want=gzip", resp.Header.Get("Content-Encoding"))
+	}
+	body, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	lines := bufio.NewScanner(body)
+
+	next := func() ctr.StreamLine {
+		t.Helper()
+		got := make(chan ctr.StreamLine)
+		go func() {
+			var l ctr.StreamLine
+			if lines.Scan() {
+				_ = json.Unmarshal(lines.Bytes(), &l)
+			}
+			got <- l
+		}()
+		select {
+		case l := <-got:
+			return l
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no line received while the request is still open")
+			return ctr.StreamLine{}
+		}
+	}
+
+	if l := next(); l.ID != "first" || l.Result == nil || l.Result.TotalItems != 7 {
+		t.Fatalf("first line got %+v", l)
+	}
+	io.WriteString(pw, "{\"id\":\"second\",\"quantity\":9}\n")
+	if l := next(); l.ID != "second" || l.Result == nil || l.Result.TotalItems != 9 {
+		t.Fatalf("second line got %+v", l)
+	}
+	pw.Close()
+	if l := next(); l.Summary == nil || l.Summary.Lines != 2 || !l.Summary.Complete {
+		t.Fatalf("summary got %+v", l)
+	}
+}
+
+func TestStream_IgnoresIdempotencyKey(t *testing.T) {
+	calc := &countingCalc{}
+	h := newIdempotentHandler(calc)
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/v1/calculate/stream", strings.NewReader(`{"quantity":250}`))
+		req.Header.Set("Content-Type", NDJSONContentType)
+		req.Header.Set(IdempotencyKeyHeader, "batch-1")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || rec.Header().Get(ReplayedHeader) != "" {
+			t.Fatalf("status=%d replayed=%q", rec.Code, rec.Header().Get(ReplayedHeader))
+		}
+	}
+	if n := calc.calls.Load(); n != 2 {
+		t.Fatalf("calls got=%d want=2: a stream is never replayed", n)
+	}
+}
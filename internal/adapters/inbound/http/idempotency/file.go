@@ -0,0 +1,201 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how often Run removes the expired records.
+const sweepEvery = time.Minute
+
+// FileStore keeps one JSON file per key in a directory, so the records
+// survive restarts (and can be shared by replicas on the same volume). It
+// holds up to Options.MaxEntries records and Options.MaxBytes; when full,
+// Put refuses new records (ErrFull) until the expired ones are swept.
+type FileStore struct {
+	dir  string
+	opts Options
+
+	mu      sync.Mutex
+	entries int   // records on disk: counted by sweep, kept up to date by Put
+	bytes   int64 // and their size
+}
+
+// compile-time check
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore creates dir if needed and removes the expired (or corrupt)
+// records; Run keeps removing them while the store is in use.
+func NewFileStore(dir string, opts Options) (*FileStore, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, errors.New("idempotency: empty directory")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("idempotency: %w", err)
+	}
+	s := &FileStore{dir: dir, opts: opts}
+	if err := s.sweep(0); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileStore) Get(key string) (Record, bool, error) {
+	path := s.path(key)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Record{}, false, nil
+	}
+	if err != nil {
+		return Record{}, false, fmt.Errorf("idempotency: %w", err)
+	}
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return Record{}, false, fmt.Errorf("idempotency: decoding %s: %w", filepath.Base(path), err)
+	}
+	if !s.opts.now().Before(rec.ExpiresAt) {
+		if os.Remove(path) == nil {
+			s.release(1, int64(len(data)))
+		}
+		return Record{}, false, nil
+	}
+	return rec, true, nil
+}
+
+// Put writes to a temporary file and renames it: readers never see a
+// partial record.
+func (s *FileStore) Put(key string, rec Record, ttl time.Duration) error {
+	rec.ExpiresAt = s.opts.now().Add(ttl)
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("idempotency: %w", err)
+	}
+	path := s.path(key)
+	entries, size := 1, int64(len(data))
+	if info, err := os.Stat(path); err == nil {
+		entries, size = 0, size-info.Size()
+	}
+	if !s.reserve(entries, size) {
+		return ErrFull
+	}
+	if err := s.write(path, data); err != nil {
+		s.release(entries, size)
+		return err
+	}
+	return nil
+}
+
+// reserve counts a record of Put in; false when the store has no room for
+// it (a replaced record only takes its growth).
+func (s *FileStore) reserve(entries int, size int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries+entries > s.opts.maxEntries() || (size > 0 && s.bytes+size > s.opts.maxBytes()) {
+		return false
+	}
+	s.entries += entries
+	s.bytes += size
+	return true
+}
+
+func (s *FileStore) release(entries int, size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries -= entries
+	s.bytes -= size
+}
+
+func (s *FileStore) write(path string, data []byte) error {
+	tmp, err := os.CreateTemp(s.dir, ".record-*")
+	if err != nil {
+		return fmt.Errorf("idempotency: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("idempotency: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("idempotency: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("idempotency: %w", err)
+	}
+	return nil
+}
+
+// Run removes the expired records every sweepEvery until ctx is done.
+func (s *FileStore) Run(ctx context.Context) {
+	ticker := time.NewTicker(sweepEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// a temporary file younger than that may be a Put in progress
+			if err := s.sweep(sweepEvery); err != nil {
+				log.Printf("idempotency: sweep: %v", err)
+			}
+		}
+	}
+}
+
+// path hashes the key: it may hold any character.
+func (s *FileStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// sweep removes the expired and corrupt records, and the temporary files
+// older than tmpAge, then counts the records left (a Put running meanwhile
+// may be missed until the next sweep).
+func (s *FileStore) sweep(tmpAge time.Duration) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("idempotency: %w", err)
+	}
+	now := s.opts.now()
+	kept, size := 0, int64(0)
+	for _, e := range entries {
+		path := filepath.Join(s.dir, e.Name())
+		if strings.HasPrefix(e.Name(), ".record-") {
+			// left by a crash during Put; the age is on the file system clock
+			if info, err := e.Info(); err == nil && time.Since(info.ModTime()) >= tmpAge {
+				_ = os.Remove(path)
+			}
+			continue
+		}
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var rec Record
+		if json.Unmarshal(data, &rec) != nil || !now.Before(rec.ExpiresAt) {
+			_ = os.Remove(path)
+			continue
+		}
+		kept, size = kept+1, size+int64(len(data))
+	}
+	s.mu.Lock()
+	s.entries, s.bytes = kept, size
+	s.mu.Unlock()
+	return nil
+}
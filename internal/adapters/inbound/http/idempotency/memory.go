@@ -0,0 +1,110 @@
+package idempotency
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps up to Options.MaxEntries records in process memory: they
+// are lost on restart and not shared between replicas.
+type MemoryStore struct {
+	opts Options
+
+	mu      sync.Mutex
+	records map[string]*memoryRecord
+	expiry  expiryHeap // first to expire on top
+}
+
+type memoryRecord struct {
+	key   string
+	rec   Record
+	index int // in expiry
+}
+
+// compile-time check
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore(opts Options) *MemoryStore {
+	return &MemoryStore{opts: opts, records: make(map[string]*memoryRecord)}
+}
+
+func (s *MemoryStore) Get(key string) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[key]
+	if !ok {
+		return Record{}, false, nil
+	}
+	if !s.opts.now().Before(r.rec.ExpiresAt) {
+		s.remove(r)
+		return Record{}, false, nil
+	}
+	return r.rec, true, nil
+}
+
+// Put drops the expired records, then, when the store is still full, the one
+// expiring first: the oldest key is the one a client is least likely to
+// retry. Both come off the top of the heap.
+func (s *MemoryStore) Put(key string, rec Record, ttl time.Duration) error {
+	now := s.opts.now()
+	rec.ExpiresAt = now.Add(ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.records[key]; ok {
+		r.rec = rec
+		heap.Fix(&s.expiry, r.index)
+		return nil
+	}
+	for len(s.expiry) > 0 && !now.Before(s.expiry[0].rec.ExpiresAt) {
+		s.remove(s.expiry[0])
+	}
+	if len(s.expiry) >= s.opts.maxEntries() {
+		s.remove(s.expiry[0])
+	}
+	r := &memoryRecord{key: key, rec: rec}
+	heap.Push(&s.expiry, r)
+	s.records[key] = r
+	return nil
+}
+
+// remove must be called with s.mu held.
+func (s *MemoryStore) remove(r *memoryRecord) {
+	heap.Remove(&s.expiry, r.index)
+	delete(s.records, r.key)
+}
+
+// Len returns the number of records held, expired ones included.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
+
+// expiryHeap orders the records by ExpiresAt (container/heap).
+type expiryHeap []*memoryRecord
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].rec.ExpiresAt.Before(h[j].rec.ExpiresAt) }
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *expiryHeap) Push(x any) {
+	r := x.(*memoryRecord)
+	r.index = len(*h)
+	*h = append(*h, r)
+}
+
+func (h *expiryHeap) Pop() any {
+	old := *h
+	r := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return r
+}
@@ -0,0 +1,72 @@
+// Package idempotency stores the first response of each Idempotency-Key so
+// retried requests are answered without running the handler again.
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Record is a stored response.
+// - Fingerprint: hash of the request (method, URI, body) that produced it
+// - ExpiresAt: set by the store on Put (now + ttl)
+type Record struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body"`
+	ExpiresAt   time.Time   `json:"expiresAt"`
+}
+
+// Store keeps records until they expire. Keys are opaque strings chosen by
+// the caller (the HTTP middleware combines the caller and the header value).
+type Store interface {
+	// Get returns the record of key; ok is false when absent or expired.
+	Get(key string) (rec Record, ok bool, err error)
+	// Put saves rec under key for ttl, replacing any previous record.
+	Put(key string, rec Record, ttl time.Duration) error
+}
+
+// ErrFull is returned by FileStore.Put when a new record would go over
+// Options.MaxEntries or Options.MaxBytes; the request is served, not stored.
+var ErrFull = errors.New("idempotency: store is full")
+
+// DefaultMaxEntries and DefaultMaxBytes bound a store when the Options leave
+// them at 0.
+const (
+	DefaultMaxEntries = 10_000
+	DefaultMaxBytes   = 256 << 20
+)
+
+// Options configures the stores.
+// - Now: clock, injectable for tests (nil = time.Now)
+// - MaxEntries: records kept; when full, a MemoryStore drops the one
+// expiring first and a FileStore refuses new keys (0 = DefaultMaxEntries)
+// - MaxBytes: size of the records kept by a FileStore (0 = DefaultMaxBytes)
+type Options struct {
+	Now        func() time.Time
+	MaxEntries int
+	MaxBytes   int64
+}
+
+func (o Options) now() time.Time {
+	if o.Now == nil {
+		return time.Now()
+	}
+	return o.Now()
+}
+
+func (o Options) maxEntries() int {
+	if o.MaxEntries <= 0 {
+		return DefaultMaxEntries
+	}
+	return o.MaxEntries
+}
+
+func (o Options) maxBytes() int64 {
+	if o.MaxBytes <= 0 {
+		return DefaultMaxBytes
+	}
+	return o.MaxBytes
+}
@@ -0,0 +1,200 @@
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time { return c.now }
+
+func newStores(t *testing.T, clk *clock) map[string]Store {
+	t.Helper()
+	fileStore, err := NewFileStore(t.TempDir(), Options{Now: clk.Now})
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	return map[string]Store{
+		"memory": NewMemoryStore(Options{Now: clk.Now}),
+		"file":   fileStore,
+	}
+}
+
+func TestStores_PutGetExpire(t *testing.T) {
+	clk := &clock{now: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)}
+	for name, store := range newStores(t, clk) {
+		t.Run(name, func(t *testing.T) {
+			clk.now = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+			key := "caller\nkey/with:odd chars"
+
+			if _, ok, err := store.Get(key); ok || err != nil {
+				t.Fatalf("empty store: ok=%v err=%v", ok, err)
+			}
+
+			rec := Record{
+				Fingerprint: "fp",
+				Status:      http.StatusOK,
+				Header:      http.Header{"Content-Type": {"application/json"}},
+				Body:        []byte(`{"totalItems":500}`),
+			}
+			if err := store.Put(key, rec, time.Hour); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			got, ok, err := store.Get(key)
+			if !ok || err != nil {
+				t.Fatalf("Get: ok=%v err=%v", ok, err)
+			}
+			rec.ExpiresAt = clk.now.Add(time.Hour)
+			if !reflect.DeepEqual(got, rec) || !got.ExpiresAt.Equal(rec.ExpiresAt) {
+				t.Fatalf("got %+v want %+v", got, rec)
+			}
+
+			clk.now = clk.now.Add(time.Hour)
+			if _, ok, _ := store.Get(key); ok {
+				t.Fatalf("record must expire after the ttl")
+			}
+		})
+	}
+}
+
+func TestMemoryStore_SweepsExpired(t *testing.T) {
+	clk := &clock{now: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore(Options{Now: clk.Now})
+	for _, k := range []string{"a", "b", "c"} {
+		_ = store.Put(k, Record{}, time.Second)
+	}
+	clk.now = clk.now.Add(2 * sweepEvery)
+	_ = store.Put("d", Record{}, time.Hour)
+	if n := store.Len(); n != 1 {
+		t.Fatalf("records after sweep got=%d want=1", n)
+	}
+}
+
+func TestMemoryStore_MaxEntries(t *testing.T) {
+	clk := &clock{now: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore(Options{Now: clk.Now, MaxEntries: 2})
+	_ = store.Put("a", Record{}, 2*time.Hour)
+	_ = store.Put("b", Record{}, time.Hour)
+	_ = store.Put("a", Record{Status: http.StatusCreated}, 3*time.Hour) // replacing does not evict
+	if n := store.Len(); n != 2 {
+		t.Fatalf("records got=%d want=2", n)
+	}
+
+	_ = store.Put("c", Record{}, time.Hour)
+	if n := store.Len(); n != 2 {
+		t.Fatalf("records got=%d want=2", n)
+	}
+	if _, ok, _ := store.Get("b"); ok {
+		t.Fatalf("the record expiring first must be evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok, _ := store.Get(k); !ok {
+			t.Fatalf("record %q was evicted", k)
+		}
+	}
+}
+
+func TestFileStore_Limits(t *testing.T) {
+	clk := &clock{now: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)}
+	store, err := NewFileStore(t.TempDir(), Options{Now: clk.Now, MaxEntries: 2, MaxBytes: 1024})
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	if err := store.Put("a", Record{}, time.Minute); err != nil {
+		t.Fatalf("Put a: %v", err)
+	}
+	if err := store.Put("b", Record{}, time.Hour); err != nil {
+		t.Fatalf("Put b: %v", err)
+	}
+	if err := store.Put("c", Record{}, time.Hour); !errors.Is(err, ErrFull) {
+		t.Fatalf("Put over MaxEntries got %v want ErrFull", err)
+	}
+	if err := store.Put("a", Record{Status: http.StatusCreated}, time.Minute); err != nil {
+		t.Fatalf("replacing a record: %v", err)
+	}
+	if err := store.Put("a", Record{Body: make([]byte, 1024)}, time.Minute); !errors.Is(err, ErrFull) {
+		t.Fatalf("Put over MaxBytes got %v want ErrFull", err)
+	}
+	if rec, ok, _ := store.Get("a"); !ok || rec.Status != http.StatusCreated {
+		t.Fatalf("a refused record replaced the stored one: %+v, %v", rec, ok)
+	}
+
+	// the expired records make room once swept
+	clk.now = clk.now.Add(2 * time.Minute)
+	if err := store.sweep(sweepEvery); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if err := store.Put("c", Record{}, time.Hour); err != nil {
+		t.Fatalf("Put after the sweep: %v", err)
+	}
+}
+
+func TestFileStore_SweepKeepsRecentTemporaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, Options{})
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	tmp := filepath.Join(dir, ".record-456") // a Put in progress
+	if err := os.WriteFile(tmp, []byte("{"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := store.sweep(sweepEvery); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if _, err := os.Stat(tmp); err != nil {
+		t.Fatalf("recent temporary file was removed: %v", err)
+	}
+	old := time.Now().Add(-2 * sweepEvery)
+	if err := os.Chtimes(tmp, old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if err := store.sweep(sweepEvery); err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Fatalf("stale temporary file was kept: %v", err)
+	}
+}
+
+func TestFileStore_SweepsOnOpen(t *testing.T) {
+	dir := t.TempDir()
+	clk := &clock{now: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)}
+	store, err := NewFileStore(dir, Options{Now: clk.Now})
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	_ = store.Put("short", Record{}, time.Minute)
+	_ = store.Put("long", Record{}, time.Hour)
+	if err := os.WriteFile(filepath.Join(dir, "corrupt.json"), []byte("{"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".record-123"), []byte("{"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	clk.now = clk.now.Add(10 * time.Minute)
+	store, err = NewFileStore(dir, Options{Now: clk.Now})
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("files after sweep got=%d want=1", len(entries))
+	}
+	if _, ok, _ := store.Get("long"); !ok {
+		t.Fatalf("live record was swept")
+	}
+}
+
+func TestNewFileStore_EmptyDir(t *testing.T) {
+	if _, err := NewFileStore(" ", Options{}); err == nil {
+		t.Fatalf("expected error for empty dir")
+	}
+}
@@ -0,0 +1,414 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Operation describes one route; Body and Content types are Go values whose
+// schema is derived by reflection (see Builder.SchemaOf).
+type Operation struct {
+	Method      string // "GET", "POST", ...
+	Path        string // gin syntax; ":id" becomes "{id}"
+	ID          string
+	Summary     string
+	Description string
+	Tags        []string
+	Params      []Param
+	Body        *Content  // application/json, required
+	AltBodies   []Content // other media types accepted for the body
+	Responses   []Response
+	Security    []string // names of schemes added with Builder.SecurityScheme (any of them)
+}
+
+// Param is a query, header or path parameter.
+type Param struct {
+	Name        string
+	In          string // "query", "header" or "path"
+	Description string
+	Required    bool
+	Type        any // zero value of the Go type, e.g. false
+	Default     any
+}
+
+// Response lists the media types answered with Status.
+type Response struct {
+	Status      int
+	Description string
+	Headers     []Header
+	Content     []Content
+}
+
+// Header is a response header.
+type Header struct {
+	Name        string
+	Description string
+	Type        any // zero value of the Go type, e.g. ""
+}
+
+// Content is a media type, its Go type and the documented examples.
+type Content struct {
+	MediaType string // defaults to application/json
+	Type      any
+	Examples  []Example
+}
+
+type Example struct {
+	Name    string
+	Summary string
+	Value   any // rendered through encoding/json, like the real responses
+}
+
+// Builder collects operations and the schemas of the types they use.
+//
+// Struct fields are described by their json tag plus:
+// - doc:"..."         description
+// - minimum:"n"       minimum (of the elements, for slices and maps)
+// - enum:"a,b"        allowed values
+// - format:"binary"   format (e.g. a file part of multipart/form-data)
+// - schema:"Name"     reference a schema added with Builder.Schema
+//
+// Fields without omitempty are required.
+type Builder struct {
+	doc   Document
+	types map[string]reflect.Type // schema name -> Go type, to detect clashes
+}
+
+func NewBuilder(info Info, servers []Server, tags []Tag) *Builder {
+	return &Builder{
+		doc: Document{
+			OpenAPI:    Version,
+			Info:       info,
+			Servers:    servers,
+			Tags:       tags,
+			Components: Components{Schemas: make(map[string]*Schema)},
+		},
+		types: make(map[string]reflect.Type),
+	}
+}
+
+// Schema adds a schema that cannot be derived from a Go type.
+func (b *Builder) Schema(name string, s *Schema) {
+	b.doc.Components.Schemas[name] = s
+}
+
+// SecurityScheme adds a scheme operations can require (Operation.Security).
+func (b *Builder) SecurityScheme(name string, s *SecurityScheme) {
+	if b.doc.Components.SecuritySchemes == nil {
+		b.doc.Components.SecuritySchemes = make(map[string]*SecurityScheme)
+	}
+	b.doc.Components.SecuritySchemes[name] = s
+}
+
+// Add registers an operation.
+func (b *Builder) Add(op Operation) error {
+	obj := &OperationObject{
+		method:      strings.ToLower(op.Method),
+		Tags:        op.Tags,
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: op.ID,
+		Responses:   make(map[string]*ResponseObject, len(op.Responses)),
+	}
+	for _, p := range op.Params {
+		s, err := b.SchemaOf(reflect.TypeOf(p.Type))
+		if err != nil {
+			return fmt.Errorf("%s param %s: %w", op.ID, p.Name, err)
+		}
+		if p.Default != nil {
+			s.Default = &yaml.Node{}
+			if err := s.Default.Encode(p.Default); err != nil {
+				return err
+			}
+		}
+		obj.Parameters = append(obj.Parameters, ParameterObject{
+			Name: p.Name, In: p.In, Required: p.Required || p.In == "path", Description: p.Description, Schema: s,
+		})
+	}
+	if op.Body != nil {
+		content, err := b.content(append([]Content{*op.Body}, op.AltBodies...))
+		if err != nil {
+			return fmt.Errorf("%s request: %w", op.ID, err)
+		}
+		obj.RequestBody = &RequestBodyObject{Required: true, Content: content}
+	}
+	for _, r := range op.Responses {
+		content, err := b.content(r.Content)
+		if err != nil {
+			return fmt.Errorf("%s response %d: %w", op.ID, r.Status, err)
+		}
+		resp := &ResponseObject{Description: r.Description, Content: content}
+		for _, h := range r.Headers {
+			s, err := b.SchemaOf(reflect.TypeOf(h.Type))
+			if err != nil {
+				return fmt.Errorf("%s response %d header %s: %w", op.ID, r.Status, h.Name, err)
+			}
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]*HeaderObject, len(r.Headers))
+			}
+			resp.Headers[h.Name] = &HeaderObject{Description: h.Description, Schema: s}
+		}
+		obj.Responses[strconv.Itoa(r.Status)] = resp
+	}
+	for _, name := range op.Security {
+		if _, ok := b.doc.Components.SecuritySchemes[name]; !ok {
+			return fmt.Errorf("%s: unknown security scheme %s", op.ID, name)
+		}
+		obj.Security = append(obj.Security, map[string][]string{name: {}})
+	}
+
+	path := toOpenAPIPath(op.Path)
+	for _, item := range b.doc.Paths {
+		if item.Path == path {
+			item.Operations = append(item.Operations, obj)
+			return nil
+		}
+	}
+	b.doc.Paths = append(b.doc.Paths, &PathItem{Path: path, Operations: []*OperationObject{obj}})
+	return nil
+}
+
+// Document returns the assembled document.
+func (b *Builder) Document() *Document { return &b.doc }
+
+// YAML renders the document, preceded by header (a comment, may be empty).
+func (b *Builder) YAML(header string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&b.doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (b *Builder) content(in []Content) (map[string]*MediaType, error) {
+	out := make(map[string]*MediaType, len(in))
+	for _, c := range in {
+		s, err := b.SchemaOf(reflect.TypeOf(c.Type))
+		if err != nil {
+			return nil, err
+		}
+		mt := &MediaType{Schema: s}
+		for _, ex := range c.Examples {
+			node, err := exampleNode(ex.Value)
+			if err != nil {
+				return nil, fmt.Errorf("example %s: %w", ex.Name, err)
+			}
+			if mt.Examples == nil {
+				mt.Examples = make(map[string]*ExampleObject)
+			}
+			mt.Examples[ex.Name] = &ExampleObject{Summary: ex.Summary, Value: node}
+		}
+		mediaType := c.MediaType
+		if mediaType == "" {
+			mediaType = "application/json"
+		}
+		out[mediaType] = mt
+	}
+	return out, nil
+}
+
+// timeType marshals as an RFC 3339 string, not as an object.
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaOf derives the schema of t; named structs become components.
+func (b *Builder) SchemaOf(t reflect.Type) (*Schema, error) {
+	if t == nil {
+		return &Schema{}, nil
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.SchemaOf(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := b.SchemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		values, err := b.SchemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Struct:
+		return b.structRef(t)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+func (b *Builder) structRef(t reflect.Type) (*Schema, error) {
+	name := t.Name()
+	if name == "" {
+		return nil, fmt.Errorf("anonymous struct %s", t)
+	}
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if prev, ok := b.types[name]; ok {
+		if prev != t {
+			return nil, fmt.Errorf("schema name %s used by %s and %s", name, prev, t)
+		}
+		return ref, nil
+	}
+	b.types[name] = t
+
+	s := &Schema{Type: "object"}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		jsonName, omitempty := parseJSONTag(f)
+		if jsonName == "-" {
+			continue
+		}
+		fs, err := b.fieldSchema(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", name, f.Name, err)
+		}
+		s.Properties = append(s.Properties, Property{Name: jsonName, Schema: fs})
+		if !omitempty {
+			s.Required = append(s.Required, jsonName)
+		}
+	}
+	b.doc.Components.Schemas[name] = s
+	return ref, nil
+}
+
+func (b *Builder) fieldSchema(f reflect.StructField) (*Schema, error) {
+	if ref := f.Tag.Get("schema"); ref != "" {
+		return &Schema{Ref: "#/components/schemas/" + ref}, nil
+	}
+	s, err := b.SchemaOf(f.Type)
+	if err != nil {
+		return nil, err
+	}
+	if s.Ref != "" && (f.Tag.Get("doc") != "" || f.Tag.Get("minimum") != "" || f.Tag.Get("enum") != "" || f.Tag.Get("format") != "") {
+		return nil, fmt.Errorf("doc, minimum, enum and format cannot decorate a $ref")
+	}
+	s.Description = f.Tag.Get("doc")
+	if v := f.Tag.Get("format"); v != "" {
+		s.Format = v
+	}
+
+	target := s // minimum and enum apply to the elements of slices and maps
+	if s.Items != nil {
+		target = s.Items
+	} else if values, ok := s.AdditionalProperties.(*Schema); ok {
+		target = values
+	}
+	if v := f.Tag.Get("minimum"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("minimum %q: %w", v, err)
+		}
+		target.Minimum = &n
+	}
+	if v := f.Tag.Get("enum"); v != "" {
+		target.Enum = strings.Split(v, ",")
+	}
+	return s, nil
+}
+
+func parseJSONTag(f reflect.StructField) (name string, omitempty bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "-", false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	for _, o := range strings.Split(opts, ",") {
+		if o == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty
+}
+
+// exampleNode renders v as the API would (encoding/json), then as YAML in
+// block style with short scalar lists kept inline.
+func exampleNode(v any) (*yaml.Node, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	node := doc.Content[0]
+	restyle(node)
+	return node, nil
+}
+
+func restyle(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		n.Style = 0
+		if n.Tag == "!!str" && needsQuotes(n.Value) {
+			n.Style = yaml.DoubleQuotedStyle
+		}
+	case yaml.SequenceNode, yaml.MappingNode:
+		flat := true
+		for _, c := range n.Content {
+			restyle(c)
+			if c.Kind != yaml.ScalarNode {
+				flat = false
+			}
+		}
+		n.Style = 0
+		if flat && len(n.Content) > 0 && n.Kind == yaml.SequenceNode {
+			n.Style = yaml.FlowStyle
+		}
+	}
+}
+
+// needsQuotes keeps strings that look like other YAML types (e.g. map keys
+// such as "250") from being read back as numbers.
+func needsQuotes(s string) bool {
+	var v any
+	if err := yaml.Unmarshal([]byte(s), &v); err != nil {
+		return true
+	}
+	_, isString := v.(string)
+	return !isString || v != s
+}
+
+// toOpenAPIPath converts gin parameters (":id") to OpenAPI ("{id}").
+func toOpenAPIPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,153 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sample struct {
+	ID      int         `json:"id" minimum:"1" doc:"identifier"`
+	Tags    []string    `json:"tags,omitempty" enum:"a,b"`
+	Counts  map[int]int `json:"counts" minimum:"0"`
+	Nested  *nested     `json:"nested,omitempty"`
+	Extra   any         `json:"extra,omitempty" schema:"Extra"`
+	Hidden  bool        `json:"-"`
+	private int
+	Raw     map[string]any `json:"raw,omitempty"`
+	File    string         `json:"file,omitempty" format:"binary"`
+	At      *time.Time     `json:"at,omitempty"`
+}
+
+type nested struct {
+	Name string `json:"name"`
+}
+
+func TestBuilder_SchemaOf(t *testing.T) {
+	b := NewBuilder(Info{Title: "t", Version: "1"}, nil, nil)
+	ref, err := b.SchemaOf(reflect.TypeOf(sample{}))
+	if err != nil {
+		t.Fatalf("SchemaOf: %v", err)
+	}
+	if ref.Ref != "#/components/schemas/sample" {
+		t.Fatalf("expected a $ref, got %+v", ref)
+	}
+
+	s := b.Document().Components.Schemas["sample"]
+	if !reflect.DeepEqual(s.Required, []string{"id", "counts"}) {
+		t.Fatalf("required got %v", s.Required)
+	}
+	var names []string
+	for _, p := range s.Properties {
+		names = append(names, p.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"id", "tags", "counts", "nested", "extra", "raw", "file", "at"}) {
+		t.Fatalf("properties got %v", names)
+	}
+
+	if id := s.Property("id"); id.Type != "integer" || *id.Minimum != 1 || id.Description != "identifier" {
+		t.Fatalf("id got %+v", id)
+	}
+	if tags := s.Property("tags"); tags.Items.Type != "string" || !reflect.DeepEqual(tags.Items.Enum, []string{"a", "b"}) {
+		t.Fatalf("enum must apply to the items, got %+v", tags.Items)
+	}
+	if counts := s.Property("counts").AdditionalProperties.(*Schema); *counts.Minimum != 0 {
+		t.Fatalf("minimum must apply to the map values, got %+v", counts)
+	}
+	if f := s.Property("file"); f.Type != "string" || f.Format != "binary" {
+		t.Fatalf("file got %+v", f)
+	}
+	if at := s.Property("at"); at.Type != "string" || at.Format != "date-time" || at.Ref != "" {
+		t.Fatalf("time.Time must be a date-time string, got %+v", at)
+	}
+	if _, ok := b.Document().Components.Schemas["Time"]; ok {
+		t.Fatalf("time.Time must not become a component")
+	}
+	if n := s.Property("nested"); n.Ref != "#/components/schemas/nested" {
+		t.Fatalf("nested got %+v", n)
+	}
+	if e := s.Property("extra"); e.Ref != "#/components/schemas/Extra" {
+		t.Fatalf("extra got %+v", e)
+	}
+	if _, ok := b.Document().Components.Schemas["nested"]; !ok {
+		t.Fatalf("nested struct must become a component")
+	}
+}
+
+func TestBuilder_NameClash(t *testing.T) {
+	type nested struct{ Other int }
+	b := NewBuilder(Info{}, nil, nil)
+	if _, err := b.SchemaOf(reflect.TypeOf(sample{})); err != nil {
+		t.Fatalf("SchemaOf: %v", err)
+	}
+	if _, err := b.SchemaOf(reflect.TypeOf(nested{})); err == nil {
+		t.Fatalf("expected an error for two types named nested")
+	}
+}
+
+func TestBuilder_YAML(t *testing.T) {
+	b := NewBuilder(Info{Title: "t", Version: "1"}, nil, nil)
+	err := b.Add(Operation{
+		Method: "GET",
+		Path:   "/v1/items/:id",
+		ID:     "getItem",
+		Params: []Param{{Name: "id", In: "path", Type: 0}, {Name: "full", In: "query", Type: false, Default: false}},
+		Responses: []Response{{
+			Status:      200,
+			Description: "ok",
+			Headers:     []Header{{Name: "ETag", Description: "revision", Type: ""}},
+			Content: []Content{{
+				Type:     nested{},
+				Examples: []Example{{Name: "numeric", Value: map[string]any{"250": "10", "list": []int{1, 2}}}},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	out, err := b.YAML("# header\n")
+	if err != nil {
+		t.Fatalf("YAML: %v", err)
+	}
+	doc := string(out)
+	for _, want := range []string{
+		"# header\nopenapi: 3.0.3\n",
+		"  /v1/items/{id}:\n    get:\n",
+		"        - name: id\n          in: path\n          required: true\n",
+		"            default: false\n",
+		"          headers:\n            ETag:\n              description: revision\n              schema:\n                type: string\n",
+		`                    "250": "10"` + "\n",
+		"                    list: [1, 2]\n",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Fatalf("missing %q in:\n%s", want, doc)
+		}
+	}
+}
+
+func TestBuilder_Security(t *testing.T) {
+	b := NewBuilder(Info{Title: "t", Version: "1"}, nil, nil)
+	op := Operation{Method: "POST", Path: "/admin", ID: "admin", Security: []string{"token"},
+		Responses: []Response{{Status: 204, Description: "done"}}}
+	if err := b.Add(op); err == nil {
+		t.Fatalf("expected an error for an unknown scheme")
+	}
+
+	b.SecurityScheme("token", &SecurityScheme{Type: "http", Scheme: "bearer"})
+	if err := b.Add(op); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	out, err := b.YAML("")
+	if err != nil {
+		t.Fatalf("YAML: %v", err)
+	}
+	for _, want := range []string{
+		"      security:\n        - token: []\n",
+		"  securitySchemes:\n    token:\n      type: http\n      scheme: bearer\n",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
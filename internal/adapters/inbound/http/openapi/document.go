@@ -0,0 +1,172 @@
+// Package openapi builds the OpenAPI 3.0 document of the HTTP API from the
+// route table and the transport DTOs, so the spec cannot drift from the code.
+package openapi
+
+import "gopkg.in/yaml.v3"
+
+// Version is the OpenAPI version of the generated documents.
+const Version = "3.0.3"
+
+// Document is the subset of OpenAPI 3.0 used by this service.
+type Document struct {
+	OpenAPI    string     `yaml:"openapi"`
+	Info       Info       `yaml:"info"`
+	Servers    []Server   `yaml:"servers,omitempty"`
+	Tags       []Tag      `yaml:"tags,omitempty"`
+	Paths      Paths      `yaml:"paths"`
+	Components Components `yaml:"components"`
+}
+
+type Info struct {
+	Title       string `yaml:"title"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `yaml:"url"`
+}
+
+type Tag struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `yaml:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `yaml:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is an HTTP authentication scheme (e.g. bearer).
+type SecurityScheme struct {
+	Type        string `yaml:"type"`
+	Scheme      string `yaml:"scheme,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// PathItem holds the operations of one path, in registration order.
+type PathItem struct {
+	Path       string
+	Operations []*OperationObject
+}
+
+// Paths keeps registration order (a plain map would be sorted).
+type Paths []*PathItem
+
+func (p Paths) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, item := range p {
+		ops := &yaml.Node{Kind: yaml.MappingNode}
+		for _, op := range item.Operations {
+			if err := appendPair(ops, op.method, op); err != nil {
+				return nil, err
+			}
+		}
+		node.Content = append(node.Content, scalar(item.Path), ops)
+	}
+	return node, nil
+}
+
+type OperationObject struct {
+	method      string
+	Tags        []string                   `yaml:"tags,omitempty,flow"`
+	Summary     string                     `yaml:"summary,omitempty"`
+	Description string                     `yaml:"description,omitempty"`
+	OperationID string                     `yaml:"operationId"`
+	Parameters  []ParameterObject          `yaml:"parameters,omitempty"`
+	RequestBody *RequestBodyObject         `yaml:"requestBody,omitempty"`
+	Responses   map[string]*ResponseObject `yaml:"responses"`
+	Security    []map[string][]string      `yaml:"security,omitempty"`
+}
+
+type ParameterObject struct {
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Required    bool    `yaml:"required"`
+	Description string  `yaml:"description,omitempty"`
+	Schema      *Schema `yaml:"schema"`
+}
+
+type RequestBodyObject struct {
+	Required bool                  `yaml:"required"`
+	Content  map[string]*MediaType `yaml:"content"`
+}
+
+type ResponseObject struct {
+	Description string                   `yaml:"description"`
+	Headers     map[string]*HeaderObject `yaml:"headers,omitempty"`
+	Content     map[string]*MediaType    `yaml:"content,omitempty"`
+}
+
+type HeaderObject struct {
+	Description string  `yaml:"description,omitempty"`
+	Schema      *Schema `yaml:"schema"`
+}
+
+type MediaType struct {
+	Schema   *Schema                   `yaml:"schema"`
+	Examples map[string]*ExampleObject `yaml:"examples,omitempty"`
+}
+
+type ExampleObject struct {
+	Summary string     `yaml:"summary,omitempty"`
+	Value   *yaml.Node `yaml:"value"`
+}
+
+// Schema is a JSON schema as understood by OpenAPI 3.0.
+type Schema struct {
+	Ref                  string     `yaml:"$ref,omitempty"`
+	Type                 string     `yaml:"type,omitempty"`
+	Format               string     `yaml:"format,omitempty"`
+	Description          string     `yaml:"description,omitempty"`
+	Required             []string   `yaml:"required,omitempty,flow"`
+	Properties           Properties `yaml:"properties,omitempty"`
+	Items                *Schema    `yaml:"items,omitempty"`
+	AdditionalProperties any        `yaml:"additionalProperties,omitempty"` // *Schema or true
+	AnyOf                []*Schema  `yaml:"anyOf,omitempty"`
+	Enum                 []string   `yaml:"enum,omitempty"`
+	Minimum              *int       `yaml:"minimum,omitempty"`
+	Default              *yaml.Node `yaml:"default,omitempty"`
+}
+
+// Property returns the schema of a property, or nil.
+func (s *Schema) Property(name string) *Schema {
+	for _, p := range s.Properties {
+		if p.Name == name {
+			return p.Schema
+		}
+	}
+	return nil
+}
+
+// Property is one entry of Schema.Properties.
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties keeps the order of the struct fields.
+type Properties []Property
+
+func (p Properties) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, prop := range p {
+		if err := appendPair(node, prop.Name, prop.Schema); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+func scalar(v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+}
+
+func appendPair(node *yaml.Node, key string, v any) error {
+	var value yaml.Node
+	if err := value.Encode(v); err != nil {
+		return err
+	}
+	node.Content = append(node.Content, scalar(key), &value)
+	return nil
+}
@@ -2,7 +2,7 @@ package order
 
 import (
 	"context"
-	"errors"
+	"time"
 
 	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
 )
@@ -12,6 +12,35 @@ import (
 type Controller struct {
 	Calc uc.CalculatePacks
 	Get  uc.GetPackSizes
+
+	// Optional use cases: their routes are only registered when set.
+	Limits     uc.GetLimits
+	Versions   uc.ListPackSizeVersions
+	Upcoming   uc.ListUpcomingPackSizes
+	Schedule   uc.SchedulePackSizes     // admin
+	Toggle     uc.SetPackSizeEnabled    // admin
+	Deliveries uc.ListWebhookDeliveries // admin
+	Audit      uc.ListAuditEntries      // admin
+}
+
+// PackStatus filters the packs of GET /v1/packsizes.
+type PackStatus string
+
+const (
+	PackStatusAll      PackStatus = "all"
+	PackStatusEnabled  PackStatus = "enabled"
+	PackStatusDisabled PackStatus = "disabled"
+)
+
+// ParsePackStatus accepts "" as PackStatusAll.
+func ParsePackStatus(s string) (PackStatus, bool) {
+	switch PackStatus(s) {
+	case "", PackStatusAll:
+		return PackStatusAll, true
+	case PackStatusEnabled, PackStatusDisabled:
+		return PackStatus(s), true
+	}
+	return "", false
 }
 
 func NewController(calc uc.CalculatePacks, get uc.GetPackSizes) *Controller {
@@ -20,33 +49,193 @@ func NewController(calc uc.CalculatePacks, get uc.GetPackSizes) *Controller {
 
 // HandleCalculate maps request → use case → response.
 func (c *Controller) HandleCalculate(ctx context.Context, req CalculateRequest) (CalculateResponse, error) {
-	out, err := c.Calc.Execute(ctx, uc.CalculatePacksInput{
+	in := uc.CalculatePacksInput{
 		Quantity:      req.Quantity,
 		PacksOverride: req.PacksOverride,
-	})
+		Explain:       req.Explain,
+	}
+	if req.AsOf != nil {
+		in.AsOf = *req.AsOf
+	}
+	out, err := c.Calc.Execute(ctx, in)
 	if err != nil {
 		return CalculateResponse{}, err
 	}
 	return CalculateResponse{
-		ItemsByPack: out.ItemsByPack,
-		TotalItems:  out.TotalItems,
-		TotalPacks:  out.TotalPacks,
-		Leftover:    out.Leftover,
+		ItemsByPack:      out.ItemsByPack,
+		TotalItems:       out.TotalItems,
+		TotalPacks:       out.TotalPacks,
+		Leftover:         out.Leftover,
+		Explanation:      toExplanationResponse(out.Explanation),
+		CatalogueVersion: out.CatalogueVersion,
 	}, nil
 }
 
-// HandleGetPackSizes simply delegates to the use case.
-func (c *Controller) HandleGetPackSizes(ctx context.Context) (PackSizesResponse, error) {
+func toExplanationResponse(ex *uc.Explanation) *ExplanationResponse {
+	if ex == nil {
+		return nil
+	}
+	runnersUp := make([]RunnerUpResponse, 0, len(ex.RunnersUp))
+	for _, r := range ex.RunnersUp {
+		runnersUp = append(runnersUp, RunnerUpResponse(r))
+	}
+	return &ExplanationResponse{
+		GCD:              ex.GCD,
+		ScaledQuantity:   ex.ScaledQuantity,
+		ScaledUpperBound: ex.ScaledUpperBound,
+		RunnersUp:        runnersUp,
+	}
+}
+
+// HandleGetPackSizes delegates to the use case and keeps the packs with
+// status; the version feeds the conditional GET headers.
+func (c *Controller) HandleGetPackSizes(ctx context.Context, status PackStatus) (PackSizesResponse, error) {
 	out, err := c.Get.Execute(ctx)
 	if err != nil {
 		return PackSizesResponse{}, err
 	}
-	return PackSizesResponse{Sizes: out.Sizes}, nil
+	packs := make([]PackResponse, 0, len(out.Packs))
+	for _, p := range out.Packs {
+		if status == PackStatusAll || p.Enabled == (status == PackStatusEnabled) {
+			packs = append(packs, toPackResponse(p))
+		}
+	}
+	return PackSizesResponse{Sizes: out.Sizes, Packs: packs, Version: out.Version, UpdatedAt: out.UpdatedAt}, nil
 }
 
-// Helpers for known errors (optional; avoids importing implementation details).
-var (
-	ErrInvalidQuantity       = errors.New("quantity must be > 0")
-	ErrNoPackSizes           = errors.New("no pack sizes available")
-	ErrInvalidPackInOverride = errors.New("override contains non-positive pack size")
-)
+// HandleSetPackSizeEnabled enables or disables a size of the current version.
+func (c *Controller) HandleSetPackSizeEnabled(ctx context.Context, size int, enabled bool) (PackResponse, error) {
+	out, err := c.Toggle.Execute(ctx, uc.SetPackSizeEnabledInput{Size: size, Enabled: enabled})
+	if err != nil {
+		return PackResponse{}, err
+	}
+	return toPackResponse(out), nil
+}
+
+func toPackResponse(p uc.PackSize) PackResponse {
+	r := PackResponse{Size: p.Size, Enabled: p.Enabled, Label: p.Label, SKU: p.SKU, Cost: p.Cost, Stock: p.Stock}
+	if p.Dimensions != nil {
+		d := DimensionsResponse(*p.Dimensions)
+		r.Dimensions = &d
+	}
+	return r
+}
+
+// HandleGetLimits exposes the calculation safeguards.
+func (c *Controller) HandleGetLimits(ctx context.Context) (LimitsResponse, error) {
+	out, err := c.Limits.Execute(ctx)
+	if err != nil {
+		return LimitsResponse{}, err
+	}
+	return LimitsResponse(out), nil
+}
+
+// HandleListPackSizeVersions lists the versions of the catalogue.
+func (c *Controller) HandleListPackSizeVersions(ctx context.Context) (PackSizeVersionsResponse, error) {
+	out, err := c.Versions.Execute(ctx)
+	if err != nil {
+		return PackSizeVersionsResponse{}, err
+	}
+	return PackSizeVersionsResponse{Versions: toPackSizeVersionResponses(out.Versions), Current: out.Current}, nil
+}
+
+// HandleListUpcomingPackSizes lists the scheduled versions.
+func (c *Controller) HandleListUpcomingPackSizes(ctx context.Context) (UpcomingPackSizesResponse, error) {
+	out, err := c.Upcoming.Execute(ctx)
+	if err != nil {
+		return UpcomingPackSizesResponse{}, err
+	}
+	return UpcomingPackSizesResponse{Current: out.Current, Upcoming: toPackSizeVersionResponses(out.Upcoming)}, nil
+}
+
+// HandleSchedulePackSizes registers a future version.
+func (c *Controller) HandleSchedulePackSizes(ctx context.Context, req ScheduleRequest) (PackSizeVersionResponse, error) {
+	out, err := c.Schedule.Execute(ctx, uc.SchedulePackSizesInput(req))
+	if err != nil {
+		return PackSizeVersionResponse{}, err
+	}
+	return toPackSizeVersionResponse(out), nil
+}
+
+func toPackSizeVersionResponses(in []uc.PackSizeVersion) []PackSizeVersionResponse {
+	out := make([]PackSizeVersionResponse, 0, len(in))
+	for _, v := range in {
+		out = append(out, toPackSizeVersionResponse(v))
+	}
+	return out
+}
+
+func toPackSizeVersionResponse(v uc.PackSizeVersion) PackSizeVersionResponse {
+	r := PackSizeVersionResponse{Version: v.Version, Sizes: v.Sizes}
+	if !v.EffectiveFrom.IsZero() {
+		from := v.EffectiveFrom
+		r.EffectiveFrom = &from
+	}
+	return r
+}
+
+// HandleListWebhookDeliveries lists the attempts to deliver the change
+// events, most recent first.
+func (c *Controller) HandleListWebhookDeliveries(ctx context.Context, eventID, status string, limit int) (WebhookDeliveriesResponse, error) {
+	out, err := c.Deliveries.Execute(ctx, uc.ListWebhookDeliveriesInput{EventID: eventID, Status: status, Limit: limit})
+	if err != nil {
+		return WebhookDeliveriesResponse{}, err
+	}
+	res := WebhookDeliveriesResponse{Deliveries: make([]WebhookDeliveryResponse, 0, len(out.Deliveries))}
+	for _, d := range out.Deliveries {
+		r := WebhookDeliveryResponse{
+			EventID:    d.EventID,
+			EventType:  d.EventType,
+			Endpoint:   d.Endpoint,
+			Attempt:    d.Attempt,
+			At:         d.At,
+			DurationMs: d.Duration.Milliseconds(),
+			Status:     d.Status,
+			StatusCode: d.StatusCode,
+			Error:      d.Error,
+		}
+		if !d.NextAttemptAt.IsZero() {
+			next := d.NextAttemptAt
+			r.NextAttemptAt = &next
+		}
+		res.Deliveries = append(res.Deliveries, r)
+	}
+	return res, nil
+}
+
+// HandleListAuditEntries lists the audit trail, most recent first; zero
+// arguments match all.
+func (c *Controller) HandleListAuditEntries(ctx context.Context, from, to time.Time, actor, action string, limit int) (AuditEntriesResponse, error) {
+	out, err := c.Audit.Execute(ctx, uc.ListAuditEntriesInput{From: from, To: to, Actor: actor, Action: action, Limit: limit})
+	if err != nil {
+		return AuditEntriesResponse{}, err
+	}
+	res := AuditEntriesResponse{Entries: make([]AuditEntryResponse, 0, len(out.Entries))}
+	for _, e := range out.Entries {
+		res.Entries = append(res.Entries, AuditEntryResponse{
+			At:        e.At,
+			Action:    e.Action,
+			Actor:     e.Actor,
+			SourceIP:  e.SourceIP,
+			RequestID: e.RequestID,
+			Method:    e.Method,
+			Path:      e.Path,
+			Status:    e.Status,
+			Before:    toAuditPackListResponse(e.Before),
+			After:     toAuditPackListResponse(e.After),
+		})
+	}
+	return res, nil
+}
+
+func toAuditPackListResponse(l *uc.AuditPackList) *AuditPackListResponse {
+	if l == nil {
+		return nil
+	}
+	res := &AuditPackListResponse{Version: l.Version, Sizes: l.Sizes}
+	if !l.EffectiveFrom.IsZero() {
+		from := l.EffectiveFrom
+		res.EffectiveFrom = &from
+	}
+	return res
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
 )
@@ -94,6 +95,37 @@ func TestController_HandleCalculate_Success_WithOverride(t *testing.T) {
 	}
 }
 
+func TestController_HandleCalculate_Explain(t *testing.T) {
+	fc := &fakeCalc{
+		out: uc.CalculatePacksOutput{
+			ItemsByPack: map[int]int{500: 1},
+			TotalItems:  500,
+			TotalPacks:  1,
+			Leftover:    249,
+			Explanation: &uc.Explanation{
+				GCD: 250, ScaledQuantity: 2, ScaledUpperBound: 3,
+				RunnersUp: []uc.RunnerUp{{ItemsByPack: map[int]int{250: 2}, TotalItems: 500, TotalPacks: 2, Leftover: 249, RejectedBy: "packs"}},
+			},
+		},
+	}
+	ctrl := NewController(fc, &fakeGet{})
+
+	res, err := ctrl.HandleCalculate(context.Background(), CalculateRequest{Quantity: 251, Explain: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !fc.lastIn.Explain {
+		t.Fatalf("explain flag not forwarded: %+v", fc.lastIn)
+	}
+	want := &ExplanationResponse{
+		GCD: 250, ScaledQuantity: 2, ScaledUpperBound: 3,
+		RunnersUp: []RunnerUpResponse{{ItemsByPack: map[int]int{250: 2}, TotalItems: 500, TotalPacks: 2, Leftover: 249, RejectedBy: "packs"}},
+	}
+	if !reflect.DeepEqual(res.Explanation, want) {
+		t.Fatalf("explanation mismatch:\n got=%+v\nwant=%+v", res.Explanation, want)
+	}
+}
+
 func TestController_HandleCalculate_ErrorIsPropagated(t *testing.T) {
 	wantErr := errors.New("boom")
 	fc := &fakeCalc{err: wantErr}
@@ -109,11 +141,11 @@ func TestController_HandleGetPackSizes_Success(t *testing.T) {
 	fg := &fakeGet{out: uc.GetPackSizesOutput{Sizes: []int{250, 500, 1000}}}
 	ctrl := NewController(&fakeCalc{}, fg)
 
-	res, err := ctrl.HandleGetPackSizes(context.Background())
+	res, err := ctrl.HandleGetPackSizes(context.Background(), PackStatusAll)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	want := PackSizesResponse{Sizes: []int{250, 500, 1000}}
+	want := PackSizesResponse{Sizes: []int{250, 500, 1000}, Packs: []PackResponse{}}
 	if !reflect.DeepEqual(res, want) {
 		t.Fatalf("response mismatch: got=%v want=%v", res, want)
 	}
@@ -124,8 +156,317 @@ func TestController_HandleGetPackSizes_ErrorIsPropagated(t *testing.T) {
 	fg := &fakeGet{err: wantErr}
 	ctrl := NewController(&fakeCalc{}, fg)
 
-	_, err := ctrl.HandleGetPackSizes(context.Background())
+	_, err := ctrl.HandleGetPackSizes(context.Background(), PackStatusAll)
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("expected error to be propagated; got=%v", err)
 	}
 }
+
+func TestController_HandleGetPackSizes_Status(t *testing.T) {
+	stock := 3
+	fg := &fakeGet{out: uc.GetPackSizesOutput{
+		Sizes: []int{500},
+		Packs: []uc.PackSize{
+			{Size: 250, SKU: "BOX-250", Stock: &stock},
+			{Size: 500, Enabled: true, Dimensions: &uc.PackDimensions{Length: 3, Width: 2, Height: 1, Unit: "cm"}},
+		},
+	}}
+	ctrl := NewController(&fakeCalc{}, fg)
+
+	disabled := PackResponse{Size: 250, SKU: "BOX-250", Stock: &stock}
+	enabled := PackResponse{Size: 500, Enabled: true, Dimensions: &DimensionsResponse{Length: 3, Width: 2, Height: 1, Unit: "cm"}}
+	tests := []struct {
+		status PackStatus
+		want   []PackResponse
+	}{
+		{PackStatusAll, []PackResponse{disabled, enabled}},
+		{PackStatusEnabled, []PackResponse{enabled}},
+		{PackStatusDisabled, []PackResponse{disabled}},
+	}
+	for _, tt := range tests {
+		res, err := ctrl.HandleGetPackSizes(context.Background(), tt.status)
+		if err != nil || !reflect.DeepEqual(res.Packs, tt.want) || !reflect.DeepEqual(res.Sizes, []int{500}) {
+			t.Fatalf("%s: got %+v %v, want packs %+v", tt.status, res, err, tt.want)
+		}
+	}
+}
+
+func TestParsePackStatus(t *testing.T) {
+	for in, want := range map[string]PackStatus{"": PackStatusAll, "all": PackStatusAll, "enabled": PackStatusEnabled, "disabled": PackStatusDisabled} {
+		if got, ok := ParsePackStatus(in); !ok || got != want {
+			t.Fatalf("ParsePackStatus(%q) got %q %v", in, got, ok)
+		}
+	}
+	if _, ok := ParsePackStatus("Enabled"); ok {
+		t.Fatalf("expected an unknown status to be rejected")
+	}
+}
+
+type fakeToggle struct {
+	in  uc.SetPackSizeEnabledInput
+	err error
+}
+
+func (f *fakeToggle) Execute(_ context.Context, in uc.SetPackSizeEnabledInput) (uc.PackSize, error) {
+	f.in = in
+	return uc.PackSize{Size: in.Size, Enabled: in.Enabled, Label: "Small"}, f.err
+}
+
+func TestController_HandleSetPackSizeEnabled(t *testing.T) {
+	toggle := &fakeToggle{}
+	ctrl := NewController(&fakeCalc{}, &fakeGet{})
+	ctrl.Toggle = toggle
+
+	res, err := ctrl.HandleSetPackSizeEnabled(context.Background(), 250, false)
+	if err != nil || res != (PackResponse{Size: 250, Label: "Small"}) || toggle.in != (uc.SetPackSizeEnabledInput{Size: 250}) {
+		t.Fatalf("got %+v %v, use case got %+v", res, err, toggle.in)
+	}
+
+	toggle.err = errors.New("boom")
+	if _, err := ctrl.HandleSetPackSizeEnabled(context.Background(), 250, true); !errors.Is(err, toggle.err) {
+		t.Fatalf("expected error to be propagated; got=%v", err)
+	}
+}
+
+type fakeLimits struct {
+	out uc.GetLimitsOutput
+	err error
+}
+
+func (f *fakeLimits) Execute(ctx context.Context) (uc.GetLimitsOutput, error) {
+	return f.out, f.err
+}
+
+func TestController_HandleGetLimits(t *testing.T) {
+	ctrl := NewController(&fakeCalc{}, &fakeGet{})
+	ctrl.Limits = &fakeLimits{out: uc.GetLimitsOutput{MaxQuantity: 10, MaxPackSizes: 2, MaxDPCells: 100}}
+
+	res, err := ctrl.HandleGetLimits(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := LimitsResponse{MaxQuantity: 10, MaxPackSizes: 2, MaxDPCells: 100}
+	if res != want {
+		t.Fatalf("response mismatch: got=%+v want=%+v", res, want)
+	}
+
+	wantErr := errors.New("boom")
+	ctrl.Limits = &fakeLimits{err: wantErr}
+	if _, err := ctrl.HandleGetLimits(context.Background()); !errors.Is(err, wantErr) {
+		t.Fatalf("expected error to be propagated; got=%v", err)
+	}
+}
+
+func TestController_HandleCalculate_AsOf(t *testing.T) {
+	fc := &fakeCalc{out: uc.CalculatePacksOutput{ItemsByPack: map[int]int{250: 1}, TotalItems: 250, TotalPacks: 1, CatalogueVersion: "2024-q1"}}
+	ctrl := NewController(fc, &fakeGet{})
+	asOf := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	res, err := ctrl.HandleCalculate(context.Background(), CalculateRequest{Quantity: 1, AsOf: &asOf})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !fc.lastIn.AsOf.Equal(asOf) {
+		t.Fatalf("asOf not forwarded: %+v", fc.lastIn)
+	}
+	if res.CatalogueVersion != "2024-q1" {
+		t.Fatalf("catalogue version got=%q", res.CatalogueVersion)
+	}
+}
+
+type fakeVersions struct {
+	out uc.ListPackSizeVersionsOutput
+	err error
+}
+
+func (f *fakeVersions) Execute(ctx context.Context) (uc.ListPackSizeVersionsOutput, error) {
+	return f.out, f.err
+}
+
+func TestController_HandleListPackSizeVersions(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	ctrl := NewController(&fakeCalc{}, &fakeGet{})
+	ctrl.Versions = &fakeVersions{out: uc.ListPackSizeVersionsOutput{
+		Versions: []uc.PackSizeVersion{
+			{Version: "v1", Sizes: []int{250}},
+			{Version: "v2", EffectiveFrom: from, Sizes: []int{500}},
+		},
+		Current: "v2",
+	}}
+
+	res, err := ctrl.HandleListPackSizeVersions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := PackSizeVersionsResponse{
+		Versions: []PackSizeVersionResponse{
+			{Version: "v1", Sizes: []int{250}},
+			{Version: "v2", EffectiveFrom: &from, Sizes: []int{500}},
+		},
+		Current: "v2",
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("response mismatch:\n got=%+v\nwant=%+v", res, want)
+	}
+
+	wantErr := errors.New("boom")
+	ctrl.Versions = &fakeVersions{err: wantErr}
+	if _, err := ctrl.HandleListPackSizeVersions(context.Background()); !errors.Is(err, wantErr) {
+		t.Fatalf("expected error to be propagated; got=%v", err)
+	}
+}
+
+type fakeUpcoming struct {
+	out uc.ListUpcomingPackSizesOutput
+	err error
+}
+
+func (f *fakeUpcoming) Execute(ctx context.Context) (uc.ListUpcomingPackSizesOutput, error) {
+	return f.out, f.err
+}
+
+type fakeSchedule struct {
+	lastIn uc.SchedulePackSizesInput
+	err    error
+}
+
+func (f *fakeSchedule) Execute(ctx context.Context, in uc.SchedulePackSizesInput) (uc.PackSizeVersion, error) {
+	f.lastIn = in
+	return uc.PackSizeVersion{Version: in.Version, EffectiveFrom: in.EffectiveFrom, Sizes: in.Sizes}, f.err
+}
+
+func TestController_HandleListUpcomingPackSizes(t *testing.T) {
+	from := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	ctrl := NewController(&fakeCalc{}, &fakeGet{})
+	ctrl.Upcoming = &fakeUpcoming{out: uc.ListUpcomingPackSizesOutput{
+		Current:  "v1",
+		Upcoming: []uc.PackSizeVersion{{Version: "v2", EffectiveFrom: from, Sizes: []int{500}}},
+	}}
+
+	res, err := ctrl.HandleListUpcomingPackSizes(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := UpcomingPackSizesResponse{
+		Current:  "v1",
+		Upcoming: []PackSizeVersionResponse{{Version: "v2", EffectiveFrom: &from, Sizes: []int{500}}},
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("response mismatch:\n got=%+v\nwant=%+v", res, want)
+	}
+}
+
+func TestController_HandleSchedulePackSizes(t *testing.T) {
+	from := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	fs := &fakeSchedule{}
+	ctrl := NewController(&fakeCalc{}, &fakeGet{})
+	ctrl.Schedule = fs
+
+	res, err := ctrl.HandleSchedulePackSizes(context.Background(), ScheduleRequest{Version: "v2", EffectiveFrom: from, Sizes: []int{500}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (uc.SchedulePackSizesInput{Version: "v2", EffectiveFrom: from, Sizes: []int{500}}); !reflect.DeepEqual(fs.lastIn, want) {
+		t.Fatalf("use case received wrong input: %+v", fs.lastIn)
+	}
+	if res.Version != "v2" || res.EffectiveFrom == nil || !res.EffectiveFrom.Equal(from) {
+		t.Fatalf("unexpected response: %+v", res)
+	}
+
+	wantErr := errors.New("boom")
+	ctrl.Schedule = &fakeSchedule{err: wantErr}
+	if _, err := ctrl.HandleSchedulePackSizes(context.Background(), ScheduleRequest{}); !errors.Is(err, wantErr) {
+		t.Fatalf("expected error to be propagated; got=%v", err)
+	}
+}
+
+type fakeDeliveries struct {
+	in  uc.ListWebhookDeliveriesInput
+	out uc.ListWebhookDeliveriesOutput
+	err error
+}
+
+func (f *fakeDeliveries) Execute(_ context.Context, in uc.ListWebhookDeliveriesInput) (uc.ListWebhookDeliveriesOutput, error) {
+	f.in = in
+	return f.out, f.err
+}
+
+func TestController_HandleListWebhookDeliveries(t *testing.T) {
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	deliveries := &fakeDeliveries{out: uc.ListWebhookDeliveriesOutput{Deliveries: []uc.WebhookDelivery{
+		{EventID: "evt-1", Attempt: 2, At: at, Duration: 1500 * time.Millisecond, Status: "delivered", StatusCode: 204},
+		{EventID: "evt-1", Attempt: 1, At: at.Add(-time.Minute), Status: "retrying", Error: "timeout", NextAttemptAt: at},
+	}}}
+	ctrl := NewController(&fakeCalc{}, &fakeGet{})
+	ctrl.Deliveries = deliveries
+
+	res, err := ctrl.HandleListWebhookDeliveries(context.Background(), "evt-1", "", 10)
+	if err != nil {
+		t.Fatalf("HandleListWebhookDeliveries: %v", err)
+	}
+	want := WebhookDeliveriesResponse{Deliveries: []WebhookDeliveryResponse{
+		{EventID: "evt-1", Attempt: 2, At: at, DurationMs: 1500, Status: "delivered", StatusCode: 204},
+		{EventID: "evt-1", Attempt: 1, At: at.Add(-time.Minute), Status: "retrying", Error: "timeout", NextAttemptAt: &at},
+	}}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %+v want %+v", res, want)
+	}
+	if deliveries.in != (uc.ListWebhookDeliveriesInput{EventID: "evt-1", Limit: 10}) {
+		t.Fatalf("use case got %+v", deliveries.in)
+	}
+
+	deliveries.err = errors.New("boom")
+	if _, err := ctrl.HandleListWebhookDeliveries(context.Background(), "", "", 0); !errors.Is(err, deliveries.err) {
+		t.Fatalf("expected error to be propagated; got=%v", err)
+	}
+}
+
+type fakeAudit struct {
+	in  uc.ListAuditEntriesInput
+	out uc.ListAuditEntriesOutput
+	err error
+}
+
+func (f *fakeAudit) Execute(_ context.Context, in uc.ListAuditEntriesInput) (uc.ListAuditEntriesOutput, error) {
+	f.in = in
+	return f.out, f.err
+}
+
+func TestController_HandleListAuditEntries(t *testing.T) {
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	from := at.Add(24 * time.Hour)
+	entries := &fakeAudit{out: uc.ListAuditEntriesOutput{Entries: []uc.AuditEntry{
+		{At: at, Action: "admin.call", Actor: "alice", SourceIP: "10.0.0.7", RequestID: "req-1", Method: "POST", Path: "/v1/admin/packsizes/schedule", Status: 201},
+		{
+			At: at, Action: "packsizes.schedule", Actor: "alice", SourceIP: "10.0.0.7", RequestID: "req-1",
+			Before: &uc.AuditPackList{Version: "v1", Sizes: []int{250, 500}},
+			After:  &uc.AuditPackList{Version: "v2", Sizes: []int{500}, EffectiveFrom: from},
+		},
+	}}}
+	ctrl := NewController(&fakeCalc{}, &fakeGet{})
+	ctrl.Audit = entries
+
+	res, err := ctrl.HandleListAuditEntries(context.Background(), at.Add(-time.Hour), time.Time{}, "alice", "", 10)
+	if err != nil {
+		t.Fatalf("HandleListAuditEntries: %v", err)
+	}
+	want := AuditEntriesResponse{Entries: []AuditEntryResponse{
+		{At: at, Action: "admin.call", Actor: "alice", SourceIP: "10.0.0.7", RequestID: "req-1", Method: "POST", Path: "/v1/admin/packsizes/schedule", Status: 201},
+		{
+			At: at, Action: "packsizes.schedule", Actor: "alice", SourceIP: "10.0.0.7", RequestID: "req-1",
+			Before: &AuditPackListResponse{Version: "v1", Sizes: []int{250, 500}},
+			After:  &AuditPackListResponse{Version: "v2", Sizes: []int{500}, EffectiveFrom: &from},
+		},
+	}}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("got %+v want %+v", res, want)
+	}
+	if entries.in != (uc.ListAuditEntriesInput{From: at.Add(-time.Hour), Actor: "alice", Limit: 10}) {
+		t.Fatalf("use case got %+v", entries.in)
+	}
+
+	entries.err = errors.New("boom")
+	if _, err := ctrl.HandleListAuditEntries(context.Background(), time.Time{}, time.Time{}, "", "", 0); !errors.Is(err, entries.err) {
+		t.Fatalf("expected error to be propagated; got=%v", err)
+	}
+}
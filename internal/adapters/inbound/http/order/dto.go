@@ -1,18 +1,173 @@
 package order
 
+import (
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/presenter"
+)
+
 // Transport DTOs (used only in the HTTP layer; different from use case DTOs).
+// The doc, minimum, enum and format tags feed the generated OpenAPI spec.
 type CalculateRequest struct {
-	Quantity      int   `json:"quantity"`
-	PacksOverride []int `json:"packsOverride,omitempty"`
+	Quantity      int        `json:"quantity" minimum:"1" doc:"Quantidade solicitada"`
+	PacksOverride []int      `json:"packsOverride,omitempty" minimum:"1" doc:"Opcional; substitui a lista de tamanhos vinda do provider"`
+	AsOf          *time.Time `json:"asOf,omitempty" doc:"Opcional; usa a versão do catálogo vigente nesse instante (RFC 3339)"`
+	Explain       bool       `json:"-"` // from the query string (?explain=true)
 }
 
 type CalculateResponse struct {
-	ItemsByPack map[int]int `json:"itemsByPack"`
-	TotalItems  int         `json:"totalItems"`
-	TotalPacks  int         `json:"totalPacks"`
-	Leftover    int         `json:"leftover"`
+	ItemsByPack map[int]int          `json:"itemsByPack" minimum:"0" doc:"Mapa \"tamanho do pack\" -> \"quantidade de pacotes\""`
+	TotalItems  int                  `json:"totalItems" minimum:"1"`
+	TotalPacks  int                  `json:"totalPacks" minimum:"0"`
+	Leftover    int                  `json:"leftover" minimum:"0"`
+	Explanation *ExplanationResponse `json:"explanation,omitempty"` // only with ?explain=true
+
+	CatalogueVersion string `json:"catalogueVersion,omitempty" doc:"Versão do catálogo usada no cálculo (ausente com packsOverride)"`
+}
+
+type ExplanationResponse struct {
+	GCD              int                `json:"gcd" minimum:"1" doc:"GCD usado para reduzir os tamanhos de pacote"`
+	ScaledQuantity   int                `json:"scaledQuantity" minimum:"1" doc:"ceil(quantity / gcd)"`
+	ScaledUpperBound int                `json:"scaledUpperBound" minimum:"1" doc:"Último total (em unidades de gcd) inspecionado pela busca"`
+	RunnersUp        []RunnerUpResponse `json:"runnersUp"`
+}
+
+type RunnerUpResponse struct {
+	ItemsByPack map[int]int `json:"itemsByPack" minimum:"0"`
+	TotalItems  int         `json:"totalItems" minimum:"1"`
+	TotalPacks  int         `json:"totalPacks" minimum:"1"`
+	Leftover    int         `json:"leftover" minimum:"0"`
+	RejectedBy  string      `json:"rejectedBy" enum:"items,packs" doc:"Regra que rejeitou a alternativa (items = mais itens; packs = empate em itens, mais pacotes)"`
 }
 
 type PackSizesResponse struct {
-	Sizes []int `json:"sizes"`
+	Sizes []int          `json:"sizes" minimum:"1" doc:"Tamanhos vigentes e habilitados (usados no cálculo), ordenados asc"`
+	Packs []PackResponse `json:"packs" doc:"Pacotes da versão vigente (filtrados por status), por tamanho asc"`
+
+	// Revision of the list, sent as ETag / Last-Modified (not in the body).
+	Version   string    `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+// PackResponse is one pack of the current version and its metadata.
+type PackResponse struct {
+	Size       int                 `json:"size" minimum:"1"`
+	Enabled    bool                `json:"enabled" doc:"Só os tamanhos habilitados entram no cálculo"`
+	Label      string              `json:"label,omitempty"`
+	SKU        string              `json:"sku,omitempty"`
+	Cost       *float64            `json:"cost,omitempty" minimum:"0"`
+	Stock      *int                `json:"stock,omitempty" minimum:"0"`
+	Dimensions *DimensionsResponse `json:"dimensions,omitempty"`
+}
+
+type DimensionsResponse struct {
+	Length float64 `json:"length"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Unit   string  `json:"unit,omitempty" doc:"Texto livre, ex. cm"`
+}
+
+type PackSizeVersionsResponse struct {
+	Versions []PackSizeVersionResponse `json:"versions" doc:"Versões do catálogo por effectiveFrom asc (inclui as futuras)"`
+	Current  string                    `json:"current" doc:"Versão vigente agora"`
+}
+
+type PackSizeVersionResponse struct {
+	Version       string     `json:"version"`
+	EffectiveFrom *time.Time `json:"effectiveFrom,omitempty" doc:"Início da vigência; ausente quando vigente desde sempre"`
+	Sizes         []int      `json:"sizes" minimum:"1"`
+}
+
+type UpcomingPackSizesResponse struct {
+	Current  string                    `json:"current" doc:"Versão vigente agora"`
+	Upcoming []PackSizeVersionResponse `json:"upcoming" doc:"Versões agendadas (effectiveFrom no futuro), asc"`
+}
+
+// ScheduleRequest is the body of POST /v1/admin/packsizes/schedule; the
+// answer is the scheduled PackSizeVersionResponse.
+type ScheduleRequest struct {
+	Version       string    `json:"version,omitempty" doc:"Opcional; derivada dos tamanhos quando ausente (letras, dígitos e . _ : -)"`
+	EffectiveFrom time.Time `json:"effectiveFrom" doc:"Início da vigência, no futuro (RFC 3339)"`
+	Sizes         []int     `json:"sizes" minimum:"1" doc:"Tamanhos da nova versão"`
+}
+
+// WebhookDeliveriesResponse is the answer of GET /v1/admin/webhooks/deliveries.
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries" doc:"Tentativas de entrega, da mais recente para a mais antiga"`
+}
+
+// WebhookDeliveryResponse is one attempt to deliver an event to a webhook.
+type WebhookDeliveryResponse struct {
+	EventID       string     `json:"eventId" doc:"ID do evento, o mesmo em todas as tentativas (header X-Webhook-Id)"`
+	EventType     string     `json:"eventType" doc:"Tipo do evento, ex. packsizes.changed"`
+	Endpoint      string     `json:"endpoint" doc:"URL do webhook, sem a query string"`
+	Attempt       int        `json:"attempt" minimum:"1"`
+	At            time.Time  `json:"at" doc:"Início da tentativa"`
+	DurationMs    int64      `json:"durationMs" minimum:"0"`
+	Status        string     `json:"status" enum:"delivered,retrying,failed" doc:"delivered = resposta 2xx; retrying = nova tentativa em nextAttemptAt; failed = desistiu (tentativas esgotadas ou resposta 4xx)"`
+	StatusCode    int        `json:"statusCode,omitempty" minimum:"100" doc:"Resposta do webhook; ausente sem resposta (ex. timeout)"`
+	Error         string     `json:"error,omitempty" doc:"Motivo da falha"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty" doc:"Próxima tentativa (só com status retrying)"`
+}
+
+// AuditEntriesResponse is the answer of GET /v1/audit.
+type AuditEntriesResponse struct {
+	Entries []AuditEntryResponse `json:"entries" doc:"Registros, do mais recente para o mais antigo"`
+}
+
+// AuditEntryResponse is one line of the audit trail.
+type AuditEntryResponse struct {
+	At        time.Time              `json:"at"`
+	Action    string                 `json:"action" enum:"packsizes.enable,packsizes.disable,packsizes.schedule,admin.call" doc:"packsizes.* = mudança do catálogo, com a lista de tamanhos antes (before) e depois (after; em packsizes.schedule, a versão agendada); admin.call = chamada a uma rota admin, com method, path e status"`
+	Actor     string                 `json:"actor,omitempty" doc:"Nome do token de admin usado (ADMIN_TOKENS; \"admin\" para o ADMIN_TOKEN)"`
+	SourceIP  string                 `json:"sourceIp,omitempty" doc:"Endereço do cliente"`
+	RequestID string                 `json:"requestId,omitempty" doc:"X-Request-Id da requisição"`
+	Method    string                 `json:"method,omitempty"`
+	Path      string                 `json:"path,omitempty"`
+	Status    int                    `json:"status,omitempty" minimum:"100" doc:"Status da resposta da chamada"`
+	Before    *AuditPackListResponse `json:"before,omitempty"`
+	After     *AuditPackListResponse `json:"after,omitempty"`
+}
+
+// AuditPackListResponse is one state of the pack list.
+type AuditPackListResponse struct {
+	Version       string     `json:"version"`
+	Sizes         []int      `json:"sizes" doc:"Tamanhos habilitados, asc"`
+	EffectiveFrom *time.Time `json:"effectiveFrom,omitempty" doc:"Início da vigência (só em versões agendadas)"`
+}
+
+type LimitsResponse struct {
+	MaxQuantity  int `json:"maxQuantity" minimum:"0" doc:"Maior quantidade aceita"`
+	MaxPackSizes int `json:"maxPackSizes" minimum:"0" doc:"Máximo de tamanhos distintos por cálculo"`
+	MaxDPCells   int `json:"maxDpCells" minimum:"0" doc:"Maior tabela alocada por um cálculo: a DP, ceil(quantity/gcd) + maior/gcd, ou a do solver pré-calculado, (maior-1)*segundo + 2*maior (em unidades do gcd)"`
+}
+
+// StreamOrderLine is one line of the NDJSON body of POST /v1/calculate/stream.
+type StreamOrderLine struct {
+	ID            string `json:"id,omitempty" doc:"Opcional; devolvido na linha de resultado"`
+	Quantity      int    `json:"quantity" minimum:"1" doc:"Quantidade solicitada"`
+	PacksOverride []int  `json:"packsOverride,omitempty" minimum:"1" doc:"Opcional; substitui a lista de tamanhos vinda do provider"`
+}
+
+// StreamLine is one line of the NDJSON response: the result or the error of
+// an input line, or the summary that closes the stream.
+type StreamLine struct {
+	Line    int                  `json:"line,omitempty" minimum:"1" doc:"Número da linha de entrada (1-based)"`
+	ID      string               `json:"id,omitempty"`
+	Result  *CalculateResponse   `json:"result,omitempty"`
+	Error   *presenter.ErrorBody `json:"error,omitempty"`
+	Summary *StreamSummary       `json:"summary,omitempty"`
+}
+
+// StreamSummary closes the stream: it only appears on the last line.
+type StreamSummary struct {
+	Lines     int  `json:"lines" minimum:"0" doc:"Linhas de entrada processadas (linhas em branco não contam)"`
+	Succeeded int  `json:"succeeded" minimum:"0"`
+	Failed    int  `json:"failed" minimum:"0"`
+	Complete  bool `json:"complete" doc:"false quando o stream foi interrompido (cancelamento ou linha inválida longa demais)"`
+}
+
+// CSVUpload is the multipart/form-data body of POST /v1/calculate/csv.
+type CSVUpload struct {
+	File string `json:"file" format:"binary" doc:"Planilha CSV: id, quantity e, opcionalmente, packsOverride"`
 }
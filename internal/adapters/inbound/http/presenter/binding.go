@@ -0,0 +1,80 @@
+package presenter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MapBindError converts a request decoding error to (status, ErrorBody),
+// keeping the position of the problem in Details.
+func MapBindError(err error) (int, ErrorBody) {
+	body := ErrorBody{Code: CodeInvalidRequest, Message: "invalid JSON payload"}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		body.Details = []FieldError{{Offset: syntaxErr.Offset, Reason: syntaxErr.Error()}}
+	case errors.As(err, &typeErr):
+		body.Details = []FieldError{{
+			Field:  jsonPath(typeErr.Field),
+			Offset: typeErr.Offset,
+			Reason: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value),
+		}}
+	case errors.Is(err, io.EOF):
+		body.Details = []FieldError{{Reason: "empty body"}}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		body.Details = []FieldError{{Reason: "unexpected end of JSON input"}}
+	}
+	return http.StatusBadRequest, body
+}
+
+// InvalidParam builds the body for a malformed query parameter.
+func InvalidParam(name, reason string) (int, ErrorBody) {
+	return http.StatusBadRequest, ErrorBody{
+		Code:    CodeInvalidRequest,
+		Message: name + " " + reason,
+		Details: []FieldError{{Field: name, Reason: reason}},
+	}
+}
+
+// jsonPath turns the decoder's dotted field ("packsOverride.1") into the
+// notation used in Details ("packsOverride[1]"); "$" is the whole body.
+func jsonPath(field string) string {
+	if field == "" {
+		return "$"
+	}
+	var b strings.Builder
+	for i, part := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(part); err == nil && i > 0 {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// ContractViolation builds the body for a request rejected by the OpenAPI
+// contract (e.g. quantity below its documented minimum).
+func ContractViolation(fe FieldError) (int, ErrorBody) {
+	return http.StatusBadRequest, ErrorBody{
+		Code:    CodeInvalidRequest,
+		Message: "request does not match the API contract",
+		Details: []FieldError{fe},
+	}
+}
+
+// JSONPointerPath renders JSON pointer tokens (["packsOverride", "2"]) in
+// the notation used in Details ("packsOverride[2]").
+func JSONPointerPath(tokens []string) string {
+	return jsonPath(strings.Join(tokens, "."))
+}
@@ -0,0 +1,63 @@
+package presenter
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+type bindTarget struct {
+	Quantity      int   `json:"quantity"`
+	PacksOverride []int `json:"packsOverride"`
+}
+
+func decode(body string) error {
+	var v bindTarget
+	return json.NewDecoder(bytes.NewBufferString(body)).Decode(&v)
+}
+
+func TestMapBindError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want FieldError
+	}{
+		{"syntax", `{"quantity": 1,}`, FieldError{Offset: 16, Reason: "invalid character '}' looking for beginning of object key string"}},
+		{"type", `{"quantity": "12"}`, FieldError{Field: "quantity", Offset: 17, Reason: "expected int, got string"}},
+		{"array item type", `{"quantity": 1, "packsOverride": [250, "x"]}`, FieldError{Field: "packsOverride[1]", Offset: 42, Reason: "expected int, got string"}},
+		{"empty", ``, FieldError{Reason: "empty body"}},
+		{"truncated", `{`, FieldError{Reason: "unexpected end of JSON input"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := MapBindError(decode(tt.body))
+			if status != http.StatusBadRequest || body.Code != "invalid_request" {
+				t.Fatalf("got (%d, %s)", status, body.Code)
+			}
+			details, ok := body.Details.([]FieldError)
+			if !ok || len(details) != 1 {
+				t.Fatalf("unexpected details: %#v", body.Details)
+			}
+			if details[0] != tt.want {
+				t.Fatalf("details got %+v want %+v", details[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidParam(t *testing.T) {
+	status, body := InvalidParam("explain", "must be a boolean")
+	if status != http.StatusBadRequest || body.Message != "explain must be a boolean" {
+		t.Fatalf("got (%d, %+v)", status, body)
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	for in, want := range map[string]string{"": "$", "quantity": "quantity", "packsOverride.1": "packsOverride[1]", "a.b.0.c": "a.b[0].c"} {
+		if got := jsonPath(in); got != want {
+			t.Errorf("jsonPath(%q) got=%q want=%q", in, got, want)
+		}
+	}
+}
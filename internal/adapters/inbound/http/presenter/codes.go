@@ -0,0 +1,67 @@
+package presenter
+
+import (
+	"sort"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
+	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
+)
+
+// Codes produced by the transport itself.
+const (
+	CodeInvalidRequest  = "invalid_request"  // malformed body or parameter
+	CodeInternalError   = "internal_error"   // untyped error
+	CodeInvalidResponse = "invalid_response" // response outside the contract (validation in dev)
+
+	CodeIdempotencyKeyReused = "idempotency_key_reused" // same Idempotency-Key, different payload
+	CodeIdempotencyKeyInUse  = "idempotency_key_in_use" // same Idempotency-Key, first request still running
+
+	CodeUnauthorized = "unauthorized" // admin route without a valid token
+)
+
+// coreErrors lists the core errors the API can answer with; the generated
+// spec documents their codes.
+var coreErrors = []*apperr.Error{
+	domain.ErrInvalidQuantity,
+	domain.ErrNoPackSizes,
+	domain.ErrInvalidPackSize,
+	domain.ErrNoFeasibleCombination,
+	domain.ErrReconstruction,
+	usecases.ErrInvalidPackInOverride,
+	usecases.ErrExplainUnsupported,
+	usecases.ErrQuantityTooLarge,
+	usecases.ErrTooManyPackSizes,
+	usecases.ErrCalculationTooLarge,
+	usecases.ErrNoCatalogueAt,
+	usecases.ErrInvalidSchedule,
+	usecases.ErrScheduleConflict,
+	usecases.ErrUnknownPackSize,
+	usecases.ErrLastEnabledPackSize,
+	usecases.ErrPackSizesUnavailable,
+	usecases.ErrInvalidAuditRange,
+	usecases.ErrAuditUnavailable,
+}
+
+// ErrorCodes returns every code an ErrorBody may carry, sorted.
+func ErrorCodes() []string {
+	set := map[string]struct{}{
+		CodeInvalidRequest:  {},
+		CodeInternalError:   {},
+		CodeInvalidResponse: {},
+
+		CodeIdempotencyKeyReused: {},
+		CodeIdempotencyKeyInUse:  {},
+
+		CodeUnauthorized: {},
+	}
+	for _, e := range coreErrors {
+		set[e.Code] = struct{}{}
+	}
+	codes := make([]string, 0, len(set))
+	for c := range set {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	return codes
+}
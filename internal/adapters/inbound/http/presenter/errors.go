@@ -1,29 +1,77 @@
 package presenter
 
 import (
-	"errors"
 	"net/http"
 
-	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
 )
 
 type ErrorBody struct {
-	Code    string `json:"code"`
+	Code    string `json:"code" doc:"Código de erro em snake_case"`
 	Message string `json:"message"`
-	Details any    `json:"details,omitempty"`
+	Details any    `json:"details,omitempty" schema:"ErrorDetails"`
 }
 
-// MapError converts use case errors to (status, ErrorBody).
-func MapError(err error) (int, ErrorBody) {
-	switch {
-	case errors.Is(err, usecases.ErrInvalidQuantity):
-		return http.StatusBadRequest, ErrorBody{Code: "invalid_quantity", Message: "quantity must be > 0"}
-	case errors.Is(err, usecases.ErrInvalidPackInOverride):
-		return http.StatusBadRequest, ErrorBody{Code: "invalid_pack", Message: "packsOverride must contain positive integers"}
-	case errors.Is(err, usecases.ErrNoPackSizes):
-		return http.StatusUnprocessableEntity, ErrorBody{Code: "no_pack_sizes", Message: "no pack sizes available"}
+// FieldError points at the part of the request that was rejected.
+// - Field: JSON path ("quantity", "packsOverride[2]") or query parameter
+// - Offset: byte offset in the body, for JSON syntax errors
+type FieldError struct {
+	Field  string `json:"field,omitempty" doc:"Caminho JSON (\"packsOverride[2]\") ou parâmetro de query"`
+	Offset int64  `json:"offset,omitempty" doc:"Posição (bytes) no corpo, para erros de sintaxe JSON"`
+	Reason string `json:"reason"`
+}
+
+// StatusOf maps an error kind to its HTTP status.
+func StatusOf(kind apperr.Kind) int {
+	switch kind {
+	case apperr.KindInvalid:
+		return http.StatusBadRequest
+	case apperr.KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case apperr.KindNotFound:
+		return http.StatusNotFound
+	case apperr.KindConflict:
+		return http.StatusConflict
+	case apperr.KindUnavailable:
+		return http.StatusServiceUnavailable
 	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// MapError converts core errors to (status, ErrorBody) by kind.
+// - Violations become []FieldError details; otherwise Params are the details
+// - internal errors keep their code but never expose message nor params
+// - untyped errors are plain 500s
+func MapError(err error) (int, ErrorBody) {
+	e, ok := apperr.As(err)
+	if !ok {
+		return http.StatusInternalServerError, ErrorBody{Code: CodeInternalError, Message: "unexpected error"}
+	}
 
-		return http.StatusInternalServerError, ErrorBody{Code: "internal_error", Message: "unexpected error"}
+	status := StatusOf(e.Kind)
+	if e.Kind == apperr.KindInternal {
+		return status, ErrorBody{Code: e.Code, Message: "unexpected error"}
 	}
+
+	body := ErrorBody{Code: e.Code, Message: e.Message}
+	switch {
+	case len(e.Violations) > 0:
+		fields := make([]FieldError, 0, len(e.Violations))
+		for _, v := range e.Violations {
+			fields = append(fields, FieldError{Field: v.Field, Reason: v.Reason})
+		}
+		body.Details = fields
+	case len(e.Params) > 0:
+		body.Details = e.Params
+	}
+	return status, body
+}
+
+// LimitDetails is the shape of Details for limit errors (their Params);
+// it only exists to document it.
+type LimitDetails struct {
+	Limit  string `json:"limit" enum:"maxQuantity,maxPackSizes,maxDpCells"`
+	Max    int    `json:"max"`
+	Actual int    `json:"actual"`
 }
@@ -0,0 +1,68 @@
+package presenter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
+	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
+)
+
+func TestMapError(t *testing.T) {
+	limit := func(err *apperr.Error, name string, maximum, actual int) error {
+		return err.With(map[string]any{"limit": name, "max": maximum, "actual": actual})
+	}
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    string
+		wantDetails any
+	}{
+		{"invalid quantity", usecases.ErrInvalidQuantity.WithViolation("quantity", "must be > 0"), http.StatusBadRequest, "invalid_quantity", []FieldError{{Field: "quantity", Reason: "must be > 0"}}},
+		{"invalid pack", usecases.ErrInvalidPackInOverride, http.StatusBadRequest, "invalid_pack", nil},
+		{"invalid pack entry", usecases.ErrInvalidPackInOverride.WithViolation("packsOverride[1]", "must be > 0, got -3"), http.StatusBadRequest, "invalid_pack", []FieldError{{Field: "packsOverride[1]", Reason: "must be > 0, got -3"}}},
+		{"no pack sizes", fmt.Errorf("wrapped: %w", usecases.ErrNoPackSizes), http.StatusUnprocessableEntity, "no_pack_sizes", nil},
+		{"quantity too large", limit(usecases.ErrQuantityTooLarge, "maxQuantity", 10, 11), http.StatusUnprocessableEntity, "quantity_too_large", map[string]any{"limit": "maxQuantity", "max": 10, "actual": 11}},
+		{"too many sizes", limit(usecases.ErrTooManyPackSizes, "maxPackSizes", 2, 3), http.StatusUnprocessableEntity, "too_many_pack_sizes", map[string]any{"limit": "maxPackSizes", "max": 2, "actual": 3}},
+		{"calculation too large", limit(usecases.ErrCalculationTooLarge, "maxDpCells", 5, 6), http.StatusUnprocessableEntity, "calculation_too_large", map[string]any{"limit": "maxDpCells", "max": 5, "actual": 6}},
+		{"no feasible combination", domain.ErrNoFeasibleCombination, http.StatusUnprocessableEntity, "no_feasible_combination", nil},
+		{"domain invalid pack", domain.ErrInvalidPackSize.With(map[string]any{"size": -1}), http.StatusBadRequest, "invalid_pack", map[string]any{"size": -1}},
+		{"internal keeps its code only", domain.ErrReconstruction.With(map[string]any{"t": 3}), http.StatusInternalServerError, "internal_reconstruction_error", nil},
+		{"unavailable", apperr.New(apperr.KindUnavailable, "provider_down", "down"), http.StatusServiceUnavailable, "provider_down", nil},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, "internal_error", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := MapError(tt.err)
+			if status != tt.wantStatus || body.Code != tt.wantCode {
+				t.Fatalf("got (%d, %s) want (%d, %s)", status, body.Code, tt.wantStatus, tt.wantCode)
+			}
+			if !reflect.DeepEqual(body.Details, tt.wantDetails) {
+				t.Fatalf("details got %#v want %#v", body.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestErrorCodes_CoverMappedErrors(t *testing.T) {
+	known := make(map[string]bool)
+	for _, c := range ErrorCodes() {
+		known[c] = true
+	}
+	for _, err := range append(coreErrors, nil) {
+		var e error = err
+		if err == nil {
+			e = errors.New("untyped")
+		}
+		if _, body := MapError(e); !known[body.Code] {
+			t.Fatalf("MapError(%v) answers %q, missing from ErrorCodes", e, body.Code)
+		}
+	}
+}
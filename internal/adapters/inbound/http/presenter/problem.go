@@ -0,0 +1,57 @@
+package presenter
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ProblemContentType is the RFC 7807 media type.
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBase prefixes the error code to build the problem "type" URI.
+const problemTypeBase = "urn:shipping-packs:problem:"
+
+// Problem is an RFC 7807 document. Code and Details are extension members
+// carrying the same values as ErrorBody, so both shapes stay in sync.
+type Problem struct {
+	Type     string `json:"type" doc:"urn:shipping-packs:problem:<code>"`
+	Title    string `json:"title" doc:"Texto do status HTTP"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty" doc:"URI da requisição"`
+	Code     string `json:"code"`
+	Details  any    `json:"details,omitempty" schema:"ErrorDetails"`
+}
+
+// ToProblem converts a legacy ErrorBody; instance is the request URI.
+func ToProblem(status int, body ErrorBody, instance string) Problem {
+	return Problem{
+		Type:     problemTypeBase + body.Code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   body.Message,
+		Instance: instance,
+		Code:     body.Code,
+		Details:  body.Details,
+	}
+}
+
+// WantsProblem tells whether the Accept header asks for problem+json.
+// Clients that do not mention it keep receiving ErrorBody.
+func WantsProblem(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mediaType != ProblemContentType {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if v, err := strconv.ParseFloat(q, 64); err != nil || v <= 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package presenter
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestWantsProblem(t *testing.T) {
+	tests := map[string]bool{
+		"":                         false,
+		"application/json":         false,
+		"*/*":                      false,
+		"application/problem+json": true,
+		"application/json, application/problem+json;q=0.9":   true,
+		"application/problem+json;q=0":                       false,
+		"text/html, application/problem+json; charset=utf-8": true,
+	}
+	for accept, want := range tests {
+		if got := WantsProblem(accept); got != want {
+			t.Errorf("WantsProblem(%q) got=%v want=%v", accept, got, want)
+		}
+	}
+}
+
+func TestToProblem(t *testing.T) {
+	details := []FieldError{{Field: "quantity", Reason: "must be > 0"}}
+	p := ToProblem(http.StatusBadRequest, ErrorBody{Code: "invalid_quantity", Message: "quantity must be > 0", Details: details}, "/v1/calculate")
+
+	if p.Type != "urn:shipping-packs:problem:invalid_quantity" || p.Title != "Bad Request" || p.Status != 400 {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+	if p.Detail != "quantity must be > 0" || p.Instance != "/v1/calculate" || p.Code != "invalid_quantity" {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+	if got, ok := p.Details.([]FieldError); !ok || got[0] != details[0] {
+		t.Fatalf("details not carried over: %#v", p.Details)
+	}
+}
@@ -0,0 +1,257 @@
+// Package jsonl keeps the audit trail in a JSON-lines file: one entry per
+// line, only ever appended to.
+package jsonl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/audit"
+)
+
+// maxLine bounds a line read back; longer ones are skipped.
+const maxLine = 1 << 20
+
+// Options configures New.
+// - Now: clock of the entries without At (nil: time.Now)
+type Options struct {
+	Now func() time.Time
+}
+
+// Log appends the entries to a file opened in append mode and synced after
+// each line, so an entry acknowledged is on disk. Entries reads the whole
+// file: the trail of administrative changes stays small, and rotating it is
+// left to the operator (e.g. logrotate with copytruncate).
+type Log struct {
+	path string
+	opts Options
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// compile-time check
+var _ audit.Log = (*Log)(nil)
+
+// New opens (or creates) the file at path, and its directory.
+func New(path string, opts Options) (*Log, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, errors.New("audit: empty path")
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+	if err := endLine(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Log{path: path, opts: opts, f: f}, nil
+}
+
+// endLine ends a line cut by a crash, so the next entry starts its own.
+func endLine(f *os.File) error {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	if last[0] != '\n' {
+		if _, err := f.Write([]byte{'\n'}); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+func (l *Log) Append(_ context.Context, e audit.Entry) error {
+	if e.At.IsZero() {
+		e.At = l.opts.Now()
+	}
+	line, err := json.Marshal(newLine(e))
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	if err := l.f.Sync(); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	return nil
+}
+
+// Entries reads the file; lines that cannot be decoded are skipped.
+func (l *Log) Entries(ctx context.Context, f audit.Filter) ([]audit.Entry, error) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+	defer file.Close()
+
+	var out []audit.Entry
+	r := bufio.NewReaderSize(file, 64<<10)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		raw, err := readLine(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("audit: %w", err)
+		}
+		var ln line
+		if raw == nil || json.Unmarshal(raw, &ln) != nil {
+			continue
+		}
+		if e := ln.entry(); matches(e, f) {
+			out = append(out, e)
+		}
+	}
+
+	// appended in time order (a clock step back aside)
+	slices.Reverse(out)
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	if out == nil {
+		out = []audit.Entry{}
+	}
+	return out, nil
+}
+
+// readLine returns the next line, nil when it is longer than maxLine.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	var buf []byte
+	long := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if !long {
+			if len(buf)+len(chunk) > maxLine {
+				long, buf = true, nil
+			} else {
+				buf = append(buf, chunk...)
+			}
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil && (!errors.Is(err, io.EOF) || (len(buf) == 0 && !long)) {
+			return nil, err
+		}
+		// the last line may lack its newline
+		if long {
+			return nil, nil
+		}
+		return buf, nil
+	}
+}
+
+func matches(e audit.Entry, f audit.Filter) bool {
+	switch {
+	case !f.From.IsZero() && e.At.Before(f.From):
+		return false
+	case !f.To.IsZero() && !e.At.Before(f.To):
+		return false
+	case f.Actor != "" && e.Actor != f.Actor:
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	}
+	return true
+}
+
+// line is the JSON of an entry.
+type line struct {
+	At        time.Time `json:"at"`
+	Action    string    `json:"action"`
+	Actor     string    `json:"actor,omitempty"`
+	SourceIP  string    `json:"sourceIp,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Status    int       `json:"status,omitempty"`
+	Before    *packList `json:"before,omitempty"`
+	After     *packList `json:"after,omitempty"`
+}
+
+type packList struct {
+	Version       string    `json:"version"`
+	Sizes         []int     `json:"sizes"`
+	EffectiveFrom time.Time `json:"effectiveFrom,omitzero"`
+}
+
+func newLine(e audit.Entry) line {
+	return line{
+		At:        e.At.UTC(),
+		Action:    e.Action,
+		Actor:     e.Actor,
+		SourceIP:  e.SourceIP,
+		RequestID: e.RequestID,
+		Method:    e.Method,
+		Path:      e.Path,
+		Status:    e.Status,
+		Before:    toLineList(e.Before),
+		After:     toLineList(e.After),
+	}
+}
+
+func (ln line) entry() audit.Entry {
+	return audit.Entry{
+		At:        ln.At,
+		Action:    ln.Action,
+		Actor:     ln.Actor,
+		SourceIP:  ln.SourceIP,
+		RequestID: ln.RequestID,
+		Method:    ln.Method,
+		Path:      ln.Path,
+		Status:    ln.Status,
+		Before:    toEntryList(ln.Before),
+		After:     toEntryList(ln.After),
+	}
+}
+
+func toLineList(l *audit.PackList) *packList {
+	if l == nil {
+		return nil
+	}
+	return &packList{Version: l.Version, Sizes: l.Sizes, EffectiveFrom: l.EffectiveFrom.UTC()}
+}
+
+func toEntryList(l *packList) *audit.PackList {
+	if l == nil {
+		return nil
+	}
+	return &audit.PackList{Version: l.Version, Sizes: l.Sizes, EffectiveFrom: l.EffectiveFrom}
+}
@@ -0,0 +1,113 @@
+package jsonl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/audit"
+)
+
+var t0 = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+func actions(entries []audit.Entry) []string {
+	out := []string{}
+	for _, e := range entries {
+		out = append(out, e.Action+"@"+e.At.Format("15:04"))
+	}
+	return out
+}
+
+func TestLog_AppendAndEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	l, err := New(path, Options{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+
+	change := audit.Entry{
+		At: t0, Action: audit.ActionPackSizesScheduled, Actor: "alice", SourceIP: "10.0.0.7", RequestID: "req-1",
+		Before: &audit.PackList{Version: "2025-06", Sizes: []int{250, 500}},
+		After:  &audit.PackList{Version: "2025-11", Sizes: []int{500}, EffectiveFrom: t0.Add(24 * time.Hour)},
+	}
+	entries := []audit.Entry{
+		change,
+		{At: t0.Add(time.Minute), Action: audit.ActionAdminCall, Actor: "alice", Method: "POST", Path: "/v1/admin/packsizes/schedule", Status: 201},
+		{At: t0.Add(time.Hour), Action: audit.ActionAdminCall, Actor: "bob", Method: "GET", Path: "/v1/audit", Status: 200},
+	}
+	for _, e := range entries {
+		if err := l.Append(ctx, e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter audit.Filter
+		want   []string
+	}{
+		{"all, most recent first", audit.Filter{}, []string{"admin.call@11:00", "admin.call@10:01", "packsizes.schedule@10:00"}},
+		{"from is inclusive, to exclusive", audit.Filter{From: t0.Add(time.Minute), To: t0.Add(time.Hour)}, []string{"admin.call@10:01"}},
+		{"actor", audit.Filter{Actor: "alice"}, []string{"admin.call@10:01", "packsizes.schedule@10:00"}},
+		{"action", audit.Filter{Action: audit.ActionAdminCall, Limit: 1}, []string{"admin.call@11:00"}},
+		{"none", audit.Filter{From: t0.Add(2 * time.Hour)}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.Entries(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("Entries: %v", err)
+			}
+			if !reflect.DeepEqual(actions(got), tt.want) {
+				t.Fatalf("got %v want %v", actions(got), tt.want)
+			}
+		})
+	}
+
+	got, _ := l.Entries(ctx, audit.Filter{Action: audit.ActionPackSizesScheduled})
+	if len(got) != 1 || !reflect.DeepEqual(got[0], change) {
+		t.Fatalf("round trip got %+v want %+v", got, change)
+	}
+}
+
+func TestLog_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	ctx := context.Background()
+	l, _ := New(path, Options{Now: func() time.Time { return t0 }})
+	_ = l.Append(ctx, audit.Entry{Action: audit.ActionAdminCall})
+	_ = l.Close()
+
+	// a line cut by a crash, then a restart
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	_, _ = f.WriteString(`{"at":"2025-09-01T10:05:00Z","act`)
+	_ = f.Close()
+	l, err := New(path, Options{Now: func() time.Time { return t0.Add(time.Hour) }})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer l.Close()
+	_ = l.Append(ctx, audit.Entry{Action: audit.ActionPackSizeDisabled})
+
+	got, err := l.Entries(ctx, audit.Filter{})
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if want := []string{"packsizes.disable@11:00", "admin.call@10:00"}; !reflect.DeepEqual(actions(got), want) {
+		t.Fatalf("got %v want %v", actions(got), want)
+	}
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Fatalf("file has %d lines, want 3 (the cut one kept):\n%s", lines, data)
+	}
+}
+
+func TestNew_EmptyPath(t *testing.T) {
+	if _, err := New(" ", Options{}); err == nil {
+		t.Fatalf("expected an error for an empty path")
+	}
+}
@@ -0,0 +1,84 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// ErrOpening is returned by a Lazy source while another call opens it.
+var ErrOpening = errors.New("pack sizes source is opening")
+
+// lazy opens its provider on first use.
+type lazy struct {
+	open func() (packsizes.Provider, error)
+
+	mu      sync.Mutex
+	prov    packsizes.Provider // nil until open succeeds
+	opening *attempt           // nil unless open runs
+}
+
+// attempt is one run of open; done is closed once it returns.
+type attempt struct {
+	done chan struct{}
+	prov packsizes.Provider
+	err  error
+}
+
+// compile-time check
+var _ packsizes.Provider = (*lazy)(nil)
+
+// Lazy defers open to the first call and retries it on every call until it
+// succeeds, so a source that cannot start (e.g. a missing or corrupt pack
+// sizes file) fails in the chain instead of at startup, and joins it once
+// fixed. open runs outside the lock: the call that starts it waits for it
+// until its ctx is done, the others get ErrOpening meanwhile, so a stuck
+// open (e.g. a database that does not answer) holds up no request.
+func Lazy(open func() (packsizes.Provider, error)) packsizes.Provider {
+	return &lazy{open: open}
+}
+
+func (l *lazy) provider(ctx context.Context) (packsizes.Provider, error) {
+	l.mu.Lock()
+	if l.prov != nil {
+		defer l.mu.Unlock()
+		return l.prov, nil
+	}
+	if l.opening != nil {
+		l.mu.Unlock()
+		return nil, ErrOpening
+	}
+	a := &attempt{done: make(chan struct{})}
+	l.opening = a
+	l.mu.Unlock()
+
+	go l.run(a)
+	select {
+	case <-a.done:
+		return a.prov, a.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run opens the provider and keeps it when open succeeds.
+func (l *lazy) run(a *attempt) {
+	a.prov, a.err = l.open()
+	l.mu.Lock()
+	if a.err == nil {
+		l.prov = a.prov
+	}
+	l.opening = nil
+	l.mu.Unlock()
+	close(a.done)
+}
+
+func (l *lazy) Load(ctx context.Context) (packsizes.Catalogue, error) {
+	prov, err := l.provider(ctx)
+	if err != nil {
+		return packsizes.Catalogue{}, err
+	}
+	return prov.Load(ctx)
+}
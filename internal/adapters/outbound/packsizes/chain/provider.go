@@ -0,0 +1,286 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+var (
+	ErrNoSource  = errors.New("pack sizes chain without sources")
+	ErrEmptyList = errors.New("empty pack sizes list")
+	ErrBackoff   = errors.New("pack sizes source backing off")
+)
+
+// Source is one provider of the chain and the name it is reported with
+// (e.g. "file", "env", "defaults").
+type Source struct {
+	Name     string
+	Provider packsizes.Provider
+}
+
+// Options configures New.
+// - Now: clock of LoadedAt and the staleness (nil: time.Now)
+// - MaxStale: how long the last good list is served once every source
+// fails (0: no limit)
+// - Backoff: how long a failing source is skipped, doubled on each failure
+// in a row (0: asked on every call)
+// - MaxBackoff: the longest skip (0: a minute)
+// - OnChange: called when the serving source or the degraded mode changes
+// (optional; not under the chain lock, so it may call Status)
+type Options struct {
+	Now        func() time.Time
+	MaxStale   time.Duration
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	OnChange   func(Status)
+}
+
+// Status tells how the last call was served.
+// - Source: the source of the list, "" when there was none to serve
+// - Degraded: a fallback source or the cache served it
+// - Cached: every source failed; the last good list was served
+// - LoadedAt: when Source last returned the list (zero without one)
+// - Errors: why the sources tried before it failed, by name
+type Status struct {
+	Source   string
+	Degraded bool
+	Cached   bool
+	LoadedAt time.Time
+	Errors   map[string]error
+}
+
+// Available tells whether a list was served.
+func (s Status) Available() bool { return s.Source != "" }
+
+// Age is how old the served list is at now: 0 unless it came from the
+// cache.
+func (s Status) Age(now time.Time) time.Duration {
+	if !s.Cached {
+		return 0
+	}
+	return now.Sub(s.LoadedAt)
+}
+
+// String describes s for logs, e.g. `"env" (degraded; file: ...)`.
+func (s Status) String() string {
+	var b strings.Builder
+	if s.Source == "" {
+		b.WriteString("no source")
+	} else {
+		fmt.Fprintf(&b, "%q", s.Source)
+	}
+	switch {
+	case s.Cached:
+		fmt.Fprintf(&b, " (cached since %s", s.LoadedAt.Format(time.RFC3339))
+	case s.Degraded:
+		b.WriteString(" (degraded")
+	default:
+		return b.String()
+	}
+	names := make([]string, 0, len(s.Errors))
+	for name := range s.Errors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "; %s: %v", name, s.Errors[name])
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// Provider tries its sources in order and serves the first list it gets,
+// keeping it as the last good one for when every source fails.
+type Provider struct {
+	sources    []Source
+	now        func() time.Time
+	maxStale   time.Duration
+	backoff    time.Duration
+	maxBackoff time.Duration
+	onChange   func(Status)
+
+	mu       sync.Mutex
+	last     *packsizes.Catalogue // nil until a source answers
+	status   Status
+	failures []failure // by source
+}
+
+// failure is the backoff of a source: skipped until retryAt, after failing
+// count times in a row, the last with err.
+type failure struct {
+	count   int
+	retryAt time.Time
+	err     error
+}
+
+// compile-time check
+var _ packsizes.Provider = (*Provider)(nil)
+
+// New creates a Provider over sources, in fallback order (e.g. remote,
+// file, env, built-in defaults). A source fails when it returns an error or
+// an empty list; it is tried again on the first call after its backoff, so
+// the chain leaves the degraded mode soon after the first one is back
+// without every request waiting on a source that is down.
+// It serves the current list only: the History, Scheduler and Toggler of
+// the sources are not exposed.
+func New(sources []Source, opts Options) (*Provider, error) {
+	if len(sources) == 0 {
+		return nil, ErrNoSource
+	}
+	seen := make(map[string]bool, len(sources))
+	for i, s := range sources {
+		if s.Provider == nil {
+			return nil, fmt.Errorf("source %d (%q): nil packsizes.Provider", i, s.Name)
+		}
+		if s.Name == "" || seen[s.Name] {
+			return nil, fmt.Errorf("source %d: name %q is empty or repeated", i, s.Name)
+		}
+		seen[s.Name] = true
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Minute
+	}
+	return &Provider{
+		sources:    slices.Clone(sources),
+		now:        opts.Now,
+		maxStale:   opts.MaxStale,
+		backoff:    opts.Backoff,
+		maxBackoff: opts.MaxBackoff,
+		onChange:   opts.OnChange,
+		failures:   make([]failure, len(sources)),
+	}, nil
+}
+
+// Load returns the catalogue of the serving source, named and stamped by
+// the chain: Source is the name of the source, LoadedAt when it answered,
+// Degraded unless it is the first one and fresh, and its version is the
+// list hash when the source does not tell, so a fallback changes the ETag of
+// the list.
+func (p *Provider) Load(ctx context.Context) (packsizes.Catalogue, error) {
+	return p.load(ctx)
+}
+
+// Status reports the last call (zero before the first one).
+func (p *Provider) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+// Check runs the chain once, e.g. for a readiness probe, and reports it.
+func (p *Provider) Check(ctx context.Context) Status {
+	_, _ = p.load(ctx)
+	return p.Status()
+}
+
+// load asks the sources in order, skipping those in backoff; when they all
+// fail it falls back to the last good list, unless it is older than
+// MaxStale. A done ctx stops the chain: the remaining sources would fail
+// the same way.
+func (p *Provider) load(ctx context.Context) (packsizes.Catalogue, error) {
+	errs := make(map[string]error)
+	var joined []error
+	for i, src := range p.sources {
+		if err := ctx.Err(); err != nil {
+			return packsizes.Catalogue{}, err
+		}
+		err := p.waiting(i)
+		var catalogue packsizes.Catalogue
+		if err == nil {
+			if catalogue, err = read(ctx, src.Provider); err != nil && ctx.Err() == nil {
+				p.failed(i, err)
+			}
+		}
+		if err != nil {
+			errs[src.Name] = err
+			joined = append(joined, fmt.Errorf("%s: %w", src.Name, err))
+			continue
+		}
+		catalogue.Source, catalogue.LoadedAt, catalogue.Degraded = src.Name, p.now(), i > 0
+		p.mu.Lock()
+		p.failures[i] = failure{}
+		p.last = &catalogue
+		p.setStatus(Status{Source: src.Name, Degraded: i > 0, LoadedAt: catalogue.LoadedAt, Errors: errs})
+		return catalogue.Clone(), nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		// the caller gave up: not a failure of the sources
+		return packsizes.Catalogue{}, err
+	}
+	p.mu.Lock()
+	last := p.last
+	if last == nil || (p.maxStale > 0 && p.now().Sub(last.LoadedAt) > p.maxStale) {
+		p.setStatus(Status{Degraded: true, Errors: errs})
+		return packsizes.Catalogue{}, fmt.Errorf("%w: %w", packsizes.ErrUnavailable, errors.Join(joined...))
+	}
+	p.setStatus(Status{Source: last.Source, Degraded: true, Cached: true, LoadedAt: last.LoadedAt, Errors: errs})
+	cached := last.Clone()
+	cached.Degraded = true
+	return cached, nil
+}
+
+// waiting returns ErrBackoff, with the last error, while source i is in
+// backoff.
+func (p *Provider) waiting(i int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f := p.failures[i]
+	if f.err == nil || !p.now().Before(f.retryAt) {
+		return nil
+	}
+	return fmt.Errorf("%w until %s: %w", ErrBackoff, f.retryAt.Format(time.RFC3339), f.err)
+}
+
+// failed puts source i in backoff: Backoff, doubled on each failure in a
+// row up to MaxBackoff. A source that is still opening (see Lazy) did not
+// fail.
+func (p *Provider) failed(i int, err error) {
+	if p.backoff <= 0 || errors.Is(err, ErrOpening) {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f := &p.failures[i]
+	f.count++
+	wait := p.backoff
+	for n := 1; n < f.count && wait < p.maxBackoff; n++ {
+		wait *= 2
+	}
+	f.retryAt, f.err = p.now().Add(min(wait, p.maxBackoff)), err
+}
+
+// setStatus records s and releases the lock held by the caller, then calls
+// OnChange when the source or the mode changed.
+func (p *Provider) setStatus(s Status) {
+	prev := p.status
+	p.status = s
+	p.mu.Unlock()
+	if p.onChange != nil && (prev.Source != s.Source || prev.Degraded != s.Degraded || prev.Cached != s.Cached) {
+		p.onChange(s)
+	}
+}
+
+// read asks one source; an empty list is a failure.
+func read(ctx context.Context, prov packsizes.Provider) (packsizes.Catalogue, error) {
+	catalogue, err := prov.Load(ctx)
+	if err != nil {
+		return packsizes.Catalogue{}, err
+	}
+	if len(catalogue.Sizes) == 0 {
+		return packsizes.Catalogue{}, ErrEmptyList
+	}
+	catalogue = catalogue.Clone()
+	catalogue.Version = catalogue.Revision()
+	return catalogue, nil
+}
@@ -0,0 +1,278 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// fakeSource answers sizes and version, or err when set.
+type fakeSource struct {
+	sizes   []int
+	version packsizes.Version
+	err     error
+	calls   int
+}
+
+func (f *fakeSource) Load(context.Context) (packsizes.Catalogue, error) {
+	f.calls++
+	if f.err != nil {
+		return packsizes.Catalogue{}, f.err
+	}
+	return packsizes.Catalogue{Sizes: f.sizes, Version: f.version, Source: "fake"}, nil
+}
+
+// load returns the catalogue of prov, or nil sizes on error.
+func load(prov packsizes.Provider) ([]int, packsizes.Catalogue, error) {
+	c, err := prov.Load(context.Background())
+	return c.Sizes, c, err
+}
+
+func TestNew_Errors(t *testing.T) {
+	src := &fakeSource{sizes: []int{250}}
+	tests := []struct {
+		name    string
+		sources []Source
+	}{
+		{"no sources", nil},
+		{"nil provider", []Source{{Name: "file"}}},
+		{"no name", []Source{{Provider: src}}},
+		{"repeated name", []Source{{Name: "a", Provider: src}, {Name: "a", Provider: src}}},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.sources, Options{}); err == nil {
+			t.Fatalf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestProvider_Fallback(t *testing.T) {
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	primary := &fakeSource{sizes: []int{250, 500}, version: packsizes.Version{ID: "v1"}}
+	fallback := &fakeSource{sizes: []int{1000}}
+	var changes []Status
+	prov, err := New([]Source{{"file", primary}, {"defaults", fallback}}, Options{
+		Now:      func() time.Time { return now },
+		OnChange: func(s Status) { changes = append(changes, s) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// healthy: the first source serves, its version is kept, named and
+	// stamped by the chain
+	got, c, _ := load(prov)
+	if !reflect.DeepEqual(got, []int{250, 500}) || c.Version.ID != "v1" || c.Source != "file" || !c.LoadedAt.Equal(now) {
+		t.Fatalf("Load got %+v", c)
+	}
+	if s := prov.Status(); s.Source != "file" || s.Degraded || s.Cached || !s.LoadedAt.Equal(now) {
+		t.Fatalf("Status got %+v", s)
+	}
+	if fallback.calls != 0 {
+		t.Fatalf("the fallback was asked %d times", fallback.calls)
+	}
+
+	// the first source fails: the next one serves, degraded
+	primary.err = errors.New("permission denied")
+	got, c, _ = load(prov)
+	if !reflect.DeepEqual(got, []int{1000}) || c.Source != "defaults" {
+		t.Fatalf("Load got %+v", c)
+	}
+	if c.Version.ID != packsizes.VersionOf([]int{1000}) || !c.Degraded {
+		t.Fatalf("Version of a source without one got %+v", c.Version)
+	}
+	s := prov.Status()
+	if s.Source != "defaults" || !s.Degraded || s.Cached || s.Errors["file"] == nil {
+		t.Fatalf("Status got %+v", s)
+	}
+
+	// back to the first one as soon as it answers
+	primary.err = nil
+	if got, _, _ := load(prov); !reflect.DeepEqual(got, []int{250, 500}) || prov.Status().Degraded {
+		t.Fatalf("List got %v, status %+v", got, prov.Status())
+	}
+
+	// one call per change, not per request
+	if len(changes) != 3 || changes[0].Source != "file" || changes[1].Source != "defaults" || changes[2].Source != "file" {
+		t.Fatalf("OnChange got %+v", changes)
+	}
+}
+
+func TestProvider_LastKnownGood(t *testing.T) {
+	loaded := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	now := loaded
+	file := &fakeSource{sizes: []int{250, 500}}
+	env := &fakeSource{err: errors.New("PACK_SIZES not set")}
+	prov, err := New([]Source{{"file", file}, {"env", env}}, Options{
+		Now:      func() time.Time { return now },
+		MaxStale: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// nothing served yet: unavailable
+	file.err = errors.New("corrupt")
+	if _, _, err := load(prov); !errors.Is(err, packsizes.ErrUnavailable) || !errors.Is(err, file.err) || !errors.Is(err, env.err) {
+		t.Fatalf("got %v, want ErrUnavailable with the cause of each source", err)
+	}
+	if s := prov.Status(); s.Available() || !s.Degraded {
+		t.Fatalf("Status got %+v", s)
+	}
+
+	file.err = nil
+	if _, _, err := load(prov); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// every source fails: the last good list, with its age
+	file.err, file.sizes = errors.New("connection refused"), nil
+	now = loaded.Add(30 * time.Minute)
+	got, c, err := load(prov)
+	if err != nil || !reflect.DeepEqual(got, []int{250, 500}) || c.Source != "file" || !c.LoadedAt.Equal(loaded) || !c.Degraded {
+		t.Fatalf("Load got %+v %v", c, err)
+	}
+	s := prov.Status()
+	if s.Source != "file" || !s.Cached || !s.Degraded || s.Age(now) != 30*time.Minute || len(s.Errors) != 2 {
+		t.Fatalf("Status got %+v", s)
+	}
+	if packs := c.AllPacks(); !reflect.DeepEqual(packs, packsizes.PlainPacks([]int{250, 500})) {
+		t.Fatalf("Packs got %+v", packs)
+	}
+
+	// too old to be served
+	now = loaded.Add(time.Hour + time.Second)
+	if _, _, err := load(prov); !errors.Is(err, packsizes.ErrUnavailable) {
+		t.Fatalf("got %v, want ErrUnavailable past MaxStale", err)
+	}
+}
+
+func TestProvider_Backoff(t *testing.T) {
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	file := &fakeSource{err: errors.New("connection refused")}
+	defaults := &fakeSource{sizes: []int{250}}
+	prov, err := New([]Source{{"sql", file}, {"defaults", defaults}}, Options{
+		Now:        func() time.Time { return now },
+		Backoff:    time.Second,
+		MaxBackoff: 3 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// skipped for 1s, 2s, then 3s (the max) after each failure in a row
+	for _, wait := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		calls := file.calls
+		if got, _, _ := load(prov); !reflect.DeepEqual(got, []int{250}) || file.calls != calls+1 {
+			t.Fatalf("Load got %v, calls %d", got, file.calls)
+		}
+		now = now.Add(wait - time.Millisecond)
+		if _, _, _ = load(prov); file.calls != calls+1 {
+			t.Fatalf("asked during its backoff of %s", wait)
+		}
+		if err := prov.Status().Errors["sql"]; !errors.Is(err, ErrBackoff) || !errors.Is(err, file.err) {
+			t.Fatalf("error got %v", err)
+		}
+		now = now.Add(time.Millisecond)
+	}
+
+	// back once it answers, and the count starts over
+	file.err, file.sizes = nil, []int{500}
+	if got, _, _ := load(prov); !reflect.DeepEqual(got, []int{500}) || prov.Status().Degraded {
+		t.Fatalf("Load got %v, status %+v", got, prov.Status())
+	}
+	file.err = errors.New("connection refused")
+	load(prov)
+	now = now.Add(time.Second)
+	calls := file.calls
+	if load(prov); file.calls != calls+1 {
+		t.Fatalf("the backoff must start over after a success")
+	}
+}
+
+func TestProvider_Canceled(t *testing.T) {
+	file := &fakeSource{sizes: []int{250}}
+	prov, _ := New([]Source{{"file", file}}, Options{})
+	if _, _, err := load(prov); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the caller gave up: no source is asked, the cache is not served
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := prov.Load(ctx); !errors.Is(err, context.Canceled) || errors.Is(err, packsizes.ErrUnavailable) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if file.calls != 1 || prov.Status().Degraded {
+		t.Fatalf("calls %d, status %+v", file.calls, prov.Status())
+	}
+}
+
+func TestProvider_EmptyListFails(t *testing.T) {
+	prov, _ := New([]Source{{"env", &fakeSource{}}, {"defaults", &fakeSource{sizes: []int{250}}}}, Options{})
+	if got, _, _ := load(prov); !reflect.DeepEqual(got, []int{250}) {
+		t.Fatalf("Load got %v", got)
+	}
+	if err := prov.Status().Errors["env"]; !errors.Is(err, ErrEmptyList) {
+		t.Fatalf("env error got %v", err)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	opens := 0
+	var openErr error
+	src := &fakeSource{sizes: []int{250}}
+	prov := Lazy(func() (packsizes.Provider, error) {
+		opens++
+		return src, openErr
+	})
+	openErr = errors.New("no such file")
+	if _, _, err := load(prov); !errors.Is(err, openErr) {
+		t.Fatalf("got %v", err)
+	}
+	openErr = nil
+	for range 2 {
+		if got, _, _ := load(prov); !reflect.DeepEqual(got, []int{250}) {
+			t.Fatalf("Load got %v", got)
+		}
+	}
+	if opens != 2 {
+		t.Fatalf("opened %d times, want once per failure plus the success", opens)
+	}
+}
+
+func TestLazy_OpenInFlight(t *testing.T) {
+	release := make(chan struct{})
+	prov := Lazy(func() (packsizes.Provider, error) {
+		<-release
+		return &fakeSource{sizes: []int{250}}, nil
+	})
+
+	// the first call waits for open until its ctx is done; meanwhile the
+	// others do not wait
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := prov.Load(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v", err)
+	}
+	if _, _, err := load(prov); !errors.Is(err, ErrOpening) {
+		t.Fatalf("got %v, want ErrOpening", err)
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, _, err := load(prov)
+		if err == nil && reflect.DeepEqual(got, []int{250}) {
+			return
+		}
+		if !errors.Is(err, ErrOpening) || time.Now().After(deadline) {
+			t.Fatalf("Load got %v %v", got, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,61 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/file"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// DefaultVar is the variable read when New gets no name.
+const DefaultVar = "PACK_SIZES"
+
+var (
+	ErrNotSet      = errors.New("pack sizes variable not set")
+	ErrNoValidPack = errors.New("no valid pack sizes parsed from variable")
+)
+
+type provider struct {
+	name string
+}
+
+// compile-time check
+var _ packsizes.Provider = (*provider)(nil)
+
+// New creates a Provider that reads the pack sizes from the environment
+// variable name, with the syntax of the pack sizes file (see
+// file.ParsePackSizes), e.g. PACK_SIZES="250,500,1000".
+// The variable is read by Load, not by New: an unset or invalid one is an
+// error of each call, so a fallback chain moves on to its next source.
+func New(name string) packsizes.Provider {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = DefaultVar
+	}
+	return &provider{name: name}
+}
+
+func (p *provider) Load(context.Context) (packsizes.Catalogue, error) {
+	val, ok := os.LookupEnv(p.name)
+	if !ok || strings.TrimSpace(val) == "" {
+		return packsizes.Catalogue{}, fmt.Errorf("%w: %s", ErrNotSet, p.name)
+	}
+	sizes, err := file.ParsePackSizes(val)
+	if err != nil {
+		return packsizes.Catalogue{}, fmt.Errorf("parsing %s: %w", p.name, err)
+	}
+	if len(sizes) == 0 {
+		return packsizes.Catalogue{}, fmt.Errorf("%s: %w", p.name, ErrNoValidPack)
+	}
+	return packsizes.Catalogue{
+		Sizes:    sizes,
+		Version:  packsizes.Version{ID: packsizes.VersionOf(sizes)},
+		Source:   "env",
+		LoadedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,42 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/file"
+)
+
+func TestLoad(t *testing.T) {
+	const name = "TEST_PACK_SIZES"
+	prov := New(name)
+
+	ctx := context.Background()
+	if _, err := prov.Load(ctx); !errors.Is(err, ErrNotSet) {
+		t.Fatalf("unset: got %v want ErrNotSet", err)
+	}
+
+	t.Setenv(name, "1000; 250,500 250")
+	got, err := prov.Load(ctx)
+	if err != nil || !reflect.DeepEqual(got.Sizes, []int{250, 500, 1000}) || got.Source != "env" || got.Version.ID == "" {
+		t.Fatalf("got %+v %v", got, err)
+	}
+
+	// read on every call
+	tests := []struct {
+		val     string
+		wantErr error
+	}{
+		{"  ", ErrNotSet},
+		{",;", ErrNoValidPack},
+		{"250,-1", file.ErrInvalidPackSize},
+	}
+	for _, tt := range tests {
+		t.Setenv(name, tt.val)
+		if _, err := prov.Load(ctx); !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%q: got %v want %v", tt.val, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+var (
+	ErrInvalidDirective = errors.New("invalid version directive")
+	ErrDuplicateVersion = errors.New("duplicate pack set version")
+)
+
+// ParseCatalogue parses a pack sizes file holding one or more versions of
+// the catalogue. Each version starts with a directive line
+//
+//	# version=2025-06 effective=2025-06-01T00:00:00Z
+//
+// followed by its sizes (see ParsePackSizes). Both keys are optional:
+// version defaults to VersionOf(sizes), effective (RFC 3339 or YYYY-MM-DD,
+// UTC) to "since always", which only the first set may use. A
+//
+//	# disabled=250,1000
+//
+// line (or key) keeps sizes in the set but out of its enabled Sizes (see
+// provider.SetEnabled); such a set has Packs. Other lines starting with #
+// are comments. Sizes before the first directive (or in a file without
+// directives) are a set in effect since always. The sets are returned
+// EffectiveFrom asc.
+func ParseCatalogue(s string) ([]packsizes.PackSet, error) {
+	sets, _, err := parseCatalogue(s)
+	return sets, err
+}
+
+// sectionLines locates the section of a set: its directive line (0 for the
+// implicit one) and the lines with a disabled key, 1-based.
+type sectionLines struct {
+	directive int
+	disabled  []int
+}
+
+// parseCatalogue is ParseCatalogue, with the lines of each set by version.
+func parseCatalogue(s string) ([]packsizes.PackSet, map[string]sectionLines, error) {
+	type section struct {
+		line          int // of the directive; 0 for the implicit one
+		version       string
+		effective     time.Time
+		disabled      []int
+		disabledLines []int
+		body          strings.Builder
+	}
+	sections := []*section{{}}
+	for i, line := range strings.Split(s, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#") {
+			cur := sections[len(sections)-1]
+			cur.body.WriteString(line)
+			cur.body.WriteByte('\n')
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(trimmed, "#"))
+		if !isDirective(fields) {
+			continue // comment
+		}
+		sec := sections[len(sections)-1]
+		if opensSection(fields) {
+			sec = &section{line: i + 1}
+			sections = append(sections, sec)
+		}
+		for _, f := range fields {
+			key, value, _ := strings.Cut(f, "=")
+			switch key {
+			case "version":
+				sec.version = value
+			case "effective":
+				t, err := parseEffective(value)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%w on line %d: effective %q: %v", ErrInvalidDirective, i+1, value, err)
+				}
+				sec.effective = t
+			case "disabled":
+				sizes, err := ParsePackSizes(value)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%w on line %d: disabled %q: %v", ErrInvalidDirective, i+1, value, err)
+				}
+				sec.disabled = append(sec.disabled, sizes...)
+				sec.disabledLines = append(sec.disabledLines, i+1)
+			default:
+				return nil, nil, fmt.Errorf("%w on line %d: unknown key %q", ErrInvalidDirective, i+1, key)
+			}
+		}
+	}
+
+	// sizes before the first directive are a set in effect since always, so
+	// a version can be appended to a plain list (see provider.Schedule)
+	if len(sections) > 1 && strings.TrimSpace(sections[0].body.String()) == "" && len(sections[0].disabled) == 0 {
+		sections = sections[1:]
+	}
+
+	sets := make([]packsizes.PackSet, 0, len(sections))
+	lines := make(map[string]sectionLines, len(sections))
+	seen := make(map[string]int, len(sections))
+	for _, sec := range sections {
+		sizes, err := ParsePackSizes(sec.body.String())
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", describe(sec.line), err)
+		}
+		set := withDisabled(sizes, sec.disabled)
+		if len(set.Sizes) == 0 {
+			return nil, nil, fmt.Errorf("%s: %w", describe(sec.line), ErrNoValidPack)
+		}
+		if sec.version != "" {
+			set.Version = sec.version
+		}
+		if prev, dup := seen[set.Version]; dup {
+			return nil, nil, fmt.Errorf("%w %q (%s and %s)", ErrDuplicateVersion, set.Version, describe(prev), describe(sec.line))
+		}
+		seen[set.Version] = sec.line
+		lines[set.Version] = sectionLines{directive: sec.line, disabled: sec.disabledLines}
+		set.EffectiveFrom = sec.effective
+		sets = append(sets, set)
+	}
+
+	if err := sortSets(sets); err != nil {
+		return nil, nil, err
+	}
+	return sets, lines, nil
+}
+
+// withDisabled builds an unnamed set from the listed sizes and the disabled
+// ones (listed or not); its version is VersionOf every size, so disabling
+// one does not rename the set.
+func withDisabled(sizes, disabled []int) packsizes.PackSet {
+	if len(disabled) == 0 {
+		return packsizes.PackSet{Version: packsizes.VersionOf(sizes), Sizes: sizes}
+	}
+	off := make(map[int]bool, len(disabled))
+	for _, d := range disabled {
+		off[d] = true
+	}
+	packs := make([]packsizes.Pack, 0, len(sizes)+len(disabled))
+	for _, s := range sizes {
+		if !off[s] {
+			packs = append(packs, packsizes.Pack{Size: s, Enabled: true})
+		}
+	}
+	for d := range off {
+		packs = append(packs, packsizes.Pack{Size: d})
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Size < packs[j].Size })
+	return packsizes.PackSet{Version: packsizes.VersionOf(allSizes(packs)), Sizes: packsizes.EnabledSizes(packs), Packs: packs}
+}
+
+func allSizes(packs []packsizes.Pack) []int {
+	sizes := make([]int, len(packs))
+	for i, p := range packs {
+		sizes[i] = p.Size
+	}
+	return sizes
+}
+
+// patchDisabled sets the disabled sizes of the version section of a text
+// catalogue and leaves the rest of it as written: comments, layout and the
+// listed sizes are kept. A disabled key sharing a line with other keys is
+// taken off that line; the new "# disabled=" line, if any, goes where the
+// first one was, or else after the directive (at the top for the implicit
+// section).
+func patchDisabled(s, version string, disabled []int) (string, error) {
+	_, sections, err := parseCatalogue(s)
+	if err != nil {
+		return "", err
+	}
+	sec, ok := sections[version]
+	if !ok {
+		return "", fmt.Errorf("%w: no version %q in the file", ErrInvalidDirective, version)
+	}
+
+	lines := strings.Split(s, "\n")
+	at := sec.directive // insert before this index, 0-based
+	if len(sec.disabled) > 0 {
+		at = sec.disabled[0] - 1
+	}
+	for _, n := range slices.Backward(sec.disabled) {
+		var kept []string
+		for _, f := range strings.Fields(strings.TrimPrefix(strings.TrimSpace(lines[n-1]), "#")) {
+			if key, _, _ := strings.Cut(f, "="); key != "disabled" {
+				kept = append(kept, f)
+			}
+		}
+		if len(kept) > 0 {
+			lines[n-1] = "# " + strings.Join(kept, " ")
+			if n-1 == at {
+				at++ // after the directive it was on
+			}
+			continue
+		}
+		lines = slices.Delete(lines, n-1, n)
+	}
+	if len(disabled) > 0 {
+		lines = slices.Insert(lines, at, "# disabled="+joinSizes(disabled))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// EncodeCatalogue writes sets in the text format, the inverse of
+// ParseCatalogue. Comments and layout of the original file are not kept;
+// metadata other than the enabled status has no place in it.
+func EncodeCatalogue(sets []packsizes.PackSet) string {
+	var b strings.Builder
+	for i, set := range sets {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		all, disabled := set.Sizes, []int(nil)
+		if set.Packs != nil {
+			all = allSizes(set.Packs)
+			for _, p := range set.Packs {
+				if !p.Enabled {
+					disabled = append(disabled, p.Size)
+				}
+			}
+		}
+		var directive []string
+		if set.Version != packsizes.VersionOf(all) {
+			directive = append(directive, "version="+set.Version)
+		}
+		if !set.EffectiveFrom.IsZero() {
+			directive = append(directive, "effective="+set.EffectiveFrom.UTC().Format(time.RFC3339))
+		}
+		if len(directive) > 0 {
+			b.WriteString("# " + strings.Join(directive, " ") + "\n")
+		}
+		if len(disabled) > 0 {
+			b.WriteString("# disabled=" + joinSizes(disabled) + "\n")
+		}
+		b.WriteString(joinSizes(set.Sizes) + "\n")
+	}
+	return b.String()
+}
+
+func joinSizes(sizes []int) string {
+	out := make([]string, len(sizes))
+	for i, s := range sizes {
+		out[i] = strconv.Itoa(s)
+	}
+	return strings.Join(out, ",")
+}
+
+// sortSets orders sets EffectiveFrom asc and rejects two starting together.
+func sortSets(sets []packsizes.PackSet) error {
+	sort.SliceStable(sets, func(i, j int) bool { return sets[i].EffectiveFrom.Before(sets[j].EffectiveFrom) })
+	for i := 1; i < len(sets); i++ {
+		if !sets[i].EffectiveFrom.After(sets[i-1].EffectiveFrom) {
+			return fmt.Errorf("%w: versions %q and %q have the same effective time", ErrInvalidDirective, sets[i-1].Version, sets[i].Version)
+		}
+	}
+	return nil
+}
+
+// isDirective tells a directive from a comment: every word is key=value and
+// one of the keys is version, effective or disabled.
+func isDirective(fields []string) bool {
+	known := false
+	for _, f := range fields {
+		key, _, ok := strings.Cut(f, "=")
+		if !ok {
+			return false
+		}
+		known = known || key == "version" || key == "effective" || key == "disabled"
+	}
+	return known
+}
+
+// opensSection: a directive without version or effective (disabled=...)
+// belongs to the current section.
+func opensSection(fields []string) bool {
+	for _, f := range fields {
+		key, _, _ := strings.Cut(f, "=")
+		if key == "version" || key == "effective" {
+			return true
+		}
+	}
+	return false
+}
+
+func parseEffective(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+// effectiveAt returns the last set with EffectiveFrom <= t.
+func effectiveAt(sets []packsizes.PackSet, t time.Time) (packsizes.PackSet, bool) {
+	i := sort.Search(len(sets), func(i int) bool { return sets[i].EffectiveFrom.After(t) })
+	if i == 0 {
+		return packsizes.PackSet{}, false
+	}
+	return sets[i-1], true
+}
+
+func describe(line int) string {
+	if line == 0 {
+		return "pack set"
+	}
+	return fmt.Sprintf("pack set on line %d", line)
+}
@@ -0,0 +1,120 @@
+package file
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+const history = `# pack sizes, one version per section
+# version=2024-q1 effective=2024-01-01
+250,500,1000
+
+# version=2025-06 effective=2025-06-01T12:00:00+02:00
+250;500
+1000 2000
+# effective=2999-01-01
+300,600
+`
+
+func TestParseCatalogue(t *testing.T) {
+	sets, err := ParseCatalogue(history)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []packsizes.PackSet{
+		{Version: "2024-q1", EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Sizes: []int{250, 500, 1000}},
+		{Version: "2025-06", EffectiveFrom: time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC), Sizes: []int{250, 500, 1000, 2000}},
+		{Version: packsizes.VersionOf([]int{300, 600}), EffectiveFrom: time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC), Sizes: []int{300, 600}},
+	}
+	if !reflect.DeepEqual(sets, want) {
+		t.Fatalf("got %+v\nwant %+v", sets, want)
+	}
+
+	// without directives: one set, in effect since always
+	sets, err = ParseCatalogue("# a comment\n500,250")
+	if err != nil || len(sets) != 1 || !sets[0].EffectiveFrom.IsZero() || sets[0].Version != packsizes.VersionOf([]int{250, 500}) {
+		t.Fatalf("plain file: %+v %v", sets, err)
+	}
+
+	// a plain list with a version appended
+	sets, err = ParseCatalogue("250,500\n\n# version=next effective=2999-01-01\n500\n")
+	if err != nil || len(sets) != 2 || !sets[0].EffectiveFrom.IsZero() || sets[1].Version != "next" {
+		t.Fatalf("plain file with a scheduled version: %+v %v", sets, err)
+	}
+}
+
+func TestParseCatalogue_Disabled(t *testing.T) {
+	sets, err := ParseCatalogue("# disabled=250\n250,500,1000\n# version=b effective=2999-01-01 disabled=2000\n500\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []packsizes.PackSet{
+		{
+			Version: packsizes.VersionOf([]int{250, 500, 1000}), Sizes: []int{500, 1000},
+			Packs: []packsizes.Pack{{Size: 250}, {Size: 500, Enabled: true}, {Size: 1000, Enabled: true}},
+		},
+		{
+			Version: "b", EffectiveFrom: time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC), Sizes: []int{500},
+			Packs: []packsizes.Pack{{Size: 500, Enabled: true}, {Size: 2000}},
+		},
+	}
+	if !reflect.DeepEqual(sets, want) {
+		t.Fatalf("got %+v\nwant %+v", sets, want)
+	}
+
+	back, err := ParseCatalogue(EncodeCatalogue(sets))
+	if err != nil || !reflect.DeepEqual(back, sets) {
+		t.Fatalf("round trip: %+v %v\n%s", back, err, EncodeCatalogue(sets))
+	}
+}
+
+func TestEncodeCatalogue(t *testing.T) {
+	sets, err := ParseCatalogue(history)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "# version=2024-q1 effective=2024-01-01T00:00:00Z\n250,500,1000\n\n" +
+		"# version=2025-06 effective=2025-06-01T10:00:00Z\n250,500,1000,2000\n\n" +
+		"# effective=2999-01-01T00:00:00Z\n300,600\n"
+	if got := EncodeCatalogue(sets); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestParseCatalogue_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr error
+		wantMsg string
+	}{
+		{"sizes before a directive without effective", "250\n# version=a\n500", ErrInvalidDirective, "same effective time"},
+		{"bad date", "# version=a effective=yesterday\n250", ErrInvalidDirective, "line 1"},
+		{"unknown key", "# version=a effectve=2024-01-01\n250", ErrInvalidDirective, `"effectve"`},
+		{"duplicate version", "# version=a effective=2024-01-01\n250\n# version=a effective=2025-01-01\n500", ErrDuplicateVersion, "line 1 and pack set on line 3"},
+		{"same effective time", "# version=a\n250\n# version=b\n500", ErrInvalidDirective, "same effective time"},
+		{"empty set", "# version=a effective=2024-01-01\n# version=b effective=2025-01-01\n500", ErrNoValidPack, "line 1"},
+		{"bad size", "# version=a\n250,x", nil, `invalid number "x"`},
+		{"bad disabled size", "250\n# disabled=0", ErrInvalidDirective, "line 2"},
+		{"everything disabled", "# disabled=250,500\n250", ErrNoValidPack, "pack set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCatalogue(tt.in)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error got=%v want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Fatalf("error %q does not mention %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
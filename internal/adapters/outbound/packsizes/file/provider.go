@@ -1,12 +1,17 @@
 package file
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
 )
@@ -20,43 +25,379 @@ var (
 )
 
 type provider struct {
-	sizes []int // ordenado asc e sem duplicados
+	path     string
+	format   Format
+	modTime  time.Time
+	loadedAt time.Time // last read or write of the file
+	now      func() time.Time
+
+	mu   sync.RWMutex
+	sets []packsizes.PackSet // EffectiveFrom asc
+}
+
+// Option customizes New.
+type Option func(*provider)
+
+// WithClock replaces time.Now to resolve the set in effect (tests).
+func WithClock(now func() time.Time) Option {
+	return func(p *provider) { p.now = now }
 }
 
 // compile-time check
-var _ packsizes.Provider = (*provider)(nil)
+var (
+	_ packsizes.Provider  = (*provider)(nil)
+	_ packsizes.History   = (*provider)(nil)
+	_ packsizes.Scheduler = (*provider)(nil)
+	_ packsizes.Toggler   = (*provider)(nil)
+)
 
 // New creates a Provider by reading and parsing the file pointed to by path.
 // The file can contain values ​​separated by commas, semicolons, spaces, or newlines.
 // Ex.: "250,500,1000\n2000,5000"
-func New(path string) (packsizes.Provider, error) {
+// It may also hold several versions of the list (see ParseCatalogue); Load
+// then returns the one in effect. A JSON or YAML file (see DetectFormat)
+// adds metadata to each pack (see ParseStructured).
+func New(path string, opts ...Option) (packsizes.Provider, error) {
 	path = strings.TrimSpace(path)
 	if path == "" {
 		return nil, ErrPathNotSet
 	}
 
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %w", path, err)
+	}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading %q: %w", path, err)
 	}
 
-	sizes, err := ParsePackSizes(string(data))
+	sets, format, err := Parse(path, data)
 	if err != nil {
 		return nil, fmt.Errorf("parsing %q: %w", path, err)
 	}
-	if len(sizes) == 0 {
-		return nil, fmt.Errorf("%w: %s", ErrNoValidPack, path)
+
+	p := &provider{path: path, format: format, sets: sets, modTime: info.ModTime().UTC(), now: time.Now}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.loadedAt = p.now()
+	if _, err := p.current(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, path)
+	}
+	return p, nil
+}
+
+// Load returns the set in effect. Its version is the set name (versionOf the
+// packs when unnamed, so a reformatted file keeps it), followed by the hash of
+// the enabled sizes when some are disabled, and it was published at the
+// later of its effective time and the file modification time.
+func (p *provider) Load(context.Context) (packsizes.Catalogue, error) {
+	set, err := p.current()
+	if err != nil {
+		return packsizes.Catalogue{}, err
+	}
+	p.mu.RLock()
+	updated, loadedAt := p.modTime, p.loadedAt
+	p.mu.RUnlock()
+	if set.EffectiveFrom.After(updated) {
+		updated = set.EffectiveFrom
+	}
+	id := set.Version
+	if len(set.Packs) > len(set.Sizes) {
+		id += "+" + packsizes.VersionOf(set.Sizes)
+	}
+	return packsizes.Catalogue{
+		Sizes:    slices.Clone(set.Sizes),
+		Version:  packsizes.Version{ID: id, UpdatedAt: updated},
+		Source:   "file",
+		LoadedAt: loadedAt,
+		Packs:    slices.Clone(set.Packs),
+	}, nil
+}
+
+// SetEnabled changes a size of the set in effect and writes it to the file:
+// only the "# disabled=" line of its section changes in a text file (see
+// patchDisabled), a JSON or YAML one is rewritten (see EncodeStructured).
+func (p *provider) SetEnabled(_ context.Context, size int, enabled bool) (packsizes.Pack, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i := sort.Search(len(p.sets), func(i int) bool { return p.sets[i].EffectiveFrom.After(p.now()) }) - 1
+	if i < 0 {
+		return packsizes.Pack{}, packsizes.ErrNoPackSet
+	}
+	set := clonePackSet(p.sets[i])
+	if set.Packs == nil {
+		set.Packs = packsizes.PlainPacks(set.Sizes)
+	}
+	j := slices.IndexFunc(set.Packs, func(pk packsizes.Pack) bool { return pk.Size == size })
+	if j < 0 {
+		return packsizes.Pack{}, fmt.Errorf("%w: %d", packsizes.ErrUnknownPackSize, size)
+	}
+	if set.Packs[j].Enabled == enabled {
+		return set.Packs[j], nil
+	}
+	if !enabled && len(set.Sizes) == 1 {
+		return packsizes.Pack{}, fmt.Errorf("%w: %d", packsizes.ErrLastEnabledPack, size)
+	}
+	set.Packs[j].Enabled = enabled
+	set.Sizes = packsizes.EnabledSizes(set.Packs)
+
+	sets := slices.Clone(p.sets)
+	sets[i] = set
+	var err error
+	if p.format == FormatText {
+		sets, err = p.patchSection(set)
+	} else {
+		err = p.rewrite(sets)
+	}
+	if err != nil {
+		return packsizes.Pack{}, err
+	}
+	p.sets, p.loadedAt = sets, p.now()
+	if info, err := os.Stat(p.path); err == nil {
+		p.modTime = info.ModTime().UTC()
 	}
+	return set.Packs[j], nil
+}
 
-	return &provider{sizes: sizes}, nil
+func (p *provider) At(_ context.Context, t time.Time) (packsizes.PackSet, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	set, ok := effectiveAt(p.sets, t)
+	if !ok {
+		return packsizes.PackSet{}, packsizes.ErrNoPackSet
+	}
+	return clonePackSet(set), nil
 }
 
-func (p *provider) List() ([]int, error) {
-	out := make([]int, len(p.sizes))
-	copy(out, p.sizes)
+func (p *provider) Versions(context.Context) ([]packsizes.PackSet, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]packsizes.PackSet, len(p.sets))
+	for i, set := range p.sets {
+		out[i] = clonePackSet(set)
+	}
 	return out, nil
 }
 
+// Schedule writes set to the file, so it survives a restart, and makes it
+// visible right away: a text file gets a new section (see ParseCatalogue), a
+// JSON or YAML one is rewritten with the new version (see EncodeStructured).
+func (p *provider) Schedule(_ context.Context, set packsizes.PackSet) error {
+	if set.Version == "" || strings.ContainsAny(set.Version, " \t\r\n=#") {
+		return fmt.Errorf("%w: version %q cannot be written to the file", ErrInvalidDirective, set.Version)
+	}
+	if len(set.Sizes) == 0 {
+		return ErrNoValidPack
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, s := range p.sets {
+		if s.Version == set.Version {
+			return fmt.Errorf("%w: version %q exists", packsizes.ErrScheduleConflict, set.Version)
+		}
+		if s.EffectiveFrom.Equal(set.EffectiveFrom) {
+			return fmt.Errorf("%w: version %q is effective at the same time", packsizes.ErrScheduleConflict, s.Version)
+		}
+	}
+
+	set = clonePackSet(set)
+	set.EffectiveFrom = set.EffectiveFrom.UTC()
+	if p.format != FormatText && set.Packs == nil {
+		set.Packs = packsizes.PlainPacks(set.Sizes)
+	}
+	sets := append(slices.Clone(p.sets), set)
+	sort.SliceStable(sets, func(i, j int) bool { return sets[i].EffectiveFrom.Before(sets[j].EffectiveFrom) })
+
+	var err error
+	if p.format == FormatText {
+		err = p.appendSection(set)
+	} else {
+		err = p.rewrite(sets)
+	}
+	if err != nil {
+		return err
+	}
+	p.sets, p.loadedAt = sets, p.now()
+	return nil
+}
+
+// Unschedule removes a version not in effect yet from the file: its section
+// of a text file (see removeSection), or the whole JSON or YAML one is
+// rewritten without it (see EncodeStructured).
+func (p *provider) Unschedule(_ context.Context, version string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i := slices.IndexFunc(p.sets, func(s packsizes.PackSet) bool { return s.Version == version })
+	if i < 0 || !p.sets[i].EffectiveFrom.After(p.now()) {
+		return fmt.Errorf("%w: version %q", packsizes.ErrNotScheduled, version)
+	}
+	sets := slices.Delete(slices.Clone(p.sets), i, i+1)
+
+	var err error
+	if p.format == FormatText {
+		err = p.removeSection(version)
+	} else {
+		err = p.rewrite(sets)
+	}
+	if err != nil {
+		return err
+	}
+	p.sets, p.loadedAt = sets, p.now()
+	return nil
+}
+
+// rewrite writes sets in the file format (see replace).
+func (p *provider) rewrite(sets []packsizes.PackSet) error {
+	data := []byte(EncodeCatalogue(sets))
+	if p.format != FormatText {
+		var err error
+		if data, err = EncodeStructured(p.format, sets); err != nil {
+			return fmt.Errorf("writing %q: %w", p.path, err)
+		}
+	}
+	return p.replace(data)
+}
+
+// appendSection adds the section of set at the end of a text file, the rest
+// of it left as written (see replace).
+func (p *provider) appendSection(set packsizes.PackSet) error {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("writing %q: %w", p.path, err)
+	}
+	sizes := make([]string, len(set.Sizes))
+	for i, s := range set.Sizes {
+		sizes[i] = strconv.Itoa(s)
+	}
+	data = fmt.Appendf(data, "\n# version=%s effective=%s\n%s\n",
+		set.Version, set.EffectiveFrom.UTC().Format(time.RFC3339), strings.Join(sizes, ","))
+	return p.replace(data)
+}
+
+// patchSection writes the disabled sizes of set to its section of a text
+// file, the rest of it left as written (see replace), and returns the sets
+// read back from it.
+func (p *provider) patchSection(set packsizes.PackSet) ([]packsizes.PackSet, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("writing %q: %w", p.path, err)
+	}
+	var disabled []int
+	for _, pk := range set.Packs {
+		if !pk.Enabled {
+			disabled = append(disabled, pk.Size)
+		}
+	}
+	patched, err := patchDisabled(string(data), set.Version, disabled)
+	if err != nil {
+		return nil, fmt.Errorf("writing %q: %w", p.path, err)
+	}
+	sets, err := ParseCatalogue(patched)
+	if err != nil {
+		return nil, fmt.Errorf("writing %q: %w", p.path, err)
+	}
+	if err := p.replace([]byte(patched)); err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+// removeSection takes the section of version out of a text file (the blank
+// line before it too when it is the last one), the rest of it left as
+// written (see replace).
+func (p *provider) removeSection(version string) error {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("writing %q: %w", p.path, err)
+	}
+	_, sections, err := parseCatalogue(string(data))
+	if err != nil {
+		return fmt.Errorf("writing %q: %w", p.path, err)
+	}
+	sec, ok := sections[version]
+	if !ok || sec.directive == 0 {
+		return fmt.Errorf("writing %q: %w: no section of version %q", p.path, ErrInvalidDirective, version)
+	}
+	lines := strings.Split(string(data), "\n")
+	from, to := sec.directive-1, len(lines) // 0-based, to excluded
+	for _, other := range sections {
+		if other.directive > sec.directive && other.directive-1 < to {
+			to = other.directive - 1
+		}
+	}
+	if to == len(lines) {
+		// the last one: the final newline stays, the blank line before goes
+		if lines[to-1] == "" {
+			to--
+		}
+		if from > 0 && strings.TrimSpace(lines[from-1]) == "" {
+			from--
+		}
+	}
+	return p.replace([]byte(strings.Join(slices.Delete(lines, from, to), "\n")))
+}
+
+// replace writes data to a temporary file, syncs it and renames it over the
+// file: a crash or a full disk leaves the old file whole, and a reader never
+// sees a partial catalogue.
+func (p *provider) replace(data []byte) error {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return fmt.Errorf("writing %q: %w", p.path, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p.path), ".packsizes-*")
+	if err != nil {
+		return fmt.Errorf("writing %q: %w", p.path, err)
+	}
+	fail := func(err error) error {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing %q: %w", p.path, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing %q: %w", p.path, err)
+	}
+	if err := os.Rename(tmp.Name(), p.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing %q: %w", p.path, err)
+	}
+	// the rename itself survives a crash once the directory is synced
+	if dir, err := os.Open(filepath.Dir(p.path)); err == nil {
+		_ = dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+func (p *provider) current() (packsizes.PackSet, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	set, ok := effectiveAt(p.sets, p.now())
+	if !ok {
+		return packsizes.PackSet{}, fmt.Errorf("%w now (the first one starts at %s)", packsizes.ErrNoPackSet, p.sets[0].EffectiveFrom.Format(time.RFC3339))
+	}
+	return set, nil
+}
+
+func clonePackSet(set packsizes.PackSet) packsizes.PackSet {
+	set.Sizes = slices.Clone(set.Sizes)
+	set.Packs = slices.Clone(set.Packs)
+	return set
+}
+
 // ParsePackSizes parses textual content containing sizes separated by commas,
 // semicolons, spaces, or line breaks (“loose” CSV).
 // Rules: remove spaces, reject <= 0, remove duplicates, and sort asc.
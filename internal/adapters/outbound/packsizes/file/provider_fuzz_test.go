@@ -0,0 +1,53 @@
+package file
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// FuzzParsePackSizes: ParsePackSizes must never panic, and whatever it
+// accepts must be positive, unique, sorted and stable when re-parsed.
+// Seed corpus: testdata/fuzz/FuzzParsePackSizes.
+func FuzzParsePackSizes(f *testing.F) {
+	f.Add("250,500,1000\n2000,5000")
+	f.Add("500, 250\n1000; 250 \n 2000  5000")
+	f.Add("abc,100")
+	f.Add("-5,0,+7")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		got, err := ParsePackSizes(s)
+		if err != nil {
+			return
+		}
+		if !sort.IntsAreSorted(got) {
+			t.Fatalf("not sorted: %v", got)
+		}
+		for i, n := range got {
+			if n <= 0 {
+				t.Fatalf("non-positive size %d in %v", n, got)
+			}
+			if i > 0 && got[i-1] == n {
+				t.Fatalf("duplicated size %d in %v", n, got)
+			}
+		}
+
+		parts := make([]string, len(got))
+		for i, n := range got {
+			parts[i] = strconv.Itoa(n)
+		}
+		again, err := ParsePackSizes(strings.Join(parts, ","))
+		if err != nil {
+			t.Fatalf("re-parse of %v failed: %v", got, err)
+		}
+		if len(again) != len(got) {
+			t.Fatalf("re-parse changed the list: %v -> %v", got, again)
+		}
+		for i := range got {
+			if again[i] != got[i] {
+				t.Fatalf("re-parse changed the list: %v -> %v", got, again)
+			}
+		}
+	})
+}
@@ -1,12 +1,28 @@
 package file
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
 )
 
+// load returns the catalogue of prov, failing the test on error.
+func load(t *testing.T, prov packsizes.Provider) packsizes.Catalogue {
+	t.Helper()
+	cat, err := prov.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return cat
+}
+
 func writeTemp(t *testing.T, content string) string {
 	t.Helper()
 	dir := t.TempDir()
@@ -49,7 +65,7 @@ func TestNew_FromFile(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	got, _ := prov.List()
+	got := load(t, prov).Sizes
 	want := []int{250, 500, 1000, 2000, 5000}
 	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("got %v want %v", got, want)
@@ -62,7 +78,7 @@ func TestNew_DuplicatesAndSpaces(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	got, _ := prov.List()
+	got := load(t, prov).Sizes
 	want := []int{250, 500, 1000}
 	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("got %v want %v", got, want)
@@ -78,13 +94,321 @@ func TestNew_PathErrors(t *testing.T) {
 	}
 }
 
-func TestList_ReturnsCopy(t *testing.T) {
+func TestLoad_ReturnsCopy(t *testing.T) {
 	path := writeTemp(t, "250,500")
 	prov, _ := New(path)
-	a, _ := prov.List()
+	a := load(t, prov).Sizes
 	a[0] = 999
-	b, _ := prov.List()
+	b := load(t, prov).Sizes
 	if b[0] != 250 {
-		t.Fatalf("List must return a defensive copy")
+		t.Fatalf("Load must return a defensive copy")
+	}
+}
+
+func TestNew_Version(t *testing.T) {
+	path := writeTemp(t, "250,500,1000")
+	modTime := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	prov, err := New(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cat, err := prov.Load(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	v := cat.Version
+	if v.ID != packsizes.VersionOf([]int{250, 500, 1000}) || !v.UpdatedAt.Equal(modTime) {
+		t.Fatalf("unexpected version: %+v", v)
+	}
+	if cat.Source != "file" || cat.LoadedAt.IsZero() || cat.Packs != nil {
+		t.Fatalf("unexpected catalogue: %+v", cat)
+	}
+
+	// same list, different formatting: same ID
+	other, err := New(writeTemp(t, "1000\n500 250 250"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v2 := load(t, other).Version; v2.ID != v.ID {
+		t.Fatalf("ID changed with formatting: %s vs %s", v2.ID, v.ID)
+	}
+}
+
+func TestNew_History(t *testing.T) {
+	path := writeTemp(t, history)
+	modTime := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	prov, err := New(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the 2999 set is not in effect yet
+	got := load(t, prov).Sizes
+	if want := []int{250, 500, 1000, 2000}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Sizes got %v want %v", got, want)
+	}
+	v := load(t, prov).Version
+	if v.ID != "2025-06" || !v.UpdatedAt.Equal(modTime) {
+		t.Fatalf("unexpected version: %+v", v)
+	}
+
+	h := prov.(packsizes.History)
+	if set, err := h.At(context.Background(), time.Date(2025, 6, 1, 9, 59, 59, 0, time.UTC)); err != nil || set.Version != "2024-q1" {
+		t.Fatalf("At before the switch: %+v %v", set, err)
+	}
+	if _, err := h.At(context.Background(), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, packsizes.ErrNoPackSet) {
+		t.Fatalf("At before the first set: %v", err)
+	}
+	all, _ := h.Versions(context.Background())
+	if len(all) != 3 {
+		t.Fatalf("Versions got %d sets", len(all))
+	}
+	all[0].Sizes[0] = 999
+	if again, _ := h.Versions(context.Background()); again[0].Sizes[0] != 250 {
+		t.Fatalf("Versions must return copies")
+	}
+
+	// once the future set is in effect, it is the current one and its
+	// effective time is the publication time
+	later, err := New(path, WithClock(func() time.Time { return time.Date(2999, 1, 2, 0, 0, 0, 0, time.UTC) }))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := load(t, later).Version; v.ID != packsizes.VersionOf([]int{300, 600}) || !v.UpdatedAt.Equal(time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("future version: %+v", v)
+	}
+}
+
+func TestNew_NothingInEffectYet(t *testing.T) {
+	if _, err := New(writeTemp(t, "# effective=2999-01-01\n250")); !errors.Is(err, packsizes.ErrNoPackSet) {
+		t.Fatalf("expected ErrNoPackSet, got %v", err)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	path := writeTemp(t, "250,500,1000")
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	prov, err := New(path, WithClock(clock))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := prov.(packsizes.Scheduler)
+
+	nov := time.Date(2025, 11, 1, 0, 0, 0, 0, time.FixedZone("BRT", -3*3600))
+	if err := s.Schedule(context.Background(), packsizes.PackSet{Version: "no-250", EffectiveFrom: nov, Sizes: []int{500, 1000}}); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	conflicts := []packsizes.PackSet{
+		{Version: "no-250", EffectiveFrom: nov.AddDate(0, 1, 0), Sizes: []int{500}},
+		{Version: "other", EffectiveFrom: nov, Sizes: []int{500}},
+	}
+	for _, set := range conflicts {
+		if err := s.Schedule(context.Background(), set); !errors.Is(err, packsizes.ErrScheduleConflict) {
+			t.Fatalf("Schedule(%+v): expected ErrScheduleConflict, got %v", set, err)
+		}
+	}
+	if err := s.Schedule(context.Background(), packsizes.PackSet{Version: "a b", EffectiveFrom: nov.AddDate(1, 0, 0), Sizes: []int{1}}); !errors.Is(err, ErrInvalidDirective) {
+		t.Fatalf("a version with spaces must be rejected, got %v", err)
+	}
+
+	// not in effect yet, but listed
+	if got := load(t, prov).Sizes; !reflect.DeepEqual(got, []int{250, 500, 1000}) {
+		t.Fatalf("Sizes before the switch: %v", got)
+	}
+	if all, _ := prov.(packsizes.History).Versions(context.Background()); len(all) != 2 || all[1].Version != "no-250" || !all[1].EffectiveFrom.Equal(nov) {
+		t.Fatalf("Versions: %+v", all)
+	}
+
+	// takes effect at the scheduled time, and survives a restart
+	now = nov
+	if got := load(t, prov).Sizes; !reflect.DeepEqual(got, []int{500, 1000}) {
+		t.Fatalf("Sizes after the switch: %v", got)
+	}
+	data, _ := os.ReadFile(path)
+	if want := "250,500,1000\n# version=no-250 effective=2025-11-01T03:00:00Z\n500,1000\n"; string(data) != want {
+		t.Fatalf("file got:\n%s\nwant:\n%s", data, want)
+	}
+	// replaced through a temporary file, none left behind
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Fatalf("files next to the pack file: %d", len(entries))
+	}
+	reloaded, err := New(path, WithClock(clock))
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if v := load(t, reloaded).Version; v.ID != "no-250" {
+		t.Fatalf("reloaded version got %q", v.ID)
+	}
+}
+
+func TestUnschedule(t *testing.T) {
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	nov, dec := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct{ name, content string }{
+		{"packs.csv", "# boxes in stock\n250,500,1000\n"},
+		{"packs.yaml", "packs: [250, 500, 1000]\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeNamed(t, tt.name, tt.content)
+			prov, err := New(path, WithClock(func() time.Time { return now }))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			s := prov.(packsizes.Scheduler)
+			ctx := context.Background()
+			for _, set := range []packsizes.PackSet{
+				{Version: "dec", EffectiveFrom: dec, Sizes: []int{500}},
+				{Version: "nov", EffectiveFrom: nov, Sizes: []int{500, 1000}},
+			} {
+				if err := s.Schedule(ctx, set); err != nil {
+					t.Fatalf("Schedule: %v", err)
+				}
+			}
+
+			if err := s.Unschedule(ctx, "dec"); err != nil {
+				t.Fatalf("Unschedule: %v", err)
+			}
+			all, _ := prov.(packsizes.History).Versions(ctx)
+			if len(all) != 2 || all[1].Version != "nov" {
+				t.Fatalf("Versions: %+v", all)
+			}
+			reloaded, err := New(path, WithClock(func() time.Time { return now }))
+			if err != nil {
+				t.Fatalf("reload: %v", err)
+			}
+			if all, _ := reloaded.(packsizes.History).Versions(ctx); len(all) != 2 || all[1].Version != "nov" {
+				t.Fatalf("reloaded Versions: %+v", all)
+			}
+
+			// unknown, or already in effect
+			if err := s.Unschedule(ctx, "dec"); !errors.Is(err, packsizes.ErrNotScheduled) {
+				t.Fatalf("unknown version: got %v", err)
+			}
+			if err := s.Unschedule(ctx, all[0].Version); !errors.Is(err, packsizes.ErrNotScheduled) {
+				t.Fatalf("version in effect: got %v", err)
+			}
+
+			// a text file is back as written
+			if err := s.Unschedule(ctx, "nov"); err != nil {
+				t.Fatalf("Unschedule: %v", err)
+			}
+			if data, _ := os.ReadFile(path); tt.name == "packs.csv" && string(data) != tt.content {
+				t.Fatalf("file got:\n%s\nwant:\n%s", data, tt.content)
+			}
+		})
+	}
+}
+
+func TestSetEnabled_KeepsTextLayout(t *testing.T) {
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	const content = "# boxes in stock\n250, 500\n\n# version=v2 effective=2025-06-01 disabled=1000\n# new supplier\n250  500  1000\n\n# version=v3 effective=2026-01-01\n500\n"
+	path := writeTemp(t, content)
+	prov, err := New(path, WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	toggler := prov.(packsizes.Toggler)
+
+	for _, step := range []struct {
+		size    int
+		enabled bool
+		want    string
+	}{
+		{250, false, "# version=v2 effective=2025-06-01\n# disabled=250,1000\n# new supplier\n250  500  1000\n"},
+		{1000, true, "# version=v2 effective=2025-06-01\n# disabled=250\n# new supplier\n250  500  1000\n"},
+		{250, true, "# version=v2 effective=2025-06-01\n# new supplier\n250  500  1000\n"},
+	} {
+		if _, err := toggler.SetEnabled(context.Background(), step.size, step.enabled); err != nil {
+			t.Fatalf("SetEnabled(%d, %t): %v", step.size, step.enabled, err)
+		}
+		want := strings.Replace(content, "# version=v2 effective=2025-06-01 disabled=1000\n# new supplier\n250  500  1000\n", step.want, 1)
+		if data, _ := os.ReadFile(path); string(data) != want {
+			t.Fatalf("SetEnabled(%d, %t): file got:\n%s\nwant:\n%s", step.size, step.enabled, data, want)
+		}
+	}
+	if got := load(t, prov).Sizes; !reflect.DeepEqual(got, []int{250, 500, 1000}) {
+		t.Fatalf("Sizes got %v", got)
+	}
+}
+
+func TestSetEnabled(t *testing.T) {
+	for _, tt := range []struct {
+		name, content, want string
+	}{
+		{"packs.csv", "# sizes in boxes\n250,500,1000\n", "# disabled=250\n# sizes in boxes\n250,500,1000\n"},
+		{"packs.yaml", "packs:\n  - {size: 250, sku: BOX-250}\n  - 500\n  - 1000\n", ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeNamed(t, tt.name, tt.content)
+			prov, err := New(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			before := load(t, prov).Version
+			toggler := prov.(packsizes.Toggler)
+
+			pack, err := toggler.SetEnabled(context.Background(), 250, false)
+			if err != nil || pack.Enabled || pack.Size != 250 {
+				t.Fatalf("SetEnabled: %+v %v", pack, err)
+			}
+			if got := load(t, prov).Sizes; !reflect.DeepEqual(got, []int{500, 1000}) {
+				t.Fatalf("Sizes got %v", got)
+			}
+			if packs := load(t, prov).AllPacks(); len(packs) != 3 || packs[0].Enabled {
+				t.Fatalf("Packs got %+v", packs)
+			}
+			after := load(t, prov).Version
+			if after.ID == before.ID {
+				t.Fatalf("the version must change with the list, got %q", after.ID)
+			}
+
+			if _, err := toggler.SetEnabled(context.Background(), 42, false); !errors.Is(err, packsizes.ErrUnknownPackSize) {
+				t.Fatalf("unknown size: got %v", err)
+			}
+			if _, err := toggler.SetEnabled(context.Background(), 500, false); err != nil {
+				t.Fatalf("SetEnabled(500): %v", err)
+			}
+			if _, err := toggler.SetEnabled(context.Background(), 1000, false); !errors.Is(err, packsizes.ErrLastEnabledPack) {
+				t.Fatalf("last size: got %v", err)
+			}
+
+			// survives a restart, metadata included
+			if _, err := toggler.SetEnabled(context.Background(), 500, true); err != nil {
+				t.Fatalf("SetEnabled(500, true): %v", err)
+			}
+			data, _ := os.ReadFile(path)
+			if tt.want != "" && string(data) != tt.want {
+				t.Fatalf("file got:\n%s\nwant:\n%s", data, tt.want)
+			}
+			reloaded, err := New(path)
+			if err != nil {
+				t.Fatalf("reload: %v\n%s", err, data)
+			}
+			if got := load(t, reloaded).Sizes; !reflect.DeepEqual(got, []int{500, 1000}) {
+				t.Fatalf("reloaded Sizes got %v", got)
+			}
+			if v := load(t, reloaded).Version; v.ID != after.ID {
+				t.Fatalf("reloaded version got %q want %q", v.ID, after.ID)
+			}
+			packs := load(t, reloaded).AllPacks()
+			if tt.name == "packs.yaml" && packs[0].SKU != "BOX-250" {
+				t.Fatalf("metadata lost: %+v", packs)
+			}
+
+			// enabling it again gives the original version back
+			if _, err := reloaded.(packsizes.Toggler).SetEnabled(context.Background(), 250, true); err != nil {
+				t.Fatalf("SetEnabled(250, true): %v", err)
+			}
+			if v := load(t, reloaded).Version; v.ID != before.ID {
+				t.Fatalf("version got %q want %q", v.ID, before.ID)
+			}
+		})
 	}
 }
@@ -0,0 +1,541 @@
+package file
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// ErrInvalidCatalogue is returned for a JSON or YAML file that does not
+// follow the schema (see ParseStructured); the message has the line.
+var ErrInvalidCatalogue = errors.New("invalid pack catalogue")
+
+// Format of a pack sizes file.
+type Format int
+
+const (
+	FormatText Format = iota // loose CSV with version directives (ParseCatalogue)
+	FormatJSON
+	FormatYAML
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatYAML:
+		return "yaml"
+	default:
+		return "text"
+	}
+}
+
+// DetectFormat uses the extension (.json, .yaml, .yml; anything else, e.g.
+// .csv or .txt, is text) and, without one, the content: a file starting with
+// { or [ is JSON, one whose first line that is not a comment looks like
+// "key:" is YAML.
+func DetectFormat(path string, data []byte) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	case "":
+	default:
+		return FormatText
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	if isJSON(trimmed) {
+		return FormatJSON
+	}
+	for _, line := range strings.Split(string(trimmed), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "---" || strings.HasPrefix(line, "- ") {
+			return FormatYAML
+		}
+		key, _, ok := strings.Cut(line, ":")
+		if ok && key != "" && !strings.ContainsAny(key, " ,;\t") {
+			return FormatYAML
+		}
+		return FormatText
+	}
+	return FormatText
+}
+
+func isJSON(trimmed []byte) bool {
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// Parse parses a pack sizes file in the format DetectFormat finds.
+func Parse(path string, data []byte) ([]packsizes.PackSet, Format, error) {
+	f := DetectFormat(path, data)
+	if f == FormatText {
+		sets, err := ParseCatalogue(string(data))
+		return sets, f, err
+	}
+	sets, err := ParseStructured(data)
+	return sets, f, err
+}
+
+// ParseStructured parses a JSON or YAML catalogue (JSON is read as YAML, so
+// both give line numbers). A single version:
+//
+//	version: 2025-06          # optional, see versionOf
+//	effective: 2025-06-01     # optional, since always
+//	packs:
+//	  - 250                   # a size without metadata
+//	  - size: 500
+//	    label: Medium box
+//	    sku: BOX-500
+//	    cost: 0.42            # >= 0
+//	    stock: 1200           # >= 0
+//	    enabled: false        # default true
+//	    dimensions: {length: 30, width: 20, height: 15, unit: cm}
+//
+// or several, as a list under "versions" of objects with the same keys. A
+// top-level list is the packs of a single version. Unknown keys are errors,
+// and each version needs at least one enabled pack. The sets are returned
+// EffectiveFrom asc, with Packs by size asc.
+func ParseStructured(data []byte) ([]packsizes.PackSet, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(expandTabs(data), &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCatalogue, err)
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("%w: empty document", ErrInvalidCatalogue)
+	}
+	root := doc.Content[0]
+
+	var sets []packsizes.PackSet
+	switch {
+	case root.Kind == yaml.SequenceNode:
+		set, err := parsePacks(root, "")
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	case root.Kind == yaml.MappingNode && lookup(root, "versions") != nil:
+		if err := onlyKeys(root, "", "versions"); err != nil {
+			return nil, err
+		}
+		versions := lookup(root, "versions")
+		if versions.Kind != yaml.SequenceNode {
+			return nil, schemaError(versions, "versions", "must be a list")
+		}
+		if len(versions.Content) == 0 {
+			return nil, schemaError(versions, "versions", "must not be empty")
+		}
+		for i, n := range versions.Content {
+			set, err := parseSet(n, fmt.Sprintf("versions[%d].", i))
+			if err != nil {
+				return nil, err
+			}
+			sets = append(sets, set)
+		}
+	case root.Kind == yaml.MappingNode:
+		set, err := parseSet(root, "")
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	default:
+		return nil, schemaError(root, "", "must be an object or a list of packs")
+	}
+
+	seen := make(map[string]struct{}, len(sets))
+	for _, s := range sets {
+		if _, dup := seen[s.Version]; dup {
+			return nil, fmt.Errorf("%w %q", ErrDuplicateVersion, s.Version)
+		}
+		seen[s.Version] = struct{}{}
+	}
+	if err := sortSets(sets); err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+func parseSet(n *yaml.Node, path string) (packsizes.PackSet, error) {
+	if n.Kind != yaml.MappingNode {
+		return packsizes.PackSet{}, schemaError(n, strings.TrimSuffix(path, "."), "must be an object")
+	}
+	if err := onlyKeys(n, path, "version", "effective", "packs"); err != nil {
+		return packsizes.PackSet{}, err
+	}
+	packs := lookup(n, "packs")
+	if packs == nil {
+		return packsizes.PackSet{}, schemaError(n, path+"packs", "is required")
+	}
+	set, err := parsePacks(packs, path+"packs")
+	if err != nil {
+		return packsizes.PackSet{}, err
+	}
+	if v := lookup(n, "version"); v != nil {
+		if v.Kind != yaml.ScalarNode || strings.TrimSpace(v.Value) == "" {
+			return packsizes.PackSet{}, schemaError(v, path+"version", "must be a non-empty string")
+		}
+		set.Version = v.Value
+	}
+	if e := lookup(n, "effective"); e != nil {
+		t, err := parseEffective(e.Value)
+		if e.Kind != yaml.ScalarNode || err != nil {
+			return packsizes.PackSet{}, schemaError(e, path+"effective", "must be an RFC 3339 time or YYYY-MM-DD")
+		}
+		set.EffectiveFrom = t
+	}
+	return set, nil
+}
+
+// parsePacks leaves Version as versionOf(packs).
+func parsePacks(n *yaml.Node, path string) (packsizes.PackSet, error) {
+	if n.Kind != yaml.SequenceNode {
+		return packsizes.PackSet{}, schemaError(n, path, "must be a list")
+	}
+	packs := make([]packsizes.Pack, 0, len(n.Content))
+	lines := make(map[int]int, len(n.Content))   // size -> line
+	skus := make(map[string]int, len(n.Content)) // sku -> line
+	for i, item := range n.Content {
+		p, err := parsePack(item, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return packsizes.PackSet{}, err
+		}
+		if prev, dup := lines[p.Size]; dup {
+			return packsizes.PackSet{}, schemaError(item, fmt.Sprintf("%s[%d]", path, i), fmt.Sprintf("size %d is already on line %d", p.Size, prev))
+		}
+		lines[p.Size] = item.Line
+		if p.SKU != "" {
+			if prev, dup := skus[p.SKU]; dup {
+				return packsizes.PackSet{}, schemaError(item, fmt.Sprintf("%s[%d]", path, i), fmt.Sprintf("sku %q is already on line %d", p.SKU, prev))
+			}
+			skus[p.SKU] = item.Line
+		}
+		packs = append(packs, p)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Size < packs[j].Size })
+
+	sizes := packsizes.EnabledSizes(packs)
+	if len(sizes) == 0 {
+		return packsizes.PackSet{}, fmt.Errorf("line %d: %s: %w", n.Line, orRoot(path), ErrNoValidPack)
+	}
+	return packsizes.PackSet{Version: versionOf(packs), Sizes: sizes, Packs: packs}, nil
+}
+
+// versionOf names an unnamed set: VersionOf(every size), as ParseCatalogue,
+// followed by "+" and a hash of the metadata when some pack has any, so
+// editing a label or a cost gives a new version. The enabled status is left
+// out: toggling a size does not rename the set (see Load).
+func versionOf(packs []packsizes.Pack) string {
+	id := packsizes.VersionOf(allSizes(packs))
+	if !slices.ContainsFunc(packs, hasMetadata) {
+		return id
+	}
+	h := sha256.New()
+	for _, p := range packs {
+		fmt.Fprintf(h, "%d,%q,%q", p.Size, p.Label, p.SKU)
+		if p.Cost != nil {
+			fmt.Fprintf(h, ",cost=%v", *p.Cost)
+		}
+		if p.Stock != nil {
+			fmt.Fprintf(h, ",stock=%d", *p.Stock)
+		}
+		if d := p.Dimensions; d != nil {
+			fmt.Fprintf(h, ",dimensions=%vx%vx%v%q", d.Length, d.Width, d.Height, d.Unit)
+		}
+		h.Write([]byte("\n"))
+	}
+	return id + "+" + hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+func hasMetadata(p packsizes.Pack) bool {
+	return p.Label != "" || p.SKU != "" || p.Cost != nil || p.Stock != nil || p.Dimensions != nil
+}
+
+func parsePack(n *yaml.Node, path string) (packsizes.Pack, error) {
+	if n.Kind == yaml.ScalarNode {
+		size, err := positiveInt(n, path)
+		if err != nil {
+			return packsizes.Pack{}, err
+		}
+		return packsizes.Pack{Size: size, Enabled: true}, nil
+	}
+	if n.Kind != yaml.MappingNode {
+		return packsizes.Pack{}, schemaError(n, path, "must be a size or an object")
+	}
+	if err := onlyKeys(n, path+".", "size", "label", "sku", "cost", "stock", "enabled", "dimensions"); err != nil {
+		return packsizes.Pack{}, err
+	}
+
+	p := packsizes.Pack{Enabled: true}
+	sizeNode := lookup(n, "size")
+	if sizeNode == nil {
+		return packsizes.Pack{}, schemaError(n, path+".size", "is required")
+	}
+	var err error
+	if p.Size, err = positiveInt(sizeNode, path+".size"); err != nil {
+		return packsizes.Pack{}, err
+	}
+	if v := lookup(n, "label"); v != nil {
+		if p.Label, err = str(v, path+".label"); err != nil {
+			return packsizes.Pack{}, err
+		}
+	}
+	if v := lookup(n, "sku"); v != nil {
+		if p.SKU, err = str(v, path+".sku"); err != nil {
+			return packsizes.Pack{}, err
+		}
+	}
+	if v := lookup(n, "cost"); v != nil {
+		cost, err := number(v, path+".cost", false)
+		if err != nil {
+			return packsizes.Pack{}, err
+		}
+		p.Cost = &cost
+	}
+	if v := lookup(n, "stock"); v != nil {
+		stock, err := integer(v, path+".stock")
+		if err != nil {
+			return packsizes.Pack{}, err
+		}
+		if stock < 0 {
+			return packsizes.Pack{}, schemaError(v, path+".stock", "must be >= 0")
+		}
+		p.Stock = &stock
+	}
+	if v := lookup(n, "enabled"); v != nil {
+		if v.Kind != yaml.ScalarNode || v.Tag != "!!bool" {
+			return packsizes.Pack{}, schemaError(v, path+".enabled", "must be true or false")
+		}
+		p.Enabled = v.Value == "true"
+	}
+	if v := lookup(n, "dimensions"); v != nil {
+		if p.Dimensions, err = parseDimensions(v, path+".dimensions"); err != nil {
+			return packsizes.Pack{}, err
+		}
+	}
+	return p, nil
+}
+
+func parseDimensions(n *yaml.Node, path string) (*packsizes.Dimensions, error) {
+	if n.Kind != yaml.MappingNode {
+		return nil, schemaError(n, path, "must be an object")
+	}
+	if err := onlyKeys(n, path+".", "length", "width", "height", "unit"); err != nil {
+		return nil, err
+	}
+	var d packsizes.Dimensions
+	for _, f := range []struct {
+		key string
+		dst *float64
+	}{{"length", &d.Length}, {"width", &d.Width}, {"height", &d.Height}} {
+		v := lookup(n, f.key)
+		if v == nil {
+			return nil, schemaError(n, path+"."+f.key, "is required")
+		}
+		x, err := number(v, path+"."+f.key, true)
+		if err != nil {
+			return nil, err
+		}
+		*f.dst = x
+	}
+	if v := lookup(n, "unit"); v != nil {
+		unit, err := str(v, path+".unit")
+		if err != nil {
+			return nil, err
+		}
+		d.Unit = unit
+	}
+	return &d, nil
+}
+
+func positiveInt(n *yaml.Node, path string) (int, error) {
+	v, err := integer(n, path)
+	if err != nil {
+		return 0, err
+	}
+	if v <= 0 {
+		return 0, schemaError(n, path, fmt.Sprintf("must be > 0, got %d", v))
+	}
+	return v, nil
+}
+
+func integer(n *yaml.Node, path string) (int, error) {
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!int" {
+		return 0, schemaError(n, path, "must be an integer")
+	}
+	v, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return 0, schemaError(n, path, "must be an integer")
+	}
+	return v, nil
+}
+
+// number accepts integers and floats; positive rejects 0 as well.
+func number(n *yaml.Node, path string, positive bool) (float64, error) {
+	if n.Kind != yaml.ScalarNode || (n.Tag != "!!int" && n.Tag != "!!float") {
+		return 0, schemaError(n, path, "must be a number")
+	}
+	v, err := strconv.ParseFloat(n.Value, 64)
+	if err != nil {
+		return 0, schemaError(n, path, "must be a number")
+	}
+	switch {
+	case positive && v <= 0:
+		return 0, schemaError(n, path, "must be > 0")
+	case v < 0:
+		return 0, schemaError(n, path, "must be >= 0")
+	}
+	return v, nil
+}
+
+func str(n *yaml.Node, path string) (string, error) {
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!str" {
+		return "", schemaError(n, path, "must be a string")
+	}
+	return n.Value, nil
+}
+
+// lookup returns the value of key in a mapping node (nil when absent).
+func lookup(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// onlyKeys rejects unknown and repeated keys; prefix is the path of n with
+// a trailing dot.
+func onlyKeys(n *yaml.Node, prefix string, keys ...string) error {
+	seen := make(map[string]struct{}, len(n.Content)/2)
+	for i := 0; i < len(n.Content); i += 2 {
+		k := n.Content[i]
+		known := false
+		for _, want := range keys {
+			known = known || k.Value == want
+		}
+		if !known {
+			return schemaError(k, prefix+k.Value, "unknown field")
+		}
+		if _, dup := seen[k.Value]; dup {
+			return schemaError(k, prefix+k.Value, "repeated field")
+		}
+		seen[k.Value] = struct{}{}
+	}
+	return nil
+}
+
+func schemaError(n *yaml.Node, path, reason string) error {
+	return fmt.Errorf("%w: line %d: %s: %s", ErrInvalidCatalogue, n.Line, orRoot(path), reason)
+}
+
+func orRoot(path string) string {
+	if path == "" {
+		return "document"
+	}
+	return path
+}
+
+// expandTabs replaces the tabs of a JSON document with spaces: JSON allows
+// them as indentation, YAML does not. A tab inside a JSON string must be
+// escaped, so no value changes.
+func expandTabs(data []byte) []byte {
+	if !isJSON(bytes.TrimSpace(data)) {
+		return data
+	}
+	return bytes.ReplaceAll(data, []byte("\t"), []byte("  "))
+}
+
+// EncodeStructured writes sets as a "versions" document in format f (JSON
+// or YAML), the inverse of ParseStructured. Comments and layout of the
+// original file are not kept.
+func EncodeStructured(f Format, sets []packsizes.PackSet) ([]byte, error) {
+	type dimensionsDoc struct {
+		Length float64 `json:"length" yaml:"length"`
+		Width  float64 `json:"width" yaml:"width"`
+		Height float64 `json:"height" yaml:"height"`
+		Unit   string  `json:"unit,omitempty" yaml:"unit,omitempty"`
+	}
+	type packDoc struct {
+		Size       int            `json:"size" yaml:"size"`
+		Label      string         `json:"label,omitempty" yaml:"label,omitempty"`
+		SKU        string         `json:"sku,omitempty" yaml:"sku,omitempty"`
+		Cost       *float64       `json:"cost,omitempty" yaml:"cost,omitempty"`
+		Stock      *int           `json:"stock,omitempty" yaml:"stock,omitempty"`
+		Enabled    *bool          `json:"enabled,omitempty" yaml:"enabled,omitempty"` // only when false
+		Dimensions *dimensionsDoc `json:"dimensions,omitempty" yaml:"dimensions,omitempty"`
+	}
+	type setDoc struct {
+		Version   string    `json:"version,omitempty" yaml:"version,omitempty"`
+		Effective string    `json:"effective,omitempty" yaml:"effective,omitempty"`
+		Packs     []packDoc `json:"packs" yaml:"packs"`
+	}
+	doc := struct {
+		Versions []setDoc `json:"versions" yaml:"versions"`
+	}{Versions: make([]setDoc, 0, len(sets))}
+
+	for _, s := range sets {
+		packs := s.Packs
+		if packs == nil {
+			packs = packsizes.PlainPacks(s.Sizes)
+		}
+		sd := setDoc{}
+		if s.Version != versionOf(packs) {
+			sd.Version = s.Version // an unnamed set stays unnamed
+		}
+		if !s.EffectiveFrom.IsZero() {
+			sd.Effective = s.EffectiveFrom.UTC().Format(time.RFC3339)
+		}
+		for _, p := range packs {
+			pd := packDoc{Size: p.Size, Label: p.Label, SKU: p.SKU, Cost: p.Cost, Stock: p.Stock}
+			if !p.Enabled {
+				disabled := false
+				pd.Enabled = &disabled
+			}
+			if d := p.Dimensions; d != nil {
+				pd.Dimensions = &dimensionsDoc{Length: d.Length, Width: d.Width, Height: d.Height, Unit: d.Unit}
+			}
+			sd.Packs = append(sd.Packs, pd)
+		}
+		doc.Versions = append(doc.Versions, sd)
+	}
+
+	switch f {
+	case FormatJSON:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("cannot encode a %s catalogue", f)
+	}
+}
@@ -0,0 +1,262 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+const yamlCatalogue = `# boxes
+versions:
+  - version: 2024-q1
+    effective: 2024-01-01
+    packs: [250, 500, 1000]
+  - version: "2025-06"
+    effective: 2025-06-01T00:00:00Z
+    packs:
+      - size: 500
+        label: Medium box
+        sku: BOX-500
+        cost: 0.42
+        stock: 1200
+        dimensions: {length: 30, width: 20, height: 15, unit: cm}
+      - size: 250
+        enabled: false
+      - 1000
+`
+
+const jsonCatalogue = "{\n\t\"version\": \"v1\",\n\t\"packs\": [\n\t\t{\"size\": 500, \"sku\": \"BOX-500\", \"cost\": 1},\n\t\t250\n\t]\n}\n"
+
+func writeNamed(t *testing.T, name, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	return p
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		want Format
+	}{
+		{"packs.json", "250", FormatJSON},
+		{"packs.YML", "", FormatYAML},
+		{"packs.yaml", "", FormatYAML},
+		{"packs.csv", "packs: [250]", FormatText},
+		{"packs", " \n{\"packs\": [250]}", FormatJSON},
+		{"packs", "[250]", FormatJSON},
+		{"packs", "# comment\n\npacks:\n  - 250", FormatYAML},
+		{"packs", "- 250\n- 500", FormatYAML},
+		{"packs", "# version=a effective=2024-01-01\n250,500", FormatText},
+		{"packs", "250 500", FormatText},
+		{"packs", "", FormatText},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.path, []byte(tt.data)); got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) got %s want %s", tt.path, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestParseStructured(t *testing.T) {
+	sets, err := ParseStructured([]byte(yamlCatalogue))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cost, stock := 0.42, 1200
+	want := []packsizes.PackSet{
+		{
+			Version: "2024-q1", EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Sizes: []int{250, 500, 1000},
+			Packs: packsizes.PlainPacks([]int{250, 500, 1000}),
+		},
+		{
+			Version: "2025-06", EffectiveFrom: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), Sizes: []int{500, 1000},
+			Packs: []packsizes.Pack{
+				{Size: 250},
+				{Size: 500, Label: "Medium box", SKU: "BOX-500", Cost: &cost, Stock: &stock, Enabled: true,
+					Dimensions: &packsizes.Dimensions{Length: 30, Width: 20, Height: 15, Unit: "cm"}},
+				{Size: 1000, Enabled: true},
+			},
+		},
+	}
+	if !reflect.DeepEqual(sets, want) {
+		t.Fatalf("got %+v\nwant %+v", sets, want)
+	}
+
+	// JSON, indented with tabs, single version
+	sets, err = ParseStructured([]byte(jsonCatalogue))
+	if err != nil || len(sets) != 1 || sets[0].Version != "v1" || !reflect.DeepEqual(sets[0].Sizes, []int{250, 500}) || sets[0].Packs[1].SKU != "BOX-500" {
+		t.Fatalf("json: %+v %v", sets, err)
+	}
+
+	// a bare list, unnamed
+	sets, err = ParseStructured([]byte("[500, 250]"))
+	if err != nil || len(sets) != 1 || sets[0].Version != packsizes.VersionOf([]int{250, 500}) || !sets[0].EffectiveFrom.IsZero() {
+		t.Fatalf("bare list: %+v %v", sets, err)
+	}
+}
+
+func TestParseStructured_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr error
+		wantMsg string
+	}{
+		{"syntax", "{\"packs\": [250,]", ErrInvalidCatalogue, "line 1"},
+		{"scalar document", "250", ErrInvalidCatalogue, "document: must be an object"},
+		{"packs missing", "version: a", ErrInvalidCatalogue, "line 1: packs: is required"},
+		{"unknown field", "packs:\n  - size: 250\n    colour: red", ErrInvalidCatalogue, `line 3: packs[0].colour: unknown field`},
+		{"repeated field", "packs: [250]\npacks: [500]", ErrInvalidCatalogue, "line 2: packs: repeated field"},
+		{"size not an integer", "packs:\n  - size: 2.5", ErrInvalidCatalogue, "line 2: packs[0].size: must be an integer"},
+		{"size as a string", `{"packs": ["250"]}`, ErrInvalidCatalogue, "packs[0]: must be an integer"},
+		{"size not positive", "packs:\n  - 250\n  - 0", ErrInvalidCatalogue, "line 3: packs[1]: must be > 0, got 0"},
+		{"duplicate size", "packs:\n  - 250\n  - size: 250", ErrInvalidCatalogue, "line 3: packs[1]: size 250 is already on line 2"},
+		{"duplicate sku", "packs:\n  - {size: 250, sku: A}\n  - {size: 500, sku: A}", ErrInvalidCatalogue, `sku "A" is already on line 2`},
+		{"negative cost", "packs:\n  - {size: 250, cost: -1}", ErrInvalidCatalogue, "packs[0].cost: must be >= 0"},
+		{"negative stock", "packs:\n  - {size: 250, stock: -1}", ErrInvalidCatalogue, "packs[0].stock: must be >= 0"},
+		{"enabled not a bool", "packs:\n  - {size: 250, enabled: yes please}", ErrInvalidCatalogue, "packs[0].enabled: must be true or false"},
+		{"dimension missing", "packs:\n  - size: 250\n    dimensions: {length: 1, width: 1}", ErrInvalidCatalogue, "line 3: packs[0].dimensions.height: is required"},
+		{"dimension zero", "packs:\n  - size: 250\n    dimensions: {length: 1, width: 0, height: 1}", ErrInvalidCatalogue, "packs[0].dimensions.width: must be > 0"},
+		{"bad effective", "effective: yesterday\npacks: [250]", ErrInvalidCatalogue, "line 1: effective: must be an RFC 3339 time"},
+		{"nothing enabled", "packs:\n  - {size: 250, enabled: false}", ErrNoValidPack, "line 2: packs"},
+		{"versions and packs", "versions: []\npacks: [250]", ErrInvalidCatalogue, "line 2: packs: unknown field"},
+		{"empty versions", "versions: []", ErrInvalidCatalogue, "versions: must not be empty"},
+		{"version not an object", "versions: [250]", ErrInvalidCatalogue, "versions[0]: must be an object"},
+		{"duplicate version", "versions:\n  - {version: a, effective: 2024-01-01, packs: [250]}\n  - {version: a, packs: [500]}", ErrDuplicateVersion, `"a"`},
+		{"same effective time", "versions:\n  - {version: a, packs: [250]}\n  - {version: b, packs: [500]}", ErrInvalidDirective, "same effective time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStructured([]byte(tt.in))
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error got=%v want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Fatalf("error %q does not mention %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestEncodeStructured_RoundTrip(t *testing.T) {
+	sets, err := ParseStructured([]byte(yamlCatalogue))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range []Format{FormatJSON, FormatYAML} {
+		data, err := EncodeStructured(f, sets)
+		if err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		got, err := ParseStructured(data)
+		if err != nil {
+			t.Fatalf("%s: reparse: %v\n%s", f, err, data)
+		}
+		if !reflect.DeepEqual(got, sets) {
+			t.Fatalf("%s: got %+v\nwant %+v", f, got, sets)
+		}
+	}
+	if _, err := EncodeStructured(FormatText, sets); err == nil {
+		t.Fatalf("expected an error for the text format")
+	}
+}
+
+func TestNew_StructuredUnnamedVersion(t *testing.T) {
+	const labelled = "- 250\n- {size: 500, label: Medium box}\n"
+	version := func(content string) string {
+		t.Helper()
+		prov, err := New(writeNamed(t, "packs.yaml", content))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return load(t, prov).Version.ID
+	}
+	a, b := version(labelled), version(strings.Replace(labelled, "Medium", "Large", 1))
+	plain := version("[250, 500]")
+	if a == b || a == plain || plain != packsizes.VersionOf([]int{250, 500}) {
+		t.Fatalf("versions %q, %q, %q: the metadata must be part of an unnamed version", a, b, plain)
+	}
+
+	// an unnamed set is written back without a version
+	sets, err := ParseStructured([]byte(labelled))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := EncodeStructured(FormatYAML, sets)
+	if err != nil || strings.Contains(string(data), "version:") {
+		t.Fatalf("got %v\n%s", err, data)
+	}
+}
+
+func TestNew_Structured(t *testing.T) {
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	prov, err := New(writeNamed(t, "packs.yaml", yamlCatalogue), WithClock(clock))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := load(t, prov).Sizes; !reflect.DeepEqual(got, []int{500, 1000}) {
+		t.Fatalf("Sizes got %v: a disabled size must not be listed", got)
+	}
+	set, _ := prov.(packsizes.History).At(context.Background(), now)
+	if len(set.Packs) != 3 || set.Packs[1].Label != "Medium box" {
+		t.Fatalf("At: %+v", set)
+	}
+
+	// the error names the file and the line
+	_, err = New(writeNamed(t, "packs.json", `{"packs": [`+"\n"+`-1]}`))
+	if !errors.Is(err, ErrInvalidCatalogue) || !strings.Contains(err.Error(), "packs.json") || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestSchedule_Structured(t *testing.T) {
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	for _, name := range []string{"packs.json", "packs.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			path := writeNamed(t, name, jsonCatalogue)
+			prov, err := New(path, WithClock(clock))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			nov := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+			if err := prov.(packsizes.Scheduler).Schedule(context.Background(), packsizes.PackSet{Version: "v2", EffectiveFrom: nov, Sizes: []int{500}}); err != nil {
+				t.Fatalf("Schedule: %v", err)
+			}
+
+			// rewritten in its own format, metadata kept
+			data, _ := os.ReadFile(path)
+			if f := DetectFormat("", data); f.String() != strings.TrimPrefix(filepath.Ext(name), ".") {
+				t.Fatalf("rewritten as %s:\n%s", f, data)
+			}
+			now := nov
+			reloaded, err := New(path, WithClock(func() time.Time { return now }))
+			if err != nil {
+				t.Fatalf("reload: %v\n%s", err, data)
+			}
+			all, _ := reloaded.(packsizes.History).Versions(context.Background())
+			if len(all) != 2 || all[0].Packs[1].SKU != "BOX-500" || all[1].Version != "v2" || !reflect.DeepEqual(all[1].Packs, packsizes.PlainPacks([]int{500})) {
+				t.Fatalf("Versions after reload: %+v", all)
+			}
+			if got := load(t, reloaded).Sizes; !reflect.DeepEqual(got, []int{500}) {
+				t.Fatalf("Sizes after the switch: %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package httpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// Defaults of Options.
+const (
+	DefaultTimeout      = 5 * time.Second
+	DefaultMaxAttempts  = 3
+	DefaultBackoff      = 200 * time.Millisecond
+	DefaultMaxBackoff   = 5 * time.Second
+	DefaultPollInterval = 30 * time.Second
+
+	maxBodySize = 1 << 20
+)
+
+var (
+	ErrInvalidURL       = errors.New("invalid pack sizes URL")
+	ErrUnexpectedStatus = errors.New("unexpected status from the pack sizes service")
+	ErrInvalidBody      = errors.New("invalid pack sizes document")
+	ErrStale            = errors.New("pack sizes list is stale")
+)
+
+// Options configures New; zero values take the defaults.
+// - Client: replaces the client built from Timeout and TLS (tests)
+// - Timeout: of each attempt (DefaultTimeout)
+// - MaxAttempts: tries of a fetch, the first one included (DefaultMaxAttempts)
+// - Backoff: wait before the first retry, doubled after each one up to
+// MaxBackoff (DefaultBackoff, DefaultMaxBackoff)
+// - PollInterval: refresh period of Run (DefaultPollInterval)
+// - MaxStale: Load fails once the last successful fetch is older (0: the
+// last list is served for as long as the service is down)
+// - Header: sent with every request (e.g. Authorization)
+// - Now, After: clock and timer, injectable for tests (nil: time.Now,
+// time.After)
+type Options struct {
+	Client       *http.Client
+	Timeout      time.Duration
+	MaxAttempts  int
+	Backoff      time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+	MaxStale     time.Duration
+	Header       http.Header
+	TLS          TLSOptions
+	Now          func() time.Time
+	After        func(time.Duration) <-chan time.Time
+}
+
+// Provider reads the pack sizes from a catalogue service: a JSON document
+// with the sizes, either {"sizes": [...]} (as served by GET /v1/packsizes)
+// or a bare list. The list is kept between fetches and revalidated with its
+// ETag, so an unchanged list costs a 304.
+type Provider struct {
+	url  string
+	opts Options
+
+	fetchMu sync.Mutex // one fetch at a time
+
+	mu        sync.RWMutex
+	sizes     []int // nil until the first successful fetch
+	version   packsizes.Version
+	etag      string
+	fetchedAt time.Time // last successful fetch (200 or 304)
+	lastErr   error     // error of the last fetch, nil after a success
+}
+
+// compile-time check
+var _ packsizes.Provider = (*Provider)(nil)
+
+// New checks rawURL and the TLS files; the service is not called until the
+// first Load (or Run).
+func New(rawURL string, opts Options) (*Provider, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidURL, rawURL)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.After == nil {
+		opts.After = time.After
+	}
+	if opts.Client == nil {
+		tlsConfig, err := opts.TLS.config()
+		if err != nil {
+			return nil, err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		opts.Client = &http.Client{Transport: transport}
+	}
+	return &Provider{url: u.String(), opts: opts}, nil
+}
+
+// Load returns the last fetched list; the first call fetches it, within
+// ctx. Its version is the ETag, without quotes (the list hash without one),
+// and its Last-Modified; LoadedAt is the last successful fetch.
+func (p *Provider) Load(ctx context.Context) (packsizes.Catalogue, error) {
+	if err := p.ensure(ctx); err != nil {
+		return packsizes.Catalogue{}, err
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return packsizes.Catalogue{
+		Sizes:    slices.Clone(p.sizes),
+		Version:  p.version,
+		Source:   "http",
+		LoadedAt: p.fetchedAt,
+	}, nil
+}
+
+// ensure fetches the list when there is none yet, and fails once it is
+// older than MaxStale. Later fetches are left to Run: a request never waits
+// for the retries of a service that is down, nor for a fetch already
+// running. Without a list to serve the error wraps
+// packsizes.ErrUnavailable (unless ctx is done).
+func (p *Provider) ensure(ctx context.Context) error {
+	p.mu.RLock()
+	loaded := p.sizes != nil
+	p.mu.RUnlock()
+	if !loaded {
+		if !p.fetchMu.TryLock() {
+			return fmt.Errorf("%w: the first fetch of %s is still running", packsizes.ErrUnavailable, p.url)
+		}
+		err := p.refresh(ctx)
+		p.fetchMu.Unlock()
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %w", packsizes.ErrUnavailable, err)
+		}
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.sizes == nil {
+		return fmt.Errorf("%w: %w", packsizes.ErrUnavailable, p.lastErr)
+	}
+	if age := p.opts.Now().Sub(p.fetchedAt); p.opts.MaxStale > 0 && age > p.opts.MaxStale {
+		err := fmt.Errorf("%w: %w: last fetched %s ago", packsizes.ErrUnavailable, ErrStale, age.Round(time.Second))
+		if p.lastErr != nil {
+			err = fmt.Errorf("%w: %w", err, p.lastErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Run refreshes the list every PollInterval until ctx is done; a failed
+// refresh keeps the previous list.
+func (p *Provider) Run(ctx context.Context) {
+	for {
+		_ = p.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.opts.After(p.opts.PollInterval):
+		}
+	}
+}
+
+// Refresh fetches the list now, retrying with backoff on network errors,
+// 429 and 5xx answers.
+func (p *Provider) Refresh(ctx context.Context) error {
+	p.fetchMu.Lock()
+	defer p.fetchMu.Unlock()
+	return p.refresh(ctx)
+}
+
+// refresh is Refresh with fetchMu held.
+func (p *Provider) refresh(ctx context.Context) error {
+	p.mu.RLock()
+	etag := p.etag
+	p.mu.RUnlock()
+
+	wait := p.opts.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		retry, err = p.fetch(ctx, etag)
+		if err == nil || !retry || attempt == p.opts.MaxAttempts || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			err = errors.Join(err, ctx.Err())
+		case <-p.opts.After(wait):
+			wait = min(2*wait, p.opts.MaxBackoff)
+			continue
+		}
+		break
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastErr = err
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", p.url, err)
+	}
+	return nil
+}
+
+// fetch does one attempt and stores the list; retry tells whether the error
+// may go away on its own.
+func (p *Provider) fetch(ctx context.Context, etag string) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return false, err
+	}
+	for k, v := range p.opts.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := p.opts.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && etag != "":
+		_, _ = io.Copy(io.Discard, resp.Body)
+		p.mu.Lock()
+		p.fetchedAt = p.opts.Now()
+		p.mu.Unlock()
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
+	if err != nil {
+		return true, err
+	}
+	if len(body) > maxBodySize {
+		return false, fmt.Errorf("%w: larger than %d bytes", ErrInvalidBody, maxBodySize)
+	}
+	sizes, err := ParseSizes(body)
+	if err != nil {
+		return false, err
+	}
+
+	version := packsizes.Version{ID: etagID(resp.Header.Get("ETag"))}
+	if version.ID == "" {
+		version.ID = packsizes.VersionOf(sizes)
+	}
+	if lm, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		version.UpdatedAt = lm.UTC()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sizes, p.version, p.etag, p.fetchedAt = sizes, version, resp.Header.Get("ETag"), p.opts.Now()
+	return false, nil
+}
+
+// etagID unquotes an ETag: W/"abc" and "abc" are both abc.
+func etagID(etag string) string {
+	return strings.Trim(strings.TrimPrefix(strings.TrimSpace(etag), "W/"), `"`)
+}
+
+// ParseSizes reads {"sizes": [...]} or a bare [...] list; the sizes are
+// normalised like the pack sizes file (> 0, sorted asc, unique).
+func ParseSizes(body []byte) ([]int, error) {
+	var sizes []int
+	trimmed := strings.TrimSpace(string(body))
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(body, &sizes); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBody, err)
+		}
+	} else {
+		var doc struct {
+			Sizes []int `json:"sizes"`
+		}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBody, err)
+		}
+		sizes = doc.Sizes
+	}
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("%w: no sizes", ErrInvalidBody)
+	}
+	for i, s := range sizes {
+		if s <= 0 {
+			return nil, fmt.Errorf("%w: sizes[%d] must be > 0, got %d", ErrInvalidBody, i, s)
+		}
+	}
+	slices.Sort(sizes)
+	return slices.Compact(sizes), nil
+}
@@ -0,0 +1,350 @@
+package httpadapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// immediate is an After that does not wait and records the waits.
+type immediate struct {
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (i *immediate) After(d time.Duration) <-chan time.Time {
+	i.mu.Lock()
+	i.waits = append(i.waits, d)
+	i.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- time.Time{}
+	return ch
+}
+
+// sizes loads the list of prov.
+func sizes(prov *Provider) ([]int, error) {
+	c, err := prov.Load(context.Background())
+	return c.Sizes, err
+}
+
+func TestNew_InvalidURL(t *testing.T) {
+	for _, u := range []string{"", "packs.json", "ftp://host/packs", "http://"} {
+		if _, err := New(u, Options{}); !errors.Is(err, ErrInvalidURL) {
+			t.Fatalf("%q: got %v want ErrInvalidURL", u, err)
+		}
+	}
+}
+
+func TestLoad_ETagRevalidation(t *testing.T) {
+	var requests atomic.Int32
+	var lastIfNoneMatch atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		lastIfNoneMatch.Store(r.Header.Get("If-None-Match"))
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `W/"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `W/"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Sep 2025 10:00:00 GMT")
+		_, _ = w.Write([]byte(`{"sizes":[500,250,1000],"packs":[]}`))
+	}))
+	defer srv.Close()
+
+	prov, err := New(srv.URL, Options{Header: http.Header{"Authorization": {"Bearer t0ken"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the first call fetches, the next ones use the kept list
+	for range 3 {
+		got, err := sizes(prov)
+		if err != nil || !reflect.DeepEqual(got, []int{250, 500, 1000}) {
+			t.Fatalf("Load got %v %v", got, err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("requests got %d want 1", n)
+	}
+	c, _ := prov.Load(context.Background())
+	if v := c.Version; v.ID != "v1" || !v.UpdatedAt.Equal(time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Version got %+v", v)
+	}
+	if c.Source != "http" || c.LoadedAt.IsZero() {
+		t.Fatalf("Load got %+v", c)
+	}
+
+	// a refresh revalidates: 304 keeps the list
+	if err := prov.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := lastIfNoneMatch.Load(); got != `W/"v1"` {
+		t.Fatalf("If-None-Match got %q", got)
+	}
+	if got, _ := sizes(prov); !reflect.DeepEqual(got, []int{250, 500, 1000}) {
+		t.Fatalf("Load after a 304 got %v", got)
+	}
+}
+
+func TestRefresh_Retries(t *testing.T) {
+	var requests, failures atomic.Int32
+	failures.Store(2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch failures.Add(-1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 0:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			if failures.Load() < -100 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte(`[250, 500]`))
+		}
+	}))
+	defer srv.Close()
+
+	timer := &immediate{}
+	prov, _ := New(srv.URL, Options{MaxAttempts: 4, Backoff: 100 * time.Millisecond, MaxBackoff: 150 * time.Millisecond, After: timer.After})
+	if err := prov.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Fatalf("requests got %d want 3", n)
+	}
+	if want := []time.Duration{100 * time.Millisecond, 150 * time.Millisecond}; !reflect.DeepEqual(timer.waits, want) {
+		t.Fatalf("backoff got %v want %v", timer.waits, want)
+	}
+
+	// gives up after MaxAttempts
+	requests.Store(0)
+	failures.Store(-1000)
+	if err := prov.Refresh(context.Background()); !errors.Is(err, ErrUnexpectedStatus) {
+		t.Fatalf("got %v want ErrUnexpectedStatus", err)
+	}
+	if n := requests.Load(); n != 4 {
+		t.Fatalf("requests got %d want 4 attempts", n)
+	}
+}
+
+func TestRefresh_NoRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{"not found", http.StatusNotFound, "", ErrUnexpectedStatus},
+		{"not json", http.StatusOK, "250,500", ErrInvalidBody},
+		{"no sizes", http.StatusOK, `{"sizes":[]}`, ErrInvalidBody},
+		{"size not positive", http.StatusOK, `[250, 0]`, ErrInvalidBody},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			prov, _ := New(srv.URL, Options{After: (&immediate{}).After})
+			if _, err := sizes(prov); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v want %v", err, tt.wantErr)
+			}
+			if n := requests.Load(); n != 1 {
+				t.Fatalf("requests got %d want 1", n)
+			}
+		})
+	}
+}
+
+func TestRefresh_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	prov, _ := New(srv.URL, Options{Timeout: 20 * time.Millisecond, MaxAttempts: 1})
+	start := time.Now()
+	if _, err := sizes(prov); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v want a deadline error", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("the timeout was not applied: %s", d)
+	}
+}
+
+func TestLoad_Canceled(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	// the first fetch runs within the context of the caller
+	prov, _ := New(srv.URL, Options{})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := prov.Load(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v want a deadline error", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("the context was not applied: %s", d)
+	}
+}
+
+func TestLoad_LastListAndMaxStale(t *testing.T) {
+	var down atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"sizes":[250]}`))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	prov, _ := New(srv.URL, Options{MaxStale: time.Minute, MaxAttempts: 1, Now: func() time.Time { return now }})
+	if _, err := sizes(prov); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a failed refresh keeps the list
+	down.Store(true)
+	if err := prov.Refresh(context.Background()); err == nil {
+		t.Fatalf("expected a refresh error")
+	}
+	now = now.Add(59 * time.Second)
+	if got, err := sizes(prov); err != nil || !reflect.DeepEqual(got, []int{250}) {
+		t.Fatalf("Load got %v %v", got, err)
+	}
+
+	// until it is older than MaxStale
+	now = now.Add(2 * time.Second)
+	_, err := sizes(prov)
+	if !errors.Is(err, ErrStale) || !errors.Is(err, ErrUnexpectedStatus) || !errors.Is(err, packsizes.ErrUnavailable) {
+		t.Fatalf("got %v want ErrStale (unavailable) with the refresh error", err)
+	}
+}
+
+func TestLoad_ColdStartUnavailable(t *testing.T) {
+	release, started := make(chan struct{}), make(chan struct{}, 1)
+	var down atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		started <- struct{}{}
+		<-release
+		_, _ = w.Write([]byte(`[250]`))
+	}))
+	defer srv.Close()
+
+	prov, _ := New(srv.URL, Options{MaxAttempts: 1})
+	done := make(chan error, 1)
+	go func() {
+		_, err := sizes(prov)
+		done <- err
+	}()
+	<-started
+
+	// another request does not queue behind the first fetch
+	if _, err := sizes(prov); !errors.Is(err, packsizes.ErrUnavailable) {
+		t.Fatalf("got %v want ErrUnavailable while the first fetch runs", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+
+	// a failed first fetch is unavailable too
+	cold, _ := New(srv.URL, Options{MaxAttempts: 1})
+	down.Store(true)
+	if _, err := sizes(cold); !errors.Is(err, packsizes.ErrUnavailable) || !errors.Is(err, ErrUnexpectedStatus) {
+		t.Fatalf("got %v want ErrUnavailable with the fetch error", err)
+	}
+}
+
+func TestRun_Polls(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			_, _ = w.Write([]byte(`[1000]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[250]`))
+	}))
+	defer srv.Close()
+
+	ticks := make(chan time.Time)
+	prov, _ := New(srv.URL, Options{PollInterval: time.Hour, After: func(d time.Duration) <-chan time.Time {
+		if d != time.Hour {
+			t.Errorf("wait got %s want the poll interval", d)
+		}
+		return ticks
+	}})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		prov.Run(ctx)
+		close(done)
+	}()
+
+	// each tick is taken once the previous poll is over
+	for range 3 {
+		ticks <- time.Time{}
+	}
+	if got, _ := sizes(prov); !reflect.DeepEqual(got, []int{1000}) {
+		t.Fatalf("Load after a poll got %v", got)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Run did not stop with its context")
+	}
+}
+
+func TestParseSizes(t *testing.T) {
+	got, err := ParseSizes([]byte(` {"sizes": [1000, 250, 250]} `))
+	if err != nil || !reflect.DeepEqual(got, []int{250, 1000}) {
+		t.Fatalf("got %v %v", got, err)
+	}
+	got, err = ParseSizes([]byte("\n[5, 1]"))
+	if err != nil || !reflect.DeepEqual(got, []int{1, 5}) {
+		t.Fatalf("got %v %v", got, err)
+	}
+	for _, in := range []string{"", "{}", `{"sizes": ["250"]}`, "[-1]", "null"} {
+		if _, err := ParseSizes([]byte(in)); !errors.Is(err, ErrInvalidBody) {
+			t.Fatalf("%q: got %v want ErrInvalidBody", in, err)
+		}
+	}
+}
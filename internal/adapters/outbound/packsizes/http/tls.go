@@ -0,0 +1,61 @@
+package httpadapter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrInvalidTLS is returned by New for TLS files it cannot use.
+var ErrInvalidTLS = errors.New("invalid pack sizes TLS options")
+
+// TLSOptions of the connection to the catalogue service (all optional).
+// - CAFile: PEM roots trusted instead of the system ones (e.g. a private CA)
+// - CertFile, KeyFile: PEM client certificate and key (mutual TLS); both or
+// none
+// - ServerName: expected name in the server certificate (default: the URL
+// host)
+// - InsecureSkipVerify: accept any server certificate (local tests only)
+type TLSOptions struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// config builds the client TLS config; nil keeps the Go defaults.
+func (o TLSOptions) config() (*tls.Config, error) {
+	if o == (TLSOptions{}) {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTLS, err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no PEM certificate in %q", ErrInvalidTLS, o.CAFile)
+		}
+		cfg.RootCAs = roots
+	}
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return nil, fmt.Errorf("%w: a client certificate needs both the cert and the key files", ErrInvalidTLS)
+	}
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTLS, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
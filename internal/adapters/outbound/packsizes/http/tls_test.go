@@ -0,0 +1,125 @@
+package httpadapter
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// clientCert writes a self-signed client certificate and its key to dir.
+func clientCert(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "shipping-products"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	if cert, err = x509.ParseCertificate(der); err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile, cert
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestTLS_MutualAuth(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, cert := clientCert(t, dir)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"sizes":[250,500]}`))
+	}))
+	clients := x509.NewCertPool()
+	clients.AddCert(cert)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clients}
+	srv.StartTLS()
+	defer srv.Close()
+
+	// the server certificate is trusted through CAFile
+	caFile := filepath.Join(dir, "ca.crt")
+	writePEM(t, caFile, "CERTIFICATE", srv.Certificate().Raw)
+
+	prov, err := New(srv.URL, Options{MaxAttempts: 1, TLS: TLSOptions{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := sizes(prov); err != nil || !reflect.DeepEqual(got, []int{250, 500}) {
+		t.Fatalf("Load got %v %v", got, err)
+	}
+
+	// without the client certificate the handshake fails
+	prov, _ = New(srv.URL, Options{MaxAttempts: 1, TLS: TLSOptions{CAFile: caFile}})
+	if _, err := sizes(prov); err == nil {
+		t.Fatalf("expected a TLS error without the client certificate")
+	}
+	// and with the system roots the server is not trusted
+	prov, _ = New(srv.URL, Options{MaxAttempts: 1})
+	if _, err := sizes(prov); err == nil {
+		t.Fatalf("expected a TLS error for an unknown CA")
+	}
+}
+
+func TestTLSOptions_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, _ := clientCert(t, dir)
+	notPEM := filepath.Join(dir, "ca.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	tests := []struct {
+		name string
+		opts TLSOptions
+	}{
+		{"missing CA", TLSOptions{CAFile: filepath.Join(dir, "missing.crt")}},
+		{"CA without PEM", TLSOptions{CAFile: notPEM}},
+		{"cert without key", TLSOptions{CertFile: certFile}},
+		{"key of another cert", TLSOptions{CertFile: certFile, KeyFile: notPEM}},
+	}
+	for _, tt := range tests {
+		if _, err := New("https://catalogue.internal/packs", Options{TLS: tt.opts}); !errors.Is(err, ErrInvalidTLS) {
+			t.Fatalf("%s: got %v want ErrInvalidTLS", tt.name, err)
+		}
+	}
+	if _, err := New("https://catalogue.internal/packs", Options{TLS: TLSOptions{CertFile: certFile, KeyFile: keyFile}}); err != nil {
+		t.Fatalf("valid pair: %v", err)
+	}
+}
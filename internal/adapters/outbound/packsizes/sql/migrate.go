@@ -0,0 +1,72 @@
+package sqladapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrations is the schema history, applied in order and once each
+// (recorded in schema_migrations); append only. The SQL sticks to what
+// SQLite, MySQL and PostgreSQL share.
+var migrations = []string{
+	// 1: one row per pack size and warehouse ("" is the default one)
+	`CREATE TABLE pack_sizes (
+		warehouse  VARCHAR(64)  NOT NULL DEFAULT '',
+		size       INTEGER      NOT NULL CHECK (size > 0),
+		enabled    BOOLEAN      NOT NULL DEFAULT TRUE,
+		label      VARCHAR(255) NOT NULL DEFAULT '',
+		sku        VARCHAR(64)  NOT NULL DEFAULT '',
+		updated_at VARCHAR(40)  NOT NULL,
+		PRIMARY KEY (warehouse, size)
+	)`,
+}
+
+// Migrate brings the schema of db up to date; it is safe to run on every
+// start.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER     NOT NULL PRIMARY KEY,
+		applied_at VARCHAR(40) NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	current, err := SchemaVersion(ctx, db)
+	if err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	for v := current + 1; v <= len(migrations); v++ {
+		if err := apply(ctx, db, v); err != nil {
+			return fmt.Errorf("migration %d: %w", v, err)
+		}
+	}
+	return nil
+}
+
+// apply runs migration v and records it in the same transaction.
+func apply(ctx context.Context, db *sql.DB, v int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, migrations[v-1]); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+		v, time.Now().UTC().Format(time.RFC3339Nano)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SchemaVersion is the last migration applied to db (0 for none); db must
+// have the schema_migrations table, i.e. have been through Migrate once.
+func SchemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var current sql.NullInt64
+	err := db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&current)
+	return int(current.Int64), err
+}
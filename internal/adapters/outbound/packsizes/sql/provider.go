@@ -0,0 +1,256 @@
+package sqladapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// DefaultTimeout bounds each call to the database.
+const DefaultTimeout = 5 * time.Second
+
+var (
+	ErrNilDB           = errors.New("nil *sql.DB")
+	ErrNoPackSizes     = errors.New("no enabled pack sizes in the warehouse")
+	ErrInvalidPackSize = errors.New("pack size must be > 0")
+)
+
+// Options configures New.
+// - Warehouse: the rows served ("" is the default warehouse)
+// - Timeout: of each call to the database (DefaultTimeout)
+// - Now: clock of updated_at (nil: time.Now)
+type Options struct {
+	Warehouse string
+	Timeout   time.Duration
+	Now       func() time.Time
+}
+
+// Provider serves the pack sizes of one warehouse from the pack_sizes
+// table (see Migrate). Every call reads the table, so a change made by
+// another instance is seen right away.
+type Provider struct {
+	db        *sql.DB
+	warehouse string
+	timeout   time.Duration
+	now       func() time.Time
+}
+
+// compile-time checks
+var (
+	_ packsizes.Provider = (*Provider)(nil)
+	_ packsizes.Toggler  = (*Provider)(nil)
+)
+
+// New migrates db (see Migrate) and returns the Provider of a warehouse.
+// The queries use ? placeholders (SQLite, MySQL).
+func New(db *sql.DB, opts Options) (*Provider, error) {
+	if db == nil {
+		return nil, ErrNilDB
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	p := &Provider{db: db, warehouse: opts.Warehouse, timeout: opts.Timeout, now: opts.Now}
+
+	ctx, cancel := p.context(context.Background())
+	defer cancel()
+	if err := Migrate(ctx, db); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// context bounds a call to the database by Timeout, within ctx.
+func (p *Provider) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, p.timeout)
+}
+
+// Load returns the packs of the warehouse: the enabled sizes, and a version
+// hashed from them with the last updated_at of the warehouse. When a pack is
+// disabled or has a label or SKU, the version also hashes every pack, so a
+// change of metadata changes it (and the ETag) too.
+func (p *Provider) Load(ctx context.Context) (packsizes.Catalogue, error) {
+	packs, updatedAt, err := p.packs(ctx)
+	if err != nil {
+		return packsizes.Catalogue{}, err
+	}
+	sizes := packsizes.EnabledSizes(packs)
+	if len(sizes) == 0 {
+		return packsizes.Catalogue{}, fmt.Errorf("%w %q", ErrNoPackSizes, p.warehouse)
+	}
+	return packsizes.Catalogue{
+		Sizes:    sizes,
+		Version:  packsizes.Version{ID: versionOf(sizes, packs), UpdatedAt: updatedAt},
+		Source:   "sql",
+		LoadedAt: p.now(),
+		Packs:    packs,
+	}, nil
+}
+
+// versionOf is packsizes.VersionOf(sizes) for plain packs, followed by
+// "+" and a hash of every pack otherwise.
+func versionOf(sizes []int, packs []packsizes.Pack) string {
+	id := packsizes.VersionOf(sizes)
+	if slices.Equal(packs, packsizes.PlainPacks(sizes)) {
+		return id
+	}
+	h := sha256.New()
+	for _, pk := range packs {
+		fmt.Fprintf(h, "%d,%t,%q,%q\n", pk.Size, pk.Enabled, pk.Label, pk.SKU)
+	}
+	return id + "+" + hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// packs lists every pack of the warehouse, disabled ones included, by size
+// asc, and the last updated_at.
+func (p *Provider) packs(ctx context.Context) ([]packsizes.Pack, time.Time, error) {
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT size, enabled, label, sku, updated_at FROM pack_sizes WHERE warehouse = ? ORDER BY size`, p.warehouse)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("query pack_sizes: %w", err)
+	}
+	defer rows.Close()
+
+	var packs []packsizes.Pack
+	var last time.Time
+	for rows.Next() {
+		var pk packsizes.Pack
+		var updated string
+		if err := rows.Scan(&pk.Size, &pk.Enabled, &pk.Label, &pk.SKU, &updated); err != nil {
+			return nil, time.Time{}, fmt.Errorf("scan pack_sizes: %w", err)
+		}
+		at, err := time.Parse(time.RFC3339Nano, updated)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("pack size %d: updated_at %q: %w", pk.Size, updated, err)
+		}
+		if at.After(last) {
+			last = at
+		}
+		packs = append(packs, pk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("query pack_sizes: %w", err)
+	}
+	return packs, last, nil
+}
+
+// Save inserts the packs in the warehouse, or updates the ones it has
+// (enabled, label and SKU), in one transaction.
+func (p *Provider) Save(ctx context.Context, packs ...packsizes.Pack) error {
+	for _, pk := range packs {
+		if pk.Size <= 0 {
+			return fmt.Errorf("%w, got %d", ErrInvalidPackSize, pk.Size)
+		}
+	}
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		now := p.stamp()
+		for _, pk := range packs {
+			// no upsert: its syntax differs between databases
+			var n int
+			if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM pack_sizes WHERE warehouse = ? AND size = ?`,
+				p.warehouse, pk.Size).Scan(&n); err != nil {
+				return fmt.Errorf("read pack size %d: %w", pk.Size, err)
+			}
+			query := `INSERT INTO pack_sizes (enabled, label, sku, updated_at, warehouse, size) VALUES (?, ?, ?, ?, ?, ?)`
+			if n > 0 {
+				query = `UPDATE pack_sizes SET enabled = ?, label = ?, sku = ?, updated_at = ? WHERE warehouse = ? AND size = ?`
+			}
+			if _, err := tx.ExecContext(ctx, query, pk.Enabled, pk.Label, pk.SKU, now, p.warehouse, pk.Size); err != nil {
+				return fmt.Errorf("save pack size %d: %w", pk.Size, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes a size from the warehouse; ErrUnknownPackSize when it has
+// none.
+func (p *Provider) Delete(ctx context.Context, size int) error {
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+	res, err := p.db.ExecContext(ctx, `DELETE FROM pack_sizes WHERE warehouse = ? AND size = ?`, p.warehouse, size)
+	if err != nil {
+		return fmt.Errorf("delete pack size %d: %w", size, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %d", packsizes.ErrUnknownPackSize, size)
+	}
+	return err
+}
+
+// Seed saves sizes, enabled, when the warehouse has no pack yet (a fresh
+// database); it tells whether it did.
+func (p *Provider) Seed(ctx context.Context, sizes []int) (bool, error) {
+	packs, _, err := p.packs(ctx)
+	if err != nil || len(packs) > 0 {
+		return false, err
+	}
+	if err := p.Save(ctx, packsizes.PlainPacks(sizes)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetEnabled changes a size of the warehouse and checks, in the same
+// transaction, that one size is still enabled.
+func (p *Provider) SetEnabled(ctx context.Context, size int, enabled bool) (packsizes.Pack, error) {
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+	var out packsizes.Pack
+	err := p.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE pack_sizes SET enabled = ?, updated_at = ? WHERE warehouse = ? AND size = ?`,
+			enabled, p.stamp(), p.warehouse, size)
+		if err != nil {
+			return fmt.Errorf("update pack size %d: %w", size, err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("%w: %d", packsizes.ErrUnknownPackSize, size)
+		}
+
+		var left int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM pack_sizes WHERE warehouse = ? AND enabled = ?`,
+			p.warehouse, true).Scan(&left); err != nil {
+			return fmt.Errorf("count enabled pack sizes: %w", err)
+		}
+		if left == 0 {
+			return fmt.Errorf("%w: %d", packsizes.ErrLastEnabledPack, size)
+		}
+
+		return tx.QueryRowContext(ctx, `SELECT size, enabled, label, sku FROM pack_sizes WHERE warehouse = ? AND size = ?`,
+			p.warehouse, size).Scan(&out.Size, &out.Enabled, &out.Label, &out.SKU)
+	})
+	return out, err
+}
+
+// inTx runs fn in a transaction, committed when fn succeeds.
+func (p *Provider) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Provider) stamp() string {
+	return p.now().UTC().Format(time.RFC3339Nano)
+}
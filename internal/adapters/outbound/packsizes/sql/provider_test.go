@@ -0,0 +1,155 @@
+package sqladapter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+	_ "modernc.org/sqlite"
+)
+
+// openDB opens an empty SQLite database in a temporary file.
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "packs.db")+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// sizes lists the enabled sizes of prov, nil on error.
+func sizes(ctx context.Context, prov *Provider) []int {
+	c, _ := prov.Load(ctx)
+	return c.Sizes
+}
+
+func TestMigrate(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	for range 2 { // idempotent
+		if err := Migrate(ctx, db); err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+	}
+	if v, err := SchemaVersion(ctx, db); err != nil || v != len(migrations) {
+		t.Fatalf("SchemaVersion got %d %v want %d", v, err, len(migrations))
+	}
+	if _, err := db.Exec(`INSERT INTO pack_sizes (size, updated_at) VALUES (0, '')`); err == nil {
+		t.Fatalf("the table must reject a size <= 0")
+	}
+}
+
+func TestProvider_ReadWrite(t *testing.T) {
+	db := openDB(t)
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	prov, err := New(db, Options{Warehouse: "lisbon", Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := prov.Load(ctx); !errors.Is(err, ErrNoPackSizes) {
+		t.Fatalf("empty warehouse: got %v want ErrNoPackSizes", err)
+	}
+	if seeded, err := prov.Seed(ctx, []int{250, 500, 1000}); !seeded || err != nil {
+		t.Fatalf("Seed got %v %v", seeded, err)
+	}
+	if seeded, _ := prov.Seed(ctx, []int{1}); seeded {
+		t.Fatalf("Seed must leave a warehouse with packs alone")
+	}
+
+	now = now.Add(time.Hour)
+	if err := prov.Save(ctx, packsizes.Pack{Size: 500, Label: "Medium box", SKU: "BOX-500", Enabled: true}, packsizes.Pack{Size: 2000}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got := sizes(ctx, prov); !reflect.DeepEqual(got, []int{250, 500, 1000}) {
+		t.Fatalf("Sizes got %v: a disabled size must not be listed", got)
+	}
+	c, _ := prov.Load(ctx)
+	packs := c.Packs
+	if len(packs) != 4 || packs[1] != (packsizes.Pack{Size: 500, Label: "Medium box", SKU: "BOX-500", Enabled: true}) || packs[3].Enabled {
+		t.Fatalf("Packs got %+v", packs)
+	}
+	// the metadata and the disabled size are in the version
+	if v := c.Version; !strings.HasPrefix(v.ID, packsizes.VersionOf([]int{250, 500, 1000})+"+") || !v.UpdatedAt.Equal(now) {
+		t.Fatalf("Version got %+v", v)
+	}
+	if err := prov.Save(ctx, packsizes.Pack{Size: 500, Label: "Medium carton", SKU: "BOX-500", Enabled: true}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if relabeled, _ := prov.Load(ctx); relabeled.Version.ID == c.Version.ID {
+		t.Fatalf("the version must change with a label")
+	}
+	if c.Source != "sql" || !c.LoadedAt.Equal(now) {
+		t.Fatalf("Load got %+v", c)
+	}
+
+	if err := prov.Delete(ctx, 1000); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := prov.Delete(ctx, 1000); !errors.Is(err, packsizes.ErrUnknownPackSize) {
+		t.Fatalf("Delete twice: got %v", err)
+	}
+	if err := prov.Save(ctx, packsizes.Pack{Size: 0}); !errors.Is(err, ErrInvalidPackSize) {
+		t.Fatalf("Save 0: got %v", err)
+	}
+
+	// warehouses are apart
+	other, _ := New(db, Options{Warehouse: "porto"})
+	if _, err := other.Load(ctx); !errors.Is(err, ErrNoPackSizes) {
+		t.Fatalf("other warehouse: got %v", err)
+	}
+	if got := sizes(ctx, prov); !reflect.DeepEqual(got, []int{250, 500}) {
+		t.Fatalf("Sizes got %v", got)
+	}
+}
+
+func TestProvider_SetEnabled(t *testing.T) {
+	prov, err := New(openDB(t), Options{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := prov.Seed(ctx, []int{250, 500}); err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+
+	before, _ := prov.Load(ctx)
+	pk, err := prov.SetEnabled(ctx, 250, false)
+	if err != nil || pk != (packsizes.Pack{Size: 250}) {
+		t.Fatalf("SetEnabled got %+v %v", pk, err)
+	}
+	if got := sizes(ctx, prov); !reflect.DeepEqual(got, []int{500}) {
+		t.Fatalf("Sizes got %v", got)
+	}
+	if after, _ := prov.Load(ctx); after.Version.ID == before.Version.ID {
+		t.Fatalf("the version must change with the list")
+	}
+
+	if _, err := prov.SetEnabled(ctx, 500, false); !errors.Is(err, packsizes.ErrLastEnabledPack) {
+		t.Fatalf("last size: got %v", err)
+	}
+	if got := sizes(ctx, prov); !reflect.DeepEqual(got, []int{500}) {
+		t.Fatalf("a refused change must be rolled back, Sizes got %v", got)
+	}
+	if _, err := prov.SetEnabled(ctx, 750, true); !errors.Is(err, packsizes.ErrUnknownPackSize) {
+		t.Fatalf("unknown size: got %v", err)
+	}
+	if pk, err := prov.SetEnabled(ctx, 250, true); err != nil || !pk.Enabled {
+		t.Fatalf("enable got %+v %v", pk, err)
+	}
+}
+
+func TestNew_NilDB(t *testing.T) {
+	if _, err := New(nil, Options{}); !errors.Is(err, ErrNilDB) {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package static
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// DefaultSizes are the built-in pack sizes, the last resort of a fallback
+// chain.
+var DefaultSizes = []int{250, 500, 1000, 2000, 5000}
+
+var (
+	ErrNoSizes         = errors.New("no pack sizes")
+	ErrInvalidPackSize = errors.New("pack size must be > 0")
+)
+
+type provider struct {
+	sizes    []int // sorted asc, unique
+	loadedAt time.Time
+}
+
+// compile-time check
+var _ packsizes.Provider = (*provider)(nil)
+
+// New creates a Provider of a fixed list, normalised like the pack sizes
+// file: sorted asc, without duplicates.
+func New(sizes []int) (packsizes.Provider, error) {
+	if len(sizes) == 0 {
+		return nil, ErrNoSizes
+	}
+	out := slices.Clone(sizes)
+	for _, s := range out {
+		if s <= 0 {
+			return nil, ErrInvalidPackSize
+		}
+	}
+	slices.Sort(out)
+	return &provider{sizes: slices.Compact(out), loadedAt: time.Now()}, nil
+}
+
+func (p *provider) Load(context.Context) (packsizes.Catalogue, error) {
+	return packsizes.Catalogue{
+		Sizes:    slices.Clone(p.sizes),
+		Version:  packsizes.Version{ID: packsizes.VersionOf(p.sizes)},
+		Source:   "static",
+		LoadedAt: p.loadedAt,
+	}, nil
+}
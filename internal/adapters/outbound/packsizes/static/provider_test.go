@@ -0,0 +1,31 @@
+package static
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	in := []int{1000, 250, 500, 250}
+	prov, err := New(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := prov.Load(context.Background())
+	if !reflect.DeepEqual(got.Sizes, []int{250, 500, 1000}) || got.Source != "static" {
+		t.Fatalf("got %+v", got)
+	}
+	got.Sizes[0] = 1
+	if again, _ := prov.Load(context.Background()); again.Sizes[0] != 250 || in[0] != 1000 {
+		t.Fatalf("the list is shared: %v %v", again, in)
+	}
+
+	if _, err := New(nil); !errors.Is(err, ErrNoSizes) {
+		t.Fatalf("empty: got %v", err)
+	}
+	if _, err := New([]int{250, 0}); !errors.Is(err, ErrInvalidPackSize) {
+		t.Fatalf("zero: got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/events"
+)
+
+const logFile = "deliveries.jsonl"
+
+// logEntry is a line of deliveries.jsonl.
+type logEntry struct {
+	EventID       string    `json:"eventId"`
+	EventType     string    `json:"eventType"`
+	Endpoint      string    `json:"endpoint"`
+	Attempt       int       `json:"attempt"`
+	At            time.Time `json:"at"`
+	DurationMs    int64     `json:"durationMs"`
+	Status        string    `json:"status"`
+	StatusCode    int       `json:"statusCode,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	NextAttemptAt time.Time `json:"nextAttemptAt,omitzero"`
+}
+
+// deliveryLog keeps the last attempts in memory and appends each one to
+// deliveries.jsonl, so the log survives restarts. The file is rewritten with
+// the kept attempts at start and once it holds twice as many.
+type deliveryLog struct {
+	path string
+	max  int
+
+	mu      sync.Mutex
+	entries []events.Delivery // oldest first
+	lines   int               // in the file
+}
+
+func openDeliveryLog(dir string, keep int) (*deliveryLog, error) {
+	l := &deliveryLog{path: filepath.Join(dir, logFile), max: keep}
+	data, err := os.ReadFile(l.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var e logEntry
+		if json.Unmarshal(sc.Bytes(), &e) != nil {
+			continue // a line cut by a crash
+		}
+		l.entries = append(l.entries, e.delivery())
+	}
+	l.trim()
+	if err := l.compact(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// add records d; the log is in memory first, a failed write only loses the
+// attempt after a restart.
+func (l *deliveryLog) add(d events.Delivery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, d)
+	l.trim()
+	if l.lines+1 >= 2*l.max {
+		_ = l.compact()
+		return
+	}
+	line, err := json.Marshal(newLogEntry(d))
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err == nil {
+		l.lines++
+	}
+}
+
+// list returns the attempts matching f, most recent first.
+func (l *deliveryLog) list(f events.DeliveryFilter) []events.Delivery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := []events.Delivery{}
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		d := l.entries[i]
+		if (f.EventID != "" && d.EventID != f.EventID) || (f.Status != "" && d.Status != f.Status) {
+			continue
+		}
+		out = append(out, d)
+		if f.Limit > 0 && len(out) == f.Limit {
+			break
+		}
+	}
+	return out
+}
+
+func (l *deliveryLog) trim() {
+	if n := len(l.entries) - l.max; n > 0 {
+		l.entries = append([]events.Delivery(nil), l.entries[n:]...)
+	}
+}
+
+// compact rewrites the file with the kept attempts.
+func (l *deliveryLog) compact() error {
+	var buf bytes.Buffer
+	for _, d := range l.entries {
+		line, err := json.Marshal(newLogEntry(d))
+		if err != nil {
+			return fmt.Errorf("webhook: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if err := writeFile(filepath.Dir(l.path), l.path, buf.Bytes()); err != nil {
+		return err
+	}
+	l.lines = len(l.entries)
+	return nil
+}
+
+func newLogEntry(d events.Delivery) logEntry {
+	return logEntry{
+		EventID:       d.EventID,
+		EventType:     d.EventType,
+		Endpoint:      d.Endpoint,
+		Attempt:       d.Attempt,
+		At:            d.At.UTC(),
+		DurationMs:    d.Duration.Milliseconds(),
+		Status:        d.Status,
+		StatusCode:    d.StatusCode,
+		Error:         d.Error,
+		NextAttemptAt: d.NextAttemptAt.UTC(),
+	}
+}
+
+func (e logEntry) delivery() events.Delivery {
+	return events.Delivery{
+		EventID:       e.EventID,
+		EventType:     e.EventType,
+		Endpoint:      e.Endpoint,
+		Attempt:       e.Attempt,
+		At:            e.At,
+		Duration:      time.Duration(e.DurationMs) * time.Millisecond,
+		Status:        e.Status,
+		StatusCode:    e.StatusCode,
+		Error:         e.Error,
+		NextAttemptAt: e.NextAttemptAt,
+	}
+}
@@ -0,0 +1,456 @@
+// Package webhook delivers the service events to HTTP endpoints: a signed
+// JSON POST per event and endpoint, retried with backoff from an outbox
+// kept on disk.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/events"
+)
+
+// Defaults of Options.
+const (
+	DefaultTimeout     = 10 * time.Second
+	DefaultMaxAttempts = 8
+	DefaultBackoff     = time.Second
+	DefaultMaxBackoff  = 10 * time.Minute
+	DefaultLogEntries  = 1000
+)
+
+// Headers of a delivery.
+const (
+	HeaderID        = "X-Webhook-Id"        // the event ID, the same on every retry
+	HeaderEvent     = "X-Webhook-Event"     // the event type
+	HeaderTimestamp = "X-Webhook-Timestamp" // unix seconds of the attempt
+	HeaderSignature = "X-Webhook-Signature" // see Sign
+)
+
+var (
+	ErrNoEndpoint      = errors.New("webhook: no endpoint")
+	ErrInvalidURL      = errors.New("webhook: invalid endpoint URL")
+	ErrEmptySecret     = errors.New("webhook: empty secret")
+	ErrRefused         = errors.New("webhook: delivery refused")
+	ErrUnknownEndpoint = errors.New("webhook: endpoint no longer configured")
+)
+
+// Endpoint is a receiver of the events.
+// - URL: http(s), the events are POSTed to it
+// - Secret: key of the HMAC signature (see Sign)
+type Endpoint struct {
+	URL    string
+	Secret string
+}
+
+// Options configures New; zero values take the defaults.
+// - Client: replaces the default client (tests)
+// - Timeout: of each attempt (DefaultTimeout)
+// - MaxAttempts: tries of a delivery, the first one included
+// (DefaultMaxAttempts)
+// - Backoff: wait before the first retry, doubled after each one up to
+// MaxBackoff (DefaultBackoff, DefaultMaxBackoff)
+// - LogEntries: attempts kept by the delivery log (DefaultLogEntries)
+// - OnDelivery: called after each attempt (e.g. metrics)
+// - Now, After: clock and timer, injectable for tests (nil: time.Now,
+// time.After)
+type Options struct {
+	Client      *http.Client
+	Timeout     time.Duration
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	LogEntries  int
+	OnDelivery  func(events.Delivery)
+	Now         func() time.Time
+	After       func(time.Duration) <-chan time.Time
+}
+
+// Notifier publishes the events to the endpoints. Publish stores the event
+// with its list in the outbox directory, in one write, then one message per
+// endpoint; Run delivers them, so an event published before a restart is
+// delivered after it, with the same ID. Each endpoint is served on its own,
+// in publish order: a slow or failing receiver delays its own events only.
+// A receiver gets each event at least once: it drops the redelivered ones by
+// their ID.
+type Notifier struct {
+	endpoints map[string]Endpoint // by URL
+	urls      []string            // the keys of endpoints, sorted
+	opts      Options
+	outbox    *outbox
+	log       *deliveryLog
+	wake      map[string]chan struct{} // by URL
+
+	mu  sync.Mutex // Seq, and one split of the published events at a time
+	seq int64      // of the last event published
+}
+
+// compile-time checks
+var (
+	_ events.Publisher   = (*Notifier)(nil)
+	_ events.DeliveryLog = (*Notifier)(nil)
+)
+
+// New checks the endpoints and opens the outbox and the delivery log in
+// dir, created if needed.
+func New(dir string, endpoints []Endpoint, opts Options) (*Notifier, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoint
+	}
+	byURL := make(map[string]Endpoint, len(endpoints))
+	for _, e := range endpoints {
+		u, err := url.Parse(strings.TrimSpace(e.URL))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidURL, redact(e.URL))
+		}
+		if e.Secret == "" {
+			return nil, fmt.Errorf("%w for %s", ErrEmptySecret, redact(e.URL))
+		}
+		e.URL = u.String()
+		byURL[e.URL] = e
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.LogEntries <= 0 {
+		opts.LogEntries = DefaultLogEntries
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.After == nil {
+		opts.After = time.After
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{}
+	}
+
+	box, err := openOutbox(dir)
+	if err != nil {
+		return nil, err
+	}
+	log, err := openDeliveryLog(dir, opts.LogEntries)
+	if err != nil {
+		return nil, err
+	}
+	n := &Notifier{endpoints: byURL, opts: opts, outbox: box, log: log, wake: make(map[string]chan struct{}, len(byURL))}
+	for u := range byURL {
+		n.urls = append(n.urls, u)
+		n.wake[u] = make(chan struct{}, 1)
+	}
+	slices.Sort(n.urls)
+
+	// the next events come after the stored ones
+	messages, err := box.list()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range messages {
+		n.seq = max(n.seq, m.Seq)
+	}
+	pending, err := box.published()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range pending {
+		n.seq = max(n.seq, e.Seq)
+	}
+	return n, nil
+}
+
+// Publish stores e, and e.Current as the last published list (see Last),
+// then a message of e for every endpoint; Run delivers them. Once e is
+// stored Publish succeeds: a failure to write the messages is retried by Run.
+func (n *Notifier) Publish(_ context.Context, e events.PackSizesChanged) error {
+	body, err := json.Marshal(newPayload(e))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	n.mu.Lock()
+	n.seq = max(n.opts.Now().UnixNano(), n.seq+1)
+	err = n.outbox.publish(published{
+		EventID: e.ID, EventType: events.TypePackSizesChanged, Body: body, Seq: n.seq, Current: packList(e.Current),
+	})
+	if err == nil {
+		_ = n.splitLocked()
+	}
+	n.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, wake := range n.wake {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// splitLocked turns the published events into messages, n.mu held.
+func (n *Notifier) splitLocked() error {
+	pending, err := n.outbox.published()
+	if err != nil {
+		return err
+	}
+	for _, e := range pending {
+		if err := n.outbox.split(e, n.urls); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Last returns the list of the last published event, nil before the first
+// one. It is the starting point of the change detection after a restart.
+func (n *Notifier) Last() (*events.PackList, error) {
+	return n.outbox.last()
+}
+
+// Deliveries lists the attempts kept by the delivery log.
+func (n *Notifier) Deliveries(_ context.Context, f events.DeliveryFilter) ([]events.Delivery, error) {
+	return n.log.list(f), nil
+}
+
+// Run delivers the messages until ctx is done: one loop per endpoint, which
+// delivers its due messages, then waits for the next one to be due or for a
+// Publish. The messages of an endpoint no longer configured fail first.
+func (n *Notifier) Run(ctx context.Context) {
+	n.deliverMatching(ctx, func(u string) bool { _, ok := n.endpoints[u]; return !ok })
+
+	var wg sync.WaitGroup
+	for _, u := range n.urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n.runEndpoint(ctx, u)
+		}()
+	}
+	wg.Wait()
+}
+
+func (n *Notifier) runEndpoint(ctx context.Context, url string) {
+	for {
+		next, ok := n.deliverMatching(ctx, func(u string) bool { return u == url })
+		var timer <-chan time.Time
+		if ok {
+			timer = n.opts.After(max(next.Sub(n.opts.Now()), 0))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-n.wake[url]:
+		case <-timer:
+		}
+	}
+}
+
+// deliverMatching attempts the due messages of the endpoints matching, each
+// one in publish order, and returns when the next pending one is due (ok
+// false without any).
+func (n *Notifier) deliverMatching(ctx context.Context, match func(url string) bool) (next time.Time, ok bool) {
+	n.mu.Lock()
+	err := n.splitLocked()
+	n.mu.Unlock()
+	if err != nil {
+		// retried at the next wake up
+		return n.opts.Now().Add(n.opts.Backoff), true
+	}
+	messages, err := n.outbox.list()
+	if err != nil {
+		return n.opts.Now().Add(n.opts.Backoff), true
+	}
+	for i := 0; i < len(messages); {
+		j := i + 1
+		for j < len(messages) && messages[j].URL == messages[i].URL {
+			j++
+		}
+		if match(messages[i].URL) {
+			if at, pending := n.deliverQueue(ctx, messages[i:j]); pending && (!ok || at.Before(next)) {
+				next, ok = at, true
+			}
+		}
+		i = j
+	}
+	return next, ok
+}
+
+// deliverQueue attempts the messages of one endpoint in order, up to the
+// first one still pending: the next ones wait for it.
+func (n *Notifier) deliverQueue(ctx context.Context, queue []message) (next time.Time, pending bool) {
+	for _, m := range queue {
+		if ctx.Err() != nil {
+			return time.Time{}, false
+		}
+		if m.NextAttemptAt.After(n.opts.Now()) {
+			return m.NextAttemptAt, true
+		}
+		if m, pending := n.deliver(ctx, m); pending {
+			return m.NextAttemptAt, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// deliver attempts m once, logs the attempt and updates the outbox; pending
+// tells whether m is retried.
+func (n *Notifier) deliver(ctx context.Context, m message) (message, bool) {
+	m.Attempt++
+	d := events.Delivery{EventID: m.EventID, EventType: m.EventType, Endpoint: redact(m.URL), Attempt: m.Attempt}
+
+	start := n.opts.Now()
+	retry, err := n.send(ctx, m, &d)
+	d.At, d.Duration = start, n.opts.Now().Sub(start)
+	if ctx.Err() != nil {
+		// shutting down: the message is attempted again after the restart
+		return m, false
+	}
+
+	pending := false
+	switch {
+	case err == nil:
+		d.Status = events.DeliveryDelivered
+	case retry && m.Attempt < n.opts.MaxAttempts:
+		d.Status, d.Error = events.DeliveryRetrying, err.Error()
+		m.NextAttemptAt = n.opts.Now().Add(n.backoff(m.Attempt))
+		d.NextAttemptAt = m.NextAttemptAt
+		pending = true
+	default:
+		d.Status, d.Error = events.DeliveryFailed, err.Error()
+	}
+
+	if pending {
+		if err := n.outbox.put(m); err != nil {
+			d.Error += "; " + err.Error()
+		}
+	} else {
+		_ = n.outbox.remove(m)
+	}
+	n.log.add(d)
+	if n.opts.OnDelivery != nil {
+		n.opts.OnDelivery(d)
+	}
+	return m, pending
+}
+
+// send POSTs m; retry tells whether the error may go away on its own:
+// network errors, 408, 429 and 5xx answers.
+func (n *Notifier) send(ctx context.Context, m message, d *events.Delivery) (retry bool, err error) {
+	endpoint, ok := n.endpoints[m.URL]
+	if !ok {
+		return false, ErrUnknownEndpoint
+	}
+	ctx, cancel := context.WithTimeout(ctx, n.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.URL, bytes.NewReader(m.Body))
+	if err != nil {
+		return false, err
+	}
+	ts := n.opts.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, m.EventID)
+	req.Header.Set(HeaderEvent, m.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, ts, m.Body))
+
+	resp, err := n.opts.Client.Do(req)
+	if err != nil {
+		// the URL may carry a token: keep it out of the log
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	d.StatusCode = resp.StatusCode
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook: %s", resp.Status)
+	default:
+		return false, fmt.Errorf("%w: %s", ErrRefused, resp.Status)
+	}
+}
+
+// backoff is the wait after the attempt-th failed attempt.
+func (n *Notifier) backoff(attempt int) time.Duration {
+	wait := n.opts.Backoff
+	for i := 1; i < attempt && wait < n.opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, n.opts.MaxBackoff)
+}
+
+// Sign returns the X-Webhook-Signature of a delivery: "sha256=" and the hex
+// HMAC-SHA256, keyed by the endpoint secret, of the timestamp, a dot and the
+// body. A receiver recomputes it, compares it in constant time and rejects
+// an old timestamp, so a captured delivery cannot be replayed.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// redact drops the credentials and the query of a URL: they may carry a
+// token.
+func redact(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	u.User, u.RawQuery, u.Fragment = nil, "", ""
+	return u.String()
+}
+
+// payload is the JSON body of a delivery.
+type payload struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Data       struct {
+		Previous packList `json:"previous"`
+		Current  packList `json:"current"`
+	} `json:"data"`
+}
+
+type packList struct {
+	Version string `json:"version"`
+	Sizes   []int  `json:"sizes"`
+	Source  string `json:"source,omitempty"`
+}
+
+func newPayload(e events.PackSizesChanged) payload {
+	p := payload{ID: e.ID, Type: events.TypePackSizesChanged, OccurredAt: e.OccurredAt.UTC()}
+	p.Data.Previous = packList(e.Previous)
+	p.Data.Current = packList(e.Current)
+	return p
+}
@@ -0,0 +1,404 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/events"
+)
+
+// clock is a Now that tests move forward.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// receiver answers the statuses in turn (200 once they are used) and keeps
+// the requests.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+var change = events.PackSizesChanged{
+	ID:         "evt-1",
+	OccurredAt: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC),
+	Previous:   events.PackList{Version: "v1", Sizes: []int{250, 500}, Source: "file"},
+	Current:    events.PackList{Version: "v2", Sizes: []int{500, 1000}, Source: "file"},
+}
+
+// deliverDue attempts the messages due now, of every endpoint, as Run
+// would without its goroutines.
+func (n *Notifier) deliverDue(ctx context.Context) (next time.Time, ok bool) {
+	return n.deliverMatching(ctx, func(string) bool { return true })
+}
+
+func newNotifier(t *testing.T, dir, url string, clk *clock) *Notifier {
+	t.Helper()
+	n, err := New(dir, []Endpoint{{URL: url, Secret: "s3cret"}}, Options{Now: clk.Now, MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return n
+}
+
+func TestPublish_Delivers(t *testing.T) {
+	rcv := &receiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	clk := &clock{now: time.Date(2025, 9, 1, 10, 0, 5, 0, time.UTC)}
+	n := newNotifier(t, t.TempDir(), srv.URL+"/hooks?token=abc", clk)
+	ctx := context.Background()
+
+	if err := n.Publish(ctx, change); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if _, pending := n.deliverDue(ctx); pending {
+		t.Fatalf("nothing should be pending")
+	}
+
+	if rcv.count() != 1 {
+		t.Fatalf("requests got %d want 1", rcv.count())
+	}
+	req, body := rcv.requests[0], rcv.bodies[0]
+	if req.Method != http.MethodPost || req.URL.Query().Get("token") != "abc" {
+		t.Fatalf("request got %s %s", req.Method, req.URL)
+	}
+	ts := strconv.FormatInt(clk.Now().Unix(), 10)
+	if req.Header.Get(HeaderID) != "evt-1" || req.Header.Get(HeaderEvent) != events.TypePackSizesChanged ||
+		req.Header.Get(HeaderTimestamp) != ts {
+		t.Fatalf("headers got %v", req.Header)
+	}
+	if got, want := req.Header.Get(HeaderSignature), Sign("s3cret", clk.Now().Unix(), body); got != want {
+		t.Fatalf("signature got %q want %q", got, want)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	want := map[string]any{
+		"id":         "evt-1",
+		"type":       "packsizes.changed",
+		"occurredAt": "2025-09-01T10:00:00Z",
+		"data": map[string]any{
+			"previous": map[string]any{"version": "v1", "sizes": []any{250.0, 500.0}, "source": "file"},
+			"current":  map[string]any{"version": "v2", "sizes": []any{500.0, 1000.0}, "source": "file"},
+		},
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Fatalf("body got %v want %v", doc, want)
+	}
+
+	got, _ := n.Deliveries(ctx, events.DeliveryFilter{})
+	if len(got) != 1 || got[0].Status != events.DeliveryDelivered || got[0].StatusCode != 200 ||
+		got[0].Endpoint != srv.URL+"/hooks" || got[0].Attempt != 1 {
+		t.Fatalf("deliveries got %+v", got)
+	}
+	if last, err := n.Last(); err != nil || !reflect.DeepEqual(*last, change.Current) {
+		t.Fatalf("Last got %+v %v", last, err)
+	}
+}
+
+func TestDeliver_Retries(t *testing.T) {
+	rcv := &receiver{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	clk := &clock{now: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)}
+	n := newNotifier(t, t.TempDir(), srv.URL, clk)
+	ctx := context.Background()
+	_ = n.Publish(ctx, change)
+
+	start := clk.Now()
+	next, pending := n.deliverDue(ctx)
+	if !pending || !next.Equal(start.Add(time.Second)) {
+		t.Fatalf("first retry got %v %v", next, pending)
+	}
+	// not due yet
+	if n.deliverDue(ctx); rcv.count() != 1 {
+		t.Fatalf("requests got %d want 1", rcv.count())
+	}
+	clk.Add(time.Second)
+	if next, pending = n.deliverDue(ctx); !pending || !next.Equal(clk.Now().Add(2*time.Second)) {
+		t.Fatalf("second retry got %v %v", next, pending)
+	}
+	clk.Add(2 * time.Second)
+	if _, pending = n.deliverDue(ctx); pending {
+		t.Fatalf("delivered at the third attempt, still pending")
+	}
+
+	var statuses []string
+	got, _ := n.Deliveries(ctx, events.DeliveryFilter{EventID: "evt-1"})
+	for _, d := range got {
+		statuses = append(statuses, d.Status)
+	}
+	want := []string{events.DeliveryDelivered, events.DeliveryRetrying, events.DeliveryRetrying}
+	if !reflect.DeepEqual(statuses, want) || got[0].Attempt != 3 {
+		t.Fatalf("deliveries got %+v", got)
+	}
+	// the same event, the same body: receivers deduplicate by the ID
+	if string(rcv.bodies[0]) != string(rcv.bodies[2]) {
+		t.Fatalf("bodies differ between attempts")
+	}
+}
+
+func TestDeliver_Fails(t *testing.T) {
+	for name, statuses := range map[string][]int{
+		"refused":     {http.StatusBadRequest},
+		"no attempts": {500, 500, 500},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rcv := &receiver{statuses: statuses}
+			srv := httptest.NewServer(rcv)
+			defer srv.Close()
+			clk := &clock{now: time.Now()}
+			n := newNotifier(t, t.TempDir(), srv.URL, clk)
+			ctx := context.Background()
+			_ = n.Publish(ctx, change)
+
+			for pending := true; pending; clk.Add(time.Hour) {
+				_, pending = n.deliverDue(ctx)
+			}
+			if rcv.count() != len(statuses) {
+				t.Fatalf("requests got %d want %d", rcv.count(), len(statuses))
+			}
+			got, _ := n.Deliveries(ctx, events.DeliveryFilter{Status: events.DeliveryFailed})
+			if len(got) != 1 || got[0].StatusCode != statuses[0] || got[0].Error == "" {
+				t.Fatalf("failed deliveries got %+v", got)
+			}
+		})
+	}
+}
+
+func TestNotifier_Restart(t *testing.T) {
+	rcv := &receiver{statuses: []int{500}}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	dir := t.TempDir()
+	clk := &clock{now: time.Now()}
+	ctx := context.Background()
+
+	n := newNotifier(t, dir, srv.URL, clk)
+	_ = n.Publish(ctx, change)
+	n.deliverDue(ctx)
+
+	// the message, its attempts, the log and the last list are on disk
+	clk.Add(time.Minute)
+	n = newNotifier(t, dir, srv.URL, clk)
+	if _, pending := n.deliverDue(ctx); pending || rcv.count() != 2 {
+		t.Fatalf("after restart: pending %v, requests %d", pending, rcv.count())
+	}
+	got, _ := n.Deliveries(ctx, events.DeliveryFilter{})
+	if len(got) != 2 || got[0].Attempt != 2 || got[1].Status != events.DeliveryRetrying {
+		t.Fatalf("deliveries got %+v", got)
+	}
+	if last, _ := n.Last(); last == nil || last.Version != "v2" {
+		t.Fatalf("Last got %+v", last)
+	}
+}
+
+func TestNotifier_Run(t *testing.T) {
+	rcv := &receiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	n, err := New(t.TempDir(), []Endpoint{{URL: srv.URL, Secret: "s"}}, Options{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { n.Run(ctx); close(done) }()
+
+	_ = n.Publish(ctx, change)
+	deadline := time.Now().Add(5 * time.Second)
+	for rcv.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if rcv.count() != 1 {
+		t.Fatalf("requests got %d want 1", rcv.count())
+	}
+}
+
+func TestDeliveryLog_Limit(t *testing.T) {
+	dir := t.TempDir()
+	l, err := openDeliveryLog(dir, 3)
+	if err != nil {
+		t.Fatalf("openDeliveryLog: %v", err)
+	}
+	for i := 1; i <= 10; i++ {
+		l.add(events.Delivery{EventID: strconv.Itoa(i), Status: events.DeliveryDelivered})
+	}
+	l, _ = openDeliveryLog(dir, 3)
+	got := l.list(events.DeliveryFilter{Limit: 2})
+	if len(got) != 2 || got[0].EventID != "10" || got[1].EventID != "9" {
+		t.Fatalf("list got %+v", got)
+	}
+	if all := l.list(events.DeliveryFilter{}); len(all) != 3 {
+		t.Fatalf("kept %d want 3", len(all))
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]struct {
+		endpoints []Endpoint
+		want      error
+	}{
+		"none":      {nil, ErrNoEndpoint},
+		"bad URL":   {[]Endpoint{{URL: "ftp://x", Secret: "s"}}, ErrInvalidURL},
+		"no secret": {[]Endpoint{{URL: "https://x.test"}}, ErrEmptySecret},
+	}
+	for name, tc := range cases {
+		if _, err := New(dir, tc.endpoints, Options{}); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v want %v", name, err, tc.want)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac key
+	want := "sha256=9d713ed406bb7076d4123f0dc2c39d2df5c654ed4b0cd56b52c8b4c940bd63ae"
+	if got := Sign("key", 1700000000, []byte("{}")); got != want {
+		t.Fatalf("Sign got %q want %q", got, want)
+	}
+}
+
+func TestDeliver_InPublishOrderPerEndpoint(t *testing.T) {
+	rcv := &receiver{statuses: []int{http.StatusServiceUnavailable}}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	clk := &clock{now: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)}
+	n := newNotifier(t, t.TempDir(), srv.URL, clk)
+	ctx := context.Background()
+
+	second := change
+	second.ID, second.Previous, second.Current = "evt-2", change.Current, events.PackList{Version: "v3", Sizes: []int{1000}}
+	_ = n.Publish(ctx, change)
+	_ = n.Publish(ctx, second)
+
+	// evt-2 waits for the retry of evt-1
+	if n.deliverDue(ctx); rcv.count() != 1 {
+		t.Fatalf("requests got %d want 1", rcv.count())
+	}
+	clk.Add(time.Second)
+	if _, pending := n.deliverDue(ctx); pending || rcv.count() != 3 {
+		t.Fatalf("pending %v, requests %d", pending, rcv.count())
+	}
+	var ids []string
+	for _, req := range rcv.requests {
+		ids = append(ids, req.Header.Get(HeaderID))
+	}
+	if want := []string{"evt-1", "evt-1", "evt-2"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("order got %v want %v", ids, want)
+	}
+}
+
+func TestNotifier_SlowEndpointDoesNotDelayOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+	rcv := &receiver{}
+	fast := httptest.NewServer(rcv)
+	defer fast.Close()
+
+	n, err := New(t.TempDir(), []Endpoint{{URL: slow.URL, Secret: "s"}, {URL: fast.URL, Secret: "s"}}, Options{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { n.Run(ctx); close(done) }()
+
+	_ = n.Publish(ctx, change)
+	deadline := time.Now().Add(5 * time.Second)
+	for rcv.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if rcv.count() != 1 {
+		t.Fatalf("requests to the fast endpoint got %d want 1", rcv.count())
+	}
+}
+
+func TestNotifier_CrashBeforeSplit(t *testing.T) {
+	rcv := &receiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	dir := t.TempDir()
+	clk := &clock{now: time.Now()}
+	ctx := context.Background()
+
+	// stored by Publish, the messages not written yet
+	box, err := openOutbox(dir)
+	if err != nil {
+		t.Fatalf("openOutbox: %v", err)
+	}
+	body, _ := json.Marshal(newPayload(change))
+	if err := box.publish(published{EventID: change.ID, EventType: events.TypePackSizesChanged, Body: body, Seq: 1, Current: packList(change.Current)}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	n := newNotifier(t, dir, srv.URL, clk)
+	if last, err := n.Last(); err != nil || !reflect.DeepEqual(*last, change.Current) {
+		t.Fatalf("Last got %+v %v: the change must not be detected again", last, err)
+	}
+	if _, pending := n.deliverDue(ctx); pending || rcv.count() != 1 || rcv.requests[0].Header.Get(HeaderID) != change.ID {
+		t.Fatalf("pending %v, requests %d", pending, rcv.count())
+	}
+	if last, _ := n.Last(); last == nil || last.Version != "v2" {
+		t.Fatalf("Last after the split got %+v", last)
+	}
+	if pending, _ := box.published(); len(pending) != 0 {
+		t.Fatalf("published events left: %+v", pending)
+	}
+}
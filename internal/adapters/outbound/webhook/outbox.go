@@ -0,0 +1,286 @@
+package webhook
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/events"
+)
+
+const lastFile = "last.json"
+
+// message is an event to deliver to one endpoint.
+// - Body: the JSON sent, encoded once so every attempt signs the same bytes
+// - Seq: publish order; an endpoint gets its messages in that order
+// - Attempt: attempts made so far
+type message struct {
+	EventID       string          `json:"eventId"`
+	EventType     string          `json:"eventType"`
+	URL           string          `json:"url"`
+	Body          json.RawMessage `json:"body"`
+	Seq           int64           `json:"seq"`
+	Attempt       int             `json:"attempt"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+}
+
+// published is a published event not yet split into messages: writing it is
+// the commit point of Publish, so its ID and its list (see last) are stored
+// in one step.
+type published struct {
+	EventID   string          `json:"eventId"`
+	EventType string          `json:"eventType"`
+	Body      json.RawMessage `json:"body"`
+	Seq       int64           `json:"seq"`
+	Current   packList        `json:"current"`
+}
+
+// outbox keeps one JSON file per published event in dir/events until it is
+// split into one JSON file per pending message in dir/outbox. Every file is
+// written to a temporary file, synced and renamed, and the directory is
+// synced: a crash never leaves a partial file nor loses a written one.
+type outbox struct {
+	dir    string // the messages
+	events string // the published events
+	root   string // last.json
+}
+
+func openOutbox(dir string) (*outbox, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, errors.New("webhook: empty directory")
+	}
+	b := &outbox{dir: filepath.Join(dir, "outbox"), events: filepath.Join(dir, "events"), root: dir}
+	for _, d := range []string{b.dir, b.events} {
+		if err := os.MkdirAll(d, 0o700); err != nil {
+			return nil, fmt.Errorf("webhook: %w", err)
+		}
+	}
+	if err := b.sweep(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *outbox) put(m message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return writeFile(b.dir, b.path(m), data)
+}
+
+// add stores m unless the outbox already has it (with its attempts).
+func (b *outbox) add(m message) error {
+	if _, err := os.Stat(b.path(m)); err == nil {
+		return nil
+	}
+	return b.put(m)
+}
+
+func (b *outbox) remove(m message) error {
+	if err := os.Remove(b.path(m)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
+
+// list returns the pending messages by endpoint, then in publish order.
+func (b *outbox) list() ([]message, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	var messages []message
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(b.dir, e.Name()))
+		if err != nil {
+			continue // delivered meanwhile
+		}
+		var m message
+		if err := json.Unmarshal(data, &m); err != nil {
+			continue // removed by the next sweep
+		}
+		messages = append(messages, m)
+	}
+	slices.SortFunc(messages, func(a, b message) int {
+		return cmp.Or(strings.Compare(a.URL, b.URL), cmp.Compare(a.Seq, b.Seq), strings.Compare(a.EventID, b.EventID))
+	})
+	return messages, nil
+}
+
+// publish stores e; split turns it into messages.
+func (b *outbox) publish(e published) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return writeFile(b.events, filepath.Join(b.events, fmt.Sprintf("%020d.json", e.Seq)), data)
+}
+
+// published returns the events not split yet, in publish order.
+func (b *outbox) published() ([]published, error) {
+	entries, err := os.ReadDir(b.events)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	var out []published
+	for _, e := range entries { // sorted by name: the padded Seq
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(b.events, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("webhook: %w", err)
+		}
+		var p published
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("webhook: decoding %s: %w", e.Name(), err)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// split stores a message of e for every url, then e.Current as the last
+// list, then drops e. After a crash it is split again: the messages already
+// stored keep their attempts, the ones delivered meanwhile are sent again
+// with the same ID.
+func (b *outbox) split(e published, urls []string) error {
+	for _, u := range urls {
+		m := message{EventID: e.EventID, EventType: e.EventType, URL: u, Body: e.Body, Seq: e.Seq}
+		if err := b.add(m); err != nil {
+			return err
+		}
+	}
+	if err := b.setLast(events.PackList(e.Current)); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(b.events, fmt.Sprintf("%020d.json", e.Seq))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
+
+// path hashes the event ID and the URL: one file per delivery.
+func (b *outbox) path(m message) string {
+	sum := sha256.Sum256([]byte(m.EventID + "\n" + m.URL))
+	return filepath.Join(b.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (b *outbox) setLast(l events.PackList) error {
+	data, err := json.Marshal(packList(l))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return writeFile(b.root, filepath.Join(b.root, lastFile), data)
+}
+
+// last returns the list of the last event published: the one of the last
+// event not split yet, otherwise last.json; nil when there is none.
+func (b *outbox) last() (*events.PackList, error) {
+	pending, err := b.published()
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		out := events.PackList(pending[len(pending)-1].Current)
+		return &out, nil
+	}
+	data, err := os.ReadFile(filepath.Join(b.root, lastFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	var l packList
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("webhook: decoding %s: %w", lastFile, err)
+	}
+	out := events.PackList(l)
+	return &out, nil
+}
+
+// sweep removes the temporary files left by a crash and the corrupt
+// messages.
+func (b *outbox) sweep() error {
+	for _, dir := range []string{b.root, b.dir, b.events} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("webhook: %w", err)
+		}
+		for _, e := range entries {
+			path := filepath.Join(dir, e.Name())
+			if strings.HasPrefix(e.Name(), ".tmp-") {
+				_ = os.Remove(path)
+				continue
+			}
+			if dir != b.dir || e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			var m message
+			if json.Unmarshal(data, &m) != nil {
+				_ = os.Remove(path)
+			}
+		}
+	}
+	return nil
+}
+
+// writeFile writes to a temporary file in dir, syncs it, renames it to
+// path and syncs dir, so the file is on disk once it returns.
+func writeFile(dir, path string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("webhook: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("webhook: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("webhook: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return syncDir(dir)
+}
+
+// syncDir makes a rename in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"strings"
+
+	ginadapter "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/gin"
+	"github.com/reangeline/go-shipping-products/internal/adapters/outbound/audit/jsonl"
+	"github.com/reangeline/go-shipping-products/internal/app/config"
+)
+
+// Audit metrics, on /debug/vars (admin).
+var auditFailures = expvar.NewInt("audit_failures")
+
+// adminTokens merges ADMIN_TOKEN (actor "admin") and ADMIN_TOKENS
+// ("actor:token,..."); empty when the admin routes are off.
+func adminTokens(cfg config.Config) (map[string]string, error) {
+	tokens := map[string]string{}
+	if cfg.AdminToken != "" {
+		tokens[ginadapter.DefaultAdminActor] = cfg.AdminToken
+	}
+	for _, item := range strings.Split(cfg.AdminTokens, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		actor, token, ok := strings.Cut(item, ":")
+		actor, token = strings.TrimSpace(actor), strings.TrimSpace(token)
+		if !ok || actor == "" || token == "" {
+			return nil, errors.New("ADMIN_TOKENS: want actor:token pairs separated by commas")
+		}
+		if _, dup := tokens[actor]; dup {
+			return nil, fmt.Errorf("ADMIN_TOKENS: actor %q has two tokens", actor)
+		}
+		tokens[actor] = token
+	}
+	return tokens, nil
+}
+
+// newAuditLog opens the audit trail; nil with AUDIT_LOG=off or without admin
+// tokens (no admin route, nothing to audit).
+func newAuditLog(cfg config.Config, admins map[string]string) (*jsonl.Log, error) {
+	if cfg.AuditLog == "" || cfg.AuditLog == "off" || len(admins) == 0 {
+		return nil, nil
+	}
+	l, err := jsonl.New(cfg.AuditLog, jsonl.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("AUDIT_LOG: %w", err)
+	}
+	return l, nil
+}
+
+// logAuditFailure reports a change that could not be recorded (refused with
+// 503 audit_unavailable).
+func logAuditFailure(err error) {
+	auditFailures.Add(1)
+	log.Printf("audit: change not recorded: %v", err)
+}
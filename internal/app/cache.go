@@ -0,0 +1,13 @@
+package app
+
+import (
+	"expvar"
+
+	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
+)
+
+// calcCacheStats is the "calc_cache" variable: hits, misses, shared flights,
+// evictions and entries of the calculation cache.
+func calcCacheStats(cache *usecases.CachedCalculatePacks) expvar.Var {
+	return expvar.Func(func() any { return cache.Stats() })
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
+)
+
+// Catalogue metrics, on /debug/vars (admin).
+var (
+	catalogueSwitches = expvar.NewInt("packsizes_switches")
+	catalogueVersion  = expvar.NewString("packsizes_version")
+)
+
+// newCatalogueWatcher reports the switches to scheduled versions; nil when
+// the provider has no history.
+func newCatalogueWatcher(prov packsizes.Provider) (*usecases.CatalogueWatcher, error) {
+	history, ok := prov.(packsizes.History)
+	if !ok {
+		return nil, nil
+	}
+	if set, err := history.At(context.Background(), time.Now()); err == nil {
+		catalogueVersion.Set(set.Version)
+	}
+	return usecases.NewCatalogueWatcher(prov, usecases.WatcherOptions{OnSwitch: logSwitch})
+}
+
+func logSwitch(e usecases.SwitchEvent) {
+	log.Printf("packsizes: version %q in effect since %s (was %q)", e.To, e.At.Format(time.RFC3339), e.From)
+	catalogueSwitches.Add(1)
+	catalogueVersion.Set(e.To)
+}
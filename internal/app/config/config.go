@@ -1,16 +1,78 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Config centralizes the application's configurations.
 type Config struct {
-	ProviderType string // "file"
+	ProviderType string // "file", "env", "http", "sql", "defaults" or "chain"
 	FilePath     string // path to packs file (when ProviderType="file")
-	EnvVar       string
+	EnvVar       string // variable holding the sizes (when ProviderType="env")
 	HTTPAddr     string
+
+	ProviderChain      string        // sources of the "chain" provider, in fallback order (e.g. "file,env,defaults")
+	DefaultSizes       string        // built-in sizes of the "defaults" provider
+	ProviderMaxStale   time.Duration // how long the chain serves its last good list once every source fails (0 = no limit)
+	ProviderBackoff    time.Duration // how long the chain skips a failing source, doubled on each failure in a row (0 = asked on every request)
+	ProviderMaxBackoff time.Duration // the longest skip
+
+	// Database of the "sql" provider
+	SQLDriver string // database/sql driver ("sqlite" is built in)
+	SQLDSN    string // data source name, e.g. the SQLite file
+	Warehouse string // rows served ("" is the default warehouse)
+
+	// Catalogue service of the "http" provider
+	RemoteURL      string        // JSON document with the sizes
+	RemoteToken    string        // bearer token sent to it (optional)
+	RemoteTimeout  time.Duration // per attempt
+	RemoteAttempts int           // tries of a fetch, with backoff between them
+	RemotePoll     time.Duration // refresh period (revalidated with the ETag)
+	RemoteMaxStale time.Duration // the list is an error once older (0 = served while the service is down)
+	RemoteCAFile   string        // PEM roots trusted instead of the system ones
+	RemoteCertFile string        // PEM client certificate (mutual TLS)
+	RemoteKeyFile  string        // PEM client key (mutual TLS)
+
+	CalcStrategy string // "precomputed" (solver per pack set) or "dp" (per-request DP)
+
+	// Calculation safeguards (0 disables a check)
+	MaxQuantity  int
+	MaxPackSizes int
+	MaxDPCells   int
+
+	CacheSize int           // calculation cache entries (0 disables the cache)
+	CacheTTL  time.Duration // calculation cache entry lifetime (0 = no expiration)
+
+	OpenAPIValidation string // "off", "requests" or "all" (requests + responses; test/dev)
+
+	WebDir string // frontend build to serve (e.g. web/dist); empty: the embedded one, if any
+
+	CompressMinSize       int    // smallest JSON response compressed with br/gzip (0 disables compression)
+	PackSizesCacheControl string // Cache-Control of GET /v1/packsizes
+
+	IdempotencyStore      string        // "memory", "file" or "off"
+	IdempotencyDir        string        // records directory (when IdempotencyStore="file")
+	IdempotencyTTL        time.Duration // how long a key replays its first response
+	IdempotencyMaxEntries int           // records kept (memory: the first expiring is dropped; file: new ones are not stored)
+	IdempotencyMaxBytes   int64         // size of the records kept when IdempotencyStore="file"
+
+	AdminToken  string // bearer token of the admin routes, actor "admin" in the audit trail
+	AdminTokens string // more tokens, named after their holder: "alice:token1,bob:token2"
+	AuditLog    string // JSON-lines file of the audit trail (admin changes and calls); "off" disables it
+
+	TrustedProxies string // comma-separated IPs or CIDRs whose X-Forwarded-For is believed; empty: none
+
+	// Change events of the pack list, POSTed to webhooks
+	WebhookURLs        string        // comma-separated receivers; empty disables the events
+	WebhookSecret      string        // HMAC key of the X-Webhook-Signature (required with WebhookURLs)
+	WebhookDir         string        // outbox and delivery log, kept across restarts
+	WebhookMaxAttempts int           // tries of a delivery, with exponential backoff between them
+	WebhookTimeout     time.Duration // per attempt
+	WebhookInterval    time.Duration // how often the pack list is compared with the last one
 }
 
 // Load reads the environment variables and builds the Config.
@@ -21,6 +83,58 @@ func Load() Config {
 		FilePath:     getEnv("PACK_SIZES_FILE", "./packs.csv"),
 		EnvVar:       getEnv("PACK_SIZES_ENV", "PACK_SIZES"),
 		HTTPAddr:     getEnv("HTTP_ADDR", ":8080"),
+
+		ProviderChain:      getEnv("PACK_PROVIDERS", "file,env,defaults"),
+		DefaultSizes:       getEnv("PACK_DEFAULT_SIZES", "250,500,1000,2000,5000"),
+		ProviderMaxStale:   getEnvDuration("PACK_MAX_STALE", 0),
+		ProviderBackoff:    getEnvDuration("PACK_RETRY_BACKOFF", time.Second),
+		ProviderMaxBackoff: getEnvDuration("PACK_RETRY_MAX_BACKOFF", time.Minute),
+
+		SQLDriver: getEnv("PACK_SQL_DRIVER", "sqlite"),
+		SQLDSN:    getEnv("PACK_SQL_DSN", "./data/packs.db"),
+		Warehouse: getEnv("PACK_WAREHOUSE", ""),
+
+		RemoteURL:      getEnv("PACK_SIZES_URL", ""),
+		RemoteToken:    getEnv("PACK_SIZES_URL_TOKEN", ""),
+		RemoteTimeout:  getEnvDuration("PACK_SIZES_URL_TIMEOUT", 5*time.Second),
+		RemoteAttempts: getEnvInt("PACK_SIZES_URL_ATTEMPTS", 3),
+		RemotePoll:     getEnvDuration("PACK_SIZES_URL_POLL", 30*time.Second),
+		RemoteMaxStale: getEnvDuration("PACK_SIZES_URL_MAX_STALE", 5*time.Minute),
+		RemoteCAFile:   getEnv("PACK_SIZES_URL_CA_FILE", ""),
+		RemoteCertFile: getEnv("PACK_SIZES_URL_CERT_FILE", ""),
+		RemoteKeyFile:  getEnv("PACK_SIZES_URL_KEY_FILE", ""),
+
+		CalcStrategy: getEnv("CALC_STRATEGY", "precomputed"),
+		MaxQuantity:  getEnvInt("MAX_QUANTITY", 100_000_000),
+		MaxPackSizes: getEnvInt("MAX_PACK_SIZES", 50),
+		MaxDPCells:   getEnvInt("MAX_DP_CELLS", 5_000_000),
+		CacheSize:    getEnvInt("CALC_CACHE_SIZE", 1024),
+		CacheTTL:     getEnvDuration("CALC_CACHE_TTL", 10*time.Minute),
+
+		OpenAPIValidation: getEnv("OPENAPI_VALIDATE", "off"),
+		WebDir:            getEnv("WEB_DIR", ""),
+
+		CompressMinSize:       getEnvInt("HTTP_COMPRESS_MIN_SIZE", 1024),
+		PackSizesCacheControl: getEnv("PACKSIZES_CACHE_CONTROL", "no-cache"),
+
+		IdempotencyStore:      getEnv("IDEMPOTENCY_STORE", "memory"),
+		IdempotencyDir:        getEnv("IDEMPOTENCY_DIR", "./data/idempotency"),
+		IdempotencyTTL:        getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencyMaxEntries: getEnvInt("IDEMPOTENCY_MAX_ENTRIES", 10_000),
+		IdempotencyMaxBytes:   int64(getEnvInt("IDEMPOTENCY_MAX_BYTES", 256<<20)),
+
+		AdminToken:  getEnv("ADMIN_TOKEN", ""),
+		AdminTokens: getEnv("ADMIN_TOKENS", ""),
+		AuditLog:    getEnv("AUDIT_LOG", "./data/audit.jsonl"),
+
+		TrustedProxies: getEnv("TRUSTED_PROXIES", ""),
+
+		WebhookURLs:        getEnv("WEBHOOK_URLS", ""),
+		WebhookSecret:      getEnv("WEBHOOK_SECRET", ""),
+		WebhookDir:         getEnv("WEBHOOK_DIR", "./data/webhooks"),
+		WebhookMaxAttempts: getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookTimeout:     getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookInterval:    getEnvDuration("WEBHOOK_CHECK_INTERVAL", 30*time.Second),
 	}
 }
 
@@ -32,3 +146,29 @@ func getEnv(key, def string) string {
 	}
 	return def
 }
+
+func getEnvInt(key string, def int) int {
+	val := getEnv(key, "")
+	if val == "" {
+		return def
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		log.Printf("config: invalid %s=%q, using %d", key, val, def)
+		return def
+	}
+	return n
+}
+
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	val := getEnv(key, "")
+	if val == "" {
+		return def
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		log.Printf("config: invalid %s=%q, using %s", key, val, def)
+		return def
+	}
+	return d
+}
@@ -0,0 +1,230 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/app/config"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+
+	ginadapter "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/gin"
+	"github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/chain"
+	envProv "github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/env"
+	fileProv "github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/file"
+	httpadapter "github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/http"
+	sqladapter "github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/sql"
+	staticProv "github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/static"
+
+	// pure Go SQLite driver ("sqlite") of the sql provider
+	_ "modernc.org/sqlite"
+)
+
+// packSources is what newProvider builds.
+type packSources struct {
+	provider packsizes.Provider
+	chain    *chain.Provider       // nil for a single source
+	remote   *httpadapter.Provider // nil without the "http" source
+	metrics  map[string]expvar.Var // of the chain, see sourceMetrics
+}
+
+// sourceMetrics are the variables of the chain provider, on /debug/vars
+// (admin).
+type sourceMetrics struct {
+	source    expvar.String
+	degraded  expvar.Int // 1 while a fallback source or the cache serves the list
+	fallbacks expvar.Int // times the chain entered the degraded mode
+}
+
+// vars names the metrics of sources, with packsizes_stale_seconds: the
+// seconds since the served list was loaded when it comes from the cache.
+func (m *sourceMetrics) vars(sources *chain.Provider) map[string]expvar.Var {
+	return map[string]expvar.Var{
+		"packsizes_source":    &m.source,
+		"packsizes_degraded":  &m.degraded,
+		"packsizes_fallbacks": &m.fallbacks,
+		"packsizes_stale_seconds": expvar.Func(func() any {
+			return sources.Status().Age(time.Now()).Seconds()
+		}),
+	}
+}
+
+// onChange logs and counts a change of the serving source (see
+// chain.Options.OnChange).
+func (m *sourceMetrics) onChange(s chain.Status) {
+	log.Printf("packsizes: served by %s", s)
+	m.source.Set(s.Source)
+	if s.Degraded {
+		if m.degraded.Value() == 0 {
+			m.fallbacks.Add(1)
+		}
+		m.degraded.Set(1)
+	} else {
+		m.degraded.Set(0)
+	}
+}
+
+// newProvider builds the PACK_PROVIDER.
+func newProvider(cfg config.Config) (packSources, error) {
+	var ps packSources
+	if cfg.ProviderType != "chain" {
+		prov, err := ps.newSource(cfg.ProviderType, cfg)
+		ps.provider = prov
+		return ps, err
+	}
+
+	var sources []chain.Source
+	for _, name := range strings.Split(cfg.ProviderChain, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		switch name {
+		case "file", "sql":
+			// a missing or corrupt file, or a database down, fails in the
+			// chain, not at startup
+			sources = append(sources, chain.Source{Name: name, Provider: chain.Lazy(func() (packsizes.Provider, error) {
+				return ps.newSource(name, cfg)
+			})})
+		default:
+			prov, err := ps.newSource(name, cfg)
+			if err != nil {
+				return packSources{}, err
+			}
+			sources = append(sources, chain.Source{Name: name, Provider: prov})
+		}
+	}
+	metrics := new(sourceMetrics)
+	sourceChain, err := chain.New(sources, chain.Options{
+		MaxStale:   cfg.ProviderMaxStale,
+		Backoff:    cfg.ProviderBackoff,
+		MaxBackoff: cfg.ProviderMaxBackoff,
+		OnChange:   metrics.onChange,
+	})
+	if err != nil {
+		return packSources{}, fmt.Errorf("PACK_PROVIDERS=%q: %w", cfg.ProviderChain, err)
+	}
+	ps.provider, ps.chain, ps.metrics = sourceChain, sourceChain, metrics.vars(sourceChain)
+	return ps, nil
+}
+
+// newSource builds one provider by name.
+func (ps *packSources) newSource(name string, cfg config.Config) (packsizes.Provider, error) {
+	switch name {
+	case "file":
+		return fileProv.New(cfg.FilePath)
+	case "env":
+		return envProv.New(cfg.EnvVar), nil
+	case "http":
+		remote, err := newRemote(cfg)
+		if err != nil {
+			return nil, err
+		}
+		ps.remote = remote
+		return remote, nil
+	case "sql":
+		return newSQL(cfg)
+	case "defaults":
+		sizes, err := defaultSizes(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return staticProv.New(sizes)
+	default:
+		return nil, fmt.Errorf("unknown provider type: %s", name)
+	}
+}
+
+func defaultSizes(cfg config.Config) ([]int, error) {
+	sizes, err := fileProv.ParsePackSizes(cfg.DefaultSizes)
+	if err != nil {
+		return nil, fmt.Errorf("PACK_DEFAULT_SIZES: %w", err)
+	}
+	return sizes, nil
+}
+
+// newSQL opens and migrates the database; an empty warehouse starts with
+// PACK_DEFAULT_SIZES.
+func newSQL(cfg config.Config) (packsizes.Provider, error) {
+	db, err := sql.Open(cfg.SQLDriver, cfg.SQLDSN)
+	if err != nil {
+		return nil, fmt.Errorf("PACK_SQL_DSN: %w", err)
+	}
+	prov, err := sqladapter.New(db, sqladapter.Options{Warehouse: cfg.Warehouse})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("PACK_SQL_DSN: %w", err)
+	}
+	sizes, err := defaultSizes(cfg)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if seeded, err := prov.Seed(context.Background(), sizes); err != nil {
+		_ = db.Close()
+		return nil, err
+	} else if seeded {
+		log.Printf("packsizes: warehouse %q seeded with %v", cfg.Warehouse, sizes)
+	}
+	return prov, nil
+}
+
+func newRemote(cfg config.Config) (*httpadapter.Provider, error) {
+	var header http.Header
+	if cfg.RemoteToken != "" {
+		header = http.Header{"Authorization": {"Bearer " + cfg.RemoteToken}}
+	}
+	remote, err := httpadapter.New(cfg.RemoteURL, httpadapter.Options{
+		Timeout:      cfg.RemoteTimeout,
+		MaxAttempts:  cfg.RemoteAttempts,
+		PollInterval: cfg.RemotePoll,
+		MaxStale:     cfg.RemoteMaxStale,
+		Header:       header,
+		TLS: httpadapter.TLSOptions{
+			CAFile:   cfg.RemoteCAFile,
+			CertFile: cfg.RemoteCertFile,
+			KeyFile:  cfg.RemoteKeyFile,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("PACK_SIZES_URL: %w", err)
+	}
+	return remote, nil
+}
+
+// sourcesReadiness reports the chain on /readyz: ok while the first source
+// serves, degraded on a fallback or the cache, down without a list.
+func sourcesReadiness(sources *chain.Provider) func(context.Context) ginadapter.Readiness {
+	return func(ctx context.Context) ginadapter.Readiness {
+		s := sources.Check(ctx)
+		r := ginadapter.Readiness{Status: ginadapter.ReadyOK}
+		switch {
+		case !s.Available():
+			r.Status = ginadapter.ReadyDown
+		case s.Degraded:
+			r.Status = ginadapter.ReadyDegraded
+		}
+		detail := map[string]any{"source": s.Source, "cached": s.Cached}
+		if s.Cached {
+			detail["loadedAt"] = s.LoadedAt.UTC().Format(time.RFC3339)
+			detail["staleSeconds"] = int(s.Age(time.Now()).Seconds())
+		}
+		if len(s.Errors) > 0 {
+			// names only: /readyz is public, the errors are in the log
+			failing := make([]string, 0, len(s.Errors))
+			for name := range s.Errors {
+				failing = append(failing, name)
+			}
+			slices.Sort(failing)
+			detail["failing"] = failing
+		}
+		r.Detail = detail
+		return r
+	}
+}
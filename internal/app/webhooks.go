@@ -0,0 +1,71 @@
+package app
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/reangeline/go-shipping-products/internal/adapters/outbound/webhook"
+	"github.com/reangeline/go-shipping-products/internal/app/config"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/events"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
+)
+
+// Webhook metrics, on /debug/vars (admin).
+var (
+	webhookDelivered = expvar.NewInt("webhook_delivered")
+	webhookRetries   = expvar.NewInt("webhook_retries")
+	webhookFailed    = expvar.NewInt("webhook_failed")
+)
+
+// newWebhooks builds the change notifier and the webhooks it publishes to;
+// both nil without WEBHOOK_URLS. The last published list is the starting
+// point of the notifier: a change made while the service was down is sent.
+func newWebhooks(cfg config.Config, prov packsizes.Provider) (*webhook.Notifier, *usecases.ChangeNotifier, error) {
+	var endpoints []webhook.Endpoint
+	for _, u := range strings.Split(cfg.WebhookURLs, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			endpoints = append(endpoints, webhook.Endpoint{URL: u, Secret: cfg.WebhookSecret})
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil, nil, nil
+	}
+	if cfg.WebhookSecret == "" {
+		return nil, nil, errors.New("WEBHOOK_SECRET is required with WEBHOOK_URLS")
+	}
+	hooks, err := webhook.New(cfg.WebhookDir, endpoints, webhook.Options{
+		Timeout:     cfg.WebhookTimeout,
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		OnDelivery:  logDelivery,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("WEBHOOK_URLS: %w", err)
+	}
+	last, err := hooks.Last()
+	if err != nil {
+		return nil, nil, err
+	}
+	changes, err := usecases.NewChangeNotifier(prov, hooks, usecases.ChangeOptions{Interval: cfg.WebhookInterval, Last: last})
+	if err != nil {
+		return nil, nil, err
+	}
+	return hooks, changes, nil
+}
+
+func logDelivery(d events.Delivery) {
+	switch d.Status {
+	case events.DeliveryDelivered:
+		webhookDelivered.Add(1)
+	case events.DeliveryRetrying:
+		webhookRetries.Add(1)
+		log.Printf("webhook: event %s to %s, attempt %d: %s (retry at %s)", d.EventID, d.Endpoint, d.Attempt, d.Error,
+			d.NextAttemptAt.Format("15:04:05"))
+	default:
+		webhookFailed.Add(1)
+		log.Printf("webhook: event %s to %s given up after %d attempts: %s", d.EventID, d.Endpoint, d.Attempt, d.Error)
+	}
+}
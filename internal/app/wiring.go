@@ -2,59 +2,273 @@
 package app
 
 import (
+	"expvar"
 	"fmt"
+	"io/fs"
+	"maps"
 	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
 
 	"github.com/reangeline/go-shipping-products/internal/app/config"
 	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
 	inbound "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
 	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
 
-	fileProv "github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/file"
-
 	ginadapter "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/gin"
+	"github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/idempotency"
 	ctr "github.com/reangeline/go-shipping-products/internal/adapters/inbound/http/order"
+	"github.com/reangeline/go-shipping-products/internal/adapters/outbound/audit/jsonl"
+	"github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/chain"
+	httpadapter "github.com/reangeline/go-shipping-products/internal/adapters/outbound/packsizes/http"
+	"github.com/reangeline/go-shipping-products/internal/adapters/outbound/webhook"
 	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+	"github.com/reangeline/go-shipping-products/web"
 )
 
 type Container struct {
-	Calc inbound.CalculatePacks
-	Get  inbound.GetPackSizes
-	HTTP http.Handler
+	Calc      inbound.CalculatePacks
+	Get       inbound.GetPackSizes
+	CalcCache *usecases.CachedCalculatePacks // nil when the cache is disabled
+	Watcher   *usecases.CatalogueWatcher     // nil when the provider has no history; run it with Run
+	Sources   *chain.Provider                // nil unless PACK_PROVIDER=chain
+	Remote    *httpadapter.Provider          // nil without the "http" provider; run it with Run (polling)
+	Changes   *usecases.ChangeNotifier       // nil without WEBHOOK_URLS; run it with Run
+	Webhooks  *webhook.Notifier              // nil without WEBHOOK_URLS; run it with Run (deliveries)
+	Audit     *jsonl.Log                     // nil with AUDIT_LOG=off or without an admin token
+	IdemFiles *idempotency.FileStore         // nil unless IDEMPOTENCY_STORE=file; run it with Run (sweeps)
+	HTTP      http.Handler
+
+	metrics map[string]expvar.Var // see Metrics
+}
+
+// Metrics are the variables of the container for /debug/vars (admin), by
+// name; main publishes them once (expvar.Publish panics on a name reused).
+func (c *Container) Metrics() map[string]expvar.Var {
+	return c.metrics
 }
 
 func Wire(cfg config.Config) (*Container, error) {
-	var prov packsizes.Provider
-	var err error
+	sources, err := newProvider(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("init provider: %w", err)
+	}
+	prov := sources.provider
 
-	switch cfg.ProviderType {
-	case "file":
-		prov, err = fileProv.New(cfg.FilePath)
+	var calcDomain domain.PackCalculator
+	switch cfg.CalcStrategy {
+	case "", "precomputed":
+		calcDomain = domain.NewPrecomputedCalculator(domain.DefaultSolverCells)
+	case "dp":
+		calcDomain = domain.NewPackCalculator()
 	default:
-		return nil, fmt.Errorf("unknown provider type: %s", cfg.ProviderType)
+		return nil, fmt.Errorf("unknown calculation strategy: %s", cfg.CalcStrategy)
 	}
 
+	limits := usecases.Limits{
+		MaxQuantity:  cfg.MaxQuantity,
+		MaxPackSizes: cfg.MaxPackSizes,
+		MaxDPCells:   cfg.MaxDPCells,
+	}
+	calcUC, err := usecases.NewCalculatePacks(calcDomain, prov, usecases.WithLimits(limits))
 	if err != nil {
-		return nil, fmt.Errorf("init provider: %w", err)
+		return nil, err
 	}
 
-	calcDomain := domain.NewPackCalculator()
-
-	calcUC, err := usecases.NewCalculatePacks(calcDomain, prov)
+	var calcCache *usecases.CachedCalculatePacks
+	if cfg.CacheSize > 0 {
+		calcCache, err = usecases.NewCachedCalculatePacks(calcUC, prov, usecases.CacheOptions{
+			MaxEntries: cfg.CacheSize,
+			TTL:        cfg.CacheTTL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		calcUC = calcCache
+	}
+	getUC, err := usecases.NewGetPackSizes(prov)
 	if err != nil {
 		return nil, err
 	}
-	getUC, err := usecases.NewGetPackSizes(prov)
+
+	validation, err := ginadapter.ParseValidationMode(cfg.OpenAPIValidation)
 	if err != nil {
 		return nil, err
 	}
 
 	controller := ctr.NewController(calcUC, getUC)
-	handler := ginadapter.BuildHandler(controller)
+	controller.Limits = usecases.NewGetLimits(limits)
+	if controller.Versions, err = usecases.NewListPackSizeVersions(prov); err != nil {
+		return nil, err
+	}
+	if controller.Upcoming, err = usecases.NewListUpcomingPackSizes(prov, nil); err != nil {
+		return nil, err
+	}
+	admins, err := adminTokens(cfg)
+	if err != nil {
+		return nil, err
+	}
+	auditLog, err := newAuditLog(cfg, admins)
+	if err != nil {
+		return nil, err
+	}
+	// one lock: a toggle cannot land between the lists of a schedule
+	auditOpts := usecases.AuditOptions{OnError: logAuditFailure, Lock: &sync.Mutex{}}
+	if _, ok := prov.(packsizes.Scheduler); ok && len(admins) > 0 {
+		if controller.Schedule, err = usecases.NewSchedulePackSizes(prov, nil); err != nil {
+			return nil, err
+		}
+		if auditLog != nil {
+			if controller.Schedule, err = usecases.NewAuditedSchedulePackSizes(controller.Schedule, prov, auditLog, auditOpts); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if _, ok := prov.(packsizes.Toggler); ok && len(admins) > 0 {
+		if controller.Toggle, err = usecases.NewSetPackSizeEnabled(prov); err != nil {
+			return nil, err
+		}
+		if auditLog != nil {
+			if controller.Toggle, err = usecases.NewAuditedSetPackSizeEnabled(controller.Toggle, prov, auditLog, auditOpts); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if auditLog != nil {
+		if controller.Audit, err = usecases.NewListAuditEntries(auditLog); err != nil {
+			return nil, err
+		}
+	}
+	watcher, err := newCatalogueWatcher(prov)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, changes, err := newWebhooks(cfg, prov)
+	if err != nil {
+		return nil, err
+	}
+	if webhooks != nil && len(admins) > 0 {
+		if controller.Deliveries, err = usecases.NewListWebhookDeliveries(webhooks); err != nil {
+			return nil, err
+		}
+	}
+	idemStore, err := newIdempotencyStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	idemFiles, _ := idemStore.(*idempotency.FileStore)
+
+	frontend, err := loadFrontend(cfg.WebDir)
+	if err != nil {
+		return nil, err
+	}
+	proxies, err := trustedProxies(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts := []ginadapter.Option{
+		ginadapter.WithTrustedProxies(proxies),
+		ginadapter.WithOpenAPIValidation(validation),
+		ginadapter.WithCompression(cfg.CompressMinSize),
+		ginadapter.WithPackSizesCacheControl(cfg.PackSizesCacheControl),
+		ginadapter.WithIdempotency(idemStore, cfg.IdempotencyTTL),
+		ginadapter.WithAdminTokens(admins),
+	}
+	if auditLog != nil {
+		opts = append(opts, ginadapter.WithAuditLog(auditLog))
+	}
+	if frontend != nil {
+		opts = append(opts, ginadapter.WithFrontend(frontend))
+	}
+	if sources.chain != nil {
+		opts = append(opts, ginadapter.WithReadinessCheck("packsizes", sourcesReadiness(sources.chain)))
+	}
+	handler := ginadapter.BuildHandler(controller, opts...)
+
+	metrics := maps.Clone(sources.metrics)
+	if metrics == nil {
+		metrics = map[string]expvar.Var{}
+	}
+	if calcCache != nil {
+		metrics["calc_cache"] = calcCacheStats(calcCache)
+	}
 
 	return &Container{
-		Calc: calcUC,
-		Get:  getUC,
-		HTTP: handler,
+		Calc:      calcUC,
+		Get:       getUC,
+		CalcCache: calcCache,
+		Watcher:   watcher,
+		Sources:   sources.chain,
+		Remote:    sources.remote,
+		Changes:   changes,
+		Webhooks:  webhooks,
+		Audit:     auditLog,
+		IdemFiles: idemFiles,
+		HTTP:      handler,
+		metrics:   metrics,
 	}, nil
 }
+
+// loadFrontend picks the React build: WEB_DIR when set, otherwise the one
+// embedded with -tags embedweb. nil means the API runs alone.
+func loadFrontend(dir string) (*ginadapter.Frontend, error) {
+	var fsys fs.FS
+	if dir != "" {
+		fsys = os.DirFS(dir)
+	} else if dist, ok := web.Dist(); ok {
+		fsys = dist
+	} else {
+		return nil, nil
+	}
+	frontend, err := ginadapter.LoadFrontend(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("load frontend: %w", err)
+	}
+	return frontend, nil
+}
+
+// newIdempotencyStore returns nil when Idempotency-Key support is off
+// (IDEMPOTENCY_STORE=off or no TTL).
+func newIdempotencyStore(cfg config.Config) (idempotency.Store, error) {
+	switch cfg.IdempotencyStore {
+	case "off":
+		return nil, nil
+	case "", "memory", "file":
+	default:
+		return nil, fmt.Errorf("unknown idempotency store: %s", cfg.IdempotencyStore)
+	}
+	if cfg.IdempotencyTTL <= 0 {
+		return nil, nil
+	}
+	if cfg.IdempotencyStore == "file" {
+		store, err := idempotency.NewFileStore(cfg.IdempotencyDir, idempotency.Options{
+			MaxEntries: cfg.IdempotencyMaxEntries,
+			MaxBytes:   cfg.IdempotencyMaxBytes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("init idempotency store: %w", err)
+		}
+		return store, nil
+	}
+	return idempotency.NewMemoryStore(idempotency.Options{MaxEntries: cfg.IdempotencyMaxEntries}), nil
+}
+
+// trustedProxies lists the TRUSTED_PROXIES (IPs or CIDRs); nil when unset,
+// so that no forwarded address is believed.
+func trustedProxies(cfg config.Config) ([]string, error) {
+	var proxies []string
+	for _, p := range strings.Split(cfg.TrustedProxies, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if _, err := netip.ParsePrefix(p); err != nil {
+			if _, err := netip.ParseAddr(p); err != nil {
+				return nil, fmt.Errorf("TRUSTED_PROXIES: %q is not an IP or a CIDR", p)
+			}
+		}
+		proxies = append(proxies, p)
+	}
+	return proxies, nil
+}
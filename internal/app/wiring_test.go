@@ -2,14 +2,18 @@ package app
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/reangeline/go-shipping-products/internal/app/config"
+	usecases "github.com/reangeline/go-shipping-products/internal/core/usecase/order"
 )
 
 func doRequest(h http.Handler, method, path string, body []byte) (int, []byte) {
@@ -85,3 +89,551 @@ func TestWire_UnknownProvider_ReturnsError(t *testing.T) {
 		t.Fatalf("expected error for unknown provider type")
 	}
 }
+
+func TestWire_CalculationCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "packs.csv")
+	if err := os.WriteFile(path, []byte("250,500,1000"), 0o600); err != nil {
+		t.Fatalf("write packs file: %v", err)
+	}
+
+	container, err := Wire(config.Config{ProviderType: "file", FilePath: path, CacheSize: 16})
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	if container.CalcCache == nil {
+		t.Fatalf("expected cache to be wired")
+	}
+	for i := 0; i < 2; i++ {
+		if status, body := doRequest(container.HTTP, http.MethodPost, "/v1/calculate", []byte(`{"quantity":251}`)); status != http.StatusOK {
+			t.Fatalf("POST /v1/calculate status=%d body=%s", status, string(body))
+		}
+	}
+	if st := container.CalcCache.Stats(); st.Hits != 1 || st.Misses != 1 {
+		t.Fatalf("unexpected cache stats: %+v", st)
+	}
+	var published usecases.CacheStats
+	if err := json.Unmarshal([]byte(container.Metrics()["calc_cache"].String()), &published); err != nil || published.Hits != 1 {
+		t.Fatalf("calc_cache on /debug/vars: %+v (%v)", published, err)
+	}
+
+	container, err = Wire(config.Config{ProviderType: "file", FilePath: path})
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	if container.CalcCache != nil || container.Metrics()["calc_cache"] != nil {
+		t.Fatalf("cache must be disabled when CacheSize=0")
+	}
+}
+
+func TestWire_CalculationStrategies(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "packs.csv")
+	if err := os.WriteFile(path, []byte("250,500,1000,2000,5000"), 0o600); err != nil {
+		t.Fatalf("write packs file: %v", err)
+	}
+
+	for _, strategy := range []string{"dp", "precomputed"} {
+		container, err := Wire(config.Config{ProviderType: "file", FilePath: path, CalcStrategy: strategy})
+		if err != nil {
+			t.Fatalf("%s: Wire failed: %v", strategy, err)
+		}
+		status, body := doRequest(container.HTTP, http.MethodPost, "/v1/calculate", []byte(`{"quantity":12001}`))
+		if status != http.StatusOK {
+			t.Fatalf("%s: status=%d body=%s", strategy, status, string(body))
+		}
+		var resp struct {
+			TotalItems int `json:"totalItems"`
+			TotalPacks int `json:"totalPacks"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("%s: invalid json: %v", strategy, err)
+		}
+		if resp.TotalItems != 12250 || resp.TotalPacks != 4 {
+			t.Fatalf("%s: unexpected result: %+v", strategy, resp)
+		}
+	}
+
+	if _, err := Wire(config.Config{ProviderType: "file", FilePath: path, CalcStrategy: "magic"}); err == nil {
+		t.Fatalf("expected error for unknown strategy")
+	}
+}
+
+func TestWire_Limits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "packs.csv")
+	if err := os.WriteFile(path, []byte("250,500"), 0o600); err != nil {
+		t.Fatalf("write packs file: %v", err)
+	}
+
+	container, err := Wire(config.Config{ProviderType: "file", FilePath: path, MaxQuantity: 1000})
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+
+	status, body := doRequest(container.HTTP, http.MethodGet, "/v1/limits", nil)
+	if status != http.StatusOK || string(body) != `{"maxQuantity":1000,"maxPackSizes":0,"maxDpCells":0}` {
+		t.Fatalf("GET /v1/limits status=%d body=%s", status, string(body))
+	}
+
+	status, body = doRequest(container.HTTP, http.MethodPost, "/v1/calculate", []byte(`{"quantity":1001}`))
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("POST /v1/calculate status=%d want=422 body=%s", status, string(body))
+	}
+}
+
+func TestWire_DocsAreEmbedded(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "packs.csv")
+	if err := os.WriteFile(path, []byte("250,500"), 0o600); err != nil {
+		t.Fatalf("write packs file: %v", err)
+	}
+	// nothing from the repository is reachable from here
+	t.Chdir(dir)
+
+	container, err := Wire(config.Config{ProviderType: "file", FilePath: path})
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	for _, p := range []string{"/docs", "/docs/openapi.yaml", "/docs/openapi.json", "/docs/assets/swagger-ui-bundle.js"} {
+		if status, _ := doRequest(container.HTTP, http.MethodGet, p, nil); status != http.StatusOK {
+			t.Fatalf("GET %s status=%d want=200", p, status)
+		}
+	}
+
+	_, body := doRequest(container.HTTP, http.MethodGet, "/docs/openapi.json", nil)
+	var spec struct {
+		OpenAPI string         `json:"openapi"`
+		Paths   map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(body, &spec); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if spec.OpenAPI == "" || spec.Paths["/v1/calculate"] == nil {
+		t.Fatalf("unexpected spec: %.200s", body)
+	}
+}
+
+func TestWire_WebDir(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "packs.csv")
+	if err := os.WriteFile(path, []byte("250,500"), 0o600); err != nil {
+		t.Fatalf("write packs file: %v", err)
+	}
+	dist := filepath.Join(dir, "dist")
+	if err := os.Mkdir(dist, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	// no index.html: a misconfigured WEB_DIR fails at startup
+	if _, err := Wire(config.Config{ProviderType: "file", FilePath: path, WebDir: dist}); err == nil {
+		t.Fatalf("expected error for a WEB_DIR without index.html")
+	}
+
+	if err := os.WriteFile(filepath.Join(dist, "index.html"), []byte("<!doctype html>app"), 0o600); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+	container, err := Wire(config.Config{ProviderType: "file", FilePath: path, WebDir: dist})
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	if status, body := doRequest(container.HTTP, http.MethodGet, "/calculator", nil); status != http.StatusOK || string(body) != "<!doctype html>app" {
+		t.Fatalf("GET /calculator status=%d body=%s", status, string(body))
+	}
+	if status, _ := doRequest(container.HTTP, http.MethodGet, "/v1/packsizes", nil); status != http.StatusOK {
+		t.Fatalf("GET /v1/packsizes status=%d", status)
+	}
+}
+
+func TestWire_IdempotencyFileStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "packs.csv")
+	if err := os.WriteFile(path, []byte("250,500"), 0o600); err != nil {
+		t.Fatalf("write packs file: %v", err)
+	}
+	cfg := config.Config{
+		ProviderType:     "file",
+		FilePath:         path,
+		IdempotencyStore: "file",
+		IdempotencyDir:   filepath.Join(dir, "idempotency"),
+		IdempotencyTTL:   time.Hour,
+	}
+
+	post := func(h http.Handler) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/calculate", bytes.NewReader([]byte(`{"quantity":251}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "order-1")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	container, err := Wire(cfg)
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	first := post(container.HTTP)
+
+	// a restart keeps the records
+	container, err = Wire(cfg)
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	retry := post(container.HTTP)
+	if retry.Header().Get("Idempotent-Replayed") != "true" || retry.Body.String() != first.Body.String() {
+		t.Fatalf("retry not replayed: headers=%v body=%s", retry.Header(), retry.Body.String())
+	}
+
+	if _, err := Wire(config.Config{ProviderType: "file", FilePath: path, IdempotencyStore: "redis"}); err == nil {
+		t.Fatalf("expected error for unknown idempotency store")
+	}
+}
+
+func TestWire_ScheduledPackSizes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "packs.csv")
+	if err := os.WriteFile(path, []byte("250,500,1000"), 0o600); err != nil {
+		t.Fatalf("write packs file: %v", err)
+	}
+	schedule := []byte(`{"version":"no-250","effectiveFrom":"2999-01-01T00:00:00Z","sizes":[500,1000]}`)
+
+	// without ADMIN_TOKEN the admin route does not exist
+	container, err := Wire(config.Config{ProviderType: "file", FilePath: path})
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	if status, _ := doRequest(container.HTTP, http.MethodPost, "/v1/admin/packsizes/schedule", schedule); status != http.StatusNotFound {
+		t.Fatalf("schedule without ADMIN_TOKEN status=%d want=404", status)
+	}
+
+	container, err = Wire(config.Config{ProviderType: "file", FilePath: path, AdminToken: "s3cret"})
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	if container.Watcher == nil {
+		t.Fatalf("the file provider has a history: want a watcher")
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/packsizes/schedule", bytes.NewReader(schedule))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	container.HTTP.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("schedule status=%d want=201 body=%s", rec.Code, rec.Body.String())
+	}
+
+	status, body := doRequest(container.HTTP, http.MethodGet, "/v1/packsizes/upcoming", nil)
+	if status != http.StatusOK || !bytes.Contains(body, []byte(`"version":"no-250"`)) {
+		t.Fatalf("GET /v1/packsizes/upcoming status=%d body=%s", status, body)
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.Contains(data, []byte("# version=no-250 effective=2999-01-01T00:00:00Z\n500,1000\n")) {
+		t.Fatalf("the schedule must be written to the file, got:\n%s", data)
+	}
+}
+
+func TestWire_DisabledPackSizes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "packs.yaml")
+	content := "packs:\n  - {size: 250, label: Envelope}\n  - 500\n  - 1000\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write packs file: %v", err)
+	}
+	container, err := Wire(config.Config{ProviderType: "file", FilePath: path, AdminToken: "s3cret"})
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/packsizes/250/disable", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	container.HTTP.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"enabled":false`)) {
+		t.Fatalf("disable status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	status, body := doRequest(container.HTTP, http.MethodGet, "/v1/packsizes?status=disabled", nil)
+	if status != http.StatusOK || !bytes.Contains(body, []byte(`"sizes":[500,1000],"packs":[{"size":250,"enabled":false,"label":"Envelope"}]`)) {
+		t.Fatalf("GET /v1/packsizes?status=disabled status=%d body=%s", status, body)
+	}
+	status, body = doRequest(container.HTTP, http.MethodPost, "/v1/calculate", []byte(`{"quantity":1}`))
+	if status != http.StatusOK || !bytes.Contains(body, []byte(`"itemsByPack":{"500":1}`)) {
+		t.Fatalf("calculate must skip the disabled size, status=%d body=%s", status, body)
+	}
+}
+
+func TestWire_ProviderChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "packs.csv") // not there yet
+	t.Setenv("TEST_CHAIN_PACK_SIZES", "")
+	cfg := config.Config{
+		ProviderType:  "chain",
+		ProviderChain: "file, env, defaults",
+		FilePath:      path,
+		EnvVar:        "TEST_CHAIN_PACK_SIZES",
+		DefaultSizes:  "250,500",
+	}
+	container, err := Wire(cfg)
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	if container.Sources == nil {
+		t.Fatalf("Sources must be set for the chain provider")
+	}
+
+	// the missing file and the unset variable fall back to the defaults
+	status, body := doRequest(container.HTTP, http.MethodGet, "/v1/packsizes", nil)
+	if status != http.StatusOK || !bytes.Contains(body, []byte(`"sizes":[250,500]`)) {
+		t.Fatalf("GET /v1/packsizes status=%d body=%s", status, body)
+	}
+	status, body = doRequest(container.HTTP, http.MethodGet, "/readyz", nil)
+	if status != http.StatusOK || !bytes.Contains(body, []byte(`"status":"degraded"`)) || !bytes.Contains(body, []byte(`"failing":["env","file"]`)) {
+		t.Fatalf("GET /readyz status=%d body=%s", status, body)
+	}
+
+	// the file joins once it is there
+	if err := os.WriteFile(path, []byte("1000,2000"), 0o600); err != nil {
+		t.Fatalf("write packs file: %v", err)
+	}
+	status, body = doRequest(container.HTTP, http.MethodGet, "/readyz", nil)
+	if status != http.StatusOK || !bytes.Contains(body, []byte(`"status":"ok"`)) || !bytes.Contains(body, []byte(`"source":"file"`)) {
+		t.Fatalf("GET /readyz status=%d body=%s", status, body)
+	}
+	if s := container.Sources.Status(); s.Source != "file" || s.Degraded {
+		t.Fatalf("Status got %+v", s)
+	}
+
+	// one fallback, over: the metrics are the container's
+	metrics := container.Metrics()
+	if metrics["packsizes_source"].String() != `"file"` || metrics["packsizes_degraded"].String() != "0" ||
+		metrics["packsizes_fallbacks"].String() != "1" || metrics["packsizes_stale_seconds"].String() != "0" {
+		t.Fatalf("metrics got %v", metrics)
+	}
+}
+
+func TestWire_ProviderChain_Unavailable(t *testing.T) {
+	cfg := config.Config{
+		ProviderType:  "chain",
+		ProviderChain: "file",
+		FilePath:      filepath.Join(t.TempDir(), "missing.csv"),
+	}
+	container, err := Wire(cfg)
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	status, body := doRequest(container.HTTP, http.MethodGet, "/v1/packsizes", nil)
+	if status != http.StatusServiceUnavailable || !bytes.Contains(body, []byte(`"code":"pack_sizes_unavailable"`)) {
+		t.Fatalf("GET /v1/packsizes status=%d body=%s", status, body)
+	}
+	if status, body := doRequest(container.HTTP, http.MethodGet, "/readyz", nil); status != http.StatusServiceUnavailable {
+		t.Fatalf("GET /readyz status=%d body=%s", status, body)
+	}
+
+	if _, err := Wire(config.Config{ProviderType: "chain", ProviderChain: "file,remote"}); err == nil {
+		t.Fatalf("expected an error for an unknown source")
+	}
+}
+
+func TestWire_RemoteProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer catalogue-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("ETag", `"2025-09"`)
+		_, _ = w.Write([]byte(`{"sizes":[300,600]}`))
+	}))
+	defer srv.Close()
+
+	container, err := Wire(config.Config{ProviderType: "chain", ProviderChain: "http,defaults", DefaultSizes: "250", RemoteURL: srv.URL, RemoteToken: "catalogue-token"})
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	if container.Remote == nil {
+		t.Fatalf("Remote must be set for the http provider")
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/packsizes", nil)
+	rec := httptest.NewRecorder()
+	container.HTTP.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"sizes":[300,600]`)) || rec.Header().Get("ETag") != `W/"2025-09"` {
+		t.Fatalf("GET /v1/packsizes status=%d etag=%q body=%s", rec.Code, rec.Header().Get("ETag"), rec.Body.String())
+	}
+	if s := container.Sources.Status(); s.Source != "http" || s.Degraded {
+		t.Fatalf("Status got %+v", s)
+	}
+
+	if _, err := Wire(config.Config{ProviderType: "http", RemoteURL: "catalogue.internal"}); err == nil {
+		t.Fatalf("expected an error for a URL without scheme")
+	}
+}
+
+func TestWire_SQLProvider(t *testing.T) {
+	cfg := config.Config{
+		ProviderType: "sql",
+		SQLDriver:    "sqlite",
+		SQLDSN:       filepath.Join(t.TempDir(), "packs.db"),
+		Warehouse:    "lisbon",
+		DefaultSizes: "250,500,1000",
+		AdminToken:   "s3cret",
+	}
+	container, err := Wire(cfg)
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+
+	// a fresh warehouse starts with the defaults
+	status, body := doRequest(container.HTTP, http.MethodGet, "/v1/packsizes", nil)
+	if status != http.StatusOK || !bytes.Contains(body, []byte(`"sizes":[250,500,1000]`)) {
+		t.Fatalf("GET /v1/packsizes status=%d body=%s", status, body)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/packsizes/250/disable", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	container.HTTP.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("disable status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	// the change is in the database: another instance sees it
+	again, err := Wire(cfg)
+	if err != nil {
+		t.Fatalf("Wire again: %v", err)
+	}
+	status, body = doRequest(again.HTTP, http.MethodGet, "/v1/packsizes", nil)
+	if status != http.StatusOK || !bytes.Contains(body, []byte(`"sizes":[500,1000]`)) {
+		t.Fatalf("GET /v1/packsizes status=%d body=%s", status, body)
+	}
+}
+
+func TestWire_Webhooks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "packs.csv")
+	if err := os.WriteFile(path, []byte("250,500,1000"), 0o600); err != nil {
+		t.Fatalf("write packs file: %v", err)
+	}
+	received := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+	}))
+	defer receiver.Close()
+
+	cfg := config.Config{
+		ProviderType:  "file",
+		FilePath:      path,
+		AdminToken:    "s3cret",
+		WebhookURLs:   receiver.URL,
+		WebhookSecret: "hook-secret",
+		WebhookDir:    filepath.Join(dir, "webhooks"),
+	}
+	if _, err := Wire(config.Config{ProviderType: "file", FilePath: path, WebhookURLs: receiver.URL}); err == nil {
+		t.Fatalf("expected an error without WEBHOOK_SECRET")
+	}
+	container, err := Wire(cfg)
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go container.Webhooks.Run(ctx)
+
+	if changed, err := container.Changes.Check(ctx); changed || err != nil {
+		t.Fatalf("first check: %v %v", changed, err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/packsizes/250/disable", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	container.HTTP.ServeHTTP(httptest.NewRecorder(), req)
+	if changed, err := container.Changes.Check(ctx); !changed || err != nil {
+		t.Fatalf("check after the change: %v %v", changed, err)
+	}
+
+	select {
+	case body := <-received:
+		if !bytes.Contains(body, []byte(`"current":{"version":"`)) || !bytes.Contains(body, []byte(`"sizes":[500,1000]`)) {
+			t.Fatalf("webhook body %s", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no webhook received")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/admin/webhooks/deliveries", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		rec := httptest.NewRecorder()
+		container.HTTP.ServeHTTP(rec, req)
+		if rec.Code == http.StatusOK && bytes.Contains(rec.Body.Bytes(), []byte(`"status":"delivered"`)) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("deliveries status=%d body=%s", rec.Code, rec.Body.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWire_AuditTrail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "packs.yaml")
+	if err := os.WriteFile(path, []byte("packs: [250, 500, 1000]\n"), 0o600); err != nil {
+		t.Fatalf("write packs file: %v", err)
+	}
+	container, err := Wire(config.Config{
+		ProviderType: "file", FilePath: path,
+		AdminTokens: "alice:a-token, bob:b-token",
+		AuditLog:    filepath.Join(dir, "audit.jsonl"),
+	})
+	if err != nil {
+		t.Fatalf("Wire failed: %v", err)
+	}
+	if container.Audit == nil {
+		t.Fatalf("expected an audit log with AUDIT_LOG and admin tokens")
+	}
+
+	send := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("X-Request-Id", "req-"+token)
+		rec := httptest.NewRecorder()
+		container.HTTP.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := send(http.MethodPost, "/v1/admin/packsizes/250/disable", "a-token"); rec.Code != http.StatusOK {
+		t.Fatalf("disable status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	rec := send(http.MethodGet, "/v1/audit?actor=alice", "b-token")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /v1/audit status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var res struct {
+		Entries []struct {
+			Action    string `json:"action"`
+			Actor     string `json:"actor"`
+			RequestID string `json:"requestId"`
+			Before    *struct {
+				Sizes []int `json:"sizes"`
+			} `json:"before"`
+			After *struct {
+				Sizes []int `json:"sizes"`
+			} `json:"after"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	// the call is recorded once answered, after the change it made
+	if len(res.Entries) != 2 || res.Entries[0].Action != "admin.call" || res.Entries[1].Action != "packsizes.disable" {
+		t.Fatalf("unexpected entries: %s", rec.Body.String())
+	}
+	change := res.Entries[1]
+	if change.Actor != "alice" || change.RequestID != "req-a-token" || change.Before == nil || change.After == nil ||
+		len(change.Before.Sizes) != 3 || len(change.After.Sizes) != 2 {
+		t.Fatalf("unexpected change: %s", rec.Body.String())
+	}
+
+	if _, err := Wire(config.Config{ProviderType: "file", FilePath: path, AdminTokens: "alice"}); err == nil {
+		t.Fatalf("expected an error for ADMIN_TOKENS without a token")
+	}
+	if _, err := Wire(config.Config{ProviderType: "file", FilePath: path, TrustedProxies: "10.0.0.0/8, lb"}); err == nil {
+		t.Fatalf("expected an error for a TRUSTED_PROXIES entry that is not an IP")
+	}
+}
@@ -0,0 +1,127 @@
+// Package apperr is the error model shared by every layer of the core.
+// Domain and use cases return *Error values; transports (HTTP today) map them
+// by Kind instead of comparing sentinels one by one.
+package apperr
+
+import (
+	"errors"
+	"maps"
+)
+
+// Kind classifies an error independently of any transport.
+type Kind int
+
+const (
+	KindInternal      Kind = iota // bug or unexpected failure; details are not exposed
+	KindInvalid                   // malformed or out-of-range input
+	KindUnprocessable             // well-formed input the service cannot process
+	KindNotFound                  // the requested resource does not exist
+	KindConflict                  // the request conflicts with the current state
+	KindUnavailable               // a dependency is temporarily unavailable
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindInvalid:
+		return "invalid"
+	case KindUnprocessable:
+		return "unprocessable"
+	case KindNotFound:
+		return "not_found"
+	case KindConflict:
+		return "conflict"
+	case KindUnavailable:
+		return "unavailable"
+	default:
+		return "internal"
+	}
+}
+
+// Violation points at the part of the input that was rejected.
+type Violation struct {
+	Field  string // JSON path, e.g. "packsOverride[2]"
+	Reason string
+}
+
+// Error is the typed error.
+// - Code: stable snake_case identifier, also used by errors.Is
+// - Params: structured context (e.g. the limit that was exceeded)
+// - Violations: field-level problems
+// - Err: optional cause
+type Error struct {
+	Kind       Kind
+	Code       string
+	Message    string
+	Params     map[string]any
+	Violations []Violation
+	Err        error
+}
+
+// New declares an error; package-level values work as sentinels.
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is matches any *Error with the same Code, so errors built with With,
+// WithViolation or Wrap still satisfy errors.Is(err, Sentinel).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// With returns a copy carrying params (merged with the existing ones).
+func (e *Error) With(params map[string]any) *Error {
+	c := e.clone()
+	if c.Params == nil {
+		c.Params = make(map[string]any, len(params))
+	}
+	maps.Copy(c.Params, params)
+	return c
+}
+
+// WithViolation returns a copy with one more field-level violation.
+func (e *Error) WithViolation(field, reason string) *Error {
+	c := e.clone()
+	c.Violations = append(c.Violations, Violation{Field: field, Reason: reason})
+	return c
+}
+
+// Wrap returns a copy whose cause is err.
+func (e *Error) Wrap(err error) *Error {
+	c := e.clone()
+	c.Err = err
+	return c
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	c.Params = maps.Clone(e.Params)
+	c.Violations = append([]Violation(nil), e.Violations...)
+	return &c
+}
+
+// As returns the first *Error in err's chain.
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// KindOf returns the Kind of err; unknown errors are KindInternal.
+func KindOf(err error) Kind {
+	if e, ok := As(err); ok {
+		return e.Kind
+	}
+	return KindInternal
+}
@@ -0,0 +1,52 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+var errSample = New(KindInvalid, "sample", "sample failed")
+
+func TestError_IsMatchesByCode(t *testing.T) {
+	derived := errSample.With(map[string]any{"max": 1}).WithViolation("quantity", "must be > 0")
+	wrapped := fmt.Errorf("use case: %w", derived)
+
+	if !errors.Is(wrapped, errSample) {
+		t.Fatalf("derived error must match its sentinel")
+	}
+	if errors.Is(wrapped, New(KindInvalid, "other", "other")) {
+		t.Fatalf("different codes must not match")
+	}
+	if errSample.Params != nil || errSample.Violations != nil {
+		t.Fatalf("sentinel was mutated: %+v", errSample)
+	}
+
+	e, ok := As(wrapped)
+	if !ok || e.Params["max"] != 1 || len(e.Violations) != 1 || e.Violations[0].Field != "quantity" {
+		t.Fatalf("unexpected error: %+v", e)
+	}
+}
+
+func TestError_WrapKeepsCause(t *testing.T) {
+	err := errSample.Wrap(io.EOF)
+	if !errors.Is(err, io.EOF) || !errors.Is(err, errSample) {
+		t.Fatalf("wrap must keep both the code and the cause")
+	}
+	if err.Error() != "sample failed: EOF" {
+		t.Fatalf("message got=%q", err.Error())
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	if KindOf(fmt.Errorf("x: %w", errSample)) != KindInvalid {
+		t.Fatalf("kind must be found through wrapping")
+	}
+	if KindOf(errors.New("plain")) != KindInternal {
+		t.Fatalf("unknown errors are internal")
+	}
+	if KindUnprocessable.String() != "unprocessable" || Kind(99).String() != "internal" {
+		t.Fatalf("unexpected kind names")
+	}
+}
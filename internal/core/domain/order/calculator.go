@@ -1,65 +1,82 @@
 package order
 
-import (
-	"errors"
-	"math"
-	"sort"
-)
+import "math"
 
 // To calculate the best package combination
 type PackCalculator interface {
 	Calculate(quantity int, packs []Pack) (Combination, error)
 }
 
+// OneOffCalculator is implemented by calculators that keep state per pack
+// set. CalculateOnce answers without keeping any: it is meant for pack sets
+// chosen by a client (e.g. an override), which must neither fill nor churn
+// that state.
+type OneOffCalculator interface {
+	CalculateOnce(quantity int, packs []Pack) (Combination, error)
+}
+
+// CostEstimator is implemented by calculators that do not allocate the DP
+// table of EstimateDPCells: EstimateCells returns the cells Calculate would
+// allocate for quantity and packs, so a caller can refuse it first.
+type CostEstimator interface {
+	EstimateCells(quantity int, packs []Pack) int
+}
+
 type packCalculator struct{}
 
+// compile-time check: the DP calculator is also able to explain its answers
+var _ Explainer = (*packCalculator)(nil)
+
 func NewPackCalculator() PackCalculator { return &packCalculator{} }
 
 func (pc *packCalculator) Calculate(quantity int, packs []Pack) (Combination, error) {
+	s, err := search(quantity, packs)
+	if err != nil {
+		return Combination{}, err
+	}
+	return s.combination(s.best)
+}
+
+// searchState keeps the DP tables so the result can be rebuilt
+// (Calculate) or compared against its alternatives (Explain).
+type searchState struct {
+	quantity    int
+	g           int   // GCD of all pack sizes
+	qScaled     int   // ceil(quantity/g)
+	upper       int   // last scaled total inspected
+	sizesScaled []int // packs in “unit of g”, asc
+	dp          []int // dp[t] = minimum packs to sum exactly t
+	prev        []int // prev[t] = last pack used to get to t
+	best        int   // chosen scaled total
+}
+
+const inf = math.MaxInt32
+
+func search(quantity int, packs []Pack) (*searchState, error) {
 	// Basic validations
 	if quantity <= 0 {
-		return Combination{}, errors.New("quantity must be > 0")
-	}
-	if len(packs) == 0 {
-		return Combination{}, errors.New("at least one pack size is required")
+		return nil, ErrInvalidQuantity
 	}
 
 	// Normalize and remove duplicated packs
-	sizeSet := make(map[int]struct{})
-	var sizes []int
-	maxPack := 0
-	for _, p := range packs {
-		if p.Size <= 0 {
-			return Combination{}, errors.New("pack size must be > 0")
-		}
-		if _, ok := sizeSet[p.Size]; !ok {
-			sizeSet[p.Size] = struct{}{}
-			sizes = append(sizes, p.Size)
-			if p.Size > maxPack {
-				maxPack = p.Size
-			}
-		}
-	}
-
-	if len(sizes) == 0 {
-		return Combination{}, errors.New("no valid pack sizes")
+	sizes, err := normalizeSizes(packs)
+	if err != nil {
+		return nil, err
 	}
-	sort.Ints(sizes)
 
 	// Otiumization: scale for GCD (reduce the DP size)
 	g := gcdAll(sizes)
-	qScaled := (quantity + g - 1) / g      // ceil(quantity/g)
-	sizesScaled := make([]int, len(sizes)) // packs in “unit of g”
+	qScaled := (quantity + g - 1) / g // ceil(quantity/g)
+	sizesScaled := make([]int, len(sizes))
 	for i, s := range sizes {
 		sizesScaled[i] = s / g
 	}
 	maxPackScaled := sizesScaled[len(sizesScaled)-1]
 
 	// DP scaled size:
-	// dp[t] = minimum packs to sum exactly t
-	// prev[t] = last pack used to get to t
+	// no optimal total goes beyond qScaled + maxPack - 1, otherwise
+	// removing its biggest pack would still cover the quantity
 	upper := qScaled + maxPackScaled - 1
-	const inf = math.MaxInt32
 	dp := make([]int, upper+1)
 	prev := make([]int, upper+1)
 	for i := range dp {
@@ -90,34 +107,59 @@ func (pc *packCalculator) Calculate(quantity int, packs []Pack) (Combination, er
 		}
 	}
 	if bestTotalScaled == -1 {
-		return Combination{}, errors.New("no feasible combination found")
+		return nil, ErrNoFeasibleCombination
 	}
 
-	// Reconstructs counts (to scale)
-	countsScaled := make(map[int]int)
-	for t := bestTotalScaled; t > 0; {
-		s := prev[t]
-		if s <= 0 {
-			return Combination{}, errors.New("internal reconstruction error")
-		}
-		countsScaled[s]++
-		t -= s
+	return &searchState{
+		quantity:    quantity,
+		g:           g,
+		qScaled:     qScaled,
+		upper:       upper,
+		sizesScaled: sizesScaled,
+		dp:          dp,
+		prev:        prev,
+		best:        bestTotalScaled,
+	}, nil
+}
+
+// combination rebuilds the minimum-packs combination for the scaled total t.
+func (s *searchState) combination(t int) (Combination, error) {
+	counts, err := s.countsScaled(t)
+	if err != nil {
+		return Combination{}, err
 	}
+	return s.descale(counts, t), nil
+}
 
-	// “De-scale” to real values
+// Reconstructs counts (to scale)
+func (s *searchState) countsScaled(t int) (map[int]int, error) {
 	counts := make(map[int]int)
-	for sScaled, c := range countsScaled {
-		counts[sScaled*g] = c
+	for t > 0 {
+		p := s.prev[t]
+		if p <= 0 {
+			return nil, ErrReconstruction
+		}
+		counts[p]++
+		t -= p
 	}
-	totalItems := bestTotalScaled * g
-	leftover := totalItems - quantity
+	return counts, nil
+}
 
+// “De-scale” to real values
+func (s *searchState) descale(countsScaled map[int]int, totalScaled int) Combination {
+	counts := make(map[int]int, len(countsScaled))
+	packs := 0
+	for sScaled, c := range countsScaled {
+		counts[sScaled*s.g] = c
+		packs += c
+	}
+	totalItems := totalScaled * s.g
 	return Combination{
 		ItemsByPack: counts,
 		TotalItems:  totalItems,
-		TotalPacks:  bestPacks,
-		Leftover:    leftover,
-	}, nil
+		TotalPacks:  packs,
+		Leftover:    totalItems - s.quantity,
+	}
 }
 
 // Helpers GCD (Euclides) greatest common divisor
@@ -134,3 +176,15 @@ func gcdAll(nums []int) int {
 	}
 	return g
 }
+
+// EstimateDPCells returns how many cells the DP table needs for quantity
+// with the given packs: ceil(quantity/GCD) + largest/GCD. It lets callers
+// refuse a calculation before allocating anything. Invalid input yields 0.
+func EstimateDPCells(quantity int, packs []Pack) int {
+	sizes, err := normalizeSizes(packs)
+	if err != nil || quantity <= 0 {
+		return 0
+	}
+	g := gcdAll(sizes)
+	return (quantity+g-1)/g + sizes[len(sizes)-1]/g
+}
@@ -0,0 +1,168 @@
+package order
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// bruteForce is the reference solver: it enumerates every count vector whose
+// total stays below quantity + largest (an optimal total is never beyond
+// that, otherwise its largest pack could be removed) and keeps the best by
+// (1) fewer items, (2) fewer packs. The last size only takes the fewest
+// packs that reach quantity: any more ships more items in more packs. Only
+// usable on small inputs.
+func bruteForce(quantity int, sizes []int) (totalItems, totalPacks int) {
+	largest := 0
+	for _, s := range sizes {
+		largest = max(largest, s)
+	}
+	bound := quantity + largest - 1
+
+	bestItems, bestPacks := -1, -1
+	var walk func(i, total, packs int)
+	walk = func(i, total, packs int) {
+		if i == len(sizes)-1 {
+			n := 0
+			if total < quantity {
+				n = (quantity - total + sizes[i] - 1) / sizes[i]
+			}
+			total, packs = total+n*sizes[i], packs+n
+			if total > bound {
+				return
+			}
+			if bestItems == -1 || total < bestItems || (total == bestItems && packs < bestPacks) {
+				bestItems, bestPacks = total, packs
+			}
+			return
+		}
+		for n := 0; total+n*sizes[i] <= bound; n++ {
+			walk(i+1, total+n*sizes[i], packs+n)
+		}
+	}
+	walk(0, 0, 0)
+	return bestItems, bestPacks
+}
+
+func checkAgainstOracle(t *testing.T, pc PackCalculator, qty int, sizes []int) {
+	t.Helper()
+	packs := make([]Pack, len(sizes))
+	for i, s := range sizes {
+		packs[i] = Pack{Size: s}
+	}
+	comb, err := pc.Calculate(qty, packs)
+	if err != nil {
+		t.Fatalf("qty=%d sizes=%v: unexpected error: %v", qty, sizes, err)
+	}
+	assertInvariants(t, qty, comb, sizes)
+
+	wantItems, wantPacks := bruteForce(qty, sizes)
+	if comb.TotalItems != wantItems || comb.TotalPacks != wantPacks {
+		t.Fatalf("qty=%d sizes=%v: got items=%d packs=%d, oracle items=%d packs=%d",
+			qty, sizes, comb.TotalItems, comb.TotalPacks, wantItems, wantPacks)
+	}
+}
+
+// bothCalculators are the strategies of CALC_STRATEGY.
+func bothCalculators() map[string]PackCalculator {
+	return map[string]PackCalculator{
+		"dp":          NewPackCalculator(),
+		"precomputed": NewPrecomputedCalculator(0),
+	}
+}
+
+func TestPackCalculator_MatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewPCG(30, 7807))
+	calculators := bothCalculators()
+
+	for i := 0; i < 300; i++ {
+		n := 1 + r.IntN(4)
+		sizes := make([]int, n)
+		for j := range sizes {
+			sizes[j] = 2 + r.IntN(39) // duplicates on purpose
+		}
+		qty := 1 + r.IntN(120)
+
+		for name, pc := range calculators {
+			t.Run(name, func(t *testing.T) {
+				checkAgainstOracle(t, pc, qty, sizes)
+			})
+		}
+	}
+}
+
+// With sizes up to 12 the solver threshold, (L-1)*second + L items, is at
+// most 144: these quantities are answered by its periodic jump.
+func TestPackCalculator_MatchesBruteForceBeyondSolverThreshold(t *testing.T) {
+	r := rand.New(rand.NewPCG(30, 144))
+	calculators := bothCalculators()
+
+	for i := 0; i < 300; i++ {
+		n := 1 + r.IntN(3)
+		sizes := make([]int, n)
+		for j := range sizes {
+			sizes[j] = 2 + r.IntN(11)
+		}
+		qty := 145 + r.IntN(400)
+
+		for name, pc := range calculators {
+			t.Run(name, func(t *testing.T) {
+				checkAgainstOracle(t, pc, qty, sizes)
+			})
+		}
+	}
+}
+
+// FuzzPackCalculator runs both calculators: against the brute-force oracle
+// while the input is small enough for it, against each other otherwise
+// (quantities far beyond the solver threshold).
+// Seed corpus: testdata/fuzz/FuzzPackCalculator.
+func FuzzPackCalculator(f *testing.F) {
+	f.Add(uint16(12001), uint8(250), uint8(50), uint8(100), uint8(200))
+	f.Add(uint16(501), uint8(250), uint8(5), uint8(10), uint8(0))
+	f.Add(uint16(1), uint8(1), uint8(0), uint8(0), uint8(0))
+	f.Add(uint16(65535), uint8(23), uint8(31), uint8(0), uint8(0))
+
+	calculators := bothCalculators()
+	f.Fuzz(func(t *testing.T, qty uint16, a, b, c, d uint8) {
+		var sizes []int
+		for _, v := range []uint8{a, b, c, d} {
+			if v != 0 {
+				sizes = append(sizes, int(v))
+			}
+		}
+		if len(sizes) == 0 {
+			for name, pc := range calculators {
+				if _, err := pc.Calculate(1+int(qty), nil); err == nil {
+					t.Fatalf("%s: expected error for empty packs", name)
+				}
+			}
+			return
+		}
+
+		small := make([]int, len(sizes))
+		for i, s := range sizes {
+			small[i] = 1 + s%40
+		}
+		for _, pc := range calculators {
+			checkAgainstOracle(t, pc, 1+int(qty)%150, small)
+		}
+
+		q := 1 + int(qty)
+		packs := make([]Pack, len(sizes))
+		for i, s := range sizes {
+			packs[i] = Pack{Size: s}
+		}
+		want, err := calculators["dp"].Calculate(q, packs)
+		if err != nil {
+			t.Fatalf("dp(%d, %v): %v", q, sizes, err)
+		}
+		got, err := calculators["precomputed"].Calculate(q, packs)
+		if err != nil {
+			t.Fatalf("precomputed(%d, %v): %v", q, sizes, err)
+		}
+		assertInvariants(t, q, got, sizes)
+		if got.TotalItems != want.TotalItems || got.TotalPacks != want.TotalPacks {
+			t.Fatalf("qty=%d sizes=%v: precomputed=%+v dp=%+v", q, sizes, got, want)
+		}
+	})
+}
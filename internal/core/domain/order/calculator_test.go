@@ -1,9 +1,12 @@
 package order
 
 import (
+	"errors"
 	"reflect"
 	"sort"
 	"testing"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
 )
 
 // helpers
@@ -40,14 +43,28 @@ func assertInvariants(t *testing.T, qty int, comb Combination, packs []int) {
 	if comb.Leftover != comb.TotalItems-qty {
 		t.Fatalf("Leftover inconsistente: got=%d want=%d", comb.Leftover, comb.TotalItems-qty)
 	}
-	// soma(size*count) == TotalItems
-	sum := 0
+	// soma(size*count) == TotalItems, soma(count) == TotalPacks, só tamanhos de packs (quando dados)
+	allowed := make(map[int]bool, len(packs))
+	for _, p := range packs {
+		allowed[p] = true
+	}
+	sum, count := 0, 0
 	for size, cnt := range comb.ItemsByPack {
+		if len(packs) > 0 && !allowed[size] {
+			t.Fatalf("ItemsByPack usa o tamanho %d fora de %v", size, packs)
+		}
+		if cnt <= 0 {
+			t.Fatalf("ItemsByPack[%d]=%d deve ser > 0", size, cnt)
+		}
 		sum += size * cnt
+		count += cnt
 	}
 	if sum != comb.TotalItems {
 		t.Fatalf("soma=%d != TotalItems=%d", sum, comb.TotalItems)
 	}
+	if count != comb.TotalPacks {
+		t.Fatalf("contagem=%d != TotalPacks=%d", count, comb.TotalPacks)
+	}
 }
 
 func TestPackCalculator_CanonicalCases(t *testing.T) {
@@ -130,13 +147,29 @@ func TestPackCalculator_CanonicalCases(t *testing.T) {
 func TestPackCalculator_ErrorsAndEdges(t *testing.T) {
 	pc := NewPackCalculator()
 
-	if _, err := pc.Calculate(0, mkPacks(t, 250)); err == nil {
-		t.Fatalf("expected error for qty<=0")
+	if _, err := pc.Calculate(0, mkPacks(t, 250)); !errors.Is(err, ErrInvalidQuantity) {
+		t.Fatalf("expected ErrInvalidQuantity for qty<=0, got %v", err)
+	}
+	if _, err := pc.Calculate(10, []Pack{}); !errors.Is(err, ErrNoPackSizes) {
+		t.Fatalf("expected ErrNoPackSizes for empty packs, got %v", err)
+	}
+	_, err := pc.Calculate(10, []Pack{{Size: -1}})
+	if !errors.Is(err, ErrInvalidPackSize) {
+		t.Fatalf("expected ErrInvalidPackSize for invalid pack size, got %v", err)
+	}
+	if e, ok := apperr.As(err); !ok || e.Kind != apperr.KindInvalid || e.Params["size"] != -1 {
+		t.Fatalf("expected typed error with the size, got %+v", e)
+	}
+}
+
+func TestEstimateDPCells(t *testing.T) {
+	if got := EstimateDPCells(12001, mkPacks(t, 250, 500, 1000, 2000, 5000)); got != 69 {
+		t.Fatalf("cells got=%d want=69", got)
 	}
-	if _, err := pc.Calculate(10, []Pack{}); err == nil {
-		t.Fatalf("expected error for empty packs")
+	if got := EstimateDPCells(1_000_000, mkPacks(t, 1, 7)); got != 1_000_007 {
+		t.Fatalf("cells got=%d want=1000007", got)
 	}
-	if _, err := pc.Calculate(10, []Pack{{Size: -1}}); err == nil {
-		t.Fatalf("expected error for invalid pack size")
+	if got := EstimateDPCells(0, mkPacks(t, 1)); got != 0 {
+		t.Fatalf("cells for invalid input got=%d want=0", got)
 	}
 }
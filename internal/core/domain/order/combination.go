@@ -1,5 +1,11 @@
 package order
 
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
 // Combination represents the result of the calculation: how many packages of each size,
 // total items, total packages, and leftovers.
 type Combination struct {
@@ -12,3 +18,17 @@ type Combination struct {
 func (c Combination) IsZero() bool {
 	return c.TotalItems == 0 && c.TotalPacks == 0
 }
+
+// key is a stable textual form of ItemsByPack ("250x1,5000x2").
+func (c Combination) key() string {
+	sizes := make([]int, 0, len(c.ItemsByPack))
+	for s := range c.ItemsByPack {
+		sizes = append(sizes, s)
+	}
+	sort.Ints(sizes)
+	parts := make([]string, 0, len(sizes))
+	for _, s := range sizes {
+		parts = append(parts, strconv.Itoa(s)+"x"+strconv.Itoa(c.ItemsByPack[s]))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,12 @@
+package order
+
+import "github.com/reangeline/go-shipping-products/internal/core/apperr"
+
+// Domain errors; the use cases and the transports reuse these values.
+var (
+	ErrInvalidQuantity       = apperr.New(apperr.KindInvalid, "invalid_quantity", "quantity must be > 0")
+	ErrNoPackSizes           = apperr.New(apperr.KindUnprocessable, "no_pack_sizes", "no pack sizes available")
+	ErrInvalidPackSize       = apperr.New(apperr.KindInvalid, "invalid_pack", "pack size must be > 0")
+	ErrNoFeasibleCombination = apperr.New(apperr.KindUnprocessable, "no_feasible_combination", "no feasible combination found")
+	ErrReconstruction        = apperr.New(apperr.KindInternal, "internal_reconstruction_error", "internal reconstruction error")
+)
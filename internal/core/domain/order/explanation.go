@@ -0,0 +1,125 @@
+package order
+
+// Explainer is implemented by calculators able to justify their result.
+type Explainer interface {
+	Explain(quantity int, packs []Pack) (Explanation, error)
+}
+
+// RejectionRule tells which ordering rule discarded a runner-up.
+type RejectionRule string
+
+const (
+	// RejectedByItems: the alternative ships more items (rule 1).
+	RejectedByItems RejectionRule = "items"
+	// RejectedByPacks: same items, but more packs (rule 2, tie-break).
+	RejectedByPacks RejectionRule = "packs"
+)
+
+// RunnerUp is a feasible combination that lost against the chosen one.
+type RunnerUp struct {
+	Combination
+	RejectedBy RejectionRule
+}
+
+// Explanation describes how the calculator reached its answer.
+// - GCD: common divisor used to scale the pack sizes down
+// - ScaledQuantity: ceil(quantity/GCD), the target of the search
+// - ScaledUpperBound: last scaled total inspected by the search
+// - RunnersUp: a few alternatives that were rejected, and why
+type Explanation struct {
+	Chosen           Combination
+	GCD              int
+	ScaledQuantity   int
+	ScaledUpperBound int
+	RunnersUp        []RunnerUp
+}
+
+// Keep the explanation short: it is meant to be read by a person.
+const (
+	maxItemsRunnersUp = 3
+	maxPacksRunnersUp = 3
+)
+
+func (pc *packCalculator) Explain(quantity int, packs []Pack) (Explanation, error) {
+	s, err := search(quantity, packs)
+	if err != nil {
+		return Explanation{}, err
+	}
+	chosen, err := s.combination(s.best)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	runnersUp, err := s.runnersUp()
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	return Explanation{
+		Chosen:           chosen,
+		GCD:              s.g,
+		ScaledQuantity:   s.qScaled,
+		ScaledUpperBound: s.upper,
+		RunnersUp:        runnersUp,
+	}, nil
+}
+
+// runnersUp collects the alternatives worth showing:
+//  1. totals above the chosen one where fewer packs become possible
+//     (the "why not 3x5000?" question), plus the next reachable total;
+//     all of them lose on items.
+//  2. combinations with the same total but more packs; they lose on the
+//     tie-break.
+func (s *searchState) runnersUp() ([]RunnerUp, error) {
+	var out []RunnerUp
+
+	// (1) rejected by items
+	var totals []int
+	fewest := s.dp[s.best]
+	for t := s.best + 1; t <= s.upper && len(totals) < maxItemsRunnersUp; t++ {
+		if s.dp[t] == inf {
+			continue
+		}
+		if len(totals) == 0 || s.dp[t] < fewest {
+			totals = append(totals, t)
+			if s.dp[t] < fewest {
+				fewest = s.dp[t]
+			}
+		}
+	}
+	for _, t := range totals {
+		comb, err := s.combination(t)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, RunnerUp{Combination: comb, RejectedBy: RejectedByItems})
+	}
+
+	// (2) rejected by packs: swap the last pack of a sub-total for another size
+	seen := make(map[string]struct{})
+	added := 0
+	for _, p := range s.sizesScaled {
+		if added == maxPacksRunnersUp {
+			break
+		}
+		rest := s.best - p
+		if rest < 0 || s.dp[rest] == inf || s.dp[rest]+1 <= s.dp[s.best] {
+			continue
+		}
+		counts, err := s.countsScaled(rest)
+		if err != nil {
+			return nil, err
+		}
+		counts[p]++
+		comb := s.descale(counts, s.best)
+		key := comb.key()
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, RunnerUp{Combination: comb, RejectedBy: RejectedByPacks})
+		added++
+	}
+
+	return out, nil
+}
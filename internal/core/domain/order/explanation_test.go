@@ -0,0 +1,81 @@
+package order
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPackCalculator_Explain(t *testing.T) {
+	pc := NewPackCalculator().(Explainer)
+
+	ex, err := pc.Explain(12001, mkPacks(t, 250, 500, 1000, 2000, 5000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ex.GCD != 250 || ex.ScaledQuantity != 49 || ex.ScaledUpperBound != 68 {
+		t.Fatalf("scaling got gcd=%d q=%d upper=%d", ex.GCD, ex.ScaledQuantity, ex.ScaledUpperBound)
+	}
+	want := map[int]int{5000: 2, 2000: 1, 250: 1}
+	if !reflect.DeepEqual(ex.Chosen.ItemsByPack, want) {
+		t.Fatalf("chosen got %v want %v", ex.Chosen.ItemsByPack, want)
+	}
+
+	// "why not 3x5000?" must be answered: fewer packs, but more items
+	var threeBig, byPacks bool
+	for _, r := range ex.RunnersUp {
+		assertInvariants(t, 12001, r.Combination, nil)
+		switch r.RejectedBy {
+		case RejectedByItems:
+			if r.TotalItems <= ex.Chosen.TotalItems {
+				t.Fatalf("items runner-up must ship more items: %+v", r)
+			}
+			if reflect.DeepEqual(r.ItemsByPack, map[int]int{5000: 3}) {
+				threeBig = true
+			}
+		case RejectedByPacks:
+			if r.TotalItems != ex.Chosen.TotalItems || r.TotalPacks <= ex.Chosen.TotalPacks {
+				t.Fatalf("packs runner-up must tie on items and use more packs: %+v", r)
+			}
+			byPacks = true
+		default:
+			t.Fatalf("unknown rule %q", r.RejectedBy)
+		}
+	}
+	if !threeBig {
+		t.Fatalf("expected 3x5000 among runners-up, got %+v", ex.RunnersUp)
+	}
+	if !byPacks {
+		t.Fatalf("expected at least one runner-up rejected by packs, got %+v", ex.RunnersUp)
+	}
+}
+
+func TestPackCalculator_Explain_MatchesCalculate(t *testing.T) {
+	pc := NewPackCalculator()
+	packs := mkPacks(t, 23, 31, 53)
+
+	comb, err := pc.Calculate(500_000, packs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ex, err := pc.(Explainer).Explain(500_000, packs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ex.Chosen, comb) {
+		t.Fatalf("explain chose %+v, calculate chose %+v", ex.Chosen, comb)
+	}
+	if ex.GCD != 1 {
+		t.Fatalf("gcd got=%d want=1", ex.GCD)
+	}
+}
+
+func TestPackCalculator_Explain_Errors(t *testing.T) {
+	pc := NewPackCalculator().(Explainer)
+	if _, err := pc.Explain(0, mkPacks(t, 250)); err == nil {
+		t.Fatalf("expected error for qty<=0")
+	}
+	if _, err := pc.Explain(10, nil); err == nil {
+		t.Fatalf("expected error for empty packs")
+	}
+}
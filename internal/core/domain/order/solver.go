@@ -0,0 +1,322 @@
+package order
+
+import (
+	"container/list"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxSolverTable caps the precomputed table (in scaled units). Pack sets
+// needing more than that fall back to the per-request DP.
+const MaxSolverTable = 1 << 22
+
+// Solver answers any quantity for one fixed pack set.
+//
+// After scaling by the GCD, an optimal combination never holds L or more
+// packs smaller than the largest one (L = largest scaled size): among L such
+// packs some subset sums to a multiple of L and could be swapped for fewer
+// largest packs. So beyond threshold = (L-1)*second + L every total is
+// reachable (it is above the Frobenius number) and its optimum is the optimum
+// of (total - k*L) plus k largest packs. The table only has to cover
+// [0, threshold + L); bigger quantities are answered by jumping back into it.
+type Solver struct {
+	g           int
+	sizesScaled []int // asc
+	largest     int   // scaled
+	threshold   int   // scaled
+	dp          []int // dp[t] = minimum packs to sum exactly t
+	prev        []int // prev[t] = last pack used to get to t
+	packs       []Pack
+	fallback    bool // table too big: delegate to the DP
+}
+
+// NewSolver precomputes the residue table for the given pack set.
+func NewSolver(packs []Pack) (*Solver, error) {
+	sizes, err := normalizeSizes(packs)
+	if err != nil {
+		return nil, err
+	}
+
+	g := gcdAll(sizes)
+	sizesScaled := make([]int, len(sizes))
+	for i, s := range sizes {
+		sizesScaled[i] = s / g
+	}
+	largest := sizesScaled[len(sizesScaled)-1]
+	second := 0
+	if len(sizesScaled) > 1 {
+		second = sizesScaled[len(sizesScaled)-2]
+	}
+
+	s := &Solver{
+		g:           g,
+		sizesScaled: sizesScaled,
+		largest:     largest,
+		packs:       append([]Pack(nil), packs...),
+	}
+
+	size, ok := tableSize(largest, second)
+	if !ok {
+		s.fallback = true
+		return s, nil
+	}
+	s.threshold = size - largest
+
+	s.dp = make([]int, size)
+	s.prev = make([]int, size)
+	for i := range s.dp {
+		s.dp[i], s.prev[i] = inf, -1
+	}
+	s.dp[0] = 0
+	for t := 0; t < size; t++ {
+		if s.dp[t] == inf {
+			continue
+		}
+		for _, p := range sizesScaled {
+			if nt := t + p; nt < size && s.dp[t]+1 < s.dp[nt] {
+				s.dp[nt] = s.dp[t] + 1
+				s.prev[nt] = p
+			}
+		}
+	}
+	return s, nil
+}
+
+// tableSize is (largest-1)*second + 2*largest, the cells of the table of
+// scaled sizes; false when it does not fit in MaxSolverTable.
+func tableSize(largest, second int) (int, bool) {
+	if second > 0 && largest-1 > (MaxSolverTable-2*largest)/second || 2*largest > MaxSolverTable {
+		return 0, false
+	}
+	return (largest-1)*second + 2*largest, true
+}
+
+// Solve returns the optimal combination for quantity
+// (minimum items, then minimum packs), like PackCalculator.Calculate.
+func (s *Solver) Solve(quantity int) (Combination, error) {
+	if quantity <= 0 {
+		return Combination{}, ErrInvalidQuantity
+	}
+	if s.fallback {
+		return (&packCalculator{}).Calculate(quantity, s.packs)
+	}
+
+	qScaled := (quantity + s.g - 1) / s.g
+
+	total, largestPacks := -1, 0
+	if qScaled > s.threshold {
+		// jump back into the table by whole largest packs
+		largestPacks = (qScaled - s.threshold + s.largest - 1) / s.largest
+		total = qScaled
+	} else {
+		// same search as the DP: less total >= qScaled (dp keeps fewer packs)
+		for t := qScaled; t < qScaled+s.largest; t++ {
+			if s.dp[t] != inf {
+				total = t
+				break
+			}
+		}
+		if total == -1 {
+			return Combination{}, ErrNoFeasibleCombination
+		}
+	}
+
+	countsScaled := make(map[int]int)
+	if largestPacks > 0 {
+		countsScaled[s.largest] = largestPacks
+	}
+	for t := total - largestPacks*s.largest; t > 0; {
+		p := s.prev[t]
+		if p <= 0 {
+			return Combination{}, ErrReconstruction
+		}
+		countsScaled[p]++
+		t -= p
+	}
+
+	counts := make(map[int]int, len(countsScaled))
+	packsUsed := 0
+	for p, c := range countsScaled {
+		counts[p*s.g] = c
+		packsUsed += c
+	}
+	totalItems := total * s.g
+	return Combination{
+		ItemsByPack: counts,
+		TotalItems:  totalItems,
+		TotalPacks:  packsUsed,
+		Leftover:    totalItems - quantity,
+	}, nil
+}
+
+// cells is the size of the table; 0 when the solver delegates to the DP.
+func (s *Solver) cells() int { return len(s.dp) }
+
+// normalizeSizes validates packs, removes duplicates and sorts asc.
+func normalizeSizes(packs []Pack) ([]int, error) {
+	if len(packs) == 0 {
+		return nil, ErrNoPackSizes
+	}
+	seen := make(map[int]struct{}, len(packs))
+	sizes := make([]int, 0, len(packs))
+	for _, p := range packs {
+		if p.Size <= 0 {
+			return nil, ErrInvalidPackSize.With(map[string]any{"size": p.Size})
+		}
+		if _, ok := seen[p.Size]; !ok {
+			seen[p.Size] = struct{}{}
+			sizes = append(sizes, p.Size)
+		}
+	}
+	sort.Ints(sizes)
+	return sizes, nil
+}
+
+// -------- PackCalculator backed by solvers --------
+
+// DefaultSolverCells bounds the table cells kept by all the solvers of a
+// precomputed calculator: two of the largest tables.
+const DefaultSolverCells = 2 * MaxSolverTable
+
+type precomputedCalculator struct {
+	maxCells  int
+	explainer packCalculator
+
+	mu      sync.Mutex
+	solvers map[string]*solverEntry // built or being built
+	lru     *list.List              // built solvers, front = most recently used
+	cells   int                     // table cells of the built solvers
+}
+
+// solverEntry is the solver of one pack set; ready is closed once s or err
+// is set, so concurrent callers of a new set wait for a single build.
+type solverEntry struct {
+	key   string
+	ready chan struct{}
+	s     *Solver
+	err   error
+	elem  *list.Element // nil until built and kept
+}
+
+// compile-time checks: explanations still come from the DP search, and so do
+// the one-off pack sets
+var (
+	_ Explainer        = (*precomputedCalculator)(nil)
+	_ OneOffCalculator = (*precomputedCalculator)(nil)
+	_ CostEstimator    = (*precomputedCalculator)(nil)
+)
+
+// NewPrecomputedCalculator returns a PackCalculator that builds one Solver
+// per pack set and reuses it across calls. A pack set that changes (e.g. the
+// provider was reloaded) simply gets a new solver; the least recently used
+// ones are dropped once their tables hold more than maxCells cells (<= 0
+// uses DefaultSolverCells). A table is built outside of the lock, once per
+// pack set however many callers ask for it.
+func NewPrecomputedCalculator(maxCells int) PackCalculator {
+	if maxCells <= 0 {
+		maxCells = DefaultSolverCells
+	}
+	return &precomputedCalculator{
+		maxCells: maxCells,
+		solvers:  make(map[string]*solverEntry),
+		lru:      list.New(),
+	}
+}
+
+func (pc *precomputedCalculator) Calculate(quantity int, packs []Pack) (Combination, error) {
+	if quantity <= 0 {
+		return Combination{}, ErrInvalidQuantity
+	}
+	s, err := pc.solver(packs)
+	if err != nil {
+		return Combination{}, err
+	}
+	return s.Solve(quantity)
+}
+
+// EstimateCells is the table Calculate would build: none when the pack set
+// already has a solver, the DP one when its table does not fit in
+// MaxSolverTable (the solver delegates to the DP).
+func (pc *precomputedCalculator) EstimateCells(quantity int, packs []Pack) int {
+	sizes, err := normalizeSizes(packs)
+	if err != nil || quantity <= 0 {
+		return 0
+	}
+	g := gcdAll(sizes)
+	largest, second := sizes[len(sizes)-1]/g, 0
+	if len(sizes) > 1 {
+		second = sizes[len(sizes)-2] / g
+	}
+	size, ok := tableSize(largest, second)
+	if !ok {
+		return EstimateDPCells(quantity, packs)
+	}
+
+	pc.mu.Lock()
+	_, kept := pc.solvers[solverKey(sizes)]
+	pc.mu.Unlock()
+	if kept {
+		return 0
+	}
+	return size
+}
+
+// CalculateOnce runs the DP: no solver is built nor kept.
+func (pc *precomputedCalculator) CalculateOnce(quantity int, packs []Pack) (Combination, error) {
+	return pc.explainer.Calculate(quantity, packs)
+}
+
+func (pc *precomputedCalculator) Explain(quantity int, packs []Pack) (Explanation, error) {
+	return pc.explainer.Explain(quantity, packs)
+}
+
+func (pc *precomputedCalculator) solver(packs []Pack) (*Solver, error) {
+	sizes, err := normalizeSizes(packs)
+	if err != nil {
+		return nil, err
+	}
+	key := solverKey(sizes)
+
+	pc.mu.Lock()
+	if e, ok := pc.solvers[key]; ok {
+		if e.elem != nil {
+			pc.lru.MoveToFront(e.elem)
+		}
+		pc.mu.Unlock()
+		<-e.ready
+		return e.s, e.err
+	}
+	e := &solverEntry{key: key, ready: make(chan struct{})}
+	pc.solvers[key] = e
+	pc.mu.Unlock()
+
+	e.s, e.err = NewSolver(packs)
+
+	pc.mu.Lock()
+	if e.err != nil || e.s.cells() > pc.maxCells {
+		// answered, not kept: a table over the bound would evict every other
+		delete(pc.solvers, key)
+	} else {
+		e.elem = pc.lru.PushFront(e)
+		pc.cells += e.s.cells()
+		for pc.cells > pc.maxCells {
+			oldest := pc.lru.Back().Value.(*solverEntry)
+			pc.lru.Remove(oldest.elem)
+			delete(pc.solvers, oldest.key)
+			pc.cells -= oldest.s.cells()
+		}
+	}
+	pc.mu.Unlock()
+	close(e.ready)
+	return e.s, e.err
+}
+
+func solverKey(sizes []int) string {
+	parts := make([]string, len(sizes))
+	for i, s := range sizes {
+		parts[i] = strconv.Itoa(s)
+	}
+	return strings.Join(parts, ",")
+}
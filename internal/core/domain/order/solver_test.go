@@ -0,0 +1,203 @@
+package order
+
+import (
+	"math/rand/v2"
+	"sync"
+	"testing"
+)
+
+// randomPacks draws 1..5 distinct sizes in [1, maxSize], sometimes sharing a
+// common factor so the GCD scaling is exercised too.
+func randomPacks(r *rand.Rand, maxSize int) []Pack {
+	factor := 1
+	if r.IntN(3) == 0 {
+		factor = 1 + r.IntN(50)
+	}
+	n := 1 + r.IntN(5)
+	seen := make(map[int]struct{}, n)
+	packs := make([]Pack, 0, n)
+	for len(packs) < n {
+		s := (1 + r.IntN(maxSize)) * factor
+		if _, dup := seen[s]; dup {
+			continue
+		}
+		seen[s] = struct{}{}
+		packs = append(packs, Pack{Size: s})
+	}
+	return packs
+}
+
+// The solver must be equivalent to the DP: same items, same number of packs.
+func TestSolver_EquivalentToDP(t *testing.T) {
+	r := rand.New(rand.NewPCG(28, 2024))
+	dp := NewPackCalculator()
+
+	for i := 0; i < 300; i++ {
+		packs := randomPacks(r, 60)
+		solver, err := NewSolver(packs)
+		if err != nil {
+			t.Fatalf("NewSolver(%v): %v", packs, err)
+		}
+
+		// small quantities hit the table, large ones the periodic jump
+		qtys := []int{1, 1 + r.IntN(100), 1 + r.IntN(5_000), 1 + r.IntN(200_000)}
+		for _, qty := range qtys {
+			want, err := dp.Calculate(qty, packs)
+			if err != nil {
+				t.Fatalf("dp(%d, %v): %v", qty, packs, err)
+			}
+			got, err := solver.Solve(qty)
+			if err != nil {
+				t.Fatalf("solver(%d, %v): %v", qty, packs, err)
+			}
+			assertInvariants(t, qty, got, nil)
+			if got.TotalItems != want.TotalItems || got.TotalPacks != want.TotalPacks {
+				t.Fatalf("qty=%d packs=%v: solver=%+v dp=%+v", qty, packs, got, want)
+			}
+		}
+	}
+}
+
+func TestSolver_CanonicalCases(t *testing.T) {
+	solver, err := NewSolver(mkPacks(t, 250, 500, 1000, 2000, 5000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for qty, want := range map[int]int{1: 250, 250: 250, 251: 500, 501: 750, 12001: 12250, 500_000: 500_000} {
+		got, err := solver.Solve(qty)
+		if err != nil {
+			t.Fatalf("qty=%d: unexpected error: %v", qty, err)
+		}
+		if got.TotalItems != want {
+			t.Fatalf("qty=%d: TotalItems got=%d want=%d", qty, got.TotalItems, want)
+		}
+	}
+	if _, err := solver.Solve(0); err == nil {
+		t.Fatalf("expected error for qty<=0")
+	}
+}
+
+func TestSolver_FallbackForHugeTables(t *testing.T) {
+	packs := mkPacks(t, 4_999_999, 5_000_000)
+	solver, err := NewSolver(packs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !solver.fallback {
+		t.Fatalf("expected fallback to the DP for a table this large")
+	}
+	got, err := solver.Solve(1)
+	if err != nil || got.TotalItems != 4_999_999 {
+		t.Fatalf("unexpected result: %+v err=%v", got, err)
+	}
+}
+
+func TestPrecomputedCalculator_ReusesSolvers(t *testing.T) {
+	// tables: 14 cells for {250,500,1000}, 32 for {3,7}, 752 for {23,31}
+	pc := NewPrecomputedCalculator(790).(*precomputedCalculator)
+
+	a := mkPacks(t, 250, 500, 1000)
+	if _, err := pc.Calculate(251, a); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// same set, different order and duplicates: same solver
+	if _, err := pc.Calculate(999, mkPacks(t, 1000, 250, 500, 250)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pc.solvers) != 1 || pc.cells != 14 {
+		t.Fatalf("solvers got=%d (%d cells) want=1 (14 cells)", len(pc.solvers), pc.cells)
+	}
+
+	// a changed pack set builds a new one; the least recently used is
+	// dropped once the cells go past the bound
+	for _, sizes := range [][]int{{3, 7}, {23, 31}} {
+		if _, err := pc.Calculate(100, mkPacks(t, sizes...)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(pc.solvers) != 2 || pc.cells != 784 {
+		t.Fatalf("solvers got=%d (%d cells) want=2 (784 cells)", len(pc.solvers), pc.cells)
+	}
+	if _, ok := pc.solvers["250,500,1000"]; ok {
+		t.Fatalf("least recently used solver should have been dropped")
+	}
+
+	if _, err := pc.Calculate(0, a); err == nil {
+		t.Fatalf("expected error for qty<=0")
+	}
+	if _, err := pc.Calculate(10, nil); err == nil {
+		t.Fatalf("expected error for empty packs")
+	}
+	if _, err := pc.Explain(12001, a); err != nil {
+		t.Fatalf("unexpected explain error: %v", err)
+	}
+}
+
+func TestPrecomputedCalculator_KeepsNoOversizedOrOneOffSolver(t *testing.T) {
+	pc := NewPrecomputedCalculator(100).(*precomputedCalculator)
+
+	// {23,31} needs 752 cells: answered, not kept
+	got, err := pc.Calculate(100, mkPacks(t, 23, 31))
+	if err != nil || got.TotalItems != 100 {
+		t.Fatalf("unexpected result: %+v err=%v", got, err)
+	}
+	once, err := pc.CalculateOnce(100, mkPacks(t, 3, 7))
+	if err != nil || once.TotalItems != 100 {
+		t.Fatalf("unexpected one-off result: %+v err=%v", once, err)
+	}
+	if len(pc.solvers) != 0 || pc.cells != 0 {
+		t.Fatalf("solvers got=%d (%d cells) want none", len(pc.solvers), pc.cells)
+	}
+}
+
+func TestPrecomputedCalculator_ConcurrentBuilds(t *testing.T) {
+	pc := NewPrecomputedCalculator(0).(*precomputedCalculator)
+	packs := mkPacks(t, 23, 31)
+	want, err := NewPackCalculator().Calculate(1000, packs)
+	if err != nil {
+		t.Fatalf("DP: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := pc.Calculate(1000, packs)
+			if err != nil || got.TotalItems != want.TotalItems || got.TotalPacks != want.TotalPacks {
+				t.Errorf("got %+v err=%v want %+v", got, err, want)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(pc.solvers) != 1 || pc.lru.Len() != 1 || pc.cells != 752 {
+		t.Fatalf("solvers got=%d lru=%d cells=%d want a single 752-cell solver", len(pc.solvers), pc.lru.Len(), pc.cells)
+	}
+}
+
+func TestPrecomputedCalculator_EstimateCells(t *testing.T) {
+	pc := NewPrecomputedCalculator(0).(*precomputedCalculator)
+	catalogue := mkPacks(t, 1990, 1991)
+
+	// (1991-1)*1990 + 2*1991: the table, not the ~2k cells of the DP
+	if got, want := pc.EstimateCells(1, catalogue), 1990*1990+2*1991; got != want {
+		t.Fatalf("new pack set got=%d want=%d", got, want)
+	}
+	if got := pc.EstimateCells(1, mkPacks(t, 3, 7)); got != 32 {
+		t.Fatalf("small pack set got=%d want=32", got)
+	}
+	if _, err := pc.Calculate(1, mkPacks(t, 3, 7)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := pc.EstimateCells(1_000_000, mkPacks(t, 7, 3)); got != 0 {
+		t.Fatalf("kept solver got=%d want=0", got)
+	}
+	// too big for a table: the solver runs the DP
+	huge := mkPacks(t, 4_999_999, 5_000_000)
+	if got, want := pc.EstimateCells(10, huge), EstimateDPCells(10, huge); got != want {
+		t.Fatalf("fallback got=%d want=%d", got, want)
+	}
+	if got := pc.EstimateCells(0, catalogue); got != 0 {
+		t.Fatalf("invalid quantity got=%d want=0", got)
+	}
+}
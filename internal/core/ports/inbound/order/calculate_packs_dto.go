@@ -1,12 +1,19 @@
 package order
 
+import "time"
+
 // CalculatePacksInput is the input DTO.
 // - Quantity: required (> 0)
 // - PacksOverride: optional; when provided, overrides the Provider's default list.
 // Must contain only positive values; duplicates will be ignored by the implementation.
+// - Explain: optional; when true, the output carries an Explanation.
+// - AsOf: optional; uses the catalogue version in effect at that time instead
+// of the current one (ignored with PacksOverride).
 type CalculatePacksInput struct {
-	Quantity      int   `json:"quantity"`
-	PacksOverride []int `json:"packsOverride,omitempty"`
+	Quantity      int       `json:"quantity"`
+	PacksOverride []int     `json:"packsOverride,omitempty"`
+	Explain       bool      `json:"explain,omitempty"`
+	AsOf          time.Time `json:"asOf,omitempty"`
 }
 
 // CalculatePacksOutput is the output DTO.
@@ -14,9 +21,34 @@ type CalculatePacksInput struct {
 // - TotalItems: sum(size*count) of ItemsByPack
 // - TotalPacks: sum of counts
 // - Leftover: TotalItems - Quantity
+// - Explanation: only present when requested
+// - CatalogueVersion: version of the provider list used ("" with PacksOverride)
 type CalculatePacksOutput struct {
+	ItemsByPack      map[int]int  `json:"itemsByPack"`
+	TotalItems       int          `json:"totalItems"`
+	TotalPacks       int          `json:"totalPacks"`
+	Leftover         int          `json:"leftover"`
+	Explanation      *Explanation `json:"explanation,omitempty"`
+	CatalogueVersion string       `json:"catalogueVersion,omitempty"`
+}
+
+// Explanation tells how the calculator reached the result.
+// - GCD: divisor used to scale pack sizes and quantity
+// - ScaledQuantity / ScaledUpperBound: search window, in GCD units
+// - RunnersUp: rejected alternatives and the rule that rejected them
+type Explanation struct {
+	GCD              int        `json:"gcd"`
+	ScaledQuantity   int        `json:"scaledQuantity"`
+	ScaledUpperBound int        `json:"scaledUpperBound"`
+	RunnersUp        []RunnerUp `json:"runnersUp"`
+}
+
+// RunnerUp is a feasible combination that lost.
+// RejectedBy is "items" (ships more items) or "packs" (same items, more packs).
+type RunnerUp struct {
 	ItemsByPack map[int]int `json:"itemsByPack"`
 	TotalItems  int         `json:"totalItems"`
 	TotalPacks  int         `json:"totalPacks"`
 	Leftover    int         `json:"leftover"`
+	RejectedBy  string      `json:"rejectedBy"`
 }
@@ -0,0 +1,9 @@
+package order
+
+import "context"
+
+// GetLimits exposes the safeguards enforced by CalculatePacks,
+// so clients can validate requests before sending them.
+type GetLimits interface {
+	Execute(ctx context.Context) (GetLimitsOutput, error)
+}
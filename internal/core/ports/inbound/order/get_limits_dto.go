@@ -0,0 +1,11 @@
+package order
+
+// GetLimitsOutput lists the configured limits (0 means "no limit").
+// - MaxQuantity: largest accepted quantity
+// - MaxPackSizes: most distinct pack sizes in a calculation
+// - MaxDPCells: largest DP table, ceil(quantity/gcd) + largest/gcd
+type GetLimitsOutput struct {
+	MaxQuantity  int `json:"maxQuantity"`
+	MaxPackSizes int `json:"maxPackSizes"`
+	MaxDPCells   int `json:"maxDpCells"`
+}
@@ -1,5 +1,41 @@
 package order
 
+import "time"
+
+// GetPackSizesOutput is the output DTO.
+// - Sizes: current list (enabled sizes), sorted asc
+// - Packs: every pack of the current set, disabled ones included, by size asc
+// - Version: opaque revision of the list (changes whenever the list changes)
+// - UpdatedAt: when that revision was published (zero when unknown)
+// - Source: what served the list (e.g. "file", or the source a chain fell
+// back to)
+// - LoadedAt: when the provider read it (zero when unknown)
 type GetPackSizesOutput struct {
-	Sizes []int `json:"sizes"`
+	Sizes     []int      `json:"sizes"`
+	Packs     []PackSize `json:"packs"`
+	Version   string     `json:"version"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Source    string     `json:"source"`
+	LoadedAt  time.Time  `json:"loadedAt"`
+}
+
+// PackSize is one pack and its metadata.
+// - Enabled: only enabled sizes are used by the calculation
+// - Cost, Stock, Dimensions: nil when unknown
+type PackSize struct {
+	Size       int             `json:"size"`
+	Enabled    bool            `json:"enabled"`
+	Label      string          `json:"label,omitempty"`
+	SKU        string          `json:"sku,omitempty"`
+	Cost       *float64        `json:"cost,omitempty"`
+	Stock      *int            `json:"stock,omitempty"`
+	Dimensions *PackDimensions `json:"dimensions,omitempty"`
+}
+
+// PackDimensions of a box; Unit is free text (e.g. "cm").
+type PackDimensions struct {
+	Length float64 `json:"length"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Unit   string  `json:"unit,omitempty"`
 }
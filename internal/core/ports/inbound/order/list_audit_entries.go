@@ -0,0 +1,9 @@
+package order
+
+import "context"
+
+// ListAuditEntries exposes the audit trail: the catalogue changes and the
+// privileged calls, with who made them.
+type ListAuditEntries interface {
+	Execute(ctx context.Context, in ListAuditEntriesInput) (ListAuditEntriesOutput, error)
+}
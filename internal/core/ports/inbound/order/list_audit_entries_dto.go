@@ -0,0 +1,44 @@
+package order
+
+import "time"
+
+// ListAuditEntriesInput is the input DTO; zero fields match all.
+// - From, To: the entries at or after From and before To
+// - Limit: most recent entries returned (0: a default page)
+type ListAuditEntriesInput struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Actor  string    `json:"actor"`
+	Action string    `json:"action"`
+	Limit  int       `json:"limit"`
+}
+
+// ListAuditEntriesOutput is the output DTO.
+// - Entries: most recent first
+type ListAuditEntriesOutput struct {
+	Entries []AuditEntry `json:"entries"`
+}
+
+// AuditEntry is one line of the audit trail.
+// - Status: of the privileged call, 0 in the catalogue changes
+// - Before, After: the pack list around a catalogue change, nil otherwise
+type AuditEntry struct {
+	At        time.Time      `json:"at"`
+	Action    string         `json:"action"`
+	Actor     string         `json:"actor"`
+	SourceIP  string         `json:"sourceIp"`
+	RequestID string         `json:"requestId"`
+	Method    string         `json:"method"`
+	Path      string         `json:"path"`
+	Status    int            `json:"status"`
+	Before    *AuditPackList `json:"before"`
+	After     *AuditPackList `json:"after"`
+}
+
+// AuditPackList is one state of the pack list.
+// - EffectiveFrom: zero unless scheduled
+type AuditPackList struct {
+	Version       string    `json:"version"`
+	Sizes         []int     `json:"sizes"`
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+}
@@ -0,0 +1,9 @@
+package order
+
+import "context"
+
+// ListPackSizeVersions exposes the history of the pack sizes catalogue
+// (every version the provider knows, future ones included).
+type ListPackSizeVersions interface {
+	Execute(ctx context.Context) (ListPackSizeVersionsOutput, error)
+}
@@ -0,0 +1,19 @@
+package order
+
+import "time"
+
+// ListPackSizeVersionsOutput is the output DTO.
+// - Versions: EffectiveFrom asc
+// - Current: version in effect now
+type ListPackSizeVersionsOutput struct {
+	Versions []PackSizeVersion `json:"versions"`
+	Current  string            `json:"current"`
+}
+
+// PackSizeVersion is one version of the catalogue.
+// - EffectiveFrom: zero when in effect since always
+type PackSizeVersion struct {
+	Version       string    `json:"version"`
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+	Sizes         []int     `json:"sizes"`
+}
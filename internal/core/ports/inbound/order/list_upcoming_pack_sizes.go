@@ -0,0 +1,9 @@
+package order
+
+import "context"
+
+// ListUpcomingPackSizes lists the scheduled versions of the catalogue, the
+// ones not in effect yet.
+type ListUpcomingPackSizes interface {
+	Execute(ctx context.Context) (ListUpcomingPackSizesOutput, error)
+}
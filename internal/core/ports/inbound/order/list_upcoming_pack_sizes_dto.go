@@ -0,0 +1,9 @@
+package order
+
+// ListUpcomingPackSizesOutput is the output DTO.
+// - Current: version in effect now
+// - Upcoming: versions with EffectiveFrom in the future, asc
+type ListUpcomingPackSizesOutput struct {
+	Current  string            `json:"current"`
+	Upcoming []PackSizeVersion `json:"upcoming"`
+}
@@ -0,0 +1,9 @@
+package order
+
+import "context"
+
+// ListWebhookDeliveries exposes the log of the change events sent to the
+// webhooks: every attempt, so a receiver that missed an event can be found.
+type ListWebhookDeliveries interface {
+	Execute(ctx context.Context, in ListWebhookDeliveriesInput) (ListWebhookDeliveriesOutput, error)
+}
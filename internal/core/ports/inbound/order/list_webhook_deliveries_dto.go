@@ -0,0 +1,35 @@
+package order
+
+import "time"
+
+// ListWebhookDeliveriesInput is the input DTO; zero fields match all.
+// - Status: "delivered", "retrying" or "failed"
+// - Limit: most recent attempts returned (0: a default page)
+type ListWebhookDeliveriesInput struct {
+	EventID string `json:"eventId"`
+	Status  string `json:"status"`
+	Limit   int    `json:"limit"`
+}
+
+// ListWebhookDeliveriesOutput is the output DTO.
+// - Deliveries: most recent first
+type ListWebhookDeliveriesOutput struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// WebhookDelivery is one attempt to deliver an event to an endpoint.
+// - Endpoint: without its query
+// - StatusCode: 0 without an answer (e.g. a timeout)
+// - NextAttemptAt: zero unless Status is "retrying"
+type WebhookDelivery struct {
+	EventID       string        `json:"eventId"`
+	EventType     string        `json:"eventType"`
+	Endpoint      string        `json:"endpoint"`
+	Attempt       int           `json:"attempt"`
+	At            time.Time     `json:"at"`
+	Duration      time.Duration `json:"duration"`
+	Status        string        `json:"status"`
+	StatusCode    int           `json:"statusCode"`
+	Error         string        `json:"error"`
+	NextAttemptAt time.Time     `json:"nextAttemptAt"`
+}
@@ -0,0 +1,9 @@
+package order
+
+import "context"
+
+// SchedulePackSizes registers a future version of the catalogue; it takes
+// effect on its own at EffectiveFrom.
+type SchedulePackSizes interface {
+	Execute(ctx context.Context, in SchedulePackSizesInput) (PackSizeVersion, error)
+}
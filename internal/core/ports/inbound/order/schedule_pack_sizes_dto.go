@@ -0,0 +1,13 @@
+package order
+
+import "time"
+
+// SchedulePackSizesInput is the input DTO.
+// - Version: optional; derived from the sizes when empty
+// - EffectiveFrom: must be in the future
+// - Sizes: positive; duplicates are ignored
+type SchedulePackSizesInput struct {
+	Version       string    `json:"version,omitempty"`
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+	Sizes         []int     `json:"sizes"`
+}
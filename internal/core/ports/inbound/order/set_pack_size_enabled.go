@@ -0,0 +1,9 @@
+package order
+
+import "context"
+
+// SetPackSizeEnabled takes a size of the current set out of the calculation,
+// or brings it back, without removing it from the catalogue.
+type SetPackSizeEnabled interface {
+	Execute(ctx context.Context, in SetPackSizeEnabledInput) (PackSize, error)
+}
@@ -0,0 +1,8 @@
+package order
+
+// SetPackSizeEnabledInput is the input DTO.
+// - Size: a size of the current set, enabled or not
+type SetPackSizeEnabledInput struct {
+	Size    int  `json:"size"`
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,63 @@
+// Package audit is the outbound port of the audit trail: who changed the
+// pack sizes catalogue, or called a privileged route, when and from where.
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Actions of the entries.
+const (
+	ActionPackSizeEnabled    = "packsizes.enable"   // a size brought back into the calculation
+	ActionPackSizeDisabled   = "packsizes.disable"  // a size taken out of the calculation
+	ActionPackSizesScheduled = "packsizes.schedule" // a future version registered
+	ActionAdminCall          = "admin.call"         // any call to a privileged route
+)
+
+// Entry is one line of the audit trail.
+// - Actor, SourceIP, RequestID: from the Caller of the request
+// - Method, Path, Status: the privileged call (Status is 0 in the catalogue
+// changes, recorded before the answer)
+// - Before, After: the pack list around a catalogue change (nil otherwise);
+// After of a schedule is the scheduled version
+type Entry struct {
+	At        time.Time
+	Action    string
+	Actor     string
+	SourceIP  string
+	RequestID string
+	Method    string
+	Path      string
+	Status    int
+	Before    *PackList
+	After     *PackList
+}
+
+// PackList is one state of the pack list.
+// - Sizes: the enabled sizes, asc
+// - EffectiveFrom: zero unless scheduled
+type PackList struct {
+	Version       string
+	Sizes         []int
+	EffectiveFrom time.Time
+}
+
+// Filter selects entries; zero fields match all.
+// - From, To: At in [From, To)
+// - Limit: most recent entries returned (0: all)
+type Filter struct {
+	From   time.Time
+	To     time.Time
+	Actor  string
+	Action string
+	Limit  int
+}
+
+// Log is the append-only audit trail: entries are never changed or removed.
+type Log interface {
+	// Append writes e; a zero At is the time of the append.
+	Append(ctx context.Context, e Entry) error
+	// Entries lists the entries matching f, most recent first.
+	Entries(ctx context.Context, f Filter) ([]Entry, error)
+}
@@ -0,0 +1,26 @@
+package audit
+
+import "context"
+
+// Caller is who made a request, set by the transport once authenticated.
+// - Actor: the name of the credential (e.g. the admin token)
+// - SourceIP: the client address
+// - RequestID: also sent back in the X-Request-Id header
+type Caller struct {
+	Actor     string
+	SourceIP  string
+	RequestID string
+}
+
+type callerKey struct{}
+
+// WithCaller returns a copy of ctx carrying c.
+func WithCaller(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+// CallerFrom returns the Caller of ctx; the zero Caller without one.
+func CallerFrom(ctx context.Context) Caller {
+	c, _ := ctx.Value(callerKey{}).(Caller)
+	return c
+}
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Delivery statuses.
+const (
+	DeliveryDelivered = "delivered" // the receiver answered 2xx
+	DeliveryRetrying  = "retrying"  // failed, tried again at NextAttemptAt
+	DeliveryFailed    = "failed"    // given up: out of attempts or refused
+)
+
+// Delivery is one attempt to deliver an event to an endpoint.
+// - Endpoint: the receiver URL, without its query (it may carry a token)
+// - Attempt: 1 for the first try
+// - StatusCode: the answer of the receiver (0 without one, e.g. a timeout)
+// - Error: why it failed ("" when delivered)
+// - NextAttemptAt: when it is retried (zero unless Status is retrying)
+type Delivery struct {
+	EventID       string
+	EventType     string
+	Endpoint      string
+	Attempt       int
+	At            time.Time
+	Duration      time.Duration
+	Status        string
+	StatusCode    int
+	Error         string
+	NextAttemptAt time.Time
+}
+
+// DeliveryFilter selects deliveries; zero fields match all.
+// - Limit: most recent deliveries returned (0: all kept)
+type DeliveryFilter struct {
+	EventID string
+	Status  string
+	Limit   int
+}
+
+// DeliveryLog is optionally implemented by publishers that record their
+// delivery attempts.
+type DeliveryLog interface {
+	// Deliveries lists the attempts matching f, most recent first.
+	Deliveries(ctx context.Context, f DeliveryFilter) ([]Delivery, error)
+}
@@ -0,0 +1,39 @@
+// Package events is the outbound port of the notifications the service
+// emits, e.g. to webhooks.
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// TypePackSizesChanged is the Type of PackSizesChanged.
+const TypePackSizesChanged = "packsizes.changed"
+
+// PackSizesChanged reports that the pack list served by the provider changed.
+// - ID: unique, so a receiver can drop a redelivered event
+// - OccurredAt: when the service noticed the change
+// - Previous, Current: the list before and after
+type PackSizesChanged struct {
+	ID         string
+	OccurredAt time.Time
+	Previous   PackList
+	Current    PackList
+}
+
+// PackList is one state of the pack list.
+// - Version: the catalogue version (see packsizes.Version)
+// - Sizes: the enabled sizes, asc
+// - Source: what served it (see packsizes.Catalogue)
+type PackList struct {
+	Version string
+	Sizes   []int
+	Source  string
+}
+
+// Publisher takes the events to deliver. Publish returns once the event is
+// stored (it must survive a restart when the publisher is persistent); the
+// delivery itself is asynchronous.
+type Publisher interface {
+	Publish(ctx context.Context, e PackSizesChanged) error
+}
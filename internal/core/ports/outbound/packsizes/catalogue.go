@@ -0,0 +1,49 @@
+package packsizes
+
+import (
+	"slices"
+	"time"
+)
+
+// Catalogue is what a Provider serves.
+// - Sizes: the enabled sizes, sorted asc, unique
+// - Version: revision of Sizes (ID empty: derived from them, see Revision)
+// - Source: what served it (e.g. "file", "http"; a chain names its source)
+// - LoadedAt: when the provider read it from the source (zero when unknown)
+// - Packs: every pack, disabled ones included, by size asc, with their
+// metadata (nil: none, see AllPacks)
+// - Degraded: a stand-in for the list of the first source, e.g. a fallback
+// source or the last good list of a chain
+type Catalogue struct {
+	Sizes    []int
+	Version  Version
+	Source   string
+	LoadedAt time.Time
+	Packs    []Pack
+	Degraded bool
+}
+
+// Revision is the Version of c, its ID derived from Sizes when the provider
+// does not tell.
+func (c Catalogue) Revision() Version {
+	v := c.Version
+	if v.ID == "" {
+		v.ID = VersionOf(c.Sizes)
+	}
+	return v
+}
+
+// AllPacks lists the packs of c; without metadata, the sizes, all enabled.
+func (c Catalogue) AllPacks() []Pack {
+	if c.Packs == nil {
+		return PlainPacks(c.Sizes)
+	}
+	return slices.Clone(c.Packs)
+}
+
+// Clone copies the slices of c, so the caller can keep it.
+func (c Catalogue) Clone() Catalogue {
+	c.Sizes = slices.Clone(c.Sizes)
+	c.Packs = slices.Clone(c.Packs)
+	return c
+}
@@ -0,0 +1,33 @@
+package packsizes
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoPackSet is returned by History.At for a time before the first set.
+var ErrNoPackSet = errors.New("no pack set in effect")
+
+// History is optionally implemented by providers that keep every version of
+// the catalogue, so a calculation can be reproduced with the set in effect
+// at a given time. Without it the provider has a single set, in effect since
+// always.
+type History interface {
+	// At returns the set in effect at t: the last one with EffectiveFrom <= t.
+	At(ctx context.Context, t time.Time) (PackSet, error)
+	// Versions lists every set, EffectiveFrom asc (future ones included).
+	Versions(ctx context.Context) ([]PackSet, error)
+}
+
+// PackSet is one version of the catalogue.
+// - Version: unique within the provider (VersionOf(Sizes) when not named)
+// - EffectiveFrom: when the set replaces the previous one (zero: since always)
+// - Sizes: the enabled sizes, sorted asc, unique
+// - Packs: every pack, disabled ones included, by size asc (nil: no metadata)
+type PackSet struct {
+	Version       string
+	EffectiveFrom time.Time
+	Sizes         []int
+	Packs         []Pack
+}
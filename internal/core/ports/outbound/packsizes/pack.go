@@ -0,0 +1,42 @@
+package packsizes
+
+// Pack is one pack size and its optional metadata.
+// - Enabled: only enabled sizes are listed in PackSet.Sizes / Catalogue.Sizes
+// - Cost, Stock, Dimensions: nil when unknown
+type Pack struct {
+	Size       int
+	Label      string
+	SKU        string
+	Cost       *float64
+	Stock      *int
+	Enabled    bool
+	Dimensions *Dimensions
+}
+
+// Dimensions of a box; Unit is free text (e.g. "cm").
+type Dimensions struct {
+	Length float64
+	Width  float64
+	Height float64
+	Unit   string
+}
+
+// PlainPacks describes sizes without metadata, all enabled.
+func PlainPacks(sizes []int) []Pack {
+	packs := make([]Pack, len(sizes))
+	for i, s := range sizes {
+		packs[i] = Pack{Size: s, Enabled: true}
+	}
+	return packs
+}
+
+// EnabledSizes lists the enabled sizes of packs, in order.
+func EnabledSizes(packs []Pack) []int {
+	sizes := make([]int, 0, len(packs))
+	for _, p := range packs {
+		if p.Enabled {
+			sizes = append(sizes, p.Size)
+		}
+	}
+	return sizes
+}
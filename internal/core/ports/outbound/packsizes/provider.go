@@ -1,5 +1,17 @@
 package packsizes
 
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnavailable is returned (wrapped) by a provider that has no list to
+// serve for now, e.g. every source of a fallback chain failed; a later call
+// may succeed.
+var ErrUnavailable = errors.New("pack sizes unavailable")
+
 type Provider interface {
-	List() ([]int, error)
+	// Load returns the catalogue in effect; a remote or database provider
+	// gives up when ctx is done.
+	Load(ctx context.Context) (Catalogue, error)
 }
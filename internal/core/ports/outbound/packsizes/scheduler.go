@@ -0,0 +1,26 @@
+package packsizes
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrScheduleConflict is returned by Scheduler.Schedule when the set reuses
+// a known version or effective time.
+var ErrScheduleConflict = errors.New("pack set conflicts with a known one")
+
+// ErrNotScheduled is returned by Scheduler.Unschedule for a version that is
+// unknown or already in effect.
+var ErrNotScheduled = errors.New("pack set is not scheduled")
+
+// Scheduler is optionally implemented by History providers that accept new
+// versions at runtime, e.g. a change announced weeks ahead. The set takes
+// effect on its own at EffectiveFrom (see History.At).
+type Scheduler interface {
+	// Schedule registers set (named, sizes normalised); it must survive a
+	// restart when the provider is persistent.
+	Schedule(ctx context.Context, set PackSet) error
+	// Unschedule withdraws a version that is not in effect yet, e.g. one
+	// whose change could not be recorded.
+	Unschedule(ctx context.Context, version string) error
+}
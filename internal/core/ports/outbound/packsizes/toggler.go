@@ -0,0 +1,24 @@
+package packsizes
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrUnknownPackSize is returned by Toggler.SetEnabled for a size the set
+	// in effect does not have.
+	ErrUnknownPackSize = errors.New("unknown pack size")
+	// ErrLastEnabledPack is returned by Toggler.SetEnabled instead of leaving
+	// the set in effect without sizes.
+	ErrLastEnabledPack = errors.New("the last enabled pack size cannot be disabled")
+)
+
+// Toggler is optionally implemented by providers that can take a size of
+// the set in effect out of Catalogue.Sizes and bring it back later, keeping
+// its metadata (e.g. a box temporarily out of stock).
+type Toggler interface {
+	// SetEnabled returns the updated pack; it must survive a restart when the
+	// provider is persistent, and the Version of the catalogue changes with it.
+	SetEnabled(ctx context.Context, size int, enabled bool) (Pack, error)
+}
@@ -0,0 +1,26 @@
+package packsizes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Version identifies a revision of the pack list.
+// - ID: opaque, changes whenever the list changes
+// - UpdatedAt: when the revision was published (zero when unknown)
+type Version struct {
+	ID        string
+	UpdatedAt time.Time
+}
+
+// VersionOf derives a Version ID from a normalised (sorted, unique) list.
+func VersionOf(sizes []int) string {
+	h := sha256.New()
+	for _, s := range sizes {
+		h.Write(strconv.AppendInt(nil, int64(s), 10))
+		h.Write([]byte{','})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
@@ -0,0 +1,186 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/audit"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// ErrAuditUnavailable is returned by the audited use cases when the entry of
+// a change cannot be written; the change is undone first: a size enabled or
+// disabled is set back ("size" in Params), a scheduled version withdrawn
+// ("version").
+var ErrAuditUnavailable = apperr.New(apperr.KindUnavailable, "audit_unavailable", "the change could not be recorded in the audit trail")
+
+// AuditOptions configures the audited use cases.
+// - Now: clock, injectable for tests (nil = time.Now)
+// - OnError: called when an entry cannot be written (nil: ignored), before
+// ErrAuditUnavailable is returned
+// - Lock: held from the read of the list before a change to the entry;
+// share one between the use cases of a provider (nil: one per use case)
+type AuditOptions struct {
+	Now     func() time.Time
+	OnError func(error)
+	Lock    sync.Locker
+}
+
+// auditTrail records the catalogue changes made by a use case, with the
+// pack list before and after them. The changes go through it one at a time,
+// so the lists of an entry are those of its change; a change made elsewhere
+// (the file edited by hand, another instance) can still land in between.
+type auditTrail struct {
+	provider packsizes.Provider
+	log      audit.Log
+	opts     AuditOptions
+}
+
+func newAuditTrail(provider packsizes.Provider, log audit.Log, opts AuditOptions) (auditTrail, error) {
+	if provider == nil {
+		return auditTrail{}, errors.New("nil packsizes.Provider")
+	}
+	if log == nil {
+		return auditTrail{}, errors.New("nil audit.Log")
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.Lock == nil {
+		opts.Lock = &sync.Mutex{}
+	}
+	return auditTrail{provider: provider, log: log, opts: opts}, nil
+}
+
+// list is the pack list in effect; nil when it cannot be read (the entry is
+// written without it).
+func (a auditTrail) list(ctx context.Context) *audit.PackList {
+	catalogue, err := current(ctx, a.provider)
+	if err != nil {
+		return nil
+	}
+	return &audit.PackList{Version: catalogue.Version.ID, Sizes: slices.Clone(catalogue.Sizes)}
+}
+
+// record writes the entry of a change; the error is reported to OnError.
+func (a auditTrail) record(ctx context.Context, action string, before, after *audit.PackList) error {
+	caller := audit.CallerFrom(ctx)
+	e := audit.Entry{
+		At:        a.opts.Now(),
+		Action:    action,
+		Actor:     caller.Actor,
+		SourceIP:  caller.SourceIP,
+		RequestID: caller.RequestID,
+		Before:    before,
+		After:     after,
+	}
+	// written even when the request is cancelled: the change is done
+	err := a.log.Append(context.WithoutCancel(ctx), e)
+	if err != nil && a.opts.OnError != nil {
+		a.opts.OnError(err)
+	}
+	return err
+}
+
+type auditedSetPackSizeEnabled struct {
+	next  uc.SetPackSizeEnabled
+	trail auditTrail
+}
+
+// compile-time check
+var _ uc.SetPackSizeEnabled = (*auditedSetPackSizeEnabled)(nil)
+
+// NewAuditedSetPackSizeEnabled records each enable or disable that changes
+// the pack list of provider; a repeated one changes nothing and is not
+// recorded. A change that cannot be recorded is undone (ErrAuditUnavailable).
+func NewAuditedSetPackSizeEnabled(next uc.SetPackSizeEnabled, provider packsizes.Provider, log audit.Log, opts AuditOptions) (uc.SetPackSizeEnabled, error) {
+	if next == nil {
+		return nil, errors.New("nil SetPackSizeEnabled")
+	}
+	trail, err := newAuditTrail(provider, log, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &auditedSetPackSizeEnabled{next: next, trail: trail}, nil
+}
+
+func (a *auditedSetPackSizeEnabled) Execute(ctx context.Context, in uc.SetPackSizeEnabledInput) (uc.PackSize, error) {
+	a.trail.opts.Lock.Lock()
+	defer a.trail.opts.Lock.Unlock()
+
+	before := a.trail.list(ctx)
+	out, err := a.next.Execute(ctx, in)
+	if err != nil {
+		return out, err
+	}
+	after := a.trail.list(ctx)
+	if before != nil && after != nil && slices.Equal(before.Sizes, after.Sizes) {
+		return out, nil
+	}
+	action := audit.ActionPackSizeDisabled
+	if in.Enabled {
+		action = audit.ActionPackSizeEnabled
+	}
+	if err := a.trail.record(ctx, action, before, after); err != nil {
+		// fail closed: set the status back, even when the request is cancelled
+		undo := uc.SetPackSizeEnabledInput{Size: in.Size, Enabled: !in.Enabled}
+		if _, undoErr := a.next.Execute(context.WithoutCancel(ctx), undo); undoErr != nil {
+			err = errors.Join(err, undoErr)
+		}
+		return uc.PackSize{}, ErrAuditUnavailable.With(map[string]any{"size": in.Size}).Wrap(err)
+	}
+	return out, nil
+}
+
+type auditedSchedulePackSizes struct {
+	next      uc.SchedulePackSizes
+	scheduler packsizes.Scheduler
+	trail     auditTrail
+}
+
+// compile-time check
+var _ uc.SchedulePackSizes = (*auditedSchedulePackSizes)(nil)
+
+// NewAuditedSchedulePackSizes records each scheduled version, after the
+// list in effect when it was scheduled; provider must implement
+// packsizes.Scheduler. A version that cannot be recorded is withdrawn
+// (ErrAuditUnavailable).
+func NewAuditedSchedulePackSizes(next uc.SchedulePackSizes, provider packsizes.Provider, log audit.Log, opts AuditOptions) (uc.SchedulePackSizes, error) {
+	if next == nil {
+		return nil, errors.New("nil SchedulePackSizes")
+	}
+	trail, err := newAuditTrail(provider, log, opts)
+	if err != nil {
+		return nil, err
+	}
+	scheduler, ok := provider.(packsizes.Scheduler)
+	if !ok {
+		return nil, errors.New("the packsizes.Provider does not accept scheduled versions")
+	}
+	return &auditedSchedulePackSizes{next: next, scheduler: scheduler, trail: trail}, nil
+}
+
+func (a *auditedSchedulePackSizes) Execute(ctx context.Context, in uc.SchedulePackSizesInput) (uc.PackSizeVersion, error) {
+	a.trail.opts.Lock.Lock()
+	defer a.trail.opts.Lock.Unlock()
+
+	before := a.trail.list(ctx)
+	out, err := a.next.Execute(ctx, in)
+	if err != nil {
+		return out, err
+	}
+	after := &audit.PackList{Version: out.Version, Sizes: slices.Clone(out.Sizes), EffectiveFrom: out.EffectiveFrom}
+	if err := a.trail.record(ctx, audit.ActionPackSizesScheduled, before, after); err != nil {
+		// fail closed: withdraw the version, even when the request is cancelled
+		if undoErr := a.scheduler.Unschedule(context.WithoutCancel(ctx), out.Version); undoErr != nil {
+			err = errors.Join(err, undoErr)
+		}
+		return uc.PackSizeVersion{}, ErrAuditUnavailable.With(map[string]any{"version": out.Version}).Wrap(err)
+	}
+	return out, nil
+}
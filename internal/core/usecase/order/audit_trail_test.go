@@ -0,0 +1,177 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/audit"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// memoryAuditLog keeps the entries, oldest first, or fails with err.
+type memoryAuditLog struct {
+	entries []audit.Entry
+	err     error
+}
+
+func (l *memoryAuditLog) Append(_ context.Context, e audit.Entry) error {
+	if l.err != nil {
+		return l.err
+	}
+	l.entries = append(l.entries, e)
+	return nil
+}
+
+func (l *memoryAuditLog) Entries(_ context.Context, f audit.Filter) ([]audit.Entry, error) {
+	return l.entries, l.err
+}
+
+func TestAuditedSetPackSizeEnabled(t *testing.T) {
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	prov := &togglingProvider{packs: packsizes.PlainPacks([]int{250, 500, 1000})}
+	toggle, _ := NewSetPackSizeEnabled(prov)
+	log := &memoryAuditLog{}
+	audited, err := NewAuditedSetPackSizeEnabled(toggle, prov, log, AuditOptions{Now: func() time.Time { return at }})
+	if err != nil {
+		t.Fatalf("NewAuditedSetPackSizeEnabled: %v", err)
+	}
+	ctx := audit.WithCaller(context.Background(), audit.Caller{Actor: "alice", SourceIP: "10.0.0.7", RequestID: "req-1"})
+
+	if _, err := audited.Execute(ctx, uc.SetPackSizeEnabledInput{Size: 250}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	want := []audit.Entry{{
+		At: at, Action: audit.ActionPackSizeDisabled, Actor: "alice", SourceIP: "10.0.0.7", RequestID: "req-1",
+		Before: &audit.PackList{Version: packsizes.VersionOf([]int{250, 500, 1000}), Sizes: []int{250, 500, 1000}},
+		After:  &audit.PackList{Version: packsizes.VersionOf([]int{500, 1000}), Sizes: []int{500, 1000}},
+	}}
+	if !reflect.DeepEqual(log.entries, want) {
+		t.Fatalf("entries got %+v want %+v", log.entries, want)
+	}
+
+	// nothing changed, nothing recorded
+	if _, err := audited.Execute(ctx, uc.SetPackSizeEnabledInput{Size: 250}); err != nil || len(log.entries) != 1 {
+		t.Fatalf("repeated disable: %v, %d entries", err, len(log.entries))
+	}
+	// a failed call is not a change
+	if _, err := audited.Execute(ctx, uc.SetPackSizeEnabledInput{Size: 42}); !errors.Is(err, ErrUnknownPackSize) || len(log.entries) != 1 {
+		t.Fatalf("unknown size: %v, %d entries", err, len(log.entries))
+	}
+
+	// a change that cannot be recorded is undone
+	log.err = errors.New("disk full")
+	var reported error
+	audited, _ = NewAuditedSetPackSizeEnabled(toggle, prov, log, AuditOptions{OnError: func(err error) { reported = err }})
+	_, err = audited.Execute(ctx, uc.SetPackSizeEnabledInput{Size: 250, Enabled: true})
+	if !errors.Is(err, ErrAuditUnavailable) || !errors.Is(err, log.err) || !errors.Is(reported, log.err) {
+		t.Fatalf("log failure: %v, reported %v", err, reported)
+	}
+	if got := packsizes.EnabledSizes(prov.packs); !reflect.DeepEqual(got, []int{500, 1000}) {
+		t.Fatalf("enabled sizes after the failure got %v want [500 1000]", got)
+	}
+}
+
+func TestAuditedSetPackSizeEnabled_Concurrent(t *testing.T) {
+	sizes := []int{100, 200, 300, 400, 500, 600, 700, 800}
+	prov := &togglingProvider{packs: packsizes.PlainPacks(sizes)}
+	toggle, _ := NewSetPackSizeEnabled(prov)
+	log := &memoryAuditLog{}
+	audited, _ := NewAuditedSetPackSizeEnabled(toggle, prov, log, AuditOptions{})
+
+	var wg sync.WaitGroup
+	for _, size := range sizes[1:] {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := audited.Execute(context.Background(), uc.SetPackSizeEnabledInput{Size: size}); err != nil {
+				t.Errorf("disable %d: %v", size, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// each entry starts from the list the previous one left, one size less
+	if len(log.entries) != len(sizes)-1 {
+		t.Fatalf("got %d entries want %d", len(log.entries), len(sizes)-1)
+	}
+	last := sizes
+	for i, e := range log.entries {
+		if !reflect.DeepEqual(e.Before.Sizes, last) || len(e.After.Sizes) != len(last)-1 {
+			t.Fatalf("entry %d: before %v after %v, previous after %v", i, e.Before.Sizes, e.After.Sizes, last)
+		}
+		last = e.After.Sizes
+	}
+}
+
+func TestAuditedSchedulePackSizes(t *testing.T) {
+	clock := &fakeClock{t: jun.Add(24 * time.Hour)}
+	prov := newSchedulingProvider(clock)
+	schedule, _ := NewSchedulePackSizes(prov, clock.Now)
+	log := &memoryAuditLog{}
+	audited, err := NewAuditedSchedulePackSizes(schedule, prov, log, AuditOptions{Now: clock.Now})
+	if err != nil {
+		t.Fatalf("NewAuditedSchedulePackSizes: %v", err)
+	}
+	later := clock.Now().Add(30 * 24 * time.Hour)
+	ctx := audit.WithCaller(context.Background(), audit.Caller{Actor: "bob"})
+
+	if _, err := audited.Execute(ctx, uc.SchedulePackSizesInput{Version: "2025-08", EffectiveFrom: later, Sizes: []int{500}}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	want := []audit.Entry{{
+		At: clock.Now(), Action: audit.ActionPackSizesScheduled, Actor: "bob",
+		Before: &audit.PackList{Version: "v2", Sizes: []int{300, 600}},
+		After:  &audit.PackList{Version: "2025-08", Sizes: []int{500}, EffectiveFrom: later},
+	}}
+	if !reflect.DeepEqual(log.entries, want) {
+		t.Fatalf("entries got %+v want %+v", log.entries, want)
+	}
+
+	// a rejected schedule is not recorded
+	if _, err := audited.Execute(ctx, uc.SchedulePackSizesInput{EffectiveFrom: later}); err == nil || len(log.entries) != 1 {
+		t.Fatalf("rejected schedule: %v, %d entries", err, len(log.entries))
+	}
+
+	// a version that cannot be recorded is reported, and withdrawn
+	log.err = errors.New("disk full")
+	_, err = audited.Execute(ctx, uc.SchedulePackSizesInput{Version: "2025-09", EffectiveFrom: later.Add(time.Hour), Sizes: []int{700}})
+	if !errors.Is(err, ErrAuditUnavailable) || !errors.Is(err, log.err) {
+		t.Fatalf("log failure: %v", err)
+	}
+	var appErr *apperr.Error
+	if !errors.As(err, &appErr) || appErr.Params["version"] != "2025-09" {
+		t.Fatalf("log failure params: %+v", appErr)
+	}
+	if all, _ := prov.Versions(ctx); slices.ContainsFunc(all, func(s packsizes.PackSet) bool { return s.Version == "2025-09" }) {
+		t.Fatalf("the version must be withdrawn: %+v", all)
+	}
+	log.err = nil
+	if _, err := audited.Execute(ctx, uc.SchedulePackSizesInput{Version: "2025-09", EffectiveFrom: later.Add(time.Hour), Sizes: []int{700}}); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+}
+
+func TestNewAudited_Errors(t *testing.T) {
+	prov := &togglingProvider{}
+	toggle, _ := NewSetPackSizeEnabled(prov)
+	if _, err := NewAuditedSetPackSizeEnabled(nil, prov, &memoryAuditLog{}, AuditOptions{}); err == nil {
+		t.Fatalf("expected an error for a nil use case")
+	}
+	if _, err := NewAuditedSetPackSizeEnabled(toggle, prov, nil, AuditOptions{}); err == nil {
+		t.Fatalf("expected an error for a nil log")
+	}
+	if _, err := NewAuditedSchedulePackSizes(nil, prov, &memoryAuditLog{}, AuditOptions{}); err == nil {
+		t.Fatalf("expected an error for a nil use case")
+	}
+	schedule, _ := NewSchedulePackSizes(newSchedulingProvider(&fakeClock{}), nil)
+	if _, err := NewAuditedSchedulePackSizes(schedule, prov, &memoryAuditLog{}, AuditOptions{}); err == nil {
+		t.Fatalf("expected an error for a provider without Schedule")
+	}
+}
@@ -0,0 +1,282 @@
+package order
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+const DefaultCacheEntries = 1024
+
+// CacheOptions configures the calculation cache.
+// - MaxEntries: LRU bound (<= 0 uses DefaultCacheEntries)
+// - TTL: entry lifetime (0 = no expiration, only LRU eviction)
+// - Now: clock, injectable for tests (nil = time.Now)
+type CacheOptions struct {
+	MaxEntries int
+	TTL        time.Duration
+	Now        func() time.Time
+}
+
+// CacheStats is a snapshot of the cache counters.
+// Shared counts callers that waited for an identical in-flight calculation.
+type CacheStats struct {
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Shared        uint64 `json:"shared"`
+	Evictions     uint64 `json:"evictions"`
+	Invalidations uint64 `json:"invalidations"`
+	Entries       int    `json:"entries"`
+}
+
+// CachedCalculatePacks decorates a CalculatePacks use case with a bounded
+// LRU/TTL cache keyed by normalised pack set + quantity + options.
+// Concurrent identical requests are computed once (single-flight), and the
+// entries computed from the provider list are dropped when that list changes.
+type CachedCalculatePacks struct {
+	next     uc.CalculatePacks
+	provider packsizes.Provider
+	opts     CacheOptions
+
+	mu          sync.Mutex
+	lru         *list.List               // front = most recently used
+	entries     map[string]*list.Element // key -> *cacheEntry
+	inflight    map[string]*flight
+	providerSet string // last seen provider list (normalised)
+	stats       CacheStats
+}
+
+type cacheEntry struct {
+	key          string
+	out          uc.CalculatePacksOutput
+	fromProvider bool
+	expiresAt    time.Time
+}
+
+type flight struct {
+	done chan struct{}
+	out  uc.CalculatePacksOutput
+	err  error
+}
+
+// compile-time check
+var _ uc.CalculatePacks = (*CachedCalculatePacks)(nil)
+
+func NewCachedCalculatePacks(next uc.CalculatePacks, provider packsizes.Provider, opts CacheOptions) (*CachedCalculatePacks, error) {
+	if next == nil {
+		return nil, errors.New("nil CalculatePacks")
+	}
+	if provider == nil {
+		return nil, errors.New("nil packsizes.Provider")
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultCacheEntries
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &CachedCalculatePacks{
+		next:     next,
+		provider: provider,
+		opts:     opts,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		inflight: make(map[string]*flight),
+	}, nil
+}
+
+func (c *CachedCalculatePacks) Execute(ctx context.Context, in uc.CalculatePacksInput) (uc.CalculatePacksOutput, error) {
+	key, set, ok := c.key(ctx, in)
+	if !ok {
+		// invalid input or provider failure: the use case owns those errors
+		return c.next.Execute(ctx, in)
+	}
+
+	c.mu.Lock()
+	if out, hit := c.lookup(key); hit {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return cloneOutput(out), nil
+	}
+	f, running := c.inflight[key]
+	if running {
+		c.stats.Shared++
+	} else {
+		c.stats.Misses++
+		f = &flight{done: make(chan struct{})}
+		c.inflight[key] = f
+		// detached: a caller that goes away must not fail the others
+		go c.compute(context.WithoutCancel(ctx), key, in, set, f)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-f.done:
+		if f.err != nil {
+			return uc.CalculatePacksOutput{}, f.err
+		}
+		return cloneOutput(f.out), nil
+	case <-ctx.Done():
+		return uc.CalculatePacksOutput{}, ctx.Err()
+	}
+}
+
+// compute runs the flight of key, on set when the use case takes it, and
+// caches its result.
+func (c *CachedCalculatePacks) compute(ctx context.Context, key string, in uc.CalculatePacksInput, set *packsizes.PackSet, f *flight) {
+	if on, ok := c.next.(calculatorOn); ok && set != nil {
+		f.out, f.err = on.executeOn(ctx, in, set)
+	} else {
+		f.out, f.err = c.next.Execute(ctx, in)
+	}
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if f.err == nil {
+		c.store(key, f.out, len(in.PacksOverride) == 0)
+	}
+	c.mu.Unlock()
+	close(f.done)
+}
+
+// Stats returns a snapshot of the hit/miss counters.
+func (c *CachedCalculatePacks) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = c.lru.Len()
+	return s
+}
+
+// key builds "[<version>=]<sizes>|<quantity>|<options>". Without an
+// override it also returns the provider set it read (with asOf, the set in
+// effect at that time): the calculation runs on that very set, so the
+// provider is read once and the result matches its key.
+func (c *CachedCalculatePacks) key(ctx context.Context, in uc.CalculatePacksInput) (string, *packsizes.PackSet, bool) {
+	if in.Quantity <= 0 {
+		return "", nil, false
+	}
+
+	var id string
+	var set *packsizes.PackSet
+	if len(in.PacksOverride) == 0 {
+		// the version is part of the key: the output carries it
+		catalogue, err := catalogueAt(ctx, c.provider, in.AsOf)
+		if err != nil || len(catalogue.Sizes) == 0 {
+			return "", nil, false
+		}
+		norm, err := normalizeOverride(catalogue.Sizes)
+		if err != nil {
+			return "", nil, false
+		}
+		id = catalogue.Version + "=" + joinInts(norm)
+		if in.AsOf.IsZero() {
+			c.observeProviderSet(id)
+		}
+		set = &catalogue
+	} else {
+		norm, err := normalizeOverride(in.PacksOverride)
+		if err != nil {
+			return "", nil, false
+		}
+		id = joinInts(norm)
+	}
+
+	var b strings.Builder
+	b.WriteString(id)
+	b.WriteByte('|')
+	b.WriteString(strconv.Itoa(in.Quantity))
+	if in.Explain {
+		b.WriteString("|explain")
+	}
+	return b.String(), set, true
+}
+
+// observeProviderSet drops the provider-based entries when the list changed.
+func (c *CachedCalculatePacks) observeProviderSet(set string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.providerSet == set {
+		return
+	}
+	if c.providerSet != "" {
+		for e := c.lru.Front(); e != nil; {
+			next := e.Next()
+			if ent := e.Value.(*cacheEntry); ent.fromProvider {
+				c.lru.Remove(e)
+				delete(c.entries, ent.key)
+			}
+			e = next
+		}
+		c.stats.Invalidations++
+	}
+	c.providerSet = set
+}
+
+// lookup must be called with c.mu held.
+func (c *CachedCalculatePacks) lookup(key string) (uc.CalculatePacksOutput, bool) {
+	e, ok := c.entries[key]
+	if !ok {
+		return uc.CalculatePacksOutput{}, false
+	}
+	ent := e.Value.(*cacheEntry)
+	if !ent.expiresAt.IsZero() && !c.opts.Now().Before(ent.expiresAt) {
+		c.lru.Remove(e)
+		delete(c.entries, key)
+		c.stats.Evictions++
+		return uc.CalculatePacksOutput{}, false
+	}
+	c.lru.MoveToFront(e)
+	return ent.out, true
+}
+
+// store must be called with c.mu held.
+func (c *CachedCalculatePacks) store(key string, out uc.CalculatePacksOutput, fromProvider bool) {
+	ent := &cacheEntry{key: key, out: cloneOutput(out), fromProvider: fromProvider}
+	if c.opts.TTL > 0 {
+		ent.expiresAt = c.opts.Now().Add(c.opts.TTL)
+	}
+	if e, ok := c.entries[key]; ok {
+		e.Value = ent
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(ent)
+	for c.lru.Len() > c.opts.MaxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.stats.Evictions++
+	}
+}
+
+// cloneOutput keeps cached values isolated from callers (maps are references).
+func cloneOutput(out uc.CalculatePacksOutput) uc.CalculatePacksOutput {
+	out.ItemsByPack = maps.Clone(out.ItemsByPack)
+	if out.Explanation != nil {
+		ex := *out.Explanation
+		ex.RunnersUp = make([]uc.RunnerUp, len(out.Explanation.RunnersUp))
+		for i, r := range out.Explanation.RunnersUp {
+			r.ItemsByPack = maps.Clone(r.ItemsByPack)
+			ex.RunnersUp[i] = r
+		}
+		out.Explanation = &ex
+	}
+	return out
+}
+
+func joinInts(in []int) string {
+	parts := make([]string, len(in))
+	for i, v := range in {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
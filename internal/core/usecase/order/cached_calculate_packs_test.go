@@ -0,0 +1,280 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// countingCalc counts the executions that reach the real use case.
+type countingCalc struct {
+	next  uc.CalculatePacks
+	calls atomic.Int64
+	gate  chan struct{} // when set, Execute waits for it
+}
+
+func (c *countingCalc) Execute(ctx context.Context, in uc.CalculatePacksInput) (uc.CalculatePacksOutput, error) {
+	c.calls.Add(1)
+	if c.gate != nil {
+		<-c.gate
+	}
+	return c.next.Execute(ctx, in)
+}
+
+// executeOn passes the pack set of the cache on to the real use case.
+func (c *countingCalc) executeOn(ctx context.Context, in uc.CalculatePacksInput, set *packsizes.PackSet) (uc.CalculatePacksOutput, error) {
+	c.calls.Add(1)
+	if c.gate != nil {
+		<-c.gate
+	}
+	return c.next.(calculatorOn).executeOn(ctx, in, set)
+}
+
+func newCached(t *testing.T, prov *fakeProvider, opts CacheOptions) (*CachedCalculatePacks, *countingCalc) {
+	t.Helper()
+	inner, err := NewCalculatePacks(domain.NewPackCalculator(), prov)
+	if err != nil {
+		t.Fatalf("NewCalculatePacks: %v", err)
+	}
+	counting := &countingCalc{next: inner}
+	cached, err := NewCachedCalculatePacks(counting, prov, opts)
+	if err != nil {
+		t.Fatalf("NewCachedCalculatePacks: %v", err)
+	}
+	return cached, counting
+}
+
+func TestCachedCalculatePacks_HitsAndMisses(t *testing.T) {
+	prov := &fakeProvider{sizes: []int{250, 500, 1000, 2000, 5000}}
+	cached, counting := newCached(t, prov, CacheOptions{})
+	ctx := context.Background()
+
+	first, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 12001})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first.ItemsByPack[5000] = 99 // must not leak into the cache
+
+	second, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 12001})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.ItemsByPack[5000] != 2 {
+		t.Fatalf("cached output was mutated by a caller: %v", second.ItemsByPack)
+	}
+
+	// an override is another entry (no catalogue version in its output), and
+	// the same set unsorted with duplicates has the same key
+	fromOverride, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 12001, PacksOverride: []int{250, 500, 1000, 2000, 5000}})
+	if err != nil || fromOverride.CatalogueVersion != "" {
+		t.Fatalf("override: out=%+v err=%v", fromOverride, err)
+	}
+	if _, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 12001, PacksOverride: []int{5000, 250, 2000, 1000, 500, 250}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// options are part of the key
+	out, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 12001, Explain: true})
+	if err != nil || out.Explanation == nil {
+		t.Fatalf("explain must not be served from the plain entry: out=%+v err=%v", out, err)
+	}
+
+	if got := counting.calls.Load(); got != 3 {
+		t.Fatalf("use case calls got=%d want=3", got)
+	}
+	st := cached.Stats()
+	if st.Hits != 2 || st.Misses != 3 || st.Entries != 3 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestCachedCalculatePacks_ErrorsAreNotCached(t *testing.T) {
+	prov := &fakeProvider{sizes: []int{250, 500}}
+	cached, counting := newCached(t, prov, CacheOptions{})
+	ctx := context.Background()
+
+	if _, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 0}); !errors.Is(err, ErrInvalidQuantity) {
+		t.Fatalf("expected ErrInvalidQuantity, got %v", err)
+	}
+	if _, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 5, PacksOverride: []int{-1}}); !errors.Is(err, ErrInvalidPackInOverride) {
+		t.Fatalf("expected ErrInvalidPackInOverride, got %v", err)
+	}
+	prov.sizes = nil
+	if _, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 5}); !errors.Is(err, ErrNoPackSizes) {
+		t.Fatalf("expected ErrNoPackSizes, got %v", err)
+	}
+	if st := cached.Stats(); st.Entries != 0 || counting.calls.Load() != 3 {
+		t.Fatalf("errors must bypass the cache: stats=%+v calls=%d", st, counting.calls.Load())
+	}
+}
+
+func TestCachedCalculatePacks_InvalidatesOnProviderChange(t *testing.T) {
+	prov := &fakeProvider{sizes: []int{250, 500}}
+	cached, _ := newCached(t, prov, CacheOptions{})
+	ctx := context.Background()
+
+	if _, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 251}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 251, PacksOverride: []int{3, 7}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	prov.sizes = []int{300}
+	out, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 251})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.TotalItems != 300 {
+		t.Fatalf("stale result after provider change: %+v", out)
+	}
+
+	st := cached.Stats()
+	// the override entry survives, the old provider entry is gone
+	if st.Invalidations != 1 || st.Entries != 2 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestCachedCalculatePacks_TTLAndLRU(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	prov := &fakeProvider{sizes: []int{250, 500}}
+	cached, counting := newCached(t, prov, CacheOptions{
+		MaxEntries: 2,
+		TTL:        time.Minute,
+		Now:        func() time.Time { return now },
+	})
+	ctx := context.Background()
+	run := func(q int) {
+		t.Helper()
+		if _, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: q}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	run(1)
+	run(2)
+	run(1) // hit, 1 becomes the most recent
+	run(3) // evicts 2
+	run(2) // miss
+	if got := counting.calls.Load(); got != 4 {
+		t.Fatalf("use case calls got=%d want=4", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	run(2) // expired
+	if got := counting.calls.Load(); got != 5 {
+		t.Fatalf("use case calls after TTL got=%d want=5", got)
+	}
+}
+
+func TestCachedCalculatePacks_SingleFlight(t *testing.T) {
+	prov := &fakeProvider{sizes: []int{250, 500, 1000, 2000, 5000}}
+	cached, counting := newCached(t, prov, CacheOptions{})
+	counting.gate = make(chan struct{})
+
+	const callers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := cached.Execute(context.Background(), uc.CalculatePacksInput{Quantity: 12001})
+			if err == nil && out.TotalItems != 12250 {
+				err = errors.New("wrong result")
+			}
+			errs <- err
+		}()
+	}
+
+	// wait until every caller is either computing or waiting
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		st := cached.Stats()
+		if st.Misses+st.Shared == callers {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("callers did not arrive: %+v", st)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(counting.gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := counting.calls.Load(); got != 1 {
+		t.Fatalf("use case calls got=%d want=1", got)
+	}
+}
+
+// countingProvider counts the reads of the pack sizes.
+type countingProvider struct {
+	fakeProvider
+	loads atomic.Int64
+}
+
+func (p *countingProvider) Load(ctx context.Context) (packsizes.Catalogue, error) {
+	p.loads.Add(1)
+	return p.fakeProvider.Load(ctx)
+}
+
+func TestCachedCalculatePacks_CancelledCallerDoesNotFailOthers(t *testing.T) {
+	prov := &countingProvider{fakeProvider: fakeProvider{sizes: []int{250, 500, 1000, 2000, 5000}}}
+	inner, err := NewCalculatePacks(domain.NewPackCalculator(), prov)
+	if err != nil {
+		t.Fatalf("NewCalculatePacks: %v", err)
+	}
+	counting := &countingCalc{next: inner, gate: make(chan struct{})}
+	cached, err := NewCachedCalculatePacks(counting, prov, CacheOptions{})
+	if err != nil {
+		t.Fatalf("NewCachedCalculatePacks: %v", err)
+	}
+
+	// the first caller starts the calculation, then goes away
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 12001})
+		first <- err
+	}()
+	for cached.Stats().Misses == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	second := make(chan error, 1)
+	go func() {
+		out, err := cached.Execute(context.Background(), uc.CalculatePacksInput{Quantity: 12001})
+		if err == nil && out.TotalItems != 12250 {
+			err = errors.New("wrong result")
+		}
+		second <- err
+	}()
+	for cached.Stats().Shared == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled caller got %v", err)
+	}
+	close(counting.gate)
+	if err := <-second; err != nil {
+		t.Fatalf("waiting caller got %v", err)
+	}
+
+	// one read for the key of each caller, none by the use case
+	if got := prov.loads.Load(); got != 2 {
+		t.Fatalf("provider loads got=%d want=2", got)
+	}
+}
@@ -3,46 +3,69 @@ package order
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sort"
 
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
 	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
 	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
 	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
 )
 
 var (
-	ErrInvalidQuantity       = errors.New("quantity must be > 0")
-	ErrNoPackSizes           = errors.New("no pack sizes available")
-	ErrInvalidPackInOverride = errors.New("override contains non-positive pack size")
+	ErrInvalidQuantity       = domain.ErrInvalidQuantity
+	ErrNoPackSizes           = domain.ErrNoPackSizes
+	ErrInvalidPackInOverride = apperr.New(apperr.KindInvalid, "invalid_pack", "packsOverride must contain positive integers")
+	ErrExplainUnsupported    = apperr.New(apperr.KindInternal, "explain_unsupported", "calculator cannot explain its results")
 )
 
 type calculatePacks struct {
 	calc     domain.PackCalculator
 	provider packsizes.Provider
+	limits   Limits
 }
 
 // compile-time check to keep my cohesion with my conctact
 var _ uc.CalculatePacks = (*calculatePacks)(nil)
 
-func NewCalculatePacks(calc domain.PackCalculator, provider packsizes.Provider) (uc.CalculatePacks, error) {
+// calculatorOn is implemented by the use case of NewCalculatePacks: it runs
+// a calculation on a pack set already read from the provider (the cache
+// passes the set it keyed the calculation on).
+type calculatorOn interface {
+	executeOn(ctx context.Context, in uc.CalculatePacksInput, set *packsizes.PackSet) (uc.CalculatePacksOutput, error)
+}
+
+func NewCalculatePacks(calc domain.PackCalculator, provider packsizes.Provider, opts ...Option) (uc.CalculatePacks, error) {
 	if calc == nil {
 		return nil, errors.New("nil PackCalculator")
 	}
 	if provider == nil {
 		return nil, errors.New("nil packsizes.Provider")
 	}
-	return &calculatePacks{calc: calc, provider: provider}, nil
+	c := &calculatePacks{calc: calc, provider: provider, limits: DefaultLimits}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
 }
 
 func (c *calculatePacks) Execute(ctx context.Context, in uc.CalculatePacksInput) (uc.CalculatePacksOutput, error) {
-	_ = ctx // (no-op for now; kept for future cancellation/telemetry)
+	return c.executeOn(ctx, in, nil)
+}
 
+// executeOn is Execute on set when it is not nil (no override): the provider
+// is not read.
+func (c *calculatePacks) executeOn(ctx context.Context, in uc.CalculatePacksInput, set *packsizes.PackSet) (uc.CalculatePacksOutput, error) {
 	if in.Quantity <= 0 {
-		return uc.CalculatePacksOutput{}, ErrInvalidQuantity
+		return uc.CalculatePacksOutput{}, ErrInvalidQuantity.WithViolation("quantity", "must be > 0")
+	}
+	if err := c.limits.checkQuantity(in.Quantity); err != nil {
+		return uc.CalculatePacksOutput{}, err
 	}
 
 	// Validate the size
 	var sizes []int
+	var catalogueVersion string
 	if len(in.PacksOverride) > 0 {
 		norm, err := normalizeOverride(in.PacksOverride)
 		if err != nil {
@@ -50,14 +73,17 @@ func (c *calculatePacks) Execute(ctx context.Context, in uc.CalculatePacksInput)
 		}
 		sizes = norm
 	} else {
-		list, err := c.provider.List()
-		if err != nil {
-			return uc.CalculatePacksOutput{}, err
+		if set == nil {
+			resolved, err := catalogueAt(ctx, c.provider, in.AsOf)
+			if err != nil {
+				return uc.CalculatePacksOutput{}, err
+			}
+			set = &resolved
 		}
-		if len(list) == 0 {
+		if len(set.Sizes) == 0 {
 			return uc.CalculatePacksOutput{}, ErrNoPackSizes
 		}
-		sizes = list
+		sizes, catalogueVersion = set.Sizes, set.Version
 	}
 
 	// Converte []int -> []domain.Pack
@@ -70,20 +96,102 @@ func (c *calculatePacks) Execute(ctx context.Context, in uc.CalculatePacksInput)
 		packs = append(packs, p)
 	}
 
-	comb, err := c.calc.Calculate(in.Quantity, packs)
+	// Refuse before the calculator allocates anything
+	if err := c.limits.checkPackSizes(countDistinct(sizes)); err != nil {
+		return uc.CalculatePacksOutput{}, err
+	}
+
+	if in.Explain {
+		// explanations always come from the DP search
+		if err := c.limits.checkDPCells(domain.EstimateDPCells(in.Quantity, packs)); err != nil {
+			return uc.CalculatePacksOutput{}, err
+		}
+		out, err := c.explain(in.Quantity, packs)
+		if err != nil {
+			return uc.CalculatePacksOutput{}, err
+		}
+		out.CatalogueVersion = catalogueVersion
+		return out, nil
+	}
+
+	calculate, err := c.plan(in, packs)
+	if err != nil {
+		return uc.CalculatePacksOutput{}, err
+	}
+	comb, err := calculate(in.Quantity, packs)
 	if err != nil {
 		return uc.CalculatePacksOutput{}, err
 	}
 
 	out := uc.CalculatePacksOutput{
-		ItemsByPack: comb.ItemsByPack,
-		TotalItems:  comb.TotalItems,
-		TotalPacks:  comb.TotalPacks,
-		Leftover:    comb.Leftover,
+		ItemsByPack:      comb.ItemsByPack,
+		TotalItems:       comb.TotalItems,
+		TotalPacks:       comb.TotalPacks,
+		Leftover:         comb.Leftover,
+		CatalogueVersion: catalogueVersion,
 	}
 	return out, nil
 }
 
+// plan picks the entry point of the calculator and checks the cells it
+// would allocate: what the calculator reports (domain.CostEstimator), the DP
+// table otherwise. When the calculator can answer without keeping anything
+// (domain.OneOffCalculator), an override runs that way, and so does a pack
+// set whose table is over MaxDPCells: the DP may still fit.
+func (c *calculatePacks) plan(in uc.CalculatePacksInput, packs []domain.Pack) (func(int, []domain.Pack) (domain.Combination, error), error) {
+	dpCells := domain.EstimateDPCells(in.Quantity, packs)
+	once, canOnce := c.calc.(domain.OneOffCalculator)
+	if canOnce && len(in.PacksOverride) > 0 {
+		// the client picks the set: nothing is kept for it
+		return once.CalculateOnce, c.limits.checkDPCells(dpCells)
+	}
+	if est, ok := c.calc.(domain.CostEstimator); ok {
+		err := c.limits.checkDPCells(est.EstimateCells(in.Quantity, packs))
+		if err == nil || !canOnce {
+			return c.calc.Calculate, err
+		}
+		return once.CalculateOnce, c.limits.checkDPCells(dpCells)
+	}
+	return c.calc.Calculate, c.limits.checkDPCells(dpCells)
+}
+
+// explain asks the domain calculator to justify its choice; nothing is
+// recomputed here, the use case only maps the result to the output DTO.
+func (c *calculatePacks) explain(quantity int, packs []domain.Pack) (uc.CalculatePacksOutput, error) {
+	explainer, ok := c.calc.(domain.Explainer)
+	if !ok {
+		return uc.CalculatePacksOutput{}, ErrExplainUnsupported
+	}
+	ex, err := explainer.Explain(quantity, packs)
+	if err != nil {
+		return uc.CalculatePacksOutput{}, err
+	}
+
+	runnersUp := make([]uc.RunnerUp, 0, len(ex.RunnersUp))
+	for _, r := range ex.RunnersUp {
+		runnersUp = append(runnersUp, uc.RunnerUp{
+			ItemsByPack: r.ItemsByPack,
+			TotalItems:  r.TotalItems,
+			TotalPacks:  r.TotalPacks,
+			Leftover:    r.Leftover,
+			RejectedBy:  string(r.RejectedBy),
+		})
+	}
+
+	return uc.CalculatePacksOutput{
+		ItemsByPack: ex.Chosen.ItemsByPack,
+		TotalItems:  ex.Chosen.TotalItems,
+		TotalPacks:  ex.Chosen.TotalPacks,
+		Leftover:    ex.Chosen.Leftover,
+		Explanation: &uc.Explanation{
+			GCD:              ex.GCD,
+			ScaledQuantity:   ex.ScaledQuantity,
+			ScaledUpperBound: ex.ScaledUpperBound,
+			RunnersUp:        runnersUp,
+		},
+	}, nil
+}
+
 // normalizeOverride applies minimal rules to the override coming from the caller:
 // - rejects values ​​<= 0 (explicit error)
 // - removes duplicates
@@ -94,9 +202,11 @@ func normalizeOverride(in []int) ([]int, error) {
 	}
 	seen := make(map[int]struct{}, len(in))
 	out := make([]int, 0, len(in))
-	for _, v := range in {
+	for i, v := range in {
 		if v <= 0 {
-			return nil, ErrInvalidPackInOverride
+			return nil, ErrInvalidPackInOverride.
+				With(map[string]any{"index": i, "value": v}).
+				WithViolation(fmt.Sprintf("packsOverride[%d]", i), fmt.Sprintf("must be > 0, got %d", v))
 		}
 		if _, dup := seen[v]; dup {
 			continue
@@ -107,3 +217,11 @@ func normalizeOverride(in []int) ([]int, error) {
 	sort.Ints(out)
 	return out, nil
 }
+
+func countDistinct(in []int) int {
+	seen := make(map[int]struct{}, len(in))
+	for _, v := range in {
+		seen[v] = struct{}{}
+	}
+	return len(seen)
+}
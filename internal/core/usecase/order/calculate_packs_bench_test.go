@@ -6,15 +6,15 @@ import (
 
 	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
 	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
 )
 
 type benchProvider struct{ sizes []int }
 
-func (f *benchProvider) List() ([]int, error) {
-
+func (f *benchProvider) Load(context.Context) (packsizes.Catalogue, error) {
 	out := make([]int, len(f.sizes))
 	copy(out, f.sizes)
-	return out, nil
+	return packsizes.Catalogue{Sizes: out}, nil
 }
 
 func BenchmarkCalculatePacks_Various(b *testing.B) {
@@ -3,8 +3,10 @@ package order
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
 	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
 	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
 	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
@@ -15,11 +17,11 @@ type fakeProvider struct {
 	err   error
 }
 
-func (f *fakeProvider) List() ([]int, error) {
+func (f *fakeProvider) Load(context.Context) (packsizes.Catalogue, error) {
 	if f.err != nil {
-		return nil, f.err
+		return packsizes.Catalogue{}, f.err
 	}
-	return append([]int(nil), f.sizes...), nil
+	return packsizes.Catalogue{Sizes: append([]int(nil), f.sizes...)}, nil
 }
 
 func TestCalculatePacks_Execute(t *testing.T) {
@@ -107,3 +109,128 @@ func TestCalculatePacks_Execute(t *testing.T) {
 		})
 	}
 }
+
+// plainCalc hides the Explainer implemented by the domain calculator.
+type plainCalc struct{ domain.PackCalculator }
+
+func TestCalculatePacks_Explain(t *testing.T) {
+	prov := &fakeProvider{sizes: []int{250, 500, 1000, 2000, 5000}}
+
+	ucase, err := NewCalculatePacks(domain.NewPackCalculator(), prov)
+	if err != nil {
+		t.Fatalf("unexpected NewCalculatePacks error: %v", err)
+	}
+	out, err := ucase.Execute(context.Background(), uc.CalculatePacksInput{Quantity: 12001, Explain: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.TotalItems != 12250 || out.TotalPacks != 4 {
+		t.Fatalf("unexpected result: %+v", out)
+	}
+	if out.Explanation == nil {
+		t.Fatalf("expected explanation")
+	}
+	if out.Explanation.GCD != 250 || len(out.Explanation.RunnersUp) == 0 {
+		t.Fatalf("unexpected explanation: %+v", out.Explanation)
+	}
+
+	plain, _ := NewCalculatePacks(plainCalc{domain.NewPackCalculator()}, prov)
+	if _, err := plain.Execute(context.Background(), uc.CalculatePacksInput{Quantity: 1, Explain: true}); !errors.Is(err, ErrExplainUnsupported) {
+		t.Fatalf("expected ErrExplainUnsupported, got %v", err)
+	}
+	out, err = plain.Execute(context.Background(), uc.CalculatePacksInput{Quantity: 1})
+	if err != nil || out.Explanation != nil {
+		t.Fatalf("explanation must be opt-in: out=%+v err=%v", out, err)
+	}
+}
+
+func TestCalculatePacks_OverrideErrorPointsAtEntry(t *testing.T) {
+	ucase, _ := NewCalculatePacks(domain.NewPackCalculator(), &fakeProvider{sizes: []int{1}})
+
+	_, err := ucase.Execute(context.Background(), uc.CalculatePacksInput{Quantity: 5, PacksOverride: []int{250, 500, 0}})
+	if !errors.Is(err, ErrInvalidPackInOverride) {
+		t.Fatalf("expected ErrInvalidPackInOverride, got %v", err)
+	}
+	ae, _ := apperr.As(err)
+	want := []apperr.Violation{{Field: "packsOverride[2]", Reason: "must be > 0, got 0"}}
+	if !reflect.DeepEqual(ae.Violations, want) || ae.Params["index"] != 2 || ae.Params["value"] != 0 {
+		t.Fatalf("unexpected error details: %+v", ae)
+	}
+}
+
+// oneOffCalc records which entry point of the calculator was used; its
+// tables are cells big.
+type oneOffCalc struct {
+	domain.PackCalculator
+	kept, once int
+	cells      int
+}
+
+func (c *oneOffCalc) EstimateCells(int, []domain.Pack) int { return c.cells }
+
+func (c *oneOffCalc) Calculate(q int, packs []domain.Pack) (domain.Combination, error) {
+	c.kept++
+	return c.PackCalculator.Calculate(q, packs)
+}
+
+func (c *oneOffCalc) CalculateOnce(q int, packs []domain.Pack) (domain.Combination, error) {
+	c.once++
+	return c.PackCalculator.Calculate(q, packs)
+}
+
+func TestCalculatePacks_OverridesAreOneOff(t *testing.T) {
+	calc := &oneOffCalc{PackCalculator: domain.NewPackCalculator()}
+	c, err := NewCalculatePacks(calc, &fakeProvider{sizes: []int{250, 500}})
+	if err != nil {
+		t.Fatalf("NewCalculatePacks: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := c.Execute(ctx, uc.CalculatePacksInput{Quantity: 251}); err != nil {
+		t.Fatalf("provider set: %v", err)
+	}
+	if _, err := c.Execute(ctx, uc.CalculatePacksInput{Quantity: 251, PacksOverride: []int{1990, 1991}}); err != nil {
+		t.Fatalf("override: %v", err)
+	}
+	if calc.kept != 1 || calc.once != 1 {
+		t.Fatalf("Calculate got=%d CalculateOnce got=%d, want 1 each", calc.kept, calc.once)
+	}
+}
+
+func TestCalculatePacks_StrategyCost(t *testing.T) {
+	ctx := context.Background()
+	limits := Limits{MaxDPCells: 5000}
+	in := uc.CalculatePacksInput{Quantity: 1}
+
+	tests := []struct {
+		name      string
+		sizes     []int
+		cells     int
+		wantKept  int
+		wantOnce  int
+		wantLimit bool
+	}{
+		{"table within the limit", []int{250, 500}, 5000, 1, 0, false},
+		{"table over the limit, DP within", []int{1990, 1991}, 3_964_082, 0, 1, false},
+		{"both over the limit", []int{5000}, 5001, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := &oneOffCalc{PackCalculator: domain.NewPackCalculator(), cells: tt.cells}
+			c, err := NewCalculatePacks(calc, &fakeProvider{sizes: tt.sizes}, WithLimits(limits))
+			if err != nil {
+				t.Fatalf("NewCalculatePacks: %v", err)
+			}
+			q := in
+			if tt.wantLimit {
+				q.Quantity = 100_000_000
+			}
+			_, err = c.Execute(ctx, q)
+			if tt.wantLimit != errors.Is(err, ErrCalculationTooLarge) || (!tt.wantLimit && err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if calc.kept != tt.wantKept || calc.once != tt.wantOnce {
+				t.Fatalf("Calculate got=%d CalculateOnce got=%d, want %d and %d", calc.kept, calc.once, tt.wantKept, tt.wantOnce)
+			}
+		})
+	}
+}
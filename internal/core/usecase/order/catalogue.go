@@ -0,0 +1,75 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// ErrNoCatalogueAt carries the requested time in Params ("asOf").
+var ErrNoCatalogueAt = apperr.New(apperr.KindUnprocessable, "no_catalogue_at", "no pack sizes catalogue was in effect at asOf")
+
+// ErrPackSizesUnavailable wraps a packsizes.ErrUnavailable of the provider:
+// the list is temporarily out of reach, not broken.
+var ErrPackSizesUnavailable = apperr.New(apperr.KindUnavailable, "pack_sizes_unavailable", "pack sizes are temporarily unavailable")
+
+// catalogueAt resolves the pack set of a calculation: the one in effect at
+// asOf, or the current one when asOf is zero. A provider without history has
+// a single set, in effect since always, so asOf does not change it.
+func catalogueAt(ctx context.Context, provider packsizes.Provider, asOf time.Time) (packsizes.PackSet, error) {
+	if history, ok := provider.(packsizes.History); ok && !asOf.IsZero() {
+		set, err := history.At(ctx, asOf)
+		if errors.Is(err, packsizes.ErrNoPackSet) {
+			return packsizes.PackSet{}, ErrNoCatalogueAt.With(map[string]any{"asOf": asOf.UTC().Format(time.RFC3339)})
+		}
+		return set, providerError(err)
+	}
+
+	catalogue, err := current(ctx, provider)
+	if err != nil {
+		return packsizes.PackSet{}, err
+	}
+	return packsizes.PackSet{Version: catalogue.Version.ID, Sizes: catalogue.Sizes, Packs: catalogue.Packs}, nil
+}
+
+// current loads the provider catalogue, its version always set.
+func current(ctx context.Context, provider packsizes.Provider) (packsizes.Catalogue, error) {
+	catalogue, err := provider.Load(ctx)
+	if err != nil {
+		return packsizes.Catalogue{}, providerError(err)
+	}
+	catalogue.Version = catalogue.Revision()
+	return catalogue, nil
+}
+
+// toPackSizes lists every pack of the catalogue; a provider without metadata
+// only has the listed sizes, all enabled.
+func toPackSizes(catalogue packsizes.Catalogue) []uc.PackSize {
+	packs := catalogue.AllPacks()
+	out := make([]uc.PackSize, 0, len(packs))
+	for _, p := range packs {
+		out = append(out, toPackSize(p))
+	}
+	return out
+}
+
+func toPackSize(p packsizes.Pack) uc.PackSize {
+	out := uc.PackSize{Size: p.Size, Enabled: p.Enabled, Label: p.Label, SKU: p.SKU, Cost: p.Cost, Stock: p.Stock}
+	if d := p.Dimensions; d != nil {
+		out.Dimensions = &uc.PackDimensions{Length: d.Length, Width: d.Width, Height: d.Height, Unit: d.Unit}
+	}
+	return out
+}
+
+// providerError types the provider errors the caller can act on (retry
+// later); the others stay untyped.
+func providerError(err error) error {
+	if errors.Is(err, packsizes.ErrUnavailable) {
+		return ErrPackSizesUnavailable.Wrap(err)
+	}
+	return err
+}
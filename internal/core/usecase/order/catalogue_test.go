@@ -0,0 +1,140 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+var (
+	jan = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	jun = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// historyProvider keeps two versions; "v2" is the current one.
+type historyProvider struct {
+	sets []packsizes.PackSet
+}
+
+func newHistoryProvider() *historyProvider {
+	return &historyProvider{sets: []packsizes.PackSet{
+		{Version: "v1", EffectiveFrom: jan, Sizes: []int{250, 500, 1000}},
+		{Version: "v2", EffectiveFrom: jun, Sizes: []int{300, 600}},
+	}}
+}
+
+func (p *historyProvider) Load(context.Context) (packsizes.Catalogue, error) {
+	cur := p.sets[len(p.sets)-1]
+	return packsizes.Catalogue{Sizes: cur.Sizes, Version: packsizes.Version{ID: cur.Version, UpdatedAt: cur.EffectiveFrom}}, nil
+}
+
+func (p *historyProvider) At(_ context.Context, t time.Time) (packsizes.PackSet, error) {
+	for i := len(p.sets) - 1; i >= 0; i-- {
+		if !p.sets[i].EffectiveFrom.After(t) {
+			return p.sets[i], nil
+		}
+	}
+	return packsizes.PackSet{}, packsizes.ErrNoPackSet
+}
+
+func (p *historyProvider) Versions(context.Context) ([]packsizes.PackSet, error) { return p.sets, nil }
+
+func TestCalculatePacks_AsOf(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    packsizes.Provider
+		in          uc.CalculatePacksInput
+		wantVersion string
+		wantTotal   int
+		wantErr     error
+	}{
+		{
+			name:        "current",
+			provider:    newHistoryProvider(),
+			in:          uc.CalculatePacksInput{Quantity: 501},
+			wantVersion: "v2", wantTotal: 600,
+		},
+		{
+			name:        "asOf before the switch",
+			provider:    newHistoryProvider(),
+			in:          uc.CalculatePacksInput{Quantity: 501, AsOf: jun.Add(-time.Second)},
+			wantVersion: "v1", wantTotal: 750,
+		},
+		{
+			name:        "asOf exactly at the switch",
+			provider:    newHistoryProvider(),
+			in:          uc.CalculatePacksInput{Quantity: 501, AsOf: jun},
+			wantVersion: "v2", wantTotal: 600,
+		},
+		{
+			name:     "asOf before the first version",
+			provider: newHistoryProvider(),
+			in:       uc.CalculatePacksInput{Quantity: 501, AsOf: jan.AddDate(-1, 0, 0)},
+			wantErr:  ErrNoCatalogueAt,
+		},
+		{
+			name:        "override ignores asOf",
+			provider:    newHistoryProvider(),
+			in:          uc.CalculatePacksInput{Quantity: 501, AsOf: jan.AddDate(-1, 0, 0), PacksOverride: []int{500}},
+			wantVersion: "", wantTotal: 1000,
+		},
+		{
+			name:        "provider without history",
+			provider:    &fakeProvider{sizes: []int{250, 500}},
+			in:          uc.CalculatePacksInput{Quantity: 501, AsOf: jan},
+			wantVersion: packsizes.VersionOf([]int{250, 500}), wantTotal: 750,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucase, err := NewCalculatePacks(domain.NewPackCalculator(), tt.provider)
+			if err != nil {
+				t.Fatalf("NewCalculatePacks: %v", err)
+			}
+			for _, explain := range []bool{false, true} {
+				in := tt.in
+				in.Explain = explain
+				out, err := ucase.Execute(context.Background(), in)
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("error got=%v want=%v", err, tt.wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if out.CatalogueVersion != tt.wantVersion || out.TotalItems != tt.wantTotal {
+					t.Fatalf("explain=%v got version=%q total=%d want %q %d", explain, out.CatalogueVersion, out.TotalItems, tt.wantVersion, tt.wantTotal)
+				}
+			}
+		})
+	}
+}
+
+func TestCachedCalculatePacks_AsOf(t *testing.T) {
+	prov := newHistoryProvider()
+	inner, _ := NewCalculatePacks(domain.NewPackCalculator(), prov)
+	counting := &countingCalc{next: inner}
+	cached, _ := NewCachedCalculatePacks(counting, prov, CacheOptions{})
+	ctx := context.Background()
+
+	current, _ := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 501})
+	past, _ := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 501, AsOf: jan})
+	again, _ := cached.Execute(ctx, uc.CalculatePacksInput{Quantity: 501, AsOf: jan.AddDate(0, 1, 0)}) // same version
+	if current.CatalogueVersion != "v2" || past.CatalogueVersion != "v1" || again.CatalogueVersion != "v1" {
+		t.Fatalf("versions got %q %q %q", current.CatalogueVersion, past.CatalogueVersion, again.CatalogueVersion)
+	}
+	if got := counting.calls.Load(); got != 2 {
+		t.Fatalf("use case calls got=%d want=2", got)
+	}
+	// a historical lookup does not invalidate the current entries
+	if st := cached.Stats(); st.Invalidations != 0 || st.Entries != 2 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
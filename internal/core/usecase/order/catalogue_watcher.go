@@ -0,0 +1,134 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// DefaultWatcherMaxWait bounds the sleep of CatalogueWatcher.Run, so a
+// version scheduled at runtime is seen even when nothing was due before it.
+const DefaultWatcherMaxWait = time.Minute
+
+// SwitchEvent reports that a scheduled version took effect.
+// - From: version in effect before ("" when there was none)
+// - To: version in effect now
+// - At: EffectiveFrom of To
+type SwitchEvent struct {
+	From string
+	To   string
+	At   time.Time
+}
+
+// WatcherOptions configures a CatalogueWatcher.
+// - Now: clock, injectable for tests (nil = time.Now)
+// - After: timer, injectable for tests (nil = time.After)
+// - MaxWait: longest sleep between checks (0 = DefaultWatcherMaxWait)
+// - OnSwitch: called once per switch (required)
+type WatcherOptions struct {
+	Now      func() time.Time
+	After    func(time.Duration) <-chan time.Time
+	MaxWait  time.Duration
+	OnSwitch func(SwitchEvent)
+}
+
+// CatalogueWatcher notices when the provider switches to a scheduled version.
+// The switch itself needs no action (the provider resolves the set in effect
+// on every call); the watcher only reports it, at the scheduled time.
+type CatalogueWatcher struct {
+	history packsizes.History
+	opts    WatcherOptions
+
+	mu      sync.Mutex
+	current string
+}
+
+// NewCatalogueWatcher needs a provider implementing packsizes.History; the
+// version in effect now is the starting point (it is not reported).
+func NewCatalogueWatcher(provider packsizes.Provider, opts WatcherOptions) (*CatalogueWatcher, error) {
+	if provider == nil {
+		return nil, errors.New("nil packsizes.Provider")
+	}
+	history, ok := provider.(packsizes.History)
+	if !ok {
+		return nil, errors.New("the packsizes.Provider has no history to watch")
+	}
+	if opts.OnSwitch == nil {
+		return nil, errors.New("nil OnSwitch")
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.After == nil {
+		opts.After = time.After
+	}
+	if opts.MaxWait <= 0 {
+		opts.MaxWait = DefaultWatcherMaxWait
+	}
+
+	w := &CatalogueWatcher{history: history, opts: opts}
+	set, err := w.inEffect(context.Background(), opts.Now())
+	if err != nil {
+		return nil, err
+	}
+	w.current = set.Version
+	return w, nil
+}
+
+// Check reports a switch when the version in effect changed since the last
+// check, and returns when the next scheduled version takes effect (zero when
+// none is scheduled).
+func (w *CatalogueWatcher) Check(ctx context.Context) (time.Time, error) {
+	now := w.opts.Now()
+	set, err := w.inEffect(ctx, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	w.mu.Lock()
+	prev := w.current
+	w.current = set.Version
+	w.mu.Unlock()
+	if set.Version != prev {
+		w.opts.OnSwitch(SwitchEvent{From: prev, To: set.Version, At: set.EffectiveFrom})
+	}
+
+	sets, err := w.history.Versions(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, s := range sets {
+		if s.EffectiveFrom.After(now) {
+			return s.EffectiveFrom, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// Run checks at each scheduled switch, and at least every MaxWait, until ctx
+// is done. A failed check is retried after MaxWait.
+func (w *CatalogueWatcher) Run(ctx context.Context) {
+	for {
+		wait := w.opts.MaxWait
+		if next, err := w.Check(ctx); err == nil && !next.IsZero() {
+			wait = min(wait, next.Sub(w.opts.Now()))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.opts.After(wait):
+		}
+	}
+}
+
+// inEffect treats "no set yet" as an empty version.
+func (w *CatalogueWatcher) inEffect(ctx context.Context, t time.Time) (packsizes.PackSet, error) {
+	set, err := w.history.At(ctx, t)
+	if errors.Is(err, packsizes.ErrNoPackSet) {
+		return packsizes.PackSet{}, nil
+	}
+	return set, err
+}
@@ -0,0 +1,97 @@
+package order
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+func TestCatalogueWatcher_Check(t *testing.T) {
+	clock := &fakeClock{t: jun.Add(-time.Hour)}
+	prov := newSchedulingProvider(clock)
+	var events []SwitchEvent
+	w, err := NewCatalogueWatcher(prov, WatcherOptions{Now: clock.Now, OnSwitch: func(e SwitchEvent) { events = append(events, e) }})
+	if err != nil {
+		t.Fatalf("NewCatalogueWatcher: %v", err)
+	}
+
+	next, err := w.Check(context.Background())
+	if err != nil || !next.Equal(jun) || len(events) != 0 {
+		t.Fatalf("before the switch: next=%s err=%v events=%v", next, err, events)
+	}
+
+	clock.t = jun
+	next, err = w.Check(context.Background())
+	if err != nil || !next.IsZero() {
+		t.Fatalf("after the switch: next=%s err=%v", next, err)
+	}
+	want := []SwitchEvent{{From: "v1", To: "v2", At: jun}}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events got=%+v want=%+v", events, want)
+	}
+
+	// reported once
+	clock.Advance(time.Hour)
+	if _, err := w.Check(context.Background()); err != nil || len(events) != 1 {
+		t.Fatalf("second check: err=%v events=%v", err, events)
+	}
+}
+
+func TestCatalogueWatcher_NeedsHistory(t *testing.T) {
+	if _, err := NewCatalogueWatcher(&fakeProvider2{sizes: []int{1}}, WatcherOptions{OnSwitch: func(SwitchEvent) {}}); err == nil {
+		t.Fatalf("expected an error for a provider without history")
+	}
+	if _, err := NewCatalogueWatcher(newHistoryProvider(), WatcherOptions{}); err == nil {
+		t.Fatalf("expected an error without OnSwitch")
+	}
+}
+
+// Run sleeps until the next switch (at most MaxWait) and reports it when the
+// timer fires.
+func TestCatalogueWatcher_Run(t *testing.T) {
+	clock := &fakeClock{t: jun.Add(-10 * time.Second)}
+	prov := newSchedulingProvider(clock)
+	waits := make(chan time.Duration)
+	fire := make(chan time.Time)
+	events := make(chan SwitchEvent, 1)
+	w, err := NewCatalogueWatcher(prov, WatcherOptions{
+		Now:      clock.Now,
+		After:    func(d time.Duration) <-chan time.Time { waits <- d; return fire },
+		MaxWait:  time.Minute,
+		OnSwitch: func(e SwitchEvent) { events <- e },
+	})
+	if err != nil {
+		t.Fatalf("NewCatalogueWatcher: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { w.Run(ctx); close(done) }()
+
+	if d := <-waits; d != 10*time.Second {
+		t.Fatalf("first wait got=%s want=10s (until v2)", d)
+	}
+	clock.Advance(10 * time.Second)
+	fire <- clock.Now()
+	if e := <-events; e.To != "v2" {
+		t.Fatalf("event got=%+v", e)
+	}
+	if d := <-waits; d != time.Minute {
+		t.Fatalf("wait without a scheduled version got=%s want=MaxWait", d)
+	}
+
+	// a version scheduled meanwhile is picked up on the next wake-up
+	if err := prov.Schedule(context.Background(), packsizes.PackSet{Version: "v3", EffectiveFrom: clock.Now().Add(90 * time.Second), Sizes: []int{700}}); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	clock.Advance(time.Minute)
+	fire <- clock.Now()
+	if d := <-waits; d != 30*time.Second {
+		t.Fatalf("wait until v3 got=%s want=30s", d)
+	}
+
+	cancel()
+	<-done
+}
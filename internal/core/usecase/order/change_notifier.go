@@ -0,0 +1,126 @@
+package order
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/events"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// DefaultChangeInterval is how often ChangeNotifier.Run reads the provider.
+const DefaultChangeInterval = 30 * time.Second
+
+// ChangeOptions configures a ChangeNotifier.
+// - Interval: between two reads of the provider (0 = DefaultChangeInterval)
+// - Last: the list of the last event published before a restart, so a
+// change made while the service was down is reported (nil: the first read
+// is the starting point)
+// - Now: clock, injectable for tests (nil = time.Now)
+// - After: timer, injectable for tests (nil = time.After)
+// - NewID: event IDs (nil = 16 random bytes, hex)
+type ChangeOptions struct {
+	Interval time.Duration
+	Last     *events.PackList
+	Now      func() time.Time
+	After    func(time.Duration) <-chan time.Time
+	NewID    func() string
+}
+
+// ChangeNotifier publishes a PackSizesChanged event whenever the list served
+// by the provider changes, whatever the cause: a size disabled, a scheduled
+// version taking effect, the catalogue service or the database answering
+// other sizes... A degraded list (a fallback source or the cache of a chain)
+// is not a change: it stands in for the list while the first source is
+// down, and the receivers would get the change and its undoing. The file
+// provider reads its file once, so editing it needs a restart (the change
+// is then sent from the last published list). The list is compared, not the version: a
+// new version with the same sizes is not a change for the receivers.
+type ChangeNotifier struct {
+	provider  packsizes.Provider
+	publisher events.Publisher
+	opts      ChangeOptions
+
+	mu   sync.Mutex       // one check at a time
+	last *events.PackList // nil until the first read
+}
+
+func NewChangeNotifier(provider packsizes.Provider, publisher events.Publisher, opts ChangeOptions) (*ChangeNotifier, error) {
+	if provider == nil {
+		return nil, errors.New("nil packsizes.Provider")
+	}
+	if publisher == nil {
+		return nil, errors.New("nil events.Publisher")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultChangeInterval
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.After == nil {
+		opts.After = time.After
+	}
+	if opts.NewID == nil {
+		opts.NewID = newEventID
+	}
+	n := &ChangeNotifier{provider: provider, publisher: publisher, opts: opts}
+	if opts.Last != nil {
+		last := *opts.Last
+		n.last = &last
+	}
+	return n, nil
+}
+
+// Check reads the provider and publishes an event when its list differs from
+// the last one; it tells whether it did. A degraded list is skipped, and a
+// failed publish is tried again by the next check.
+func (n *ChangeNotifier) Check(ctx context.Context) (bool, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	catalogue, err := current(ctx, n.provider)
+	if err != nil {
+		return false, err
+	}
+	if catalogue.Degraded {
+		return false, nil
+	}
+	now := events.PackList{Version: catalogue.Version.ID, Sizes: slices.Clone(catalogue.Sizes), Source: catalogue.Source}
+	if n.last == nil || slices.Equal(n.last.Sizes, now.Sizes) {
+		// the version and source of the next event's Previous
+		n.last = &now
+		return false, nil
+	}
+
+	e := events.PackSizesChanged{ID: n.opts.NewID(), OccurredAt: n.opts.Now(), Previous: *n.last, Current: now}
+	if err := n.publisher.Publish(ctx, e); err != nil {
+		return false, err
+	}
+	n.last = &now
+	return true, nil
+}
+
+// Run checks every Interval until ctx is done; a failed check is retried at
+// the next one.
+func (n *ChangeNotifier) Run(ctx context.Context) {
+	for {
+		_, _ = n.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-n.opts.After(n.opts.Interval):
+		}
+	}
+}
+
+func newEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,130 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/events"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// recordingPublisher keeps the published events, or fails with err.
+type recordingPublisher struct {
+	events []events.PackSizesChanged
+	err    error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e events.PackSizesChanged) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, e)
+	return nil
+}
+
+func TestChangeNotifier_Check(t *testing.T) {
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	prov := &fakeProvider2{sizes: []int{250, 500}, version: packsizes.Version{ID: "v1"}, source: "file"}
+	pub := &recordingPublisher{}
+	n, err := NewChangeNotifier(prov, pub, ChangeOptions{Now: func() time.Time { return now }, NewID: func() string { return "evt-1" }})
+	if err != nil {
+		t.Fatalf("NewChangeNotifier: %v", err)
+	}
+	ctx := context.Background()
+
+	// the first read is the starting point
+	if changed, err := n.Check(ctx); changed || err != nil {
+		t.Fatalf("first check: %v %v", changed, err)
+	}
+
+	// a new version with the same sizes is not a change
+	prov.version.ID = "v1-reformatted"
+	if changed, err := n.Check(ctx); changed || err != nil {
+		t.Fatalf("same sizes: %v %v", changed, err)
+	}
+
+	prov.sizes, prov.version.ID = []int{500, 1000}, "v2"
+	if changed, err := n.Check(ctx); !changed || err != nil {
+		t.Fatalf("changed sizes: %v %v", changed, err)
+	}
+	want := []events.PackSizesChanged{{
+		ID:         "evt-1",
+		OccurredAt: now,
+		Previous:   events.PackList{Version: "v1-reformatted", Sizes: []int{250, 500}, Source: "file"},
+		Current:    events.PackList{Version: "v2", Sizes: []int{500, 1000}, Source: "file"},
+	}}
+	if !reflect.DeepEqual(pub.events, want) {
+		t.Fatalf("events got %+v want %+v", pub.events, want)
+	}
+
+	// reported once
+	if changed, _ := n.Check(ctx); changed || len(pub.events) != 1 {
+		t.Fatalf("second check: %v %+v", changed, pub.events)
+	}
+}
+
+func TestChangeNotifier_Degraded(t *testing.T) {
+	prov := &fakeProvider2{sizes: []int{250, 500}, source: "file"}
+	pub := &recordingPublisher{}
+	n, _ := NewChangeNotifier(prov, pub, ChangeOptions{})
+	ctx := context.Background()
+	if _, err := n.Check(ctx); err != nil {
+		t.Fatalf("first check: %v", err)
+	}
+
+	// a fallback with other sizes, then the first source back: no event
+	prov.sizes, prov.source, prov.degraded = []int{1000}, "defaults", true
+	if changed, err := n.Check(ctx); changed || err != nil {
+		t.Fatalf("fallback: %v %v", changed, err)
+	}
+	prov.sizes, prov.source, prov.degraded = []int{250, 500}, "file", false
+	if changed, err := n.Check(ctx); changed || err != nil || len(pub.events) != 0 {
+		t.Fatalf("recovered: %v %v %+v", changed, err, pub.events)
+	}
+
+	// a change made while degraded is reported against the last healthy list
+	prov.degraded = true
+	_, _ = n.Check(ctx)
+	prov.sizes, prov.degraded = []int{500}, false
+	if changed, err := n.Check(ctx); !changed || err != nil || !reflect.DeepEqual(pub.events[0].Previous.Sizes, []int{250, 500}) {
+		t.Fatalf("changed: %v %v %+v", changed, err, pub.events)
+	}
+}
+
+func TestChangeNotifier_Failures(t *testing.T) {
+	prov := &fakeProvider2{sizes: []int{250}}
+	pub := &recordingPublisher{err: errors.New("disk full")}
+	last := events.PackList{Version: "old", Sizes: []int{100}}
+	n, _ := NewChangeNotifier(prov, pub, ChangeOptions{Last: &last})
+	ctx := context.Background()
+
+	// a change made while the service was down is reported, once published
+	if changed, err := n.Check(ctx); changed || !errors.Is(err, pub.err) {
+		t.Fatalf("failed publish: %v %v", changed, err)
+	}
+	pub.err = nil
+	if changed, err := n.Check(ctx); !changed || err != nil {
+		t.Fatalf("retried publish: %v %v", changed, err)
+	}
+	if e := pub.events[0]; e.Previous.Version != "old" || e.ID == "" {
+		t.Fatalf("event got %+v", e)
+	}
+
+	// the provider fails: nothing is published
+	prov.err = errors.New("fail")
+	if changed, err := n.Check(ctx); changed || err == nil {
+		t.Fatalf("provider error: %v %v", changed, err)
+	}
+}
+
+func TestNewChangeNotifier_Errors(t *testing.T) {
+	if _, err := NewChangeNotifier(nil, &recordingPublisher{}, ChangeOptions{}); err == nil {
+		t.Fatalf("expected an error for a nil provider")
+	}
+	if _, err := NewChangeNotifier(&fakeProvider2{}, nil, ChangeOptions{}); err == nil {
+		t.Fatalf("expected an error for a nil publisher")
+	}
+}
@@ -23,11 +23,16 @@ func NewGetPackSizes(provider packsizes.Provider) (uc.GetPackSizes, error) {
 }
 
 func (g *getPackSizes) Execute(ctx context.Context) (uc.GetPackSizesOutput, error) {
-	_ = ctx // (no-op for now; kept for future cancellation/telemetry)
-
-	sizes, err := g.provider.List()
+	catalogue, err := current(ctx, g.provider)
 	if err != nil {
 		return uc.GetPackSizesOutput{}, err
 	}
-	return uc.GetPackSizesOutput{Sizes: sizes}, nil
+	return uc.GetPackSizesOutput{
+		Sizes:     catalogue.Sizes,
+		Packs:     toPackSizes(catalogue),
+		Version:   catalogue.Version.ID,
+		UpdatedAt: catalogue.Version.UpdatedAt,
+		Source:    catalogue.Source,
+		LoadedAt:  catalogue.LoadedAt,
+	}, nil
 }
@@ -3,14 +3,16 @@ package order
 import (
 	"context"
 	"testing"
+
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
 )
 
 type benchProvider2 struct{ sizes []int }
 
-func (f *benchProvider2) List() ([]int, error) {
+func (f *benchProvider2) Load(context.Context) (packsizes.Catalogue, error) {
 	out := make([]int, len(f.sizes))
 	copy(out, f.sizes)
-	return out, nil
+	return packsizes.Catalogue{Sizes: out}, nil
 }
 
 func BenchmarkGetPackSizes(b *testing.B) {
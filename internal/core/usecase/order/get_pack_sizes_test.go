@@ -3,22 +3,41 @@ package order
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
 	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
 )
 
 type fakeProvider2 struct {
-	sizes []int
-	err   error
+	sizes    []int
+	version  packsizes.Version
+	packs    []packsizes.Pack
+	source   string
+	loadedAt time.Time
+	degraded bool
+	err      error
 }
 
-func (f *fakeProvider2) List() ([]int, error) {
+func (f *fakeProvider2) Load(ctx context.Context) (packsizes.Catalogue, error) {
+	if err := ctx.Err(); err != nil {
+		return packsizes.Catalogue{}, err
+	}
 	if f.err != nil {
-		return nil, f.err
+		return packsizes.Catalogue{}, f.err
 	}
-	return append([]int(nil), f.sizes...), nil
+	return packsizes.Catalogue{
+		Sizes:    append([]int(nil), f.sizes...),
+		Version:  f.version,
+		Packs:    f.packs,
+		Source:   f.source,
+		LoadedAt: f.loadedAt,
+		Degraded: f.degraded,
+	}, nil
 }
 
 func TestGetPackSizes_Execute(t *testing.T) {
@@ -63,3 +82,103 @@ func TestGetPackSizes_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPackSizes_Version(t *testing.T) {
+	updatedAt := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	// providers that know their version
+	prov := &fakeProvider2{sizes: []int{250, 500}, version: packsizes.Version{ID: "rev-7", UpdatedAt: updatedAt}}
+	ucase, _ := NewGetPackSizes(prov)
+	out, err := ucase.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Version != "rev-7" || !out.UpdatedAt.Equal(updatedAt) {
+		t.Fatalf("unexpected version: %+v", out)
+	}
+
+	prov.err = errors.New("fail")
+	if _, err := ucase.Execute(context.Background()); err == nil {
+		t.Fatalf("expected provider error")
+	}
+
+	// the others: derived from the list, so it follows its changes
+	plain := &fakeProvider2{sizes: []int{250, 500}}
+	ucase, _ = NewGetPackSizes(plain)
+	first, _ := ucase.Execute(context.Background())
+	again, _ := ucase.Execute(context.Background())
+	plain.sizes = []int{250, 500, 1000}
+	changed, _ := ucase.Execute(context.Background())
+	if first.Version == "" || first.Version != again.Version || first.Version == changed.Version {
+		t.Fatalf("derived versions: %q %q %q", first.Version, again.Version, changed.Version)
+	}
+	if !first.UpdatedAt.IsZero() {
+		t.Fatalf("UpdatedAt must be unknown, got %v", first.UpdatedAt)
+	}
+}
+
+func TestGetPackSizes_Packs(t *testing.T) {
+	// without metadata: every listed size, enabled
+	ucase, _ := NewGetPackSizes(&fakeProvider2{sizes: []int{250, 500}})
+	out, err := ucase.Execute(context.Background())
+	want := []uc.PackSize{{Size: 250, Enabled: true}, {Size: 500, Enabled: true}}
+	if err != nil || !reflect.DeepEqual(out.Packs, want) {
+		t.Fatalf("got %+v %v, want %+v", out.Packs, err, want)
+	}
+
+	// with metadata: disabled packs are listed but not in Sizes
+	cost := 0.5
+	prov := &fakeProvider2{
+		sizes: []int{500},
+		packs: []packsizes.Pack{
+			{Size: 250, SKU: "BOX-250"},
+			{Size: 500, Enabled: true, Cost: &cost, Dimensions: &packsizes.Dimensions{Length: 3, Width: 2, Height: 1, Unit: "cm"}},
+		},
+	}
+	ucase, _ = NewGetPackSizes(prov)
+	out, err = ucase.Execute(context.Background())
+	want = []uc.PackSize{
+		{Size: 250, SKU: "BOX-250"},
+		{Size: 500, Enabled: true, Cost: &cost, Dimensions: &uc.PackDimensions{Length: 3, Width: 2, Height: 1, Unit: "cm"}},
+	}
+	if err != nil || !reflect.DeepEqual(out.Sizes, []int{500}) || !reflect.DeepEqual(out.Packs, want) {
+		t.Fatalf("got %+v %v", out, err)
+	}
+
+	prov.err = errors.New("fail")
+	if _, err := ucase.Execute(context.Background()); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
+
+func TestGetPackSizes_Source(t *testing.T) {
+	loadedAt := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	ucase, _ := NewGetPackSizes(&fakeProvider2{sizes: []int{250}, source: "env", loadedAt: loadedAt})
+	out, err := ucase.Execute(context.Background())
+	if err != nil || out.Source != "env" || !out.LoadedAt.Equal(loadedAt) {
+		t.Fatalf("got %+v %v", out, err)
+	}
+
+	// the request context reaches the provider
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ucase.Execute(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestGetPackSizes_Unavailable(t *testing.T) {
+	cause := fmt.Errorf("%w: every source failed", packsizes.ErrUnavailable)
+	ucase, err := NewGetPackSizes(&fakeProvider2{err: cause})
+	if err != nil {
+		t.Fatalf("NewGetPackSizes unexpected error: %v", err)
+	}
+
+	_, err = ucase.Execute(context.Background())
+	if !errors.Is(err, ErrPackSizesUnavailable) || !errors.Is(err, packsizes.ErrUnavailable) {
+		t.Fatalf("got %v, want ErrPackSizesUnavailable wrapping the cause", err)
+	}
+	if apperr.KindOf(err) != apperr.KindUnavailable {
+		t.Fatalf("kind got %v", apperr.KindOf(err))
+	}
+}
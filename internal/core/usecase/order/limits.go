@@ -0,0 +1,92 @@
+package order
+
+import (
+	"context"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+)
+
+// Limit errors carry the exceeded limit in Params:
+// "limit" ("maxQuantity", "maxPackSizes" or "maxDpCells"), "max" and "actual".
+var (
+	ErrQuantityTooLarge    = apperr.New(apperr.KindUnprocessable, "quantity_too_large", "quantity exceeds the configured maximum")
+	ErrTooManyPackSizes    = apperr.New(apperr.KindUnprocessable, "too_many_pack_sizes", "too many distinct pack sizes")
+	ErrCalculationTooLarge = apperr.New(apperr.KindUnprocessable, "calculation_too_large", "quantity is too large for the smallest pack size")
+)
+
+// Limits protects the service from requests that would allocate huge DP
+// tables. A zero field disables the corresponding check.
+// - MaxQuantity: largest accepted quantity
+// - MaxPackSizes: most distinct pack sizes (after removing duplicates)
+// - MaxDPCells: largest table a calculation may allocate: the one the
+// calculator reports (domain.CostEstimator, e.g. a precomputed solver),
+// otherwise the DP one (domain.EstimateDPCells)
+type Limits struct {
+	MaxQuantity  int
+	MaxPackSizes int
+	MaxDPCells   int
+}
+
+// DefaultLimits keeps a single calculation well below 100MB of DP tables.
+var DefaultLimits = Limits{
+	MaxQuantity:  100_000_000,
+	MaxPackSizes: 50,
+	MaxDPCells:   5_000_000,
+}
+
+func limitError(err *apperr.Error, limit string, maximum, actual int) error {
+	return err.With(map[string]any{"limit": limit, "max": maximum, "actual": actual})
+}
+
+func (l Limits) checkQuantity(q int) error {
+	if l.MaxQuantity > 0 && q > l.MaxQuantity {
+		return limitError(ErrQuantityTooLarge, "maxQuantity", l.MaxQuantity, q)
+	}
+	return nil
+}
+
+func (l Limits) checkPackSizes(n int) error {
+	if l.MaxPackSizes > 0 && n > l.MaxPackSizes {
+		return limitError(ErrTooManyPackSizes, "maxPackSizes", l.MaxPackSizes, n)
+	}
+	return nil
+}
+
+func (l Limits) checkDPCells(cells int) error {
+	if l.MaxDPCells > 0 && cells > l.MaxDPCells {
+		return limitError(ErrCalculationTooLarge, "maxDpCells", l.MaxDPCells, cells)
+	}
+	return nil
+}
+
+// Option customizes the CalculatePacks use case.
+type Option func(*calculatePacks)
+
+// WithLimits replaces DefaultLimits.
+func WithLimits(l Limits) Option {
+	return func(c *calculatePacks) { c.limits = l }
+}
+
+// -------- GetLimits use case --------
+
+type getLimits struct {
+	limits Limits
+}
+
+// compile-time check
+var _ uc.GetLimits = (*getLimits)(nil)
+
+func NewGetLimits(limits Limits) uc.GetLimits {
+	return &getLimits{limits: limits}
+}
+
+func (g *getLimits) Execute(ctx context.Context) (uc.GetLimitsOutput, error) {
+	_ = ctx // (no-op for now; kept for future cancellation/telemetry)
+
+	return uc.GetLimitsOutput{
+		MaxQuantity:  g.limits.MaxQuantity,
+		MaxPackSizes: g.limits.MaxPackSizes,
+		MaxDPCells:   g.limits.MaxDPCells,
+	}, nil
+}
@@ -0,0 +1,105 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+)
+
+func TestCalculatePacks_Limits(t *testing.T) {
+	limits := Limits{MaxQuantity: 1_000_000, MaxPackSizes: 3, MaxDPCells: 10_000}
+	prov := &fakeProvider{sizes: []int{250, 500, 1000, 2000, 5000}}
+
+	tests := []struct {
+		name      string
+		input     uc.CalculatePacksInput
+		wantErr   error
+		wantLimit string
+		wantMax   int
+		wantGot   int
+	}{
+		{
+			name:      "quantity above max",
+			input:     uc.CalculatePacksInput{Quantity: 1_000_001, PacksOverride: []int{250}},
+			wantErr:   ErrQuantityTooLarge,
+			wantLimit: "maxQuantity", wantMax: 1_000_000, wantGot: 1_000_001,
+		},
+		{
+			name:      "too many distinct sizes (provider)",
+			input:     uc.CalculatePacksInput{Quantity: 10},
+			wantErr:   ErrTooManyPackSizes,
+			wantLimit: "maxPackSizes", wantMax: 3, wantGot: 5,
+		},
+		{
+			name:    "duplicates do not count",
+			input:   uc.CalculatePacksInput{Quantity: 10, PacksOverride: []int{3, 3, 7, 7, 9}},
+			wantErr: nil,
+		},
+		{
+			name:      "dp table too large for the smallest pack",
+			input:     uc.CalculatePacksInput{Quantity: 20_000, PacksOverride: []int{1, 5000}},
+			wantErr:   ErrCalculationTooLarge,
+			wantLimit: "maxDpCells", wantMax: 10_000, wantGot: 25_000,
+		},
+		{
+			name:    "gcd keeps big quantities cheap",
+			input:   uc.CalculatePacksInput{Quantity: 1_000_000, PacksOverride: []int{250, 5000}},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucase, err := NewCalculatePacks(domain.NewPackCalculator(), prov, WithLimits(limits))
+			if err != nil {
+				t.Fatalf("unexpected NewCalculatePacks error: %v", err)
+			}
+			_, err = ucase.Execute(context.Background(), tt.input)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			ae, ok := apperr.As(err)
+			if !ok || ae.Kind != apperr.KindUnprocessable {
+				t.Fatalf("expected an unprocessable *apperr.Error, got %T (%v)", err, err)
+			}
+			if ae.Params["limit"] != tt.wantLimit || ae.Params["max"] != tt.wantMax || ae.Params["actual"] != tt.wantGot {
+				t.Fatalf("limit params got %+v", ae.Params)
+			}
+		})
+	}
+}
+
+func TestCalculatePacks_ZeroLimitsDisableChecks(t *testing.T) {
+	ucase, err := NewCalculatePacks(domain.NewPackCalculator(), &fakeProvider{sizes: []int{1}}, WithLimits(Limits{}))
+	if err != nil {
+		t.Fatalf("unexpected NewCalculatePacks error: %v", err)
+	}
+	if _, err := ucase.Execute(context.Background(), uc.CalculatePacksInput{Quantity: 6_000_000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetLimits_Execute(t *testing.T) {
+	out, err := NewGetLimits(DefaultLimits).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := uc.GetLimitsOutput{
+		MaxQuantity:  DefaultLimits.MaxQuantity,
+		MaxPackSizes: DefaultLimits.MaxPackSizes,
+		MaxDPCells:   DefaultLimits.MaxDPCells,
+	}
+	if out != want {
+		t.Fatalf("got %+v want %+v", out, want)
+	}
+}
@@ -0,0 +1,73 @@
+package order
+
+import (
+	"context"
+	"errors"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/audit"
+)
+
+// Page size of ListAuditEntries.
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 1000
+)
+
+// ErrInvalidAuditRange carries the rejected "from" and "to" in Params.
+var ErrInvalidAuditRange = apperr.New(apperr.KindInvalid, "invalid_audit_range", "from must be before to")
+
+type listAuditEntries struct {
+	log audit.Log
+}
+
+// compile-time check
+var _ uc.ListAuditEntries = (*listAuditEntries)(nil)
+
+func NewListAuditEntries(log audit.Log) (uc.ListAuditEntries, error) {
+	if log == nil {
+		return nil, errors.New("nil audit.Log")
+	}
+	return &listAuditEntries{log: log}, nil
+}
+
+// Execute returns at most MaxAuditLimit entries.
+func (l *listAuditEntries) Execute(ctx context.Context, in uc.ListAuditEntriesInput) (uc.ListAuditEntriesOutput, error) {
+	if !in.From.IsZero() && !in.To.IsZero() && !in.From.Before(in.To) {
+		return uc.ListAuditEntriesOutput{}, ErrInvalidAuditRange.With(map[string]any{"from": in.From, "to": in.To})
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+	limit = min(limit, MaxAuditLimit)
+	entries, err := l.log.Entries(ctx, audit.Filter{From: in.From, To: in.To, Actor: in.Actor, Action: in.Action, Limit: limit})
+	if err != nil {
+		return uc.ListAuditEntriesOutput{}, err
+	}
+	out := uc.ListAuditEntriesOutput{Entries: make([]uc.AuditEntry, 0, len(entries))}
+	for _, e := range entries {
+		out.Entries = append(out.Entries, uc.AuditEntry{
+			At:        e.At,
+			Action:    e.Action,
+			Actor:     e.Actor,
+			SourceIP:  e.SourceIP,
+			RequestID: e.RequestID,
+			Method:    e.Method,
+			Path:      e.Path,
+			Status:    e.Status,
+			Before:    toAuditPackList(e.Before),
+			After:     toAuditPackList(e.After),
+		})
+	}
+	return out, nil
+}
+
+func toAuditPackList(l *audit.PackList) *uc.AuditPackList {
+	if l == nil {
+		return nil
+	}
+	out := uc.AuditPackList(*l)
+	return &out
+}
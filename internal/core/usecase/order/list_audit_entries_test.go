@@ -0,0 +1,70 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/audit"
+)
+
+// filterAuditLog returns entries and records the filter.
+type filterAuditLog struct {
+	memoryAuditLog
+	filter audit.Filter
+}
+
+func (l *filterAuditLog) Entries(ctx context.Context, f audit.Filter) ([]audit.Entry, error) {
+	l.filter = f
+	return l.memoryAuditLog.Entries(ctx, f)
+}
+
+func TestListAuditEntries_Execute(t *testing.T) {
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	log := &filterAuditLog{memoryAuditLog: memoryAuditLog{entries: []audit.Entry{
+		{At: at, Action: audit.ActionAdminCall, Actor: "alice", Method: "GET", Path: "/v1/audit", Status: 200},
+		{At: at, Action: audit.ActionPackSizeDisabled, Actor: "alice", Before: &audit.PackList{Version: "a", Sizes: []int{250, 500}}},
+	}}}
+	ucase, err := NewListAuditEntries(log)
+	if err != nil {
+		t.Fatalf("NewListAuditEntries: %v", err)
+	}
+	ctx := context.Background()
+
+	in := uc.ListAuditEntriesInput{From: at.Add(-time.Hour), To: at.Add(time.Hour), Actor: "alice"}
+	got, err := ucase.Execute(ctx, in)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	want := uc.ListAuditEntriesOutput{Entries: []uc.AuditEntry{
+		{At: at, Action: "admin.call", Actor: "alice", Method: "GET", Path: "/v1/audit", Status: 200},
+		{At: at, Action: "packsizes.disable", Actor: "alice", Before: &uc.AuditPackList{Version: "a", Sizes: []int{250, 500}}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v want %+v", got, want)
+	}
+	if want := (audit.Filter{From: in.From, To: in.To, Actor: "alice", Limit: DefaultAuditLimit}); !reflect.DeepEqual(log.filter, want) {
+		t.Fatalf("filter got %+v want %+v", log.filter, want)
+	}
+
+	_, _ = ucase.Execute(ctx, uc.ListAuditEntriesInput{Limit: 1 << 20})
+	if log.filter.Limit != MaxAuditLimit {
+		t.Fatalf("limit got %d want %d", log.filter.Limit, MaxAuditLimit)
+	}
+
+	if _, err := ucase.Execute(ctx, uc.ListAuditEntriesInput{From: at, To: at}); !errors.Is(err, ErrInvalidAuditRange) {
+		t.Fatalf("empty range: got %v", err)
+	}
+
+	log.err = errors.New("fail")
+	if _, err := ucase.Execute(ctx, uc.ListAuditEntriesInput{}); err == nil {
+		t.Fatalf("expected the log error")
+	}
+
+	if _, err := NewListAuditEntries(nil); err == nil {
+		t.Fatalf("expected error for a nil log")
+	}
+}
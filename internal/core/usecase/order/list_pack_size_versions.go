@@ -0,0 +1,50 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+type listPackSizeVersions struct {
+	provider packsizes.Provider
+}
+
+// compile-time check
+var _ uc.ListPackSizeVersions = (*listPackSizeVersions)(nil)
+
+func NewListPackSizeVersions(provider packsizes.Provider) (uc.ListPackSizeVersions, error) {
+	if provider == nil {
+		return nil, errors.New("nil packsizes.Provider")
+	}
+	return &listPackSizeVersions{provider: provider}, nil
+}
+
+// Execute lists the provider history; a provider without history has one
+// version, the current list, in effect since always.
+func (l *listPackSizeVersions) Execute(ctx context.Context) (uc.ListPackSizeVersionsOutput, error) {
+	current, err := catalogueAt(ctx, l.provider, time.Time{})
+	if err != nil {
+		return uc.ListPackSizeVersionsOutput{}, err
+	}
+	history, ok := l.provider.(packsizes.History)
+	if !ok {
+		return uc.ListPackSizeVersionsOutput{
+			Versions: []uc.PackSizeVersion{{Version: current.Version, Sizes: current.Sizes}},
+			Current:  current.Version,
+		}, nil
+	}
+
+	sets, err := history.Versions(ctx)
+	if err != nil {
+		return uc.ListPackSizeVersionsOutput{}, err
+	}
+	out := uc.ListPackSizeVersionsOutput{Versions: make([]uc.PackSizeVersion, 0, len(sets)), Current: current.Version}
+	for _, s := range sets {
+		out.Versions = append(out.Versions, uc.PackSizeVersion{Version: s.Version, EffectiveFrom: s.EffectiveFrom, Sizes: s.Sizes})
+	}
+	return out, nil
+}
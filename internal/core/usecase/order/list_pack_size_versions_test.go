@@ -0,0 +1,64 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+func TestListPackSizeVersions_Execute(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider packsizes.Provider
+		want     uc.ListPackSizeVersionsOutput
+		wantErr  bool
+	}{
+		{
+			name:     "history",
+			provider: newHistoryProvider(),
+			want: uc.ListPackSizeVersionsOutput{
+				Versions: []uc.PackSizeVersion{
+					{Version: "v1", EffectiveFrom: jan, Sizes: []int{250, 500, 1000}},
+					{Version: "v2", EffectiveFrom: jun, Sizes: []int{300, 600}},
+				},
+				Current: "v2",
+			},
+		},
+		{
+			name:     "single list",
+			provider: &fakeProvider2{sizes: []int{250, 500}},
+			want: uc.ListPackSizeVersionsOutput{
+				Versions: []uc.PackSizeVersion{{Version: packsizes.VersionOf([]int{250, 500}), Sizes: []int{250, 500}}},
+				Current:  packsizes.VersionOf([]int{250, 500}),
+			},
+		},
+		{
+			name:     "provider error",
+			provider: &fakeProvider2{err: errors.New("fail")},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucase, err := NewListPackSizeVersions(tt.provider)
+			if err != nil {
+				t.Fatalf("NewListPackSizeVersions: %v", err)
+			}
+			got, err := ucase.Execute(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error got=%v wantErr=%v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewListPackSizeVersions(nil); err == nil {
+		t.Fatalf("expected error for nil provider")
+	}
+}
@@ -0,0 +1,55 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+type listUpcomingPackSizes struct {
+	provider packsizes.Provider
+	now      func() time.Time
+}
+
+// compile-time check
+var _ uc.ListUpcomingPackSizes = (*listUpcomingPackSizes)(nil)
+
+// NewListUpcomingPackSizes: now is the clock (nil = time.Now).
+func NewListUpcomingPackSizes(provider packsizes.Provider, now func() time.Time) (uc.ListUpcomingPackSizes, error) {
+	if provider == nil {
+		return nil, errors.New("nil packsizes.Provider")
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &listUpcomingPackSizes{provider: provider, now: now}, nil
+}
+
+// Execute lists the sets that are not in effect yet; a provider without
+// history has none.
+func (l *listUpcomingPackSizes) Execute(ctx context.Context) (uc.ListUpcomingPackSizesOutput, error) {
+	current, err := catalogueAt(ctx, l.provider, time.Time{})
+	if err != nil {
+		return uc.ListUpcomingPackSizesOutput{}, err
+	}
+	out := uc.ListUpcomingPackSizesOutput{Current: current.Version, Upcoming: []uc.PackSizeVersion{}}
+	history, ok := l.provider.(packsizes.History)
+	if !ok {
+		return out, nil
+	}
+
+	sets, err := history.Versions(ctx)
+	if err != nil {
+		return uc.ListUpcomingPackSizesOutput{}, err
+	}
+	now := l.now()
+	for _, s := range sets {
+		if s.EffectiveFrom.After(now) {
+			out.Upcoming = append(out.Upcoming, uc.PackSizeVersion{Version: s.Version, EffectiveFrom: s.EffectiveFrom, Sizes: s.Sizes})
+		}
+	}
+	return out, nil
+}
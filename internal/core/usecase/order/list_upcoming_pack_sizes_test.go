@@ -0,0 +1,62 @@
+package order
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+func TestListUpcomingPackSizes_Execute(t *testing.T) {
+	aug := jun.AddDate(0, 2, 0)
+	clock := &fakeClock{t: jan.AddDate(0, 1, 0)} // v1 in effect, v2 upcoming
+	prov := newSchedulingProvider(clock)
+	if err := prov.Schedule(context.Background(), packsizes.PackSet{Version: "v3", EffectiveFrom: aug, Sizes: []int{700}}); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	ucase, err := NewListUpcomingPackSizes(prov, clock.Now)
+	if err != nil {
+		t.Fatalf("NewListUpcomingPackSizes: %v", err)
+	}
+
+	steps := []struct {
+		at   time.Time
+		want uc.ListUpcomingPackSizesOutput
+	}{
+		{clock.Now(), uc.ListUpcomingPackSizesOutput{Current: "v1", Upcoming: []uc.PackSizeVersion{
+			{Version: "v2", EffectiveFrom: jun, Sizes: []int{300, 600}},
+			{Version: "v3", EffectiveFrom: aug, Sizes: []int{700}},
+		}}},
+		{jun, uc.ListUpcomingPackSizesOutput{Current: "v2", Upcoming: []uc.PackSizeVersion{
+			{Version: "v3", EffectiveFrom: aug, Sizes: []int{700}},
+		}}},
+		{aug, uc.ListUpcomingPackSizesOutput{Current: "v3", Upcoming: []uc.PackSizeVersion{}}},
+	}
+	for _, s := range steps {
+		clock.t = s.at
+		got, err := ucase.Execute(context.Background())
+		if err != nil {
+			t.Fatalf("at %s: %v", s.at, err)
+		}
+		if !reflect.DeepEqual(got, s.want) {
+			t.Fatalf("at %s:\n got=%+v\nwant=%+v", s.at, got, s.want)
+		}
+	}
+}
+
+func TestListUpcomingPackSizes_WithoutHistory(t *testing.T) {
+	ucase, err := NewListUpcomingPackSizes(&fakeProvider2{sizes: []int{250}}, nil)
+	if err != nil {
+		t.Fatalf("NewListUpcomingPackSizes: %v", err)
+	}
+	got, err := ucase.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Current != packsizes.VersionOf([]int{250}) || len(got.Upcoming) != 0 {
+		t.Fatalf("got=%+v", got)
+	}
+}
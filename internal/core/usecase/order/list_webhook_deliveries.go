@@ -0,0 +1,47 @@
+package order
+
+import (
+	"context"
+	"errors"
+
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/events"
+)
+
+// Page size of ListWebhookDeliveries.
+const (
+	DefaultDeliveriesLimit = 50
+	MaxDeliveriesLimit     = 500
+)
+
+type listWebhookDeliveries struct {
+	log events.DeliveryLog
+}
+
+// compile-time check
+var _ uc.ListWebhookDeliveries = (*listWebhookDeliveries)(nil)
+
+func NewListWebhookDeliveries(log events.DeliveryLog) (uc.ListWebhookDeliveries, error) {
+	if log == nil {
+		return nil, errors.New("nil events.DeliveryLog")
+	}
+	return &listWebhookDeliveries{log: log}, nil
+}
+
+// Execute returns at most MaxDeliveriesLimit attempts.
+func (l *listWebhookDeliveries) Execute(ctx context.Context, in uc.ListWebhookDeliveriesInput) (uc.ListWebhookDeliveriesOutput, error) {
+	limit := in.Limit
+	if limit <= 0 {
+		limit = DefaultDeliveriesLimit
+	}
+	limit = min(limit, MaxDeliveriesLimit)
+	deliveries, err := l.log.Deliveries(ctx, events.DeliveryFilter{EventID: in.EventID, Status: in.Status, Limit: limit})
+	if err != nil {
+		return uc.ListWebhookDeliveriesOutput{}, err
+	}
+	out := uc.ListWebhookDeliveriesOutput{Deliveries: make([]uc.WebhookDelivery, 0, len(deliveries))}
+	for _, d := range deliveries {
+		out.Deliveries = append(out.Deliveries, uc.WebhookDelivery(d))
+	}
+	return out, nil
+}
@@ -0,0 +1,65 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/events"
+)
+
+// fakeDeliveryLog returns deliveries and records the filter.
+type fakeDeliveryLog struct {
+	deliveries []events.Delivery
+	err        error
+	filter     events.DeliveryFilter
+}
+
+func (f *fakeDeliveryLog) Deliveries(_ context.Context, filter events.DeliveryFilter) ([]events.Delivery, error) {
+	f.filter = filter
+	return f.deliveries, f.err
+}
+
+func TestListWebhookDeliveries_Execute(t *testing.T) {
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	log := &fakeDeliveryLog{deliveries: []events.Delivery{{
+		EventID: "evt-1", EventType: events.TypePackSizesChanged, Endpoint: "https://hooks.example.com/packs",
+		Attempt: 2, At: at, Duration: 120 * time.Millisecond, Status: events.DeliveryDelivered, StatusCode: 204,
+	}}}
+	ucase, err := NewListWebhookDeliveries(log)
+	if err != nil {
+		t.Fatalf("NewListWebhookDeliveries: %v", err)
+	}
+
+	got, err := ucase.Execute(context.Background(), uc.ListWebhookDeliveriesInput{EventID: "evt-1", Status: "delivered"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	want := uc.ListWebhookDeliveriesOutput{Deliveries: []uc.WebhookDelivery{{
+		EventID: "evt-1", EventType: "packsizes.changed", Endpoint: "https://hooks.example.com/packs",
+		Attempt: 2, At: at, Duration: 120 * time.Millisecond, Status: "delivered", StatusCode: 204,
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v want %+v", got, want)
+	}
+	if want := (events.DeliveryFilter{EventID: "evt-1", Status: "delivered", Limit: DefaultDeliveriesLimit}); log.filter != want {
+		t.Fatalf("filter got %+v want %+v", log.filter, want)
+	}
+
+	_, _ = ucase.Execute(context.Background(), uc.ListWebhookDeliveriesInput{Limit: 10_000})
+	if log.filter.Limit != MaxDeliveriesLimit {
+		t.Fatalf("limit got %d want %d", log.filter.Limit, MaxDeliveriesLimit)
+	}
+
+	log.err = errors.New("fail")
+	if _, err := ucase.Execute(context.Background(), uc.ListWebhookDeliveriesInput{}); err == nil {
+		t.Fatalf("expected the log error")
+	}
+
+	if _, err := NewListWebhookDeliveries(nil); err == nil {
+		t.Fatalf("expected error for a nil log")
+	}
+}
@@ -0,0 +1,105 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// ErrInvalidSchedule carries one violation per rejected field;
+// ErrScheduleConflict carries the "version" in Params.
+var (
+	ErrInvalidSchedule  = apperr.New(apperr.KindInvalid, "invalid_schedule", "invalid pack sizes schedule")
+	ErrScheduleConflict = apperr.New(apperr.KindConflict, "schedule_conflict", "the catalogue already has this version or effective time")
+)
+
+// versionPattern keeps names safe to write back in the provider file.
+var versionPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+type schedulePackSizes struct {
+	scheduler packsizes.Scheduler
+	now       func() time.Time
+}
+
+// compile-time check
+var _ uc.SchedulePackSizes = (*schedulePackSizes)(nil)
+
+// NewSchedulePackSizes needs a provider implementing packsizes.Scheduler;
+// now is the clock (nil = time.Now).
+func NewSchedulePackSizes(provider packsizes.Provider, now func() time.Time) (uc.SchedulePackSizes, error) {
+	if provider == nil {
+		return nil, errors.New("nil packsizes.Provider")
+	}
+	scheduler, ok := provider.(packsizes.Scheduler)
+	if !ok {
+		return nil, errors.New("the packsizes.Provider does not accept scheduled versions")
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &schedulePackSizes{scheduler: scheduler, now: now}, nil
+}
+
+func (s *schedulePackSizes) Execute(ctx context.Context, in uc.SchedulePackSizesInput) (uc.PackSizeVersion, error) {
+	set, err := s.validate(in)
+	if err != nil {
+		return uc.PackSizeVersion{}, err
+	}
+	if err := s.scheduler.Schedule(ctx, set); err != nil {
+		if errors.Is(err, packsizes.ErrScheduleConflict) {
+			return uc.PackSizeVersion{}, ErrScheduleConflict.With(map[string]any{"version": set.Version}).Wrap(err)
+		}
+		return uc.PackSizeVersion{}, err
+	}
+	return uc.PackSizeVersion{Version: set.Version, EffectiveFrom: set.EffectiveFrom, Sizes: set.Sizes}, nil
+}
+
+// validate reports every rejected field at once and normalises the set.
+func (s *schedulePackSizes) validate(in uc.SchedulePackSizesInput) (packsizes.PackSet, error) {
+	var invalid *apperr.Error
+	reject := func(field, reason string) {
+		if invalid == nil {
+			invalid = ErrInvalidSchedule
+		}
+		invalid = invalid.WithViolation(field, reason)
+	}
+
+	if in.Version != "" && !versionPattern.MatchString(in.Version) {
+		reject("version", "must be 1 to 64 letters, digits or . _ : -")
+	}
+	if !in.EffectiveFrom.After(s.now()) {
+		reject("effectiveFrom", "must be in the future")
+	}
+	if len(in.Sizes) == 0 {
+		reject("sizes", "must not be empty")
+	}
+	seen := make(map[int]struct{}, len(in.Sizes))
+	sizes := make([]int, 0, len(in.Sizes))
+	for i, v := range in.Sizes {
+		if v <= 0 {
+			reject(fmt.Sprintf("sizes[%d]", i), fmt.Sprintf("must be > 0, got %d", v))
+			continue
+		}
+		if _, dup := seen[v]; !dup {
+			seen[v] = struct{}{}
+			sizes = append(sizes, v)
+		}
+	}
+	if invalid != nil {
+		return packsizes.PackSet{}, invalid
+	}
+
+	sort.Ints(sizes)
+	version := in.Version
+	if version == "" {
+		version = packsizes.VersionOf(sizes)
+	}
+	return packsizes.PackSet{Version: version, EffectiveFrom: in.EffectiveFrom.UTC(), Sizes: sizes}, nil
+}
@@ -0,0 +1,144 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// fakeClock is a manual clock.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.t }
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// schedulingProvider resolves its sets with the clock, like the file provider.
+type schedulingProvider struct {
+	historyProvider
+	clock *fakeClock
+}
+
+func newSchedulingProvider(clock *fakeClock) *schedulingProvider {
+	return &schedulingProvider{historyProvider: *newHistoryProvider(), clock: clock}
+}
+
+func (p *schedulingProvider) Load(ctx context.Context) (packsizes.Catalogue, error) {
+	set, err := p.At(ctx, p.clock.Now())
+	return packsizes.Catalogue{Sizes: set.Sizes, Version: packsizes.Version{ID: set.Version}}, err
+}
+
+func (p *schedulingProvider) Schedule(_ context.Context, set packsizes.PackSet) error {
+	for _, s := range p.sets {
+		if s.Version == set.Version || s.EffectiveFrom.Equal(set.EffectiveFrom) {
+			return packsizes.ErrScheduleConflict
+		}
+	}
+	p.sets = append(p.sets, set)
+	sort.Slice(p.sets, func(i, j int) bool { return p.sets[i].EffectiveFrom.Before(p.sets[j].EffectiveFrom) })
+	return nil
+}
+
+func (p *schedulingProvider) Unschedule(_ context.Context, version string) error {
+	for i, s := range p.sets {
+		if s.Version == version && s.EffectiveFrom.After(p.clock.Now()) {
+			p.sets = append(p.sets[:i], p.sets[i+1:]...)
+			return nil
+		}
+	}
+	return packsizes.ErrNotScheduled
+}
+
+func TestNewSchedulePackSizes_NeedsAScheduler(t *testing.T) {
+	if _, err := NewSchedulePackSizes(newHistoryProvider(), nil); err == nil {
+		t.Fatalf("expected an error for a provider without Schedule")
+	}
+	if _, err := NewSchedulePackSizes(nil, nil); err == nil {
+		t.Fatalf("expected an error for a nil provider")
+	}
+}
+
+func TestSchedulePackSizes_Execute(t *testing.T) {
+	now := jun.Add(24 * time.Hour)
+	later := now.Add(30 * 24 * time.Hour)
+
+	tests := []struct {
+		name           string
+		in             uc.SchedulePackSizesInput
+		want           uc.PackSizeVersion
+		wantErr        error
+		wantViolations []string
+	}{
+		{
+			name: "named",
+			in:   uc.SchedulePackSizesInput{Version: "2025-08", EffectiveFrom: later, Sizes: []int{1000, 500, 500}},
+			want: uc.PackSizeVersion{Version: "2025-08", EffectiveFrom: later, Sizes: []int{500, 1000}},
+		},
+		{
+			name: "version derived from the sizes, time in UTC",
+			in:   uc.SchedulePackSizesInput{EffectiveFrom: later.In(time.FixedZone("BRT", -3*3600)), Sizes: []int{700}},
+			want: uc.PackSizeVersion{Version: packsizes.VersionOf([]int{700}), EffectiveFrom: later, Sizes: []int{700}},
+		},
+		{
+			name:           "every field rejected",
+			in:             uc.SchedulePackSizesInput{Version: "has space", EffectiveFrom: now, Sizes: []int{250, 0, -1}},
+			wantErr:        ErrInvalidSchedule,
+			wantViolations: []string{"version", "effectiveFrom", "sizes[1]", "sizes[2]"},
+		},
+		{
+			name:           "no sizes",
+			in:             uc.SchedulePackSizesInput{EffectiveFrom: later},
+			wantErr:        ErrInvalidSchedule,
+			wantViolations: []string{"sizes"},
+		},
+		{
+			name:    "known version",
+			in:      uc.SchedulePackSizesInput{Version: "v2", EffectiveFrom: later, Sizes: []int{700}},
+			wantErr: ErrScheduleConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{t: now}
+			prov := newSchedulingProvider(clock)
+			ucase, err := NewSchedulePackSizes(prov, clock.Now)
+			if err != nil {
+				t.Fatalf("NewSchedulePackSizes: %v", err)
+			}
+
+			got, err := ucase.Execute(context.Background(), tt.in)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				e, _ := apperr.As(err)
+				var fields []string
+				for _, v := range e.Violations {
+					fields = append(fields, v.Field)
+				}
+				if !reflect.DeepEqual(fields, tt.wantViolations) {
+					t.Fatalf("violations got=%v want=%v", fields, tt.wantViolations)
+				}
+				if len(prov.sets) != 2 {
+					t.Fatalf("nothing must be scheduled on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got=%+v want=%+v", got, tt.want)
+			}
+			if last := prov.sets[len(prov.sets)-1]; last.Version != tt.want.Version {
+				t.Fatalf("the set was not scheduled: %+v", prov.sets)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package order
+
+import (
+	"context"
+	"errors"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// ErrUnknownPackSize and ErrLastEnabledPackSize carry the "size" in Params.
+var (
+	ErrUnknownPackSize     = apperr.New(apperr.KindNotFound, "unknown_pack_size", "the current catalogue has no such pack size")
+	ErrLastEnabledPackSize = apperr.New(apperr.KindConflict, "last_enabled_pack_size", "the last enabled pack size cannot be disabled")
+)
+
+type setPackSizeEnabled struct {
+	toggler packsizes.Toggler
+}
+
+// compile-time check
+var _ uc.SetPackSizeEnabled = (*setPackSizeEnabled)(nil)
+
+// NewSetPackSizeEnabled needs a provider implementing packsizes.Toggler.
+func NewSetPackSizeEnabled(provider packsizes.Provider) (uc.SetPackSizeEnabled, error) {
+	if provider == nil {
+		return nil, errors.New("nil packsizes.Provider")
+	}
+	toggler, ok := provider.(packsizes.Toggler)
+	if !ok {
+		return nil, errors.New("the packsizes.Provider cannot enable or disable pack sizes")
+	}
+	return &setPackSizeEnabled{toggler: toggler}, nil
+}
+
+// Execute is idempotent: setting the status a pack already has changes
+// nothing.
+func (s *setPackSizeEnabled) Execute(ctx context.Context, in uc.SetPackSizeEnabledInput) (uc.PackSize, error) {
+	params := map[string]any{"size": in.Size}
+	if in.Size <= 0 {
+		return uc.PackSize{}, ErrUnknownPackSize.With(params)
+	}
+	pack, err := s.toggler.SetEnabled(ctx, in.Size, in.Enabled)
+	switch {
+	case errors.Is(err, packsizes.ErrUnknownPackSize):
+		return uc.PackSize{}, ErrUnknownPackSize.With(params).Wrap(err)
+	case errors.Is(err, packsizes.ErrLastEnabledPack):
+		return uc.PackSize{}, ErrLastEnabledPackSize.With(params).Wrap(err)
+	case err != nil:
+		return uc.PackSize{}, err
+	}
+	return toPackSize(pack), nil
+}
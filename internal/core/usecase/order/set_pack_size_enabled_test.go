@@ -0,0 +1,121 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/reangeline/go-shipping-products/internal/core/apperr"
+	domain "github.com/reangeline/go-shipping-products/internal/core/domain/order"
+	uc "github.com/reangeline/go-shipping-products/internal/core/ports/inbound/order"
+	"github.com/reangeline/go-shipping-products/internal/core/ports/outbound/packsizes"
+)
+
+// togglingProvider keeps the packs in memory, as the file provider does.
+type togglingProvider struct {
+	packs []packsizes.Pack
+	err   error
+}
+
+func (p *togglingProvider) Load(context.Context) (packsizes.Catalogue, error) {
+	return packsizes.Catalogue{Sizes: packsizes.EnabledSizes(p.packs), Packs: p.packs}, nil
+}
+
+func (p *togglingProvider) SetEnabled(_ context.Context, size int, enabled bool) (packsizes.Pack, error) {
+	if p.err != nil {
+		return packsizes.Pack{}, p.err
+	}
+	for i := range p.packs {
+		if p.packs[i].Size != size {
+			continue
+		}
+		if !enabled && len(packsizes.EnabledSizes(p.packs)) == 1 && p.packs[i].Enabled {
+			return packsizes.Pack{}, packsizes.ErrLastEnabledPack
+		}
+		p.packs[i].Enabled = enabled
+		return p.packs[i], nil
+	}
+	return packsizes.Pack{}, packsizes.ErrUnknownPackSize
+}
+
+func TestNewSetPackSizeEnabled(t *testing.T) {
+	if _, err := NewSetPackSizeEnabled(nil); err == nil {
+		t.Fatalf("expected an error for a nil provider")
+	}
+	if _, err := NewSetPackSizeEnabled(&fakeProvider2{}); err == nil {
+		t.Fatalf("expected an error for a provider without Toggler")
+	}
+}
+
+func TestSetPackSizeEnabled_Execute(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       uc.SetPackSizeEnabledInput
+		err      error
+		want     uc.PackSize
+		wantCode string
+		wantKind apperr.Kind
+	}{
+		{name: "disable", in: uc.SetPackSizeEnabledInput{Size: 250}, want: uc.PackSize{Size: 250, Label: "Small"}},
+		{name: "enable, already enabled", in: uc.SetPackSizeEnabledInput{Size: 500, Enabled: true}, want: uc.PackSize{Size: 500, Enabled: true}},
+		{name: "unknown size", in: uc.SetPackSizeEnabledInput{Size: 42}, wantCode: "unknown_pack_size", wantKind: apperr.KindNotFound},
+		{name: "not a size", in: uc.SetPackSizeEnabledInput{Size: -1}, wantCode: "unknown_pack_size", wantKind: apperr.KindNotFound},
+		{name: "provider error", in: uc.SetPackSizeEnabledInput{Size: 250}, err: errors.New("disk full"), wantKind: apperr.KindInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := &togglingProvider{packs: []packsizes.Pack{{Size: 250, Label: "Small", Enabled: true}, {Size: 500, Enabled: true}}, err: tt.err}
+			ucase, err := NewSetPackSizeEnabled(prov)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := ucase.Execute(context.Background(), tt.in)
+			if tt.wantKind != 0 || tt.err != nil {
+				if apperr.KindOf(err) != tt.wantKind {
+					t.Fatalf("kind got %v want %v (%v)", apperr.KindOf(err), tt.wantKind, err)
+				}
+				var ae *apperr.Error
+				if tt.wantCode != "" && (!errors.As(err, &ae) || ae.Code != tt.wantCode || ae.Params["size"] != tt.in.Size) {
+					t.Fatalf("error got %v, want code %s with the size", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetPackSizeEnabled_LastEnabled(t *testing.T) {
+	prov := &togglingProvider{packs: []packsizes.Pack{{Size: 250}, {Size: 500, Enabled: true}}}
+	ucase, _ := NewSetPackSizeEnabled(prov)
+	_, err := ucase.Execute(context.Background(), uc.SetPackSizeEnabledInput{Size: 500})
+	var ae *apperr.Error
+	if !errors.As(err, &ae) || ae.Code != "last_enabled_pack_size" || ae.Kind != apperr.KindConflict {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestCalculatePacks_OnlyEnabledSizes(t *testing.T) {
+	prov := &togglingProvider{packs: packsizes.PlainPacks([]int{250, 500, 1000})}
+	calc, _ := NewCalculatePacks(domain.NewPackCalculator(), prov)
+	toggle, _ := NewSetPackSizeEnabled(prov)
+
+	if _, err := toggle.Execute(context.Background(), uc.SetPackSizeEnabledInput{Size: 250}); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	out, err := calc.Execute(context.Background(), uc.CalculatePacksInput{Quantity: 250})
+	if err != nil || !reflect.DeepEqual(out.ItemsByPack, map[int]int{500: 1}) {
+		t.Fatalf("with 250 disabled got %+v %v", out.ItemsByPack, err)
+	}
+
+	if _, err := toggle.Execute(context.Background(), uc.SetPackSizeEnabledInput{Size: 250, Enabled: true}); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	out, err = calc.Execute(context.Background(), uc.CalculatePacksInput{Quantity: 250})
+	if err != nil || !reflect.DeepEqual(out.ItemsByPack, map[int]int{250: 1}) {
+		t.Fatalf("with 250 enabled got %+v %v", out.ItemsByPack, err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package web ships the React build (web/dist) inside the API binary.
+//
+// The assets are only embedded when building with the embedweb tag, after
+// `npm run build`:
+//
+//	go build -tags embedweb ./cmd/api
+//
+// Without the tag (tests, `go run`) Dist reports no assets and the API can
+// still serve a directory (WEB_DIR).
+package web
@@ -0,0 +1,8 @@
+//go:build !embedweb
+
+package web
+
+import "io/fs"
+
+// Dist reports that the binary was built without the frontend.
+func Dist() (fs.FS, bool) { return nil, false }